@@ -12,7 +12,8 @@ import (
 
 // BookkeepingAccountApi 结构体定义了账户管理的API处理器
 type BookkeepingAccountApi struct {
-	Service service.BookkeepingAccountService
+	Service        service.BookkeepingAccountService
+	BalanceService service.BookkeepingBalanceService
 }
 
 // CreateAccount godoc
@@ -56,6 +57,7 @@ func (a *BookkeepingAccountApi) CreateAccount(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param   x-token header string true "令牌"
+// @Param   include_archived query bool false "是否包含已归档的账户，默认false"
 // @Success 200 {object} response.Response{data=[]dto.AccountResponse,msg=string} "获取成功"
 // @Failure 500 {object} response.Response{msg=string} "服务器内部错误"
 // @Router /bk/accounts [get]
@@ -66,7 +68,9 @@ func (a *BookkeepingAccountApi) ListAccounts(c *gin.Context) {
 		return
 	}
 
-	accounts, err := a.Service.ListAccounts(userID)
+	includeArchived, _ := strconv.ParseBool(c.DefaultQuery("include_archived", "false"))
+
+	accounts, err := a.Service.ListAccounts(userID, includeArchived)
 	if err != nil {
 		response.FailWithMessage(c, "获取账户列表失败: "+err.Error())
 		return
@@ -186,3 +190,233 @@ func (a *BookkeepingAccountApi) DeleteAccount(c *gin.Context) {
 
 	response.OkWithMessage(c, "删除账户成功")
 }
+
+// ArchiveAccount godoc
+// @Tags BookkeepingAccount
+// @Summary 归档（关闭）账户
+// @Description 归档指定ID的账户，归档后默认不在列表中显示且不能记录新交易，历史交易仍参与统计
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path int true "账户ID"
+// @Param   archive_info body dto.ArchiveAccountRequest false "归档信息"
+// @Success 200 {object} response.Response{data=dto.AccountResponse,msg=string} "归档成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "账户不存在"
+// @Router /bk/accounts/{id}/archive [post]
+func (a *BookkeepingAccountApi) ArchiveAccount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.FailWithMessage(c, "无效的账户ID")
+		return
+	}
+
+	var req dto.ArchiveAccountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.FailWithMessage(c, "请求参数错误: "+utils.GetErrorMsg(req, err))
+			return
+		}
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	account, err := a.Service.ArchiveAccount(userID, uint(id), req)
+	if err != nil {
+		response.FailWithMessage(c, "归档账户失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, account)
+}
+
+// ReopenAccount godoc
+// @Tags BookkeepingAccount
+// @Summary 重新启用账户
+// @Description 重新启用已归档的账户
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path int true "账户ID"
+// @Success 200 {object} response.Response{data=dto.AccountResponse,msg=string} "启用成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "账户不存在"
+// @Router /bk/accounts/{id}/reopen [post]
+func (a *BookkeepingAccountApi) ReopenAccount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.FailWithMessage(c, "无效的账户ID")
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	account, err := a.Service.ReopenAccount(userID, uint(id))
+	if err != nil {
+		response.FailWithMessage(c, "重新启用账户失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, account)
+}
+
+// GetAccountBalance godoc
+// @Tags BookkeepingAccount
+// @Summary 获取账户历史余额
+// @Description 获取指定账户在某一天结束时的余额
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path int true "账户ID"
+// @Param   date query string false "日期 (YYYY-MM-DD)，默认今天"
+// @Success 200 {object} response.Response{data=dto.AccountBalanceResponse,msg=string} "获取成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "账户不存在"
+// @Router /bk/accounts/{id}/balance [get]
+func (a *BookkeepingAccountApi) GetAccountBalance(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.FailWithMessage(c, "无效的账户ID")
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	balance, err := a.BalanceService.GetBalanceAt(userID, uint(id), c.Query("date"))
+	if err != nil {
+		response.FailWithMessage(c, "获取账户历史余额失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, balance)
+}
+
+// GetAccountBalanceHistory godoc
+// @Tags BookkeepingAccount
+// @Summary 获取账户余额历史
+// @Description 获取指定账户在一段时间内按日或按月的余额序列
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path int true "账户ID"
+// @Param   start_date query string false "开始日期 (YYYY-MM-DD)，默认结束日期前30天"
+// @Param   end_date query string false "结束日期 (YYYY-MM-DD)，默认今天"
+// @Param   interval query string false "粒度 (day, month)，默认day"
+// @Success 200 {object} response.Response{data=dto.AccountBalanceHistoryResponse,msg=string} "获取成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "账户不存在"
+// @Router /bk/accounts/{id}/balance-history [get]
+func (a *BookkeepingAccountApi) GetAccountBalanceHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.FailWithMessage(c, "无效的账户ID")
+		return
+	}
+
+	var query dto.BalanceHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.FailWithMessage(c, "请求参数错误: "+utils.GetErrorMsg(query, err))
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	history, err := a.BalanceService.GetBalanceHistory(userID, uint(id), query)
+	if err != nil {
+		response.FailWithMessage(c, "获取账户余额历史失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, history)
+}
+
+// RecalculateAccountBalance godoc
+// @Tags BookkeepingAccount
+// @Summary 重新计算账户余额
+// @Description 按初始余额和全部交易重新计算账户的当前余额，用于校正余额
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path int true "账户ID"
+// @Success 200 {object} response.Response{data=dto.RecalculateBalanceResponse,msg=string} "重算成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "账户不存在"
+// @Router /bk/accounts/{id}/recalculate [post]
+func (a *BookkeepingAccountApi) RecalculateAccountBalance(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.FailWithMessage(c, "无效的账户ID")
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	result, err := a.Service.RecalculateBalance(userID, uint(id))
+	if err != nil {
+		response.FailWithMessage(c, "重新计算余额失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, result)
+}
+
+// MergeAccount godoc
+// @Tags BookkeepingAccount
+// @Summary 合并账户
+// @Description 将指定账户合并到目标账户：迁移全部交易及关联记录，按合并后的交易重算目标账户余额，源账户归档或删除。dry_run为true时只返回合并预览
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path int true "源账户ID"
+// @Param   merge_info body dto.MergeAccountRequest true "合并信息"
+// @Success 200 {object} response.Response{data=dto.MergeAccountResponse,msg=string} "合并成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "账户不存在"
+// @Router /bk/accounts/{id}/merge [post]
+func (a *BookkeepingAccountApi) MergeAccount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.FailWithMessage(c, "无效的账户ID")
+		return
+	}
+
+	var req dto.MergeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "请求参数错误: "+utils.GetErrorMsg(req, err))
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	result, err := a.Service.MergeAccount(userID, uint(id), req)
+	if err != nil {
+		response.FailWithMessage(c, "合并账户失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, result)
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingBillApi 账单与付款提醒相关API
+type BookkeepingBillApi struct {
+	billService service.BookkeepingBillService
+}
+
+// @Summary 创建账单
+// @Description 创建周期性账单 (房租、水电、保险等)，可以是固定金额或预估金额
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateBillRequest true "账单信息"
+// @Success 200 {object} dto.BillResponse
+// @Router /bk/bills [post]
+func (api *BookkeepingBillApi) CreateBill(c *gin.Context) {
+	var req dto.CreateBillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务创建账单
+	result, err := api.billService.CreateBill(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取账单列表
+// @Description 获取当前用户的账单列表，按下一期到期日期升序
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param is_active query bool false "是否启用"
+// @Success 200 {array} dto.BillResponse
+// @Router /bk/bills [get]
+func (api *BookkeepingBillApi) ListBills(c *gin.Context) {
+	var query dto.BillQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取账单列表
+	result, err := api.billService.ListBills(userId, query)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取即将付款列表
+// @Description 获取未来若干天内到期的账单、贷款还款和借入还款，包括所有已逾期未付的项目，按到期日期升序
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param days query int false "未来天数，默认30，最多366"
+// @Success 200 {object} dto.UpcomingPaymentsResponse
+// @Router /bk/bills/upcoming [get]
+func (api *BookkeepingBillApi) ListUpcoming(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 0 || days > 366 {
+		utils.ErrorWithMsg(c, "无效的天数")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取即将付款列表
+	result, err := api.billService.ListUpcoming(userId, days)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取账单详情
+// @Description 获取账单详情，包括下一期到期日期和逾期情况
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Success 200 {object} dto.BillResponse
+// @Router /bk/bills/{id} [get]
+func (api *BookkeepingBillApi) GetBill(c *gin.Context) {
+	// 解析账单ID
+	billID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取账单详情
+	result, err := api.billService.GetBill(userId, uint(billID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新账单
+// @Description 更新账单信息，修改到期计划后已付期数按新计划重新推算
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Param request body dto.UpdateBillRequest true "账单信息"
+// @Success 200 {object} dto.BillResponse
+// @Router /bk/bills/{id} [put]
+func (api *BookkeepingBillApi) UpdateBill(c *gin.Context) {
+	// 解析账单ID
+	billID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单ID")
+		return
+	}
+
+	var req dto.UpdateBillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务更新账单
+	result, err := api.billService.UpdateBill(userId, uint(billID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除账单
+// @Description 删除账单及其付款记录，付款时生成的支出流水保留
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Success 200 {object} response.Response
+// @Router /bk/bills/{id} [delete]
+func (api *BookkeepingBillApi) DeleteBill(c *gin.Context) {
+	// 解析账单ID
+	billID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除账单
+	if err := api.billService.DeleteBill(userId, uint(billID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除成功")
+}
+
+// @Summary 标记账单已付
+// @Description 标记账单下一期已付，从付款账户生成一笔支出流水，账单移到下一期
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Param request body dto.PayBillRequest false "付款信息"
+// @Success 200 {object} dto.BillPaymentResponse
+// @Router /bk/bills/{id}/pay [post]
+func (api *BookkeepingBillApi) PayBill(c *gin.Context) {
+	// 解析账单ID
+	billID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单ID")
+		return
+	}
+
+	// 请求体可以为空，全部使用账单的默认值
+	var req dto.PayBillRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleValidationError(c, err)
+			return
+		}
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务记录付款
+	result, err := api.billService.PayBill(userId, uint(billID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取账单付款记录
+// @Description 获取账单的付款记录，按期数倒序
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Success 200 {array} dto.BillPaymentResponse
+// @Router /bk/bills/{id}/payments [get]
+func (api *BookkeepingBillApi) ListPayments(c *gin.Context) {
+	// 解析账单ID
+	billID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取付款记录
+	result, err := api.billService.ListPayments(userId, uint(billID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取账单日历订阅地址
+// @Description 获取 iCalendar 订阅地址，可在日历应用中订阅账单到期日；首次获取时生成订阅密钥
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.BillCalendarFeedResponse
+// @Router /bk/bills/calendar [get]
+func (api *BookkeepingBillApi) GetCalendarFeed(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取订阅地址
+	result, err := api.billService.GetCalendarFeed(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	result.URL = calendarFeedURL(c, result.Path)
+	utils.OkWithData(c, result)
+}
+
+// @Summary 重置账单日历订阅地址
+// @Description 重新生成订阅密钥，原订阅地址立即失效
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.BillCalendarFeedResponse
+// @Router /bk/bills/calendar/reset [post]
+func (api *BookkeepingBillApi) ResetCalendarFeed(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务重置订阅地址
+	result, err := api.billService.ResetCalendarFeed(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	result.URL = calendarFeedURL(c, result.Path)
+	utils.OkWithData(c, result)
+}
+
+// @Summary 账单日历 (iCalendar)
+// @Description 按订阅密钥返回 text/calendar 格式的账单日历，不需要登录；包含逾期未付和未来12个月内到期的项目
+// @Tags 账单提醒
+// @Produce text/calendar
+// @Param token path string true "订阅密钥 (可带 .ics 后缀)"
+// @Success 200 {string} string
+// @Router /public/bk/calendar/{token} [get]
+func (api *BookkeepingBillApi) Calendar(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	// 调用服务生成日历
+	body, err := api.billService.RenderCalendar(token)
+	if err != nil {
+		c.String(http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+// calendarFeedURL 按当前请求的协议和主机生成完整的订阅地址
+func calendarFeedURL(c *gin.Context, path string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + path
+}
+
+// @Summary 撤销账单付款
+// @Description 撤销账单最近一次付款，删除付款记录和付款时生成的支出流水，账单回到该期未付
+// @Tags 账单提醒
+// @Accept json
+// @Produce json
+// @Param id path int true "账单ID"
+// @Param payment_id path int true "付款记录ID"
+// @Success 200 {object} dto.BillResponse
+// @Router /bk/bills/{id}/payments/{payment_id} [delete]
+func (api *BookkeepingBillApi) DeletePayment(c *gin.Context) {
+	// 解析账单ID和付款记录ID
+	billID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单ID")
+		return
+	}
+	paymentID, err := strconv.Atoi(c.Param("payment_id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的付款记录ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务撤销付款
+	result, err := api.billService.DeletePayment(userId, uint(billID), uint(paymentID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
@@ -51,9 +51,11 @@ func (api *BookkeepingBudgetApi) CreateBudget(c *gin.Context) {
 // @Param page query int true "页码" default(1)
 // @Param page_size query int true "每页大小" default(10)
 // @Param type query string false "预算类型 (overall, category)"
-// @Param period query string false "预算周期 (weekly, monthly, yearly)"
+// @Param kind query string false "预算方向 (expense, income)"
+// @Param period query string false "预算周期 (weekly, biweekly, monthly, quarterly, yearly, custom, once)"
 // @Param category_id query int false "分类ID（仅当筛选分类预算时使用）"
 // @Param is_active query bool false "是否激活"
+// @Param is_envelope query bool false "是否为信封"
 // @Success 200 {object} dto.BudgetListResponse
 // @Router /bk/budgets [get]
 func (api *BookkeepingBudgetApi) ListBudgets(c *gin.Context) {
@@ -254,3 +256,115 @@ func (api *BookkeepingBudgetApi) CheckBudgetAlerts(c *gin.Context) {
 
 	utils.OkWithData(c, result)
 }
+
+// @Summary 获取预算历史
+// @Description 获取预算从开始日期以来每个周期的预算金额、实际支出、差额和是否超支
+// @Tags 预算管理
+// @Accept json
+// @Produce json
+// @Param id path int true "预算ID"
+// @Success 200 {object} dto.BudgetHistoryResponse
+// @Router /bk/budgets/{id}/history [get]
+func (api *BookkeepingBudgetApi) GetBudgetHistory(c *gin.Context) {
+	// 解析预算ID
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的预算ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取预算历史
+	result, err := api.budgetService.GetBudgetHistory(userId, uint(budgetID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取预算阈值记录
+// @Description 获取预算在各周期跨过提醒阈值或超支的记录，交易写入和后台扫描时记录，每个周期每个级别只记录一次
+// @Tags 预算管理
+// @Accept json
+// @Produce json
+// @Param id path int true "预算ID"
+// @Success 200 {array} dto.BudgetThresholdEventResponse
+// @Router /bk/budgets/{id}/events [get]
+func (api *BookkeepingBudgetApi) ListBudgetEvents(c *gin.Context) {
+	// 解析预算ID
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的预算ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取预算阈值记录
+	result, err := api.budgetService.ListBudgetEvents(userId, uint(budgetID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取所有预算历史
+// @Description 获取所有预算从开始日期以来每个周期的执行情况
+// @Tags 预算管理
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.BudgetHistoryResponse
+// @Router /bk/budgets/history [get]
+func (api *BookkeepingBudgetApi) ListBudgetHistory(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取预算历史
+	result, err := api.budgetService.ListBudgetHistory(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取预算与实际对比表
+// @Description 获取指定年份所有预算按月的预算金额与实际支出对比
+// @Tags 预算管理
+// @Accept json
+// @Produce json
+// @Param year query int false "年份，默认今年"
+// @Param include_inactive query bool false "是否包含未激活的预算"
+// @Success 200 {object} dto.BudgetMatrixResponse
+// @Router /bk/budgets/matrix [get]
+func (api *BookkeepingBudgetApi) GetBudgetMatrix(c *gin.Context) {
+	var req dto.BudgetMatrixRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取对比表
+	result, err := api.budgetService.GetBudgetMatrix(userId, req.Year, req.IncludeInactive)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
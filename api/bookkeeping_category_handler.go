@@ -133,11 +133,12 @@ func (a *BookkeepingCategoryApi) UpdateCategory(c *gin.Context) {
 // DeleteCategory godoc
 // @Tags BookkeepingCategory
 // @Summary 删除分类
-// @Description 根据ID删除用户的分类
+// @Description 根据ID删除用户的分类，指定reassign_to时先将交易、预算和子分类合并到该分类再删除
 // @Accept  json
 // @Produce  json
 // @Param   x-token header string true "令牌"
 // @Param   id path uint true "分类ID"
+// @Param   reassign_to query uint false "接收交易、预算和子分类的同类型分类ID"
 // @Success 200 {object} response.Response{msg=string} "删除成功"
 // @Failure 400 {object} response.Response{msg=string} "请求参数错误"
 // @Failure 404 {object} response.Response{msg=string} "分类不存在"
@@ -151,13 +152,22 @@ func (a *BookkeepingCategoryApi) DeleteCategory(c *gin.Context) {
 		return
 	}
 
+	var reassignTo uint64
+	if reassignToStr := c.Query("reassign_to"); reassignToStr != "" {
+		reassignTo, err = strconv.ParseUint(reassignToStr, 10, 32)
+		if err != nil {
+			response.FailWithMessage(c, "无效的目标分类ID")
+			return
+		}
+	}
+
 	userID := utils.GetUserID(c)
 	if userID == 0 {
 		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
 		return
 	}
 
-	if err := a.Service.DeleteCategory(userID, uint(categoryID)); err != nil {
+	if err := a.Service.DeleteCategory(userID, uint(categoryID), uint(reassignTo)); err != nil {
 		response.FailWithMessage(c, "删除分类失败: "+err.Error())
 		return
 	}
@@ -174,6 +184,7 @@ func (a *BookkeepingCategoryApi) DeleteCategory(c *gin.Context) {
 // @Param   x-token header string true "令牌"
 // @Param   type query string false "分类类型 (income/expense)"
 // @Param   parent_id query int false "父分类ID (查询指定父分类下的子分类，0表示顶级分类)"
+// @Param   include_archived query bool false "是否包含已归档的分类，默认false"
 // @Success 200 {object} response.Response{data=[]dto.CategoryResponse,msg=string} "获取成功"
 // @Failure 500 {object} response.Response{msg=string} "服务器内部错误"
 // @Router /bk/categories [get]
@@ -197,7 +208,9 @@ func (a *BookkeepingCategoryApi) ListCategories(c *gin.Context) {
 		}
 	}
 
-	categories, err := a.Service.ListCategories(userID, categoryType, parentID)
+	includeArchived, _ := strconv.ParseBool(c.DefaultQuery("include_archived", "false"))
+
+	categories, err := a.Service.ListCategories(userID, categoryType, parentID, includeArchived)
 	if err != nil {
 		response.FailWithMessage(c, "获取分类列表失败: "+err.Error())
 		return
@@ -214,6 +227,7 @@ func (a *BookkeepingCategoryApi) ListCategories(c *gin.Context) {
 // @Produce  json
 // @Param   x-token header string true "令牌"
 // @Param   type query string false "分类类型 (income/expense)"
+// @Param   include_archived query bool false "是否包含已归档的分类，默认false"
 // @Success 200 {object} response.Response{data=[]dto.CategoryResponse,msg=string} "获取成功"
 // @Failure 500 {object} response.Response{msg=string} "服务器内部错误"
 // @Router /bk/categories/flat [get]
@@ -226,7 +240,9 @@ func (a *BookkeepingCategoryApi) ListAllCategoriesFlat(c *gin.Context) {
 
 	categoryType := model.CategoryType(c.Query("type"))
 
-	categories, err := a.Service.GetAllCategoriesFlat(userID, categoryType)
+	includeArchived, _ := strconv.ParseBool(c.DefaultQuery("include_archived", "false"))
+
+	categories, err := a.Service.GetAllCategoriesFlat(userID, categoryType, includeArchived)
 	if err != nil {
 		response.FailWithMessage(c, "获取分类列表失败: "+err.Error())
 		return
@@ -234,3 +250,218 @@ func (a *BookkeepingCategoryApi) ListAllCategoriesFlat(c *gin.Context) {
 
 	response.OkWithData(c, categories)
 }
+
+// ArchiveCategory godoc
+// @Tags BookkeepingCategory
+// @Summary 归档分类
+// @Description 归档指定分类及其所有子分类，归档后默认不在列表中显示且不能用于新交易，历史交易仍参与统计
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path uint true "分类ID"
+// @Success 200 {object} response.Response{data=dto.CategoryResponse,msg=string} "归档成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "分类不存在"
+// @Router /bk/categories/{id}/archive [post]
+func (a *BookkeepingCategoryApi) ArchiveCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage(c, "无效的分类ID")
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	category, err := a.Service.ArchiveCategory(userID, uint(categoryID))
+	if err != nil {
+		response.FailWithMessage(c, "归档分类失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, category)
+}
+
+// ReopenCategory godoc
+// @Tags BookkeepingCategory
+// @Summary 重新启用分类
+// @Description 重新启用已归档的分类及其所有子分类
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path uint true "分类ID"
+// @Success 200 {object} response.Response{data=dto.CategoryResponse,msg=string} "启用成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "分类不存在"
+// @Router /bk/categories/{id}/reopen [post]
+func (a *BookkeepingCategoryApi) ReopenCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage(c, "无效的分类ID")
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	category, err := a.Service.ReopenCategory(userID, uint(categoryID))
+	if err != nil {
+		response.FailWithMessage(c, "重新启用分类失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, category)
+}
+
+// MergeCategory godoc
+// @Tags BookkeepingCategory
+// @Summary 合并分类
+// @Description 将指定分类合并到同类型的目标分类：迁移交易、预算等关联记录，子分类移到目标分类下，随后删除源分类。dry_run为true时只返回合并预览
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path uint true "源分类ID"
+// @Param   merge_info body dto.MergeCategoryRequest true "合并信息"
+// @Success 200 {object} response.Response{data=dto.MergeCategoryResponse,msg=string} "合并成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "分类不存在"
+// @Router /bk/categories/{id}/merge [post]
+func (a *BookkeepingCategoryApi) MergeCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage(c, "无效的分类ID")
+		return
+	}
+
+	var req dto.MergeCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "请求参数错误: "+utils.GetErrorMsg(req, err))
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	result, err := a.Service.MergeCategory(userID, uint(categoryID), req)
+	if err != nil {
+		response.FailWithMessage(c, "合并分类失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, result)
+}
+
+// MoveCategory godoc
+// @Tags BookkeepingCategory
+// @Summary 移动分类
+// @Description 将分类移动到新的父分类下或移动为顶级分类，拒绝形成循环、类型不一致或超过最大层级的移动
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   id path uint true "分类ID"
+// @Param   move_info body dto.MoveCategoryRequest true "移动信息"
+// @Success 200 {object} response.Response{data=dto.CategoryResponse,msg=string} "移动成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Failure 404 {object} response.Response{msg=string} "分类不存在"
+// @Router /bk/categories/{id}/move [post]
+func (a *BookkeepingCategoryApi) MoveCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailWithMessage(c, "无效的分类ID")
+		return
+	}
+
+	var req dto.MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "请求参数错误: "+utils.GetErrorMsg(req, err))
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	category, err := a.Service.MoveCategory(userID, uint(categoryID), req)
+	if err != nil {
+		response.FailWithMessage(c, "移动分类失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, category)
+}
+
+// SortCategories godoc
+// @Tags BookkeepingCategory
+// @Summary 调整同级分类顺序
+// @Description 按给定的分类ID顺序批量设置同一父分类下分类的排序
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   sort_info body dto.SortCategoriesRequest true "排序信息"
+// @Success 200 {object} response.Response{msg=string} "排序成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Router /bk/categories/sort [put]
+func (a *BookkeepingCategoryApi) SortCategories(c *gin.Context) {
+	var req dto.SortCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "请求参数错误: "+utils.GetErrorMsg(req, err))
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	if err := a.Service.SortCategories(userID, req); err != nil {
+		response.FailWithMessage(c, "调整分类顺序失败: "+err.Error())
+		return
+	}
+
+	response.OkWithMessage(c, "调整分类顺序成功")
+}
+
+// ReorderCategoryTree godoc
+// @Tags BookkeepingCategory
+// @Summary 调整分类树
+// @Description 按拖拽后的树结构整体调整分类的父分类和排序，校验通过后一次性写入
+// @Accept  json
+// @Produce  json
+// @Param   x-token header string true "令牌"
+// @Param   tree body dto.ReorderCategoryTreeRequest true "新的分类树"
+// @Success 200 {object} response.Response{data=[]dto.CategoryResponse,msg=string} "调整成功"
+// @Failure 400 {object} response.Response{msg=string} "请求参数错误"
+// @Router /bk/categories/tree [put]
+func (a *BookkeepingCategoryApi) ReorderCategoryTree(c *gin.Context) {
+	var req dto.ReorderCategoryTreeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "请求参数错误: "+utils.GetErrorMsg(req, err))
+		return
+	}
+
+	userID := utils.GetUserID(c)
+	if userID == 0 {
+		response.FailWithMessage(c, "用户未登录或无法获取用户信息")
+		return
+	}
+
+	categories, err := a.Service.ReorderCategoryTree(userID, req)
+	if err != nil {
+		response.FailWithMessage(c, "调整分类树失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, categories)
+}
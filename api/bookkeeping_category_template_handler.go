@@ -0,0 +1,247 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingCategoryTemplateApi 分类模板相关API
+type BookkeepingCategoryTemplateApi struct {
+	templateService service.BookkeepingCategoryTemplateService
+}
+
+// @Summary 获取分类模板列表
+// @Description 获取启用的分类模板列表 (不包含分类树)
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Param locale query string false "语言，如 zh-CN、en-US，为空时返回所有语言"
+// @Success 200 {array} dto.CategoryTemplateResponse
+// @Router /bk/category-templates [get]
+func (api *BookkeepingCategoryTemplateApi) ListTemplates(c *gin.Context) {
+	var req dto.ListCategoryTemplatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 调用服务获取模板列表
+	result, err := api.templateService.ListTemplates(req.Locale, false)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取分类模板详情
+// @Description 获取启用的分类模板及其分类树
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} dto.CategoryTemplateResponse
+// @Router /bk/category-templates/{id} [get]
+func (api *BookkeepingCategoryTemplateApi) GetTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的模板ID")
+		return
+	}
+
+	// 调用服务获取模板详情
+	result, err := api.templateService.GetTemplate(uint(id), false)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 应用分类模板
+// @Description 按模板为当前用户创建分类，已存在的同名分类会被复用，可以重复应用
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} dto.ApplyCategoryTemplateResponse
+// @Router /bk/category-templates/{id}/apply [post]
+func (api *BookkeepingCategoryTemplateApi) ApplyTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的模板ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务应用模板
+	result, err := api.templateService.ApplyTemplate(userId, uint(id))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 导出分类为模板
+// @Description 将当前用户的分类树 (不含已归档分类) 导出为模板格式，可以直接用于创建模板
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.SaveCategoryTemplateRequest
+// @Router /bk/category-templates/export [get]
+func (api *BookkeepingCategoryTemplateApi) ExportTemplate(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务导出分类
+	result, err := api.templateService.ExportTemplate(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取所有分类模板 (管理员)
+// @Description 获取分类模板列表，包含停用的模板
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Param locale query string false "语言，为空时返回所有语言"
+// @Success 200 {array} dto.CategoryTemplateResponse
+// @Router /bk/admin/category-templates [get]
+func (api *BookkeepingCategoryTemplateApi) AdminListTemplates(c *gin.Context) {
+	var req dto.ListCategoryTemplatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 调用服务获取模板列表
+	result, err := api.templateService.ListTemplates(req.Locale, true)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取分类模板详情 (管理员)
+// @Description 获取分类模板及其分类树，包含停用的模板
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} dto.CategoryTemplateResponse
+// @Router /bk/admin/category-templates/{id} [get]
+func (api *BookkeepingCategoryTemplateApi) AdminGetTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的模板ID")
+		return
+	}
+
+	// 调用服务获取模板详情
+	result, err := api.templateService.GetTemplate(uint(id), true)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 创建分类模板 (管理员)
+// @Description 创建分类模板，同一编码同一语言只能有一个模板
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Param request body dto.SaveCategoryTemplateRequest true "模板信息"
+// @Success 200 {object} dto.CategoryTemplateResponse
+// @Router /bk/admin/category-templates [post]
+func (api *BookkeepingCategoryTemplateApi) CreateTemplate(c *gin.Context) {
+	var req dto.SaveCategoryTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 调用服务创建模板
+	result, err := api.templateService.CreateTemplate(req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新分类模板 (管理员)
+// @Description 更新分类模板，分类树整体替换；内置模板不能修改编码和语言
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Param request body dto.SaveCategoryTemplateRequest true "模板信息"
+// @Success 200 {object} dto.CategoryTemplateResponse
+// @Router /bk/admin/category-templates/{id} [put]
+func (api *BookkeepingCategoryTemplateApi) UpdateTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的模板ID")
+		return
+	}
+
+	var req dto.SaveCategoryTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 调用服务更新模板
+	result, err := api.templateService.UpdateTemplate(uint(id), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除分类模板 (管理员)
+// @Description 删除分类模板，内置模板不能删除，只能停用
+// @Tags 分类模板
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {string} string "删除成功"
+// @Router /bk/admin/category-templates/{id} [delete]
+func (api *BookkeepingCategoryTemplateApi) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的模板ID")
+		return
+	}
+
+	// 调用服务删除模板
+	if err := api.templateService.DeleteTemplate(uint(id)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除成功")
+}
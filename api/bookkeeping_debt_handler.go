@@ -0,0 +1,375 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingDebtApi 个人借贷相关API
+type BookkeepingDebtApi struct {
+	debtService service.BookkeepingDebtService
+}
+
+// @Summary 创建联系人
+// @Description 创建借贷往来的联系人
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateCounterpartyRequest true "联系人信息"
+// @Success 200 {object} dto.CounterpartyResponse
+// @Router /bk/debts/counterparties [post]
+func (api *BookkeepingDebtApi) CreateCounterparty(c *gin.Context) {
+	var req dto.CreateCounterpartyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务创建联系人
+	result, err := api.debtService.CreateCounterparty(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取联系人列表
+// @Description 获取当前用户的借贷联系人列表
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.CounterpartyResponse
+// @Router /bk/debts/counterparties [get]
+func (api *BookkeepingDebtApi) ListCounterparties(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取联系人列表
+	result, err := api.debtService.ListCounterparties(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新联系人
+// @Description 更新联系人信息
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param id path int true "联系人ID"
+// @Param request body dto.UpdateCounterpartyRequest true "更新信息"
+// @Success 200 {object} dto.CounterpartyResponse
+// @Router /bk/debts/counterparties/{id} [put]
+func (api *BookkeepingDebtApi) UpdateCounterparty(c *gin.Context) {
+	// 解析联系人ID
+	counterpartyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的联系人ID")
+		return
+	}
+
+	var req dto.UpdateCounterpartyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务更新联系人
+	result, err := api.debtService.UpdateCounterparty(userId, uint(counterpartyID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除联系人
+// @Description 删除没有借贷记录的联系人
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param id path int true "联系人ID"
+// @Success 200 {object} response.Response
+// @Router /bk/debts/counterparties/{id} [delete]
+func (api *BookkeepingDebtApi) DeleteCounterparty(c *gin.Context) {
+	// 解析联系人ID
+	counterpartyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的联系人ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除联系人
+	if err := api.debtService.DeleteCounterparty(userId, uint(counterpartyID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除联系人成功")
+}
+
+// @Summary 记录借出或借入
+// @Description 记录借给别人或向别人借的钱，生成的资金流水不计入收支统计
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateDebtRequest true "借贷信息"
+// @Success 200 {object} dto.DebtResponse
+// @Router /bk/debts [post]
+func (api *BookkeepingDebtApi) CreateDebt(c *gin.Context) {
+	var req dto.CreateDebtRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务记录借贷
+	result, err := api.debtService.CreateDebt(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取借贷记录列表
+// @Description 获取借贷记录列表，支持按联系人、方向和状态筛选
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param counterparty_id query int false "联系人ID"
+// @Param direction query string false "方向 (lend, borrow)"
+// @Param status query string false "状态 (open, settled)"
+// @Success 200 {array} dto.DebtResponse
+// @Router /bk/debts [get]
+func (api *BookkeepingDebtApi) ListDebts(c *gin.Context) {
+	var query dto.DebtQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取借贷记录列表
+	result, err := api.debtService.ListDebts(userId, query)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取借贷汇总
+// @Description 按联系人汇总未结清的应收和应付金额
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.DebtSummaryResponse
+// @Router /bk/debts/summary [get]
+func (api *BookkeepingDebtApi) GetSummary(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取借贷汇总
+	result, err := api.debtService.GetSummary(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取逾期借贷
+// @Description 获取已过约定还款日期仍未结清的借贷记录
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param direction query string false "方向 (lend, borrow)"
+// @Success 200 {array} dto.DebtResponse
+// @Router /bk/debts/overdue [get]
+func (api *BookkeepingDebtApi) ListOverdue(c *gin.Context) {
+	direction := c.Query("direction")
+	if direction != "" && direction != "lend" && direction != "borrow" {
+		utils.ErrorWithMsg(c, "无效的借贷方向")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取逾期借贷
+	result, err := api.debtService.ListOverdue(userId, direction)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取借贷详情
+// @Description 获取借贷记录详情及还款记录
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param id path int true "借贷记录ID"
+// @Success 200 {object} dto.DebtResponse
+// @Router /bk/debts/{id} [get]
+func (api *BookkeepingDebtApi) GetDebt(c *gin.Context) {
+	// 解析借贷记录ID
+	debtID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的借贷记录ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取借贷详情
+	result, err := api.debtService.GetDebt(userId, uint(debtID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除借贷记录
+// @Description 删除借贷记录及其还款记录，同时删除生成的资金流水
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param id path int true "借贷记录ID"
+// @Success 200 {object} response.Response
+// @Router /bk/debts/{id} [delete]
+func (api *BookkeepingDebtApi) DeleteDebt(c *gin.Context) {
+	// 解析借贷记录ID
+	debtID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的借贷记录ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除借贷记录
+	if err := api.debtService.DeleteDebt(userId, uint(debtID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除借贷记录成功")
+}
+
+// @Summary 记录还款
+// @Description 记录一笔（部分）还款，还清后借贷记录标记为已结清
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param id path int true "借贷记录ID"
+// @Param request body dto.CreateDebtRepaymentRequest true "还款信息"
+// @Success 200 {object} dto.DebtResponse
+// @Router /bk/debts/{id}/repayments [post]
+func (api *BookkeepingDebtApi) AddRepayment(c *gin.Context) {
+	// 解析借贷记录ID
+	debtID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的借贷记录ID")
+		return
+	}
+
+	var req dto.CreateDebtRepaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务记录还款
+	result, err := api.debtService.AddRepayment(userId, uint(debtID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 撤销还款
+// @Description 撤销一笔还款，删除还款记录和生成的资金流水，已还金额回退，未还清时借贷记录恢复为未结清
+// @Tags 个人借贷
+// @Accept json
+// @Produce json
+// @Param id path int true "借贷记录ID"
+// @Param repayment_id path int true "还款记录ID"
+// @Success 200 {object} dto.DebtResponse
+// @Router /bk/debts/{id}/repayments/{repayment_id} [delete]
+func (api *BookkeepingDebtApi) DeleteRepayment(c *gin.Context) {
+	// 解析借贷记录ID和还款记录ID
+	debtID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的借贷记录ID")
+		return
+	}
+	repaymentID, err := strconv.Atoi(c.Param("repayment_id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的还款记录ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务撤销还款
+	result, err := api.debtService.DeleteRepayment(userId, uint(debtID), uint(repaymentID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
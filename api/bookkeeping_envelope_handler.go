@@ -0,0 +1,130 @@
+package api
+
+import (
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingEnvelopeApi 信封预算 (零基预算) 相关API
+type BookkeepingEnvelopeApi struct {
+	envelopeService service.BookkeepingEnvelopeService
+}
+
+// @Summary 获取信封月度概览
+// @Description 获取指定月份的待分配金额，以及各信封的结转、分配、支出和可用余额
+// @Tags 信封预算
+// @Accept json
+// @Produce json
+// @Param month query string false "月份 (YYYY-MM)，默认本月"
+// @Success 200 {object} dto.EnvelopeMonthResponse
+// @Router /bk/envelopes [get]
+func (api *BookkeepingEnvelopeApi) GetMonth(c *gin.Context) {
+	var query dto.EnvelopeMonthQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取信封概览
+	result, err := api.envelopeService.GetMonth(userId, query.Month)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 创建信封
+// @Description 为支出分类创建信封，从本月开始按月分配资金，信封同时是一个预算，可以通过预算接口查看进度和历史
+// @Tags 信封预算
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateEnvelopeRequest true "信封信息"
+// @Success 200 {object} dto.BudgetResponse
+// @Router /bk/envelopes [post]
+func (api *BookkeepingEnvelopeApi) CreateEnvelope(c *gin.Context) {
+	var req dto.CreateEnvelopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务创建信封
+	result, err := api.envelopeService.CreateEnvelope(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 分配或移动信封资金
+// @Description 从待分配中分配资金到信封、在信封之间移动资金，或把信封的资金退回待分配，每次操作都会记录
+// @Tags 信封预算
+// @Accept json
+// @Produce json
+// @Param request body dto.EnvelopeMoveRequest true "移动信息"
+// @Success 200 {object} dto.EnvelopeMoveResponse
+// @Router /bk/envelopes/moves [post]
+func (api *BookkeepingEnvelopeApi) MoveMoney(c *gin.Context) {
+	var req dto.EnvelopeMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务移动资金
+	result, err := api.envelopeService.MoveMoney(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取资金移动记录
+// @Description 获取信封资金的分配和移动记录，按时间倒序
+// @Tags 信封预算
+// @Accept json
+// @Produce json
+// @Param month query string false "月份 (YYYY-MM)"
+// @Param budget_id query int false "信封ID"
+// @Success 200 {array} dto.EnvelopeMoveResponse
+// @Router /bk/envelopes/moves [get]
+func (api *BookkeepingEnvelopeApi) ListMoves(c *gin.Context) {
+	var query dto.EnvelopeMoveQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取资金移动记录
+	result, err := api.envelopeService.ListMoves(userId, query)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingInvestmentApi 投资管理相关API
+type BookkeepingInvestmentApi struct {
+	investmentService service.BookkeepingInvestmentService
+}
+
+// @Summary 创建证券
+// @Description 创建用于记录投资交易的证券（股票、基金等）
+// @Tags 投资管理
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateSecurityRequest true "证券信息"
+// @Success 200 {object} dto.SecurityResponse
+// @Router /bk/investments/securities [post]
+func (api *BookkeepingInvestmentApi) CreateSecurity(c *gin.Context) {
+	var req dto.CreateSecurityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务创建证券
+	result, err := api.investmentService.CreateSecurity(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取证券列表
+// @Description 获取当前用户的证券列表（含最新价格）
+// @Tags 投资管理
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.SecurityResponse
+// @Router /bk/investments/securities [get]
+func (api *BookkeepingInvestmentApi) ListSecurities(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取证券列表
+	result, err := api.investmentService.ListSecurities(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取证券历史价格
+// @Description 获取证券的历史价格，按日期倒序
+// @Tags 投资管理
+// @Accept json
+// @Produce json
+// @Param id path int true "证券ID"
+// @Param start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param end_date query string false "结束日期 (YYYY-MM-DD)"
+// @Success 200 {array} dto.SecurityPriceResponse
+// @Router /bk/investments/securities/{id}/prices [get]
+func (api *BookkeepingInvestmentApi) ListPrices(c *gin.Context) {
+	// 解析证券ID
+	securityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的证券ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取历史价格
+	result, err := api.investmentService.ListPrices(userId, uint(securityID), c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 批量保存证券价格
+// @Description 批量保存证券价格，同一证券同一日期的价格会被覆盖
+// @Tags 投资管理
+// @Accept json
+// @Produce json
+// @Param request body dto.SavePricesRequest true "价格列表"
+// @Success 200 {object} dto.SavePricesResponse
+// @Router /bk/investments/prices [post]
+func (api *BookkeepingInvestmentApi) SavePrices(c *gin.Context) {
+	var req dto.SavePricesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务保存价格
+	result, err := api.investmentService.SavePrices(userId, req.Items, "api")
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 导入证券价格
+// @Description 通过CSV文件导入证券价格，每行依次为证券代码、日期 (YYYY-MM-DD)、价格
+// @Tags 投资管理
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV文件"
+// @Success 200 {object} dto.SavePricesResponse
+// @Router /bk/investments/prices/import [post]
+func (api *BookkeepingInvestmentApi) ImportPrices(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorWithMsg(c, "请上传CSV文件")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorWithMsg(c, "读取上传文件失败")
+		return
+	}
+	defer file.Close()
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务导入价格
+	result, err := api.investmentService.ImportPricesCSV(userId, file)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 记录投资交易
+// @Description 在投资账户中记录买入、卖出、分红或拆股，买入、卖出和分红会同时生成资金流水
+// @Tags 投资管理
+// @Accept json
+// @Produce json
+// @Param id path int true "投资账户ID"
+// @Param request body dto.CreateInvestmentTradeRequest true "交易信息"
+// @Success 200 {object} dto.InvestmentTradeResponse
+// @Router /bk/investments/accounts/{id}/trades [post]
+func (api *BookkeepingInvestmentApi) CreateTrade(c *gin.Context) {
+	// 解析账户ID
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账户ID")
+		return
+	}
+
+	var req dto.CreateInvestmentTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务记录交易
+	result, err := api.investmentService.CreateTrade(userId, uint(accountID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除投资交易
+// @Description 删除证券最近记录的一笔交易，恢复卖出扣减或拆股调整的持仓批次，并删除生成的资金流水
+// @Tags 投资管理
+// @Accept json
+// @Produce json
+// @Param id path int true "投资账户ID"
+// @Param trade_id path int true "投资交易ID"
+// @Success 200 {object} response.Response
+// @Router /bk/investments/accounts/{id}/trades/{trade_id} [delete]
+func (api *BookkeepingInvestmentApi) DeleteTrade(c *gin.Context) {
+	// 解析账户ID和交易ID
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账户ID")
+		return
+	}
+	tradeID, err := strconv.Atoi(c.Param("trade_id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的投资交易ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除交易
+	if err := api.investmentService.DeleteTrade(userId, uint(accountID), uint(tradeID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除成功")
+}
+
+// @Summary 获取投资交易列表
+// @Description 获取投资账户的交易记录，按交易日期倒序
+// @Tags 投资管理
+// @Accept json
+// @Produce json
+// @Param id path int true "投资账户ID"
+// @Param security_id query int false "证券ID"
+// @Success 200 {array} dto.InvestmentTradeResponse
+// @Router /bk/investments/accounts/{id}/trades [get]
+func (api *BookkeepingInvestmentApi) ListTrades(c *gin.Context) {
+	// 解析账户ID
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账户ID")
+		return
+	}
+
+	var securityID uint64
+	if raw := c.Query("security_id"); raw != "" {
+		if securityID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			utils.ErrorWithMsg(c, "无效的证券ID")
+			return
+		}
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取交易列表
+	result, err := api.investmentService.ListTrades(userId, uint(accountID), uint(securityID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取投资账户估值
+// @Description 获取投资账户在指定日期的持仓市值、成本、已实现/未实现收益及年化收益率
+// @Tags 投资管理
+// @Accept json
+// @Produce json
+// @Param id path int true "投资账户ID"
+// @Param date query string false "估值日期 (YYYY-MM-DD)，默认今天"
+// @Success 200 {object} dto.InvestmentValuationResponse
+// @Router /bk/investments/accounts/{id}/valuation [get]
+func (api *BookkeepingInvestmentApi) GetValuation(c *gin.Context) {
+	// 解析账户ID
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账户ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务计算估值
+	result, err := api.investmentService.GetValuation(userId, uint(accountID), c.Query("date"))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
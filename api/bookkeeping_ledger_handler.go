@@ -0,0 +1,65 @@
+package api
+
+import (
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingLedgerApi 账本检查相关API (管理员)
+type BookkeepingLedgerApi struct {
+	ledgerService service.BookkeepingLedgerService
+}
+
+// @Summary 检查账本一致性
+// @Description 检查账户余额与交易记录是否一致，以及交易关联的账户和分类是否有效，只报告问题不做修改
+// @Tags 账本检查
+// @Accept json
+// @Produce json
+// @Param user_id query int false "要检查的用户ID，为空时检查所有用户"
+// @Success 200 {object} dto.LedgerCheckReport
+// @Router /bk/admin/ledger/check [get]
+func (api *BookkeepingLedgerApi) Check(c *gin.Context) {
+	var req dto.LedgerCheckRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 调用服务检查账本
+	result, err := api.ledgerService.Check(req.UserID, false)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 修复账本
+// @Description 检查账本并修复可以自动修复的问题：恢复被删除的账户、将分类异常的交易归入"未分类"、重新计算余额
+// @Tags 账本检查
+// @Accept json
+// @Produce json
+// @Param request body dto.LedgerCheckRequest false "要修复的用户ID，为空时修复所有用户"
+// @Success 200 {object} dto.LedgerCheckReport
+// @Router /bk/admin/ledger/repair [post]
+func (api *BookkeepingLedgerApi) Repair(c *gin.Context) {
+	var req dto.LedgerCheckRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleValidationError(c, err)
+			return
+		}
+	}
+
+	// 调用服务修复账本
+	result, err := api.ledgerService.Check(req.UserID, true)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
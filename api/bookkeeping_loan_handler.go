@@ -0,0 +1,341 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingLoanApi 贷款管理相关API
+type BookkeepingLoanApi struct {
+	loanService service.BookkeepingLoanService
+}
+
+// @Summary 创建贷款
+// @Description 创建房贷、车贷等贷款，未指定负债账户时自动创建贷款账户
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateLoanRequest true "贷款信息"
+// @Success 200 {object} dto.LoanResponse
+// @Router /bk/loans [post]
+func (api *BookkeepingLoanApi) CreateLoan(c *gin.Context) {
+	var req dto.CreateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务创建贷款
+	result, err := api.loanService.CreateLoan(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取贷款列表
+// @Description 获取当前用户的贷款列表
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param status query string false "贷款状态 (active, paid_off)"
+// @Success 200 {array} dto.LoanResponse
+// @Router /bk/loans [get]
+func (api *BookkeepingLoanApi) ListLoans(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != "active" && status != "paid_off" {
+		utils.ErrorWithMsg(c, "无效的贷款状态")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取贷款列表
+	result, err := api.loanService.ListLoans(userId, status)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取贷款详情
+// @Description 获取贷款详情，包括剩余本金、累计已还利息和下一期还款
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Success 200 {object} dto.LoanResponse
+// @Router /bk/loans/{id} [get]
+func (api *BookkeepingLoanApi) GetLoan(c *gin.Context) {
+	// 解析贷款ID
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的贷款ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取贷款详情
+	result, err := api.loanService.GetLoan(userId, uint(loanID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新贷款
+// @Description 更新贷款的名称、利息分类和备注
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Param request body dto.UpdateLoanRequest true "更新信息"
+// @Success 200 {object} dto.LoanResponse
+// @Router /bk/loans/{id} [put]
+func (api *BookkeepingLoanApi) UpdateLoan(c *gin.Context) {
+	// 解析贷款ID
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的贷款ID")
+		return
+	}
+
+	var req dto.UpdateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务更新贷款
+	result, err := api.loanService.UpdateLoan(userId, uint(loanID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除贷款
+// @Description 删除没有还款记录的贷款，已有还款的需要先逐笔撤销，关联的负债账户不会被删除
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Success 200 {object} response.Response
+// @Router /bk/loans/{id} [delete]
+func (api *BookkeepingLoanApi) DeleteLoan(c *gin.Context) {
+	// 解析贷款ID
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的贷款ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除贷款
+	if err := api.loanService.DeleteLoan(userId, uint(loanID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除贷款成功")
+}
+
+// @Summary 获取还款计划
+// @Description 获取贷款的完整还款计划，包括已还记录和按当前状态推算的未还计划
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Success 200 {object} dto.LoanScheduleResponse
+// @Router /bk/loans/{id}/schedule [get]
+func (api *BookkeepingLoanApi) GetSchedule(c *gin.Context) {
+	// 解析贷款ID
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的贷款ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取还款计划
+	result, err := api.loanService.GetSchedule(userId, uint(loanID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取还款记录
+// @Description 获取贷款的还款记录（含提前还款）
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Success 200 {array} dto.LoanRepaymentResponse
+// @Router /bk/loans/{id}/repayments [get]
+func (api *BookkeepingLoanApi) ListRepayments(c *gin.Context) {
+	// 解析贷款ID
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的贷款ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取还款记录
+	result, err := api.loanService.ListRepayments(userId, uint(loanID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 按期还款
+// @Description 归还下一期贷款，本金和利息按还款计划自动拆分并生成流水
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Param request body dto.LoanRepaymentRequest true "还款信息"
+// @Success 200 {object} dto.LoanRepaymentResponse
+// @Router /bk/loans/{id}/repayments [post]
+func (api *BookkeepingLoanApi) Repay(c *gin.Context) {
+	// 解析贷款ID
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的贷款ID")
+		return
+	}
+
+	var req dto.LoanRepaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务还款
+	result, err := api.loanService.Repay(userId, uint(loanID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 提前还款
+// @Description 提前归还部分或全部本金，并按缩短期限或减少月供重新计算还款计划
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Param request body dto.LoanPrepaymentRequest true "提前还款信息"
+// @Success 200 {object} dto.LoanRepaymentResponse
+// @Router /bk/loans/{id}/prepayments [post]
+func (api *BookkeepingLoanApi) Prepay(c *gin.Context) {
+	// 解析贷款ID
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的贷款ID")
+		return
+	}
+
+	var req dto.LoanPrepaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务提前还款
+	result, err := api.loanService.Prepay(userId, uint(loanID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 撤销还款
+// @Description 撤销贷款最近一次还款（按期还款或提前还款），删除生成的流水并恢复贷款的剩余本金、期数和状态
+// @Tags 贷款管理
+// @Accept json
+// @Produce json
+// @Param id path int true "贷款ID"
+// @Param repayment_id path int true "还款记录ID"
+// @Success 200 {object} dto.LoanResponse
+// @Router /bk/loans/{id}/repayments/{repayment_id} [delete]
+func (api *BookkeepingLoanApi) DeleteRepayment(c *gin.Context) {
+	// 解析贷款ID
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的贷款ID")
+		return
+	}
+
+	// 解析还款记录ID
+	repaymentID, err := strconv.Atoi(c.Param("repayment_id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的还款记录ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务撤销还款
+	result, err := api.loanService.DeleteRepayment(userId, uint(loanID), uint(repaymentID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingNotificationApi 通知中心相关API
+type BookkeepingNotificationApi struct {
+	notificationService service.BookkeepingNotificationService
+}
+
+// @Summary 获取通知列表
+// @Description 分页获取站内通知，按时间倒序，同时返回全部未读通知数
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param page query int true "页码"
+// @Param page_size query int true "每页大小"
+// @Param event query string false "事件类型 (budget_threshold, bill_due, large_transaction, import_completed)"
+// @Param is_read query bool false "是否已读"
+// @Success 200 {object} dto.NotificationListResponse
+// @Router /bk/notifications [get]
+func (api *BookkeepingNotificationApi) ListNotifications(c *gin.Context) {
+	var query dto.NotificationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取通知列表
+	result, err := api.notificationService.ListNotifications(userId, query)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 标记通知已读
+// @Description 将一条通知标记为已读
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param id path int true "通知ID"
+// @Success 200 {object} response.Response
+// @Router /bk/notifications/{id}/read [post]
+func (api *BookkeepingNotificationApi) MarkRead(c *gin.Context) {
+	// 解析通知ID
+	notificationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的通知ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务标记已读
+	if err := api.notificationService.MarkRead(userId, uint(notificationID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "已标记为已读")
+}
+
+// @Summary 全部标记已读
+// @Description 将全部未读通知标记为已读
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /bk/notifications/read-all [post]
+func (api *BookkeepingNotificationApi) MarkAllRead(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务全部标记已读
+	if err := api.notificationService.MarkAllRead(userId); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "已全部标记为已读")
+}
+
+// @Summary 删除通知
+// @Description 删除一条通知，删除后同一事件不会再次通知
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param id path int true "通知ID"
+// @Success 200 {object} response.Response
+// @Router /bk/notifications/{id} [delete]
+func (api *BookkeepingNotificationApi) DeleteNotification(c *gin.Context) {
+	// 解析通知ID
+	notificationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的通知ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除通知
+	if err := api.notificationService.DeleteNotification(userId, uint(notificationID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除成功")
+}
+
+// @Summary 获取通知偏好
+// @Description 获取各类事件是否产生通知，以及站内通知之外的推送渠道
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.NotificationPreferenceItem
+// @Router /bk/notifications/preferences [get]
+func (api *BookkeepingNotificationApi) GetPreferences(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取通知偏好
+	result, err := api.notificationService.GetPreferences(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新通知偏好
+// @Description 更新指定事件的通知偏好，推送渠道可选 email、webhook、push，邮箱和Webhook地址在记账设置中配置
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateNotificationPreferencesRequest true "通知偏好"
+// @Success 200 {array} dto.NotificationPreferenceItem
+// @Router /bk/notifications/preferences [put]
+func (api *BookkeepingNotificationApi) UpdatePreferences(c *gin.Context) {
+	var req dto.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务更新通知偏好
+	result, err := api.notificationService.UpdatePreferences(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 订阅通知推送
+// @Description 以 Server-Sent Events 推送新通知，事件名为 notification，数据为通知JSON；每30秒发送一次 ping 保持连接
+// @Tags 通知中心
+// @Produce text/event-stream
+// @Success 200 {object} dto.NotificationResponse
+// @Router /bk/notifications/stream [get]
+func (api *BookkeepingNotificationApi) Stream(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 订阅当前用户的新通知，连接断开时取消订阅
+	notifications, unsubscribe := api.notificationService.Subscribe(userId)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case notification := <-notifications:
+			c.SSEvent("notification", notification)
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+		}
+		return true
+	})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingSavingsGoalApi 储蓄目标相关API
+type BookkeepingSavingsGoalApi struct {
+	goalService service.BookkeepingSavingsGoalService
+}
+
+// @Summary 创建储蓄目标
+// @Description 创建储蓄目标，设置目标金额、目标日期和关联的资产账户
+// @Tags 储蓄目标
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateSavingsGoalRequest true "储蓄目标信息"
+// @Success 200 {object} dto.SavingsGoalResponse
+// @Router /bk/savings-goals [post]
+func (api *BookkeepingSavingsGoalApi) CreateGoal(c *gin.Context) {
+	var req dto.CreateSavingsGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务创建储蓄目标
+	result, err := api.goalService.CreateGoal(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取储蓄目标列表
+// @Description 获取储蓄目标列表及各自的当前进度
+// @Tags 储蓄目标
+// @Accept json
+// @Produce json
+// @Param is_active query bool false "是否激活"
+// @Success 200 {array} dto.SavingsGoalResponse
+// @Router /bk/savings-goals [get]
+func (api *BookkeepingSavingsGoalApi) ListGoals(c *gin.Context) {
+	var query dto.SavingsGoalQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取储蓄目标列表
+	result, err := api.goalService.ListGoals(userId, query)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取储蓄目标详情
+// @Description 获取储蓄目标详情，包括当前进度、每月需存入金额和预计达成日期
+// @Tags 储蓄目标
+// @Accept json
+// @Produce json
+// @Param id path int true "储蓄目标ID"
+// @Success 200 {object} dto.SavingsGoalResponse
+// @Router /bk/savings-goals/{id} [get]
+func (api *BookkeepingSavingsGoalApi) GetGoal(c *gin.Context) {
+	// 解析储蓄目标ID
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的储蓄目标ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取储蓄目标详情
+	result, err := api.goalService.GetGoal(userId, uint(goalID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新储蓄目标
+// @Description 更新储蓄目标信息
+// @Tags 储蓄目标
+// @Accept json
+// @Produce json
+// @Param id path int true "储蓄目标ID"
+// @Param request body dto.UpdateSavingsGoalRequest true "更新信息"
+// @Success 200 {object} dto.SavingsGoalResponse
+// @Router /bk/savings-goals/{id} [put]
+func (api *BookkeepingSavingsGoalApi) UpdateGoal(c *gin.Context) {
+	// 解析储蓄目标ID
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的储蓄目标ID")
+		return
+	}
+
+	var req dto.UpdateSavingsGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务更新储蓄目标
+	result, err := api.goalService.UpdateGoal(userId, uint(goalID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除储蓄目标
+// @Description 删除储蓄目标，不影响关联的账户和交易
+// @Tags 储蓄目标
+// @Accept json
+// @Produce json
+// @Param id path int true "储蓄目标ID"
+// @Success 200 {object} response.Response
+// @Router /bk/savings-goals/{id} [delete]
+func (api *BookkeepingSavingsGoalApi) DeleteGoal(c *gin.Context) {
+	// 解析储蓄目标ID
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的储蓄目标ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除储蓄目标
+	if err := api.goalService.DeleteGoal(userId, uint(goalID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除成功")
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingSettingApi 记账偏好设置相关API
+type BookkeepingSettingApi struct {
+	settingService service.BookkeepingSettingService
+}
+
+// @Summary 获取记账设置
+// @Description 获取当前用户的记账偏好设置 (每月起始日、信封预算模式等)
+// @Tags 记账设置
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.UserSettingResponse
+// @Router /bk/settings [get]
+func (api *BookkeepingSettingApi) GetSettings(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取设置
+	result, err := api.settingService.GetSettings(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新记账设置
+// @Description 更新当前用户的记账偏好设置，修改每月起始日后按月、季度、年计算的预算会对齐到新的起始日，开启信封预算模式后收入计入待分配
+// @Tags 记账设置
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateUserSettingRequest true "设置信息"
+// @Success 200 {object} dto.UserSettingResponse
+// @Router /bk/settings [put]
+func (api *BookkeepingSettingApi) UpdateSettings(c *gin.Context) {
+	var req dto.UpdateUserSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务更新设置
+	result, err := api.settingService.UpdateSettings(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
@@ -0,0 +1,468 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingSplitApi AA分摊相关API
+type BookkeepingSplitApi struct {
+	splitService service.BookkeepingSplitService
+}
+
+// @Summary 创建账单组
+// @Description 创建AA账单组，自动添加代表本人的成员
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateSplitGroupRequest true "账单组信息"
+// @Success 200 {object} dto.SplitGroupResponse
+// @Router /bk/splits/groups [post]
+func (api *BookkeepingSplitApi) CreateGroup(c *gin.Context) {
+	var req dto.CreateSplitGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务创建账单组
+	result, err := api.splitService.CreateGroup(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取账单组列表
+// @Description 获取当前用户的AA账单组列表，包含本人结余
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.SplitGroupResponse
+// @Router /bk/splits/groups [get]
+func (api *BookkeepingSplitApi) ListGroups(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取账单组列表
+	result, err := api.splitService.ListGroups(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取账单组详情
+// @Description 获取AA账单组详情及成员
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Success 200 {object} dto.SplitGroupResponse
+// @Router /bk/splits/groups/{id} [get]
+func (api *BookkeepingSplitApi) GetGroup(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取账单组详情
+	result, err := api.splitService.GetGroup(userId, uint(groupID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新账单组
+// @Description 更新AA账单组的名称和描述
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Param request body dto.UpdateSplitGroupRequest true "更新信息"
+// @Success 200 {object} dto.SplitGroupResponse
+// @Router /bk/splits/groups/{id} [put]
+func (api *BookkeepingSplitApi) UpdateGroup(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	var req dto.UpdateSplitGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务更新账单组
+	result, err := api.splitService.UpdateGroup(userId, uint(groupID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除账单组
+// @Description 删除没有支出和结算记录的AA账单组
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Success 200 {object} response.Response
+// @Router /bk/splits/groups/{id} [delete]
+func (api *BookkeepingSplitApi) DeleteGroup(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除账单组
+	if err := api.splitService.DeleteGroup(userId, uint(groupID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除账单组成功")
+}
+
+// @Summary 添加成员
+// @Description 向AA账单组添加成员
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Param request body dto.AddSplitMemberRequest true "成员信息"
+// @Success 200 {object} dto.SplitGroupResponse
+// @Router /bk/splits/groups/{id}/members [post]
+func (api *BookkeepingSplitApi) AddMember(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	var req dto.AddSplitMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务添加成员
+	result, err := api.splitService.AddMember(userId, uint(groupID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 移除成员
+// @Description 移除未参与支出和结算的成员
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Param member_id path int true "成员ID"
+// @Success 200 {object} response.Response
+// @Router /bk/splits/groups/{id}/members/{member_id} [delete]
+func (api *BookkeepingSplitApi) RemoveMember(c *gin.Context) {
+	// 解析账单组ID和成员ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("member_id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的成员ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务移除成员
+	if err := api.splitService.RemoveMember(userId, uint(groupID), uint(memberID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "移除成员成功")
+}
+
+// @Summary 记录共同支出
+// @Description 记录一笔共同支出及分摊方式，只有本人份额计入分类统计
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Param request body dto.CreateSplitExpenseRequest true "支出信息"
+// @Success 200 {object} dto.SplitExpenseResponse
+// @Router /bk/splits/groups/{id}/expenses [post]
+func (api *BookkeepingSplitApi) CreateExpense(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	var req dto.CreateSplitExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务记录共同支出
+	result, err := api.splitService.CreateExpense(userId, uint(groupID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取共同支出列表
+// @Description 获取AA账单组的共同支出及各成员份额
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Success 200 {array} dto.SplitExpenseResponse
+// @Router /bk/splits/groups/{id}/expenses [get]
+func (api *BookkeepingSplitApi) ListExpenses(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取共同支出列表
+	result, err := api.splitService.ListExpenses(userId, uint(groupID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除共同支出
+// @Description 删除共同支出，同时删除生成的资金流水
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Param expense_id path int true "共同支出ID"
+// @Success 200 {object} response.Response
+// @Router /bk/splits/groups/{id}/expenses/{expense_id} [delete]
+func (api *BookkeepingSplitApi) DeleteExpense(c *gin.Context) {
+	// 解析账单组ID和共同支出ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+	expenseID, err := strconv.Atoi(c.Param("expense_id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的共同支出ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除共同支出
+	if err := api.splitService.DeleteExpense(userId, uint(groupID), uint(expenseID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除共同支出成功")
+}
+
+// @Summary 获取成员结余
+// @Description 获取各成员的垫付、应承担金额和结余，以及结清欠款所需的最少转账
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Success 200 {object} dto.SplitBalanceResponse
+// @Router /bk/splits/groups/{id}/balances [get]
+func (api *BookkeepingSplitApi) GetBalances(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务计算结余
+	result, err := api.splitService.GetBalances(userId, uint(groupID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 记录结算
+// @Description 记录成员之间的结算转账，涉及本人时在本人账户生成不计入收支统计的流水
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Param request body dto.CreateSplitSettlementRequest true "结算信息"
+// @Success 200 {object} dto.SplitSettlementResponse
+// @Router /bk/splits/groups/{id}/settlements [post]
+func (api *BookkeepingSplitApi) CreateSettlement(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	var req dto.CreateSplitSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务记录结算
+	result, err := api.splitService.CreateSettlement(userId, uint(groupID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取结算记录
+// @Description 获取AA账单组的结算记录
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Success 200 {array} dto.SplitSettlementResponse
+// @Router /bk/splits/groups/{id}/settlements [get]
+func (api *BookkeepingSplitApi) ListSettlements(c *gin.Context) {
+	// 解析账单组ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取结算记录
+	result, err := api.splitService.ListSettlements(userId, uint(groupID))
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除结算记录
+// @Description 删除结算记录，同时删除生成的资金流水
+// @Tags AA分摊
+// @Accept json
+// @Produce json
+// @Param id path int true "账单组ID"
+// @Param settlement_id path int true "结算记录ID"
+// @Success 200 {object} response.Response
+// @Router /bk/splits/groups/{id}/settlements/{settlement_id} [delete]
+func (api *BookkeepingSplitApi) DeleteSettlement(c *gin.Context) {
+	// 解析账单组ID和结算记录ID
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的账单组ID")
+		return
+	}
+	settlementID, err := strconv.Atoi(c.Param("settlement_id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的结算记录ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除结算记录
+	if err := api.splitService.DeleteSettlement(userId, uint(groupID), uint(settlementID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除结算记录成功")
+}
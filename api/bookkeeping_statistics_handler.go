@@ -101,6 +101,56 @@ func (api *StatisticsAPI) GetAccountSummary(c *gin.Context) {
 	utils.OkWithData(c, result)
 }
 
+// @Summary 获取净资产
+// @Description 获取当前总资产、总负债和净资产，负债账户（如信用卡、贷款）的欠款计入总负债
+// @Tags 统计
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.NetWorthResponse
+// @Router /statistics/net-worth [get]
+func (api *StatisticsAPI) GetNetWorth(c *gin.Context) {
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取净资产
+	result, err := api.statisticsService.GetNetWorth(userId)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取月度净资产趋势
+// @Description 根据交易历史推算最近几个月每个月末的净资产
+// @Tags 统计
+// @Accept json
+// @Produce json
+// @Param months_count query int false "查询的月份数量，默认为12" default(12)
+// @Success 200 {object} dto.NetWorthTrendResponse
+// @Router /statistics/net-worth-trend [get]
+func (api *StatisticsAPI) GetNetWorthTrend(c *gin.Context) {
+	monthsCount := utils.StrToInt(c.DefaultQuery("months_count", "12"))
+	if monthsCount <= 0 {
+		monthsCount = 12
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取净资产趋势
+	result, err := api.statisticsService.GetNetWorthTrend(userId, monthsCount)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
 // @Summary 获取月度收支趋势
 // @Description 获取最近几个月的收支趋势数据
 // @Tags 统计
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/dotdancer/gogofly/service"
+	"github.com/dotdancer/gogofly/service/dto"
+	"github.com/dotdancer/gogofly/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BookkeepingSubscriptionApi 订阅检测相关API
+type BookkeepingSubscriptionApi struct {
+	subscriptionService service.BookkeepingSubscriptionService
+}
+
+// @Summary 检测候选订阅
+// @Description 从最近若干个月的支出中检测同一收款方、金额相近、间隔规律的定期扣费，返回每月和每年费用、最近扣费、下次预计扣费和涨价信息
+// @Tags 订阅检测
+// @Accept json
+// @Produce json
+// @Param months query int false "分析最近几个月的交易，默认24，范围3-60"
+// @Success 200 {object} dto.SubscriptionCandidateListResponse
+// @Router /bk/subscriptions/candidates [get]
+func (api *BookkeepingSubscriptionApi) ListCandidates(c *gin.Context) {
+	var query dto.SubscriptionCandidateQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务检测候选订阅
+	result, err := api.subscriptionService.ListCandidates(userId, query)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 确认订阅
+// @Description 将候选订阅确认为跟踪的订阅
+// @Tags 订阅检测
+// @Accept json
+// @Produce json
+// @Param request body dto.ConfirmSubscriptionRequest true "候选订阅"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Router /bk/subscriptions/confirm [post]
+func (api *BookkeepingSubscriptionApi) ConfirmSubscription(c *gin.Context) {
+	var req dto.ConfirmSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务确认订阅
+	result, err := api.subscriptionService.ConfirmSubscription(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 忽略候选订阅
+// @Description 忽略候选订阅，该收款方不再作为候选返回
+// @Tags 订阅检测
+// @Accept json
+// @Produce json
+// @Param request body dto.DismissSubscriptionRequest true "候选订阅"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Router /bk/subscriptions/dismiss [post]
+func (api *BookkeepingSubscriptionApi) DismissSubscription(c *gin.Context) {
+	var req dto.DismissSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务忽略订阅
+	result, err := api.subscriptionService.DismissSubscription(userId, req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 获取订阅列表
+// @Description 获取已确认 (默认) 或已忽略的订阅，已确认的订阅按最新交易重新分析扣费和涨价
+// @Tags 订阅检测
+// @Accept json
+// @Produce json
+// @Param status query string false "状态 (tracked, dismissed)，默认 tracked"
+// @Success 200 {object} dto.SubscriptionListResponse
+// @Router /bk/subscriptions [get]
+func (api *BookkeepingSubscriptionApi) ListSubscriptions(c *gin.Context) {
+	var query dto.SubscriptionQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务获取订阅列表
+	result, err := api.subscriptionService.ListSubscriptions(userId, query)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 更新订阅
+// @Description 更新订阅的名称和备注
+// @Tags 订阅检测
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param request body dto.UpdateSubscriptionRequest true "订阅信息"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Router /bk/subscriptions/{id} [put]
+func (api *BookkeepingSubscriptionApi) UpdateSubscription(c *gin.Context) {
+	// 解析订阅ID
+	subscriptionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的订阅ID")
+		return
+	}
+
+	var req dto.UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务更新订阅
+	result, err := api.subscriptionService.UpdateSubscription(userId, uint(subscriptionID), req)
+	if err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithData(c, result)
+}
+
+// @Summary 删除订阅
+// @Description 删除已确认或已忽略的订阅，该收款方重新参与候选订阅检测
+// @Tags 订阅检测
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} response.Response
+// @Router /bk/subscriptions/{id} [delete]
+func (api *BookkeepingSubscriptionApi) DeleteSubscription(c *gin.Context) {
+	// 解析订阅ID
+	subscriptionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMsg(c, "无效的订阅ID")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, _ := c.Get("userID")
+	userId := userID.(uint)
+
+	// 调用服务删除订阅
+	if err := api.subscriptionService.DeleteSubscription(userId, uint(subscriptionID)); err != nil {
+		utils.ErrorWithMsg(c, err.Error())
+		return
+	}
+
+	utils.OkWithMessage(c, "删除成功")
+}
@@ -18,7 +18,7 @@ type BookkeepingTransactionApi struct {
 // CreateTransaction godoc
 // @Tags BookkeepingTransaction
 // @Summary 创建交易流水
-// @Description 用户创建一个新的交易记录
+// @Description 用户创建一个新的交易记录，响应中的 budget_impact 为本次写入使预算跨过的阈值
 // @Accept  json
 // @Produce  json
 // @Param   x-token header string true "令牌"
@@ -61,6 +61,7 @@ func (a *BookkeepingTransactionApi) CreateTransaction(c *gin.Context) {
 // @Param   account_id query int false "账户ID筛选"
 // @Param   category_id query int false "分类ID筛选"
 // @Param   type query string false "交易类型筛选 (income, expense, transfer)"
+// @Param   tag query string false "标签筛选"
 // @Param   start_date query string false "开始日期筛选 (YYYY-MM-DD)"
 // @Param   end_date query string false "结束日期筛选 (YYYY-MM-DD)"
 // @Success 200 {object} response.Response{data=dto.TransactionListResponse,msg=string} "获取成功"
@@ -80,6 +81,7 @@ func (a *BookkeepingTransactionApi) ListTransactions(c *gin.Context) {
 	accountID, _ := strconv.Atoi(c.DefaultQuery("account_id", "0"))
 	categoryID, _ := strconv.Atoi(c.DefaultQuery("category_id", "0"))
 	transactionType := c.DefaultQuery("type", "")
+	tag := c.DefaultQuery("tag", "")
 	startDate := c.DefaultQuery("start_date", "")
 	endDate := c.DefaultQuery("end_date", "")
 
@@ -90,6 +92,7 @@ func (a *BookkeepingTransactionApi) ListTransactions(c *gin.Context) {
 		AccountID:  uint(accountID),
 		CategoryID: uint(categoryID),
 		Type:       transactionType,
+		Tag:        tag,
 		StartDate:  startDate,
 		EndDate:    endDate,
 	}
@@ -141,7 +144,7 @@ func (a *BookkeepingTransactionApi) GetTransaction(c *gin.Context) {
 // UpdateTransaction godoc
 // @Tags BookkeepingTransaction
 // @Summary 更新交易流水信息
-// @Description 更新指定ID的交易流水信息
+// @Description 更新指定ID的交易流水信息，响应中的 budget_impact 为本次写入使预算跨过的阈值
 // @Accept  json
 // @Produce  json
 // @Param   x-token header string true "令牌"
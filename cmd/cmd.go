@@ -7,6 +7,7 @@ import (
 	"github.com/dotdancer/gogofly/global"
 	"github.com/dotdancer/gogofly/model" // Added for model access
 	"github.com/dotdancer/gogofly/router"
+	"github.com/dotdancer/gogofly/service"
 )
 
 func Start() {
@@ -16,6 +17,8 @@ func Start() {
 
 	if global.DB != nil {
 		global.Logger.Info("Attempting to migrate database tables...")
+		// 账户性质字段由本次迁移添加时，迁移后需要为已有的负债账户补写性质
+		backfillAccountNature := global.DB.Migrator().HasTable(&model.Account{}) && !global.DB.Migrator().HasColumn(&model.Account{}, "Nature")
 		// Register table migrations
 		err := global.DB.AutoMigrate(
 			&model.UserInfo{},
@@ -24,6 +27,39 @@ func Start() {
 			&model.Transaction{},
 			&model.Category{},
 			&model.Budget{}, // Add Budget model for migration
+			&model.BudgetAmountVersion{},
+			&model.BudgetCategory{},
+			&model.BudgetAccount{},
+			&model.BudgetThresholdEvent{},
+			&model.SavingsGoal{},
+			&model.SavingsGoalAccount{},
+			&model.EnvelopeAllocation{},
+			&model.EnvelopeMove{},
+			&model.AccountBalanceSnapshot{},
+			&model.Security{},
+			&model.SecurityPrice{},
+			&model.InvestmentTrade{},
+			&model.InvestmentLot{},
+			&model.InvestmentLotSale{},
+			&model.Loan{},
+			&model.LoanRepayment{},
+			&model.Counterparty{},
+			&model.Debt{},
+			&model.DebtRepayment{},
+			&model.Bill{},
+			&model.BillPayment{},
+			&model.BillCalendarFeed{},
+			&model.Subscription{},
+			&model.SplitGroup{},
+			&model.SplitMember{},
+			&model.SplitExpense{},
+			&model.SplitShare{},
+			&model.SplitSettlement{},
+			&model.CategoryTemplate{},
+			&model.CategoryTemplateItem{},
+			&model.UserSetting{},
+			&model.Notification{},
+			&model.NotificationPreference{},
 		)
 		if err != nil {
 			global.Logger.Error("Failed to migrate database tables: " + err.Error())
@@ -31,6 +67,20 @@ func Start() {
 			// For now, logging the error and continuing.
 		} else {
 			global.Logger.Info("Database tables migrated successfully or no changes needed.")
+
+			if backfillAccountNature {
+				if err := new(service.BookkeepingAccountService).BackfillAccountNature(); err != nil {
+					global.Logger.Error("Failed to backfill account nature: " + err.Error())
+				}
+			}
+
+			// 写入内置分类模板
+			if err := new(service.BookkeepingCategoryTemplateService).SeedBuiltinTemplates(); err != nil {
+				global.Logger.Error("Failed to seed builtin category templates: " + err.Error())
+			}
+
+			// 后台扫描预算阈值和即将到期的账单，产生通知
+			service.StartNotificationScanner()
 		}
 	} else {
 		global.Logger.Warn("Database not initialized (global.DB is nil), skipping migrations.")
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dotdancer/gogofly/core"
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/service"
+)
+
+// LedgerCheck 命令行执行账本检查，返回进程退出码
+// 用法: gogofly ledger-check [--user ID] [--repair] [--json]
+// 存在未修复的问题时退出码为1，便于在定时任务中告警
+func LedgerCheck(args []string) int {
+	flags := flag.NewFlagSet("ledger-check", flag.ContinueOnError)
+	userID := flags.Uint("user", 0, "要检查的用户ID，默认检查所有用户")
+	repair := flags.Bool("repair", false, "重新计算余额并修复可以自动修复的问题")
+	asJSON := flags.Bool("json", false, "以JSON格式输出检查报告")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	core.InitConfig()
+	global.Logger = core.InitLogger()
+	global.DB = core.InitMysql()
+	if global.DB == nil {
+		fmt.Fprintln(os.Stderr, "数据库未配置，无法执行账本检查")
+		return 2
+	}
+
+	ledgerService := service.BookkeepingLedgerService{}
+	report, err := ledgerService.Check(*userID, *repair)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	} else {
+		fmt.Printf("检查账户 %d 个，交易 %d 笔，发现问题 %d 个", report.CheckedAccounts, report.CheckedTransactions, report.IssueCount)
+		if report.Repair {
+			fmt.Printf("，已修复 %d 个", report.RepairedCount)
+		}
+		fmt.Println()
+		for _, issue := range report.Issues {
+			status := ""
+			switch {
+			case issue.Repaired:
+				status = " [已修复]"
+			case issue.RepairError != "":
+				status = " [" + issue.RepairError + "]"
+			}
+			fmt.Printf("- [%s] 用户 %d: %s；建议：%s%s\n", issue.Type, issue.UserID, issue.Description, issue.Suggestion, status)
+		}
+	}
+
+	if report.IssueCount > report.RepairedCount {
+		return 1
+	}
+	return 0
+}
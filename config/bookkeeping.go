@@ -0,0 +1,8 @@
+package config
+
+type Bookkeeping struct {
+	AdminUserIDs            []uint `mapstructure:"admin-user-ids" json:"admin-user-ids" yaml:"admin-user-ids"`                                  // 可以访问管理接口的用户ID
+	MaxCategoryDepth        int    `mapstructure:"max-category-depth" json:"max-category-depth" yaml:"max-category-depth"`                      // 分类树的最大层级，顶级分类为第1层
+	DefaultCategoryTemplate string `mapstructure:"default-category-template" json:"default-category-template" yaml:"default-category-template"` // 新用户注册时自动应用的分类模板编码，为空时不自动创建分类
+	DefaultLocale           string `mapstructure:"default-locale" json:"default-locale" yaml:"default-locale"`                                  // 注册时未指定语言时使用的语言
+}
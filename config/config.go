@@ -6,4 +6,7 @@ type Server struct {
 	Mysql  Mysql  `mapstructure:"mysql" json:"mysql" yaml:"mysql"`
 	Redis  Redis  `mapstructure:"redis" json:"redis" yaml:"redis"`
 	Jwt    Jwt    `mapstructure:"jwt" json:"jwt" yaml:"jwt"`
+
+	Bookkeeping  Bookkeeping  `mapstructure:"bookkeeping" json:"bookkeeping" yaml:"bookkeeping"`
+	Notification Notification `mapstructure:"notification" json:"notification" yaml:"notification"`
 }
@@ -0,0 +1,17 @@
+package config
+
+type Notification struct {
+	ScanInterval        int  `mapstructure:"scan-interval" json:"scan-interval" yaml:"scan-interval"`                         // 扫描预算阈值和到期账单的间隔 (分钟)，0 表示不扫描
+	DueReminderDays     int  `mapstructure:"due-reminder-days" json:"due-reminder-days" yaml:"due-reminder-days"`             // 账单到期前多少天提醒
+	WebhookTimeout      int  `mapstructure:"webhook-timeout" json:"webhook-timeout" yaml:"webhook-timeout"`                   // Webhook 请求超时时间 (秒)
+	WebhookAllowPrivate bool `mapstructure:"webhook-allow-private" json:"webhook-allow-private" yaml:"webhook-allow-private"` // 是否允许Webhook地址指向本机或内网，默认不允许
+	Smtp                Smtp `mapstructure:"smtp" json:"smtp" yaml:"smtp"`                                                    // 发送邮件通知的SMTP服务器，Host 为空时不发送邮件
+}
+
+type Smtp struct {
+	Host     string `mapstructure:"host" json:"host" yaml:"host"`
+	Port     int    `mapstructure:"port" json:"port" yaml:"port"`
+	Username string `mapstructure:"username" json:"username" yaml:"username"`
+	Password string `mapstructure:"password" json:"password" yaml:"password"`
+	From     string `mapstructure:"from" json:"from" yaml:"from"` // 发件人地址，为空时使用 Username
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 	"time"
 
 	"github.com/dotdancer/gogofly/cmd"
@@ -21,6 +22,11 @@ import (
 // @name                        x-token
 // @BasePath                    /
 func main() {
+	// 子命令: ledger-check 账本一致性检查
+	if len(os.Args) > 1 && os.Args[1] == "ledger-check" {
+		os.Exit(cmd.LedgerCheck(os.Args[2:]))
+	}
+
 	defer cmd.Clear()
 	cmd.Start()
 	//r := gin.New()
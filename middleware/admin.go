@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth 管理员权限中间件，需在JWTAuth之后使用
+// 只有配置在 bookkeeping.admin-user-ids 中的用户可以访问
+func AdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetUint("userID")
+		for _, adminID := range global.Config.Bookkeeping.AdminUserIDs {
+			if userID != 0 && userID == adminID {
+				c.Next()
+				return
+			}
+		}
+
+		response.FailWithMessage(c, "无权限访问")
+		c.Abort()
+	}
+}
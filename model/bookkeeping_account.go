@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/dotdancer/gogofly/global"
 	"gorm.io/gorm"
 )
@@ -15,19 +17,42 @@ const (
 	AccountTypeAlipay     AccountType = "alipay"      // 支付宝
 	AccountTypeWechatPay  AccountType = "wechat_pay"  // 微信钱包
 	AccountTypeInvestment AccountType = "investment"  // 投资账户
+	AccountTypeLoan       AccountType = "loan"        // 贷款
 	AccountTypeOther      AccountType = "other"       // 其他
 )
 
+// AccountNature 定义账户性质 (资产或负债)
+type AccountNature string
+
+const (
+	AccountNatureAsset     AccountNature = "asset"     // 资产
+	AccountNatureLiability AccountNature = "liability" // 负债
+)
+
+// DefaultAccountNature 根据账户类型返回默认的账户性质，信用卡和贷款默认为负债
+func DefaultAccountNature(accountType AccountType) AccountNature {
+	switch accountType {
+	case AccountTypeCreditCard, AccountTypeLoan:
+		return AccountNatureLiability
+	default:
+		return AccountNatureAsset
+	}
+}
+
 // Account 账户模型
 type Account struct {
 	global.GlyModel
-	UserID         uint        `json:"user_id" gorm:"index;comment:用户ID"`
-	Name           string      `json:"name" gorm:"type:varchar(100);not null;comment:账户名称"`
-	Type           AccountType `json:"type" gorm:"type:varchar(50);not null;comment:账户类型"`
-	InitialBalance float64     `json:"initial_balance" gorm:"type:decimal(10,2);default:0.00;comment:初始余额"`
-	CurrentBalance float64     `json:"current_balance" gorm:"type:decimal(10,2);default:0.00;comment:当前余额"`
-	Remark         string      `json:"remark" gorm:"type:varchar(255);comment:备注"`
-	IsDefault      bool        `json:"is_default" gorm:"default:false;comment:是否默认账户"`
+	UserID              uint          `json:"user_id" gorm:"index;comment:用户ID"`
+	Name                string        `json:"name" gorm:"type:varchar(100);not null;comment:账户名称"`
+	Type                AccountType   `json:"type" gorm:"type:varchar(50);not null;comment:账户类型"`
+	InitialBalance      float64       `json:"initial_balance" gorm:"type:decimal(10,2);default:0.00;comment:初始余额"`
+	CurrentBalance      float64       `json:"current_balance" gorm:"type:decimal(10,2);default:0.00;comment:当前余额"`
+	Remark              string        `json:"remark" gorm:"type:varchar(255);comment:备注"`
+	IsDefault           bool          `json:"is_default" gorm:"default:false;comment:是否默认账户"`
+	Nature              AccountNature `json:"nature" gorm:"type:varchar(20);default:asset;comment:账户性质 (asset, liability)"` // 负债账户的余额以负数表示欠款
+	ExcludeFromNetWorth bool          `json:"exclude_from_net_worth" gorm:"default:false;comment:是否不计入净资产"`
+	IsArchived          bool          `json:"is_archived" gorm:"index;default:false;comment:是否已归档 (已关闭)"`
+	ClosedAt            *time.Time    `json:"closed_at" gorm:"comment:关闭日期"`
 }
 
 // TableName 指定表名
@@ -35,6 +60,19 @@ func (a *Account) TableName() string {
 	return "bookkeeping_accounts"
 }
 
+// IsLiability 判断账户是否为负债账户
+func (a *Account) IsLiability() bool {
+	return a.Nature == AccountNatureLiability
+}
+
+// BeforeCreate 钩子，未指定账户性质时按账户类型设置默认值
+func (a *Account) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.Nature == "" {
+		a.Nature = DefaultAccountNature(a.Type)
+	}
+	return nil
+}
+
 // AfterCreate 钩子，在创建账户后，如果设置了初始余额，则将当前余额也设置为初始余额
 func (a *Account) AfterCreate(tx *gorm.DB) (err error) {
 	if a.InitialBalance != 0 && a.CurrentBalance == 0 {
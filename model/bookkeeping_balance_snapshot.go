@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AccountBalanceSnapshot 账户余额快照模型
+// 记录账户在某一天结束时的余额，用于加速历史余额查询
+type AccountBalanceSnapshot struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	UserID       uint      `json:"user_id" gorm:"index;comment:用户ID"`
+	AccountID    uint      `json:"account_id" gorm:"uniqueIndex:idx_account_snapshot_date;comment:账户ID"`
+	SnapshotDate time.Time `json:"snapshot_date" gorm:"type:date;uniqueIndex:idx_account_snapshot_date;comment:快照日期 (当日结束时的余额)"`
+	Balance      float64   `json:"balance" gorm:"type:decimal(10,2);not null;comment:快照余额"`
+}
+
+// TableName 指定表名
+func (s *AccountBalanceSnapshot) TableName() string {
+	return "bookkeeping_account_balance_snapshots"
+}
+
+// InvalidateBalanceSnapshots 删除账户在指定日期及之后的余额快照
+// 交易发生变动后，变动日期之后的快照都已失效，会在下次查询时重新生成
+func InvalidateBalanceSnapshots(tx *gorm.DB, accountID uint, from time.Time) error {
+	return tx.Where("account_id = ? AND snapshot_date >= ?", accountID, from.Format("2006-01-02")).
+		Delete(&AccountBalanceSnapshot{}).Error
+}
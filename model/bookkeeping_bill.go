@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// BillAmountType 账单金额类型
+type BillAmountType string
+
+const (
+	BillAmountFixed     BillAmountType = "fixed"     // 固定金额 (如房租、订阅)
+	BillAmountEstimated BillAmountType = "estimated" // 预估金额 (如水电费)，付款时填写实际金额
+)
+
+// BillFrequency 账单的到期频率
+type BillFrequency string
+
+const (
+	BillFrequencyOnce      BillFrequency = "once"      // 一次性
+	BillFrequencyWeekly    BillFrequency = "weekly"    // 每周
+	BillFrequencyMonthly   BillFrequency = "monthly"   // 每月
+	BillFrequencyQuarterly BillFrequency = "quarterly" // 每季度
+	BillFrequencyYearly    BillFrequency = "yearly"    // 每年
+)
+
+// Bill 周期性账单 (房租、水电、保险等)，每一期的到期日期都从首次到期日期按频率推算
+// 标记付款时从付款账户生成一笔支出，PaidCount 加1，NextDueDate 移到下一期；全部付清后 NextDueDate 为空
+type Bill struct {
+	global.GlyModel
+	UserID        uint           `json:"user_id" gorm:"index;comment:用户ID"`
+	Name          string         `json:"name" gorm:"type:varchar(100);not null;comment:账单名称"`
+	Payee         string         `json:"payee" gorm:"type:varchar(100);comment:收款方"`
+	Amount        float64        `json:"amount" gorm:"type:decimal(12,2);not null;comment:每期金额 (预估金额类型为预估值)"`
+	AmountType    BillAmountType `json:"amount_type" gorm:"type:varchar(20);default:fixed;comment:金额类型 (fixed, estimated)"`
+	CategoryID    uint           `json:"category_id" gorm:"index;comment:支出分类ID"`
+	AccountID     uint           `json:"account_id" gorm:"index;comment:付款账户ID"`
+	Frequency     BillFrequency  `json:"frequency" gorm:"type:varchar(20);not null;comment:到期频率 (once, weekly, monthly, quarterly, yearly)"`
+	IntervalCount int            `json:"interval_count" gorm:"default:1;comment:每几个频率周期到期一次，如 frequency 为 monthly、interval_count 为2表示每两个月"`
+	FirstDueDate  time.Time      `json:"first_due_date" gorm:"type:date;not null;comment:首次到期日期"`
+	EndDate       *time.Time     `json:"end_date" gorm:"type:date;comment:最后到期日期，为空表示长期"`
+	PaidCount     int            `json:"paid_count" gorm:"default:0;comment:已付期数"`
+	NextDueDate   *time.Time     `json:"next_due_date" gorm:"type:date;index;comment:下一期未付的到期日期，全部付清后为空"`
+	IsActive      bool           `json:"is_active" gorm:"default:true;comment:是否启用，暂停的账单不提醒也不出现在即将付款列表中"`
+	Notes         string         `json:"notes" gorm:"type:varchar(255);comment:备注"`
+
+	// Associations
+	Category Category `json:"category" gorm:"foreignKey:CategoryID"`
+	Account  Account  `json:"account" gorm:"foreignKey:AccountID"`
+}
+
+// TableName 指定表名
+func (b *Bill) TableName() string {
+	return "bookkeeping_bills"
+}
+
+// BillPayment 账单的付款记录，每一期付款对应一笔支出流水
+type BillPayment struct {
+	global.GlyModel
+	UserID        uint      `json:"user_id" gorm:"index;comment:用户ID"`
+	BillID        uint      `json:"bill_id" gorm:"index;comment:账单ID"`
+	Period        int       `json:"period" gorm:"comment:期数 (从1开始)"`
+	DueDate       time.Time `json:"due_date" gorm:"type:date;comment:该期的到期日期"`
+	PaidDate      time.Time `json:"paid_date" gorm:"not null;comment:付款日期"`
+	Amount        float64   `json:"amount" gorm:"type:decimal(12,2);not null;comment:实际付款金额"`
+	AccountID     uint      `json:"account_id" gorm:"index;comment:付款账户ID"`
+	TransactionID *uint     `json:"transaction_id" gorm:"comment:对应的支出流水ID"`
+	Notes         string    `json:"notes" gorm:"type:varchar(255);comment:备注"`
+}
+
+// TableName 指定表名
+func (p *BillPayment) TableName() string {
+	return "bookkeeping_bill_payments"
+}
+
+// BillCalendarFeed 用户账单日历 (iCalendar) 订阅地址的密钥，持有密钥即可读取到期日历，不需要登录
+type BillCalendarFeed struct {
+	global.GlyModel
+	UserID uint   `json:"user_id" gorm:"uniqueIndex;comment:用户ID"`
+	Token  string `json:"-" gorm:"type:varchar(64);uniqueIndex;comment:订阅密钥"`
+}
+
+// TableName 指定表名
+func (f *BillCalendarFeed) TableName() string {
+	return "bookkeeping_bill_calendar_feeds"
+}
@@ -14,31 +14,63 @@ const (
 	BudgetTypeCategory BudgetType = "category" // 分类预算
 )
 
+// BudgetKind 预算方向，支出预算限制花费上限，收入目标跟踪收入是否达到目标金额
+type BudgetKind string
+
+const (
+	BudgetKindExpense BudgetKind = "expense" // 支出预算
+	BudgetKindIncome  BudgetKind = "income"  // 收入目标 (如每季度的副业收入)
+)
+
 // BudgetPeriod 预算周期
 type BudgetPeriod string
 
 const (
-	BudgetPeriodWeekly  BudgetPeriod = "weekly"  // 每周
-	BudgetPeriodMonthly BudgetPeriod = "monthly" // 每月
-	BudgetPeriodYearly  BudgetPeriod = "yearly"  // 每年
+	BudgetPeriodWeekly    BudgetPeriod = "weekly"    // 每周
+	BudgetPeriodBiweekly  BudgetPeriod = "biweekly"  // 每两周
+	BudgetPeriodMonthly   BudgetPeriod = "monthly"   // 每月
+	BudgetPeriodQuarterly BudgetPeriod = "quarterly" // 每季度
+	BudgetPeriodYearly    BudgetPeriod = "yearly"    // 每年
+	BudgetPeriodCustom    BudgetPeriod = "custom"    // 自定义天数
+	BudgetPeriodOnce      BudgetPeriod = "once"      // 一次性，从开始日期到结束日期 (如一次旅行)
+)
+
+// BudgetRolloverMode 预算结转方式，决定上一周期的结余或超支是否计入下一周期
+type BudgetRolloverMode string
+
+const (
+	BudgetRolloverNone    BudgetRolloverMode = "none"    // 不结转，每个周期重新开始
+	BudgetRolloverSurplus BudgetRolloverMode = "surplus" // 只结转结余
+	BudgetRolloverDeficit BudgetRolloverMode = "deficit" // 只结转超支
+	BudgetRolloverBoth    BudgetRolloverMode = "both"    // 结余和超支都结转
 )
 
 // Budget 预算模型
 type Budget struct {
 	global.GlyModel
-	UserID      uint         `json:"user_id" gorm:"index;comment:用户ID"`
-	Name        string       `json:"name" gorm:"type:varchar(100);not null;comment:预算名称"`
-	Type        BudgetType   `json:"type" gorm:"type:varchar(50);not null;comment:预算类型 (overall, category)"`
-	Period      BudgetPeriod `json:"period" gorm:"type:varchar(50);not null;comment:预算周期 (weekly, monthly, yearly)"`
-	Amount      float64      `json:"amount" gorm:"type:decimal(10,2);not null;comment:预算金额"`
-	StartDate   time.Time    `json:"start_date" gorm:"not null;comment:开始日期"`
-	CategoryID  *uint        `json:"category_id" gorm:"index;comment:分类ID (当类型为分类预算时使用)"` // 指针类型，允许为空
-	NotifyRate  float64      `json:"notify_rate" gorm:"type:decimal(5,2);default:0.80;comment:提醒阈值 (如: 0.8 表示达到80%时提醒)"`
-	Description string       `json:"description" gorm:"type:varchar(255);comment:备注"`
-	IsActive    bool         `json:"is_active" gorm:"default:true;comment:是否激活"`
+	UserID       uint               `json:"user_id" gorm:"index;comment:用户ID"`
+	Name         string             `json:"name" gorm:"type:varchar(100);not null;comment:预算名称"`
+	Type         BudgetType         `json:"type" gorm:"type:varchar(50);not null;comment:预算类型 (overall, category)"`
+	Kind         BudgetKind         `json:"kind" gorm:"type:varchar(20);default:expense;comment:预算方向 (expense, income)"`
+	Period       BudgetPeriod       `json:"period" gorm:"type:varchar(50);not null;comment:预算周期 (weekly, biweekly, monthly, quarterly, yearly, custom, once)"`
+	PeriodDays   int                `json:"period_days" gorm:"default:0;comment:自定义周期的天数 (周期为 custom 时使用)"`
+	Amount       float64            `json:"amount" gorm:"type:decimal(10,2);not null;comment:预算金额"`
+	StartDate    time.Time          `json:"start_date" gorm:"not null;comment:开始日期"`
+	EndDate      *time.Time         `json:"end_date" gorm:"comment:结束日期 (为空表示长期有效，过了结束日期自动停用)"`
+	CategoryID   *uint              `json:"category_id" gorm:"index;comment:分类ID (当类型为分类预算时使用)"` // 指针类型，允许为空
+	NotifyRate   float64            `json:"notify_rate" gorm:"type:decimal(5,2);default:0.80;comment:提醒阈值 (如: 0.8 表示达到80%时提醒)"`
+	Description  string             `json:"description" gorm:"type:varchar(255);comment:备注"`
+	IsActive     bool               `json:"is_active" gorm:"default:true;comment:是否激活"`
+	RolloverMode BudgetRolloverMode `json:"rollover_mode" gorm:"type:varchar(20);default:none;comment:结转方式 (none, surplus, deficit, both)"`
+	RolloverCap  *float64           `json:"rollover_cap" gorm:"type:decimal(10,2);comment:结转金额上限 (绝对值，为空表示不限制)"`
+	Tags         string             `json:"tags" gorm:"type:varchar(255);comment:限定标签 (逗号分隔，交易包含任一标签即计入)"`
+	Payee        string             `json:"payee" gorm:"type:varchar(100);comment:限定收款方 (包含匹配)"`
+	IsEnvelope   bool               `json:"is_envelope" gorm:"default:false;comment:是否为信封 (零基预算模式下每月的金额来自分配)"`
 
 	// Associations
-	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"` // 关联的分类
+	Category   *Category        `json:"category,omitempty" gorm:"foreignKey:CategoryID"` // 关联的分类
+	Categories []BudgetCategory `json:"categories,omitempty" gorm:"foreignKey:BudgetID"` // 计入或排除的其他分类
+	Accounts   []BudgetAccount  `json:"accounts,omitempty" gorm:"foreignKey:BudgetID"`   // 限定的账户
 }
 
 // TableName 指定表名
@@ -46,6 +78,31 @@ func (b *Budget) TableName() string {
 	return "bookkeeping_budgets"
 }
 
+// BudgetCategory 预算统计范围中的分类，Exclude 为 true 时表示排除该分类，均包含其子分类
+type BudgetCategory struct {
+	global.GlyModel
+	BudgetID   uint `json:"budget_id" gorm:"index;comment:预算ID"`
+	CategoryID uint `json:"category_id" gorm:"index;comment:分类ID"`
+	Exclude    bool `json:"exclude" gorm:"default:false;comment:是否为排除的分类"`
+}
+
+// TableName 指定表名
+func (c *BudgetCategory) TableName() string {
+	return "bookkeeping_budget_categories"
+}
+
+// BudgetAccount 预算统计范围限定的账户
+type BudgetAccount struct {
+	global.GlyModel
+	BudgetID  uint `json:"budget_id" gorm:"index;comment:预算ID"`
+	AccountID uint `json:"account_id" gorm:"index;comment:账户ID"`
+}
+
+// TableName 指定表名
+func (a *BudgetAccount) TableName() string {
+	return "bookkeeping_budget_accounts"
+}
+
 // BudgetProgress 预算进度视图模型（非数据库表）
 // 用于查询和展示预算进度
 type BudgetProgress struct {
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// BudgetThresholdLevel 预算在周期内达到的阈值级别
+type BudgetThresholdLevel string
+
+const (
+	BudgetThresholdWarning  BudgetThresholdLevel = "warning"  // 达到提醒阈值 (NotifyRate)
+	BudgetThresholdExceeded BudgetThresholdLevel = "exceeded" // 超出预算 (100%)
+)
+
+// BudgetThresholdEvent 预算在某个周期内跨过阈值的记录，每个预算每个周期每个级别只记录一次
+type BudgetThresholdEvent struct {
+	global.GlyModel
+	UserID          uint                 `json:"user_id" gorm:"index;comment:用户ID"`
+	BudgetID        uint                 `json:"budget_id" gorm:"uniqueIndex:idx_budget_threshold_event;comment:预算ID"`
+	PeriodStart     time.Time            `json:"period_start" gorm:"type:date;uniqueIndex:idx_budget_threshold_event;comment:周期开始日期"`
+	Level           BudgetThresholdLevel `json:"level" gorm:"type:varchar(20);uniqueIndex:idx_budget_threshold_event;comment:阈值级别 (warning, exceeded)"`
+	TransactionID   *uint                `json:"transaction_id" gorm:"comment:使预算跨过阈值的交易ID，由后台扫描发现时为空"`
+	SpentAmount     float64              `json:"spent_amount" gorm:"type:decimal(12,2);comment:跨过阈值时的已花费金额"`
+	EffectiveAmount float64              `json:"effective_amount" gorm:"type:decimal(12,2);comment:跨过阈值时的实际可用金额"`
+	UsageRate       float64              `json:"usage_rate" gorm:"type:decimal(12,4);comment:跨过阈值时的使用率"`
+}
+
+// TableName 指定表名
+func (e *BudgetThresholdEvent) TableName() string {
+	return "bookkeeping_budget_threshold_events"
+}
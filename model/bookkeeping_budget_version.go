@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// BudgetAmountVersion 预算金额的历史版本，修改预算金额时从当前周期开始生效，之前的周期保留原来的金额
+type BudgetAmountVersion struct {
+	global.GlyModel
+	UserID        uint      `json:"user_id" gorm:"index;comment:用户ID"`
+	BudgetID      uint      `json:"budget_id" gorm:"index;comment:预算ID"`
+	Amount        float64   `json:"amount" gorm:"type:decimal(10,2);not null;comment:预算金额"`
+	EffectiveFrom time.Time `json:"effective_from" gorm:"not null;comment:生效日期 (从该日期开始的周期使用此金额)"`
+}
+
+// TableName 指定表名
+func (v *BudgetAmountVersion) TableName() string {
+	return "bookkeeping_budget_amount_versions"
+}
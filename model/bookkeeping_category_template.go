@@ -0,0 +1,39 @@
+package model
+
+import "github.com/dotdancer/gogofly/global"
+
+// CategoryTemplate 分类模板，新用户注册时或用户主动应用时按模板批量创建分类
+// 同一个模板编码可以有多个语言版本 (Locale)
+type CategoryTemplate struct {
+	global.GlyModel
+	Code        string `json:"code" gorm:"type:varchar(50);index:idx_category_template_code_locale;not null;comment:模板编码 (household, student, freelancer...)"`
+	Locale      string `json:"locale" gorm:"type:varchar(20);index:idx_category_template_code_locale;not null;comment:语言 (zh-CN, en-US...)"`
+	Name        string `json:"name" gorm:"type:varchar(100);not null;comment:模板名称"`
+	Description string `json:"description" gorm:"type:varchar(255);comment:模板描述"`
+	IsBuiltin   bool   `json:"is_builtin" gorm:"default:false;comment:是否为系统内置模板"`
+	IsActive    bool   `json:"is_active" gorm:"default:true;comment:是否对用户可见"`
+
+	// Associations
+	Items []CategoryTemplateItem `json:"items,omitempty" gorm:"foreignKey:TemplateID"`
+}
+
+// TableName 指定表名
+func (t *CategoryTemplate) TableName() string {
+	return "bookkeeping_category_templates"
+}
+
+// CategoryTemplateItem 分类模板中的一个分类，通过 ParentID 指向同一模板中的父节点
+type CategoryTemplateItem struct {
+	global.GlyModel
+	TemplateID uint         `json:"template_id" gorm:"index;comment:模板ID"`
+	ParentID   *uint        `json:"parent_id" gorm:"index;comment:父节点ID (同一模板中的模板分类ID)"`
+	Name       string       `json:"name" gorm:"type:varchar(100);not null;comment:分类名称"`
+	Type       CategoryType `json:"type" gorm:"type:varchar(50);not null;comment:分类类型 (income, expense)"`
+	Icon       string       `json:"icon" gorm:"type:varchar(100);comment:图标"`
+	SortOrder  int          `json:"sort_order" gorm:"default:0;comment:排序字段"`
+}
+
+// TableName 指定表名
+func (i *CategoryTemplateItem) TableName() string {
+	return "bookkeeping_category_template_items"
+}
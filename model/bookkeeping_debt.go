@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// DebtDirection 借贷方向
+type DebtDirection string
+
+const (
+	DebtDirectionLend   DebtDirection = "lend"   // 借出（应收）
+	DebtDirectionBorrow DebtDirection = "borrow" // 借入（应付）
+)
+
+// DebtStatus 借贷状态
+type DebtStatus string
+
+const (
+	DebtStatusOpen    DebtStatus = "open"    // 未结清
+	DebtStatusSettled DebtStatus = "settled" // 已结清
+)
+
+// Counterparty 借贷往来的联系人
+type Counterparty struct {
+	global.GlyModel
+	UserID uint   `json:"user_id" gorm:"index;comment:用户ID"`
+	Name   string `json:"name" gorm:"type:varchar(100);not null;comment:姓名"`
+	Phone  string `json:"phone" gorm:"type:varchar(30);comment:联系电话"`
+	Remark string `json:"remark" gorm:"type:varchar(255);comment:备注"`
+}
+
+// TableName 指定表名
+func (c *Counterparty) TableName() string {
+	return "bookkeeping_counterparties"
+}
+
+// Debt 个人借贷记录（借给别人或向别人借的钱）
+// 借出和借入都会在账户上生成资金流水，但不计入收支统计
+type Debt struct {
+	global.GlyModel
+	UserID         uint          `json:"user_id" gorm:"index;comment:用户ID"`
+	CounterpartyID uint          `json:"counterparty_id" gorm:"index;comment:联系人ID"`
+	Direction      DebtDirection `json:"direction" gorm:"type:varchar(20);not null;comment:方向 (lend, borrow)"`
+	AccountID      uint          `json:"account_id" gorm:"index;comment:借出或借入资金的账户ID"`
+	Amount         float64       `json:"amount" gorm:"type:decimal(10,2);not null;comment:借贷金额"`
+	RepaidAmount   float64       `json:"repaid_amount" gorm:"type:decimal(10,2);default:0;comment:已还金额"`
+	DebtDate       time.Time     `json:"debt_date" gorm:"not null;comment:借贷日期"`
+	DueDate        *time.Time    `json:"due_date" gorm:"index;comment:约定还款日期"`
+	Status         DebtStatus    `json:"status" gorm:"type:varchar(20);index;default:open;comment:状态 (open, settled)"`
+	TransactionID  *uint         `json:"transaction_id" gorm:"comment:对应的资金流水ID"`
+	Notes          string        `json:"notes" gorm:"type:varchar(255);comment:备注"`
+
+	// Associations
+	Counterparty Counterparty `json:"counterparty" gorm:"foreignKey:CounterpartyID"`
+}
+
+// TableName 指定表名
+func (d *Debt) TableName() string {
+	return "bookkeeping_debts"
+}
+
+// Outstanding 返回尚未归还的金额
+func (d *Debt) Outstanding() float64 {
+	return d.Amount - d.RepaidAmount
+}
+
+// DebtRepayment 借贷的还款记录，支持部分还款
+type DebtRepayment struct {
+	global.GlyModel
+	UserID        uint      `json:"user_id" gorm:"index;comment:用户ID"`
+	DebtID        uint      `json:"debt_id" gorm:"index;comment:借贷记录ID"`
+	AccountID     uint      `json:"account_id" gorm:"index;comment:收款或还款账户ID"`
+	Amount        float64   `json:"amount" gorm:"type:decimal(10,2);not null;comment:还款金额"`
+	RepaymentDate time.Time `json:"repayment_date" gorm:"not null;comment:还款日期"`
+	TransactionID *uint     `json:"transaction_id" gorm:"comment:对应的资金流水ID"`
+	Notes         string    `json:"notes" gorm:"type:varchar(255);comment:备注"`
+}
+
+// TableName 指定表名
+func (r *DebtRepayment) TableName() string {
+	return "bookkeeping_debt_repayments"
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/dotdancer/gogofly/global"
+)
+
+// EnvelopeAllocation 信封在某个月分配到的金额合计，由资金分配和移动记录累加
+// 信封是 IsEnvelope 为 true 的分类预算，每月的预算金额即该月的分配金额
+type EnvelopeAllocation struct {
+	global.GlyModel
+	UserID   uint    `json:"user_id" gorm:"index;comment:用户ID"`
+	BudgetID uint    `json:"budget_id" gorm:"index;comment:信封 (预算) ID"`
+	Month    string  `json:"month" gorm:"type:varchar(7);index;comment:月份 (YYYY-MM)"`
+	Amount   float64 `json:"amount" gorm:"type:decimal(12,2);default:0;comment:分配金额"`
+}
+
+// TableName 指定表名
+func (a *EnvelopeAllocation) TableName() string {
+	return "bookkeeping_envelope_allocations"
+}
+
+// EnvelopeMove 信封资金的分配和移动记录，来源或去向为空表示待分配
+type EnvelopeMove struct {
+	global.GlyModel
+	UserID       uint    `json:"user_id" gorm:"index;comment:用户ID"`
+	Month        string  `json:"month" gorm:"type:varchar(7);index;comment:月份 (YYYY-MM)"`
+	FromBudgetID *uint   `json:"from_budget_id" gorm:"index;comment:来源信封ID (为空表示从待分配中分配)"`
+	ToBudgetID   *uint   `json:"to_budget_id" gorm:"index;comment:去向信封ID (为空表示退回待分配)"`
+	Amount       float64 `json:"amount" gorm:"type:decimal(12,2);not null;comment:金额"`
+	Note         string  `json:"note" gorm:"type:varchar(255);comment:备注"`
+}
+
+// TableName 指定表名
+func (m *EnvelopeMove) TableName() string {
+	return "bookkeeping_envelope_moves"
+}
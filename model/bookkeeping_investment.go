@@ -0,0 +1,117 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// SecurityKind 证券品种类型
+type SecurityKind string
+
+const (
+	SecurityKindStock SecurityKind = "stock" // 股票
+	SecurityKindFund  SecurityKind = "fund"  // 基金
+	SecurityKindBond  SecurityKind = "bond"  // 债券
+	SecurityKindOther SecurityKind = "other" // 其他
+)
+
+// InvestmentTradeType 投资交易类型
+type InvestmentTradeType string
+
+const (
+	InvestmentTradeBuy      InvestmentTradeType = "buy"      // 买入
+	InvestmentTradeSell     InvestmentTradeType = "sell"     // 卖出
+	InvestmentTradeDividend InvestmentTradeType = "dividend" // 分红
+	InvestmentTradeSplit    InvestmentTradeType = "split"    // 拆股/送股
+)
+
+// Security 证券（股票、基金等）模型
+type Security struct {
+	global.GlyModel
+	UserID uint         `json:"user_id" gorm:"index;comment:用户ID"`
+	Symbol string       `json:"symbol" gorm:"type:varchar(30);not null;comment:证券代码"`
+	Name   string       `json:"name" gorm:"type:varchar(100);not null;comment:证券名称"`
+	Kind   SecurityKind `json:"kind" gorm:"type:varchar(20);not null;comment:品种类型 (stock, fund, bond, other)"`
+	Remark string       `json:"remark" gorm:"type:varchar(255);comment:备注"`
+}
+
+// TableName 指定表名
+func (s *Security) TableName() string {
+	return "bookkeeping_securities"
+}
+
+// SecurityPrice 证券价格表，由接口或CSV导入维护
+type SecurityPrice struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	UserID     uint      `json:"user_id" gorm:"index;comment:用户ID"`
+	SecurityID uint      `json:"security_id" gorm:"uniqueIndex:idx_security_price_date;comment:证券ID"`
+	PriceDate  time.Time `json:"price_date" gorm:"type:date;uniqueIndex:idx_security_price_date;comment:价格日期"`
+	Price      float64   `json:"price" gorm:"type:decimal(18,4);not null;comment:收盘价/净值"`
+	Source     string    `json:"source" gorm:"type:varchar(20);comment:来源 (api, csv)"`
+}
+
+// TableName 指定表名
+func (p *SecurityPrice) TableName() string {
+	return "bookkeeping_security_prices"
+}
+
+// InvestmentTrade 投资交易记录模型
+// 买入、卖出和分红会生成对应的资金流水 (TransactionID)，拆股只调整持仓批次
+type InvestmentTrade struct {
+	global.GlyModel
+	UserID        uint                `json:"user_id" gorm:"index;comment:用户ID"`
+	AccountID     uint                `json:"account_id" gorm:"index;comment:投资账户ID"`
+	SecurityID    uint                `json:"security_id" gorm:"index;comment:证券ID"`
+	Type          InvestmentTradeType `json:"type" gorm:"type:varchar(20);not null;comment:交易类型 (buy, sell, dividend, split)"`
+	TradeDate     time.Time           `json:"trade_date" gorm:"not null;comment:交易日期"`
+	Quantity      float64             `json:"quantity" gorm:"type:decimal(18,6);default:0;comment:数量"`
+	Price         float64             `json:"price" gorm:"type:decimal(18,4);default:0;comment:成交价"`
+	Fee           float64             `json:"fee" gorm:"type:decimal(10,2);default:0;comment:手续费"`
+	Amount        float64             `json:"amount" gorm:"type:decimal(12,2);default:0;comment:资金变动金额 (买入为支出，卖出和分红为收入)"`
+	SplitRatio    float64             `json:"split_ratio" gorm:"type:decimal(10,4);default:0;comment:拆股比例 (如2表示1拆2)"`
+	RealizedGain  float64             `json:"realized_gain" gorm:"type:decimal(12,2);default:0;comment:卖出实现的收益"`
+	TransactionID *uint               `json:"transaction_id" gorm:"index;comment:对应的资金流水ID"`
+	Notes         string              `json:"notes" gorm:"type:varchar(255);comment:备注"`
+
+	// Associations
+	Security *Security `json:"security,omitempty" gorm:"foreignKey:SecurityID"`
+}
+
+// TableName 指定表名
+func (t *InvestmentTrade) TableName() string {
+	return "bookkeeping_investment_trades"
+}
+
+// InvestmentLot 持仓批次模型，每笔买入形成一个批次，卖出按先进先出扣减
+type InvestmentLot struct {
+	global.GlyModel
+	UserID            uint      `json:"user_id" gorm:"index;comment:用户ID"`
+	AccountID         uint      `json:"account_id" gorm:"index;comment:投资账户ID"`
+	SecurityID        uint      `json:"security_id" gorm:"index;comment:证券ID"`
+	TradeID           uint      `json:"trade_id" gorm:"index;comment:买入交易ID"`
+	AcquiredDate      time.Time `json:"acquired_date" gorm:"not null;comment:买入日期"`
+	OriginalQuantity  float64   `json:"original_quantity" gorm:"type:decimal(18,6);not null;comment:买入数量 (按拆股调整后)"`
+	RemainingQuantity float64   `json:"remaining_quantity" gorm:"type:decimal(18,6);not null;comment:剩余数量"`
+	CostPerUnit       float64   `json:"cost_per_unit" gorm:"type:decimal(18,6);not null;comment:单位成本 (含手续费)"`
+}
+
+// TableName 指定表名
+func (l *InvestmentLot) TableName() string {
+	return "bookkeeping_investment_lots"
+}
+
+// InvestmentLotSale 卖出扣减持仓批次的明细，删除卖出交易时按明细恢复批次的剩余数量
+type InvestmentLotSale struct {
+	global.GlyModel
+	TradeID  uint    `json:"trade_id" gorm:"index;comment:卖出交易ID"`
+	LotID    uint    `json:"lot_id" gorm:"index;comment:持仓批次ID"`
+	Quantity float64 `json:"quantity" gorm:"type:decimal(18,6);not null;comment:扣减数量"`
+}
+
+// TableName 指定表名
+func (s *InvestmentLotSale) TableName() string {
+	return "bookkeeping_investment_lot_sales"
+}
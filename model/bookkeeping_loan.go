@@ -0,0 +1,93 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// LoanMethod 贷款还款方式
+type LoanMethod string
+
+const (
+	LoanMethodEqualInstallment LoanMethod = "equal_installment" // 等额本息
+	LoanMethodEqualPrincipal   LoanMethod = "equal_principal"   // 等额本金
+)
+
+// LoanStatus 贷款状态
+type LoanStatus string
+
+const (
+	LoanStatusActive  LoanStatus = "active"   // 还款中
+	LoanStatusPaidOff LoanStatus = "paid_off" // 已结清
+)
+
+// LoanRepaymentKind 还款类型
+type LoanRepaymentKind string
+
+const (
+	LoanRepaymentRegular    LoanRepaymentKind = "regular"    // 按期还款
+	LoanRepaymentPrepayment LoanRepaymentKind = "prepayment" // 提前还款
+)
+
+// LoanPrepaymentMode 提前还款后的重算方式
+type LoanPrepaymentMode string
+
+const (
+	LoanPrepaymentShortenTerm   LoanPrepaymentMode = "shorten_term"   // 月供不变，缩短期限
+	LoanPrepaymentReducePayment LoanPrepaymentMode = "reduce_payment" // 期限不变，减少月供
+)
+
+// Loan 贷款模型（房贷、车贷等），关联一个负债账户
+// 剩余本金和剩余期数随还款和提前还款更新，还款计划由当前状态推算
+type Loan struct {
+	global.GlyModel
+	UserID             uint       `json:"user_id" gorm:"index;comment:用户ID"`
+	AccountID          uint       `json:"account_id" gorm:"index;comment:负债账户ID"`
+	Name               string     `json:"name" gorm:"type:varchar(100);not null;comment:贷款名称"`
+	Principal          float64    `json:"principal" gorm:"type:decimal(12,2);not null;comment:贷款本金"`
+	AnnualRate         float64    `json:"annual_rate" gorm:"type:decimal(8,4);not null;comment:年利率 (百分比，如4.9表示4.9%)"`
+	TermMonths         int        `json:"term_months" gorm:"not null;comment:贷款期数 (月)"`
+	Method             LoanMethod `json:"method" gorm:"type:varchar(30);not null;comment:还款方式 (equal_installment, equal_principal)"`
+	StartDate          time.Time  `json:"start_date" gorm:"not null;comment:放款日期，第一期还款日为放款日后一个月"`
+	InterestCategoryID *uint      `json:"interest_category_id" gorm:"comment:利息支出的分类ID"`
+	RemainingPrincipal float64    `json:"remaining_principal" gorm:"type:decimal(12,2);not null;comment:剩余本金"`
+	RemainingTerm      int        `json:"remaining_term" gorm:"not null;comment:剩余期数"`
+	PaidPeriods        int        `json:"paid_periods" gorm:"default:0;comment:已还期数"`
+	TotalInterestPaid  float64    `json:"total_interest_paid" gorm:"type:decimal(12,2);default:0;comment:累计已还利息"`
+	Status             LoanStatus `json:"status" gorm:"type:varchar(20);default:active;comment:状态 (active, paid_off)"`
+	Notes              string     `json:"notes" gorm:"type:varchar(255);comment:备注"`
+
+	// Associations
+	Account Account `json:"account" gorm:"foreignKey:AccountID"`
+}
+
+// TableName 指定表名
+func (l *Loan) TableName() string {
+	return "bookkeeping_loans"
+}
+
+// LoanRepayment 贷款还款记录模型
+// 每次还款从还款账户拆分生成本金和利息两笔流水，并向贷款账户记入本金
+type LoanRepayment struct {
+	global.GlyModel
+	UserID                 uint               `json:"user_id" gorm:"index;comment:用户ID"`
+	LoanID                 uint               `json:"loan_id" gorm:"index;comment:贷款ID"`
+	Kind                   LoanRepaymentKind  `json:"kind" gorm:"type:varchar(20);not null;comment:还款类型 (regular, prepayment)"`
+	Period                 int                `json:"period" gorm:"default:0;comment:期数 (提前还款为0)"`
+	RepaymentDate          time.Time          `json:"repayment_date" gorm:"not null;comment:还款日期"`
+	Principal              float64            `json:"principal" gorm:"type:decimal(12,2);not null;comment:本金"`
+	Interest               float64            `json:"interest" gorm:"type:decimal(12,2);default:0;comment:利息"`
+	Amount                 float64            `json:"amount" gorm:"type:decimal(12,2);not null;comment:还款总额"`
+	FromAccountID          uint               `json:"from_account_id" gorm:"index;comment:还款账户ID"`
+	PrepaymentMode         LoanPrepaymentMode `json:"prepayment_mode" gorm:"type:varchar(20);comment:提前还款重算方式 (shorten_term, reduce_payment)"`
+	PrincipalTransactionID *uint              `json:"principal_transaction_id" gorm:"comment:还款账户的本金流水ID"`
+	InterestTransactionID  *uint              `json:"interest_transaction_id" gorm:"comment:还款账户的利息流水ID"`
+	LoanTransactionID      *uint              `json:"loan_transaction_id" gorm:"comment:贷款账户的本金流水ID"`
+	Notes                  string             `json:"notes" gorm:"type:varchar(255);comment:备注"`
+}
+
+// TableName 指定表名
+func (r *LoanRepayment) TableName() string {
+	return "bookkeeping_loan_repayments"
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// NotificationEvent 产生通知的事件类型
+type NotificationEvent string
+
+const (
+	NotificationEventBudgetThreshold  NotificationEvent = "budget_threshold"  // 预算达到提醒阈值或超支
+	NotificationEventBillDue          NotificationEvent = "bill_due"          // 账单即将到期
+	NotificationEventLargeTransaction NotificationEvent = "large_transaction" // 大额交易
+	NotificationEventImportCompleted  NotificationEvent = "import_completed"  // 导入完成
+)
+
+// NotificationEvents 所有通知事件类型，用于生成默认的通知偏好
+var NotificationEvents = []NotificationEvent{
+	NotificationEventBudgetThreshold,
+	NotificationEventBillDue,
+	NotificationEventLargeTransaction,
+	NotificationEventImportCompleted,
+}
+
+// Notification 站内通知，同一用户的同一去重键只会产生一条通知
+type Notification struct {
+	global.GlyModel
+	UserID   uint              `json:"user_id" gorm:"uniqueIndex:idx_notification_dedup;index:idx_notification_user_read;comment:用户ID"`
+	Event    NotificationEvent `json:"event" gorm:"type:varchar(30);index;comment:事件类型"`
+	DedupKey string            `json:"-" gorm:"type:varchar(150);uniqueIndex:idx_notification_dedup;comment:去重键，同一事件只通知一次"`
+	Title    string            `json:"title" gorm:"type:varchar(100);comment:标题"`
+	Content  string            `json:"content" gorm:"type:varchar(500);comment:内容"`
+	RefType  string            `json:"ref_type" gorm:"type:varchar(30);comment:关联对象类型 (budget, transaction, loan, debt...)"`
+	RefID    uint              `json:"ref_id" gorm:"comment:关联对象ID"`
+	IsRead   bool              `json:"is_read" gorm:"index:idx_notification_user_read;default:false;comment:是否已读"`
+	ReadAt   *time.Time        `json:"read_at" gorm:"comment:阅读时间"`
+}
+
+// TableName 指定表名
+func (n *Notification) TableName() string {
+	return "bookkeeping_notifications"
+}
+
+// NotificationPreference 用户对某类事件的通知偏好，没有记录时使用默认偏好
+type NotificationPreference struct {
+	global.GlyModel
+	UserID   uint              `json:"user_id" gorm:"uniqueIndex:idx_notification_preference;comment:用户ID"`
+	Event    NotificationEvent `json:"event" gorm:"type:varchar(30);uniqueIndex:idx_notification_preference;comment:事件类型"`
+	Enabled  bool              `json:"enabled" gorm:"default:true;comment:是否产生通知"`
+	Channels string            `json:"channels" gorm:"type:varchar(100);comment:站内通知之外的推送渠道，逗号分隔 (email, webhook, push)"`
+}
+
+// TableName 指定表名
+func (p *NotificationPreference) TableName() string {
+	return "bookkeeping_notification_preferences"
+}
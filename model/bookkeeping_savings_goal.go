@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// SavingsGoalProgressMode 储蓄目标进度的计算方式
+type SavingsGoalProgressMode string
+
+const (
+	SavingsGoalProgressBalance       SavingsGoalProgressMode = "balance"       // 按关联账户的当前余额合计
+	SavingsGoalProgressContributions SavingsGoalProgressMode = "contributions" // 按开始日期以来关联账户的净存入
+)
+
+// SavingsGoal 储蓄目标，如应急金、购房首付
+type SavingsGoal struct {
+	global.GlyModel
+	UserID       uint                    `json:"user_id" gorm:"index;comment:用户ID"`
+	Name         string                  `json:"name" gorm:"type:varchar(100);not null;comment:目标名称"`
+	TargetAmount float64                 `json:"target_amount" gorm:"type:decimal(12,2);not null;comment:目标金额"`
+	StartDate    time.Time               `json:"start_date" gorm:"not null;comment:开始日期 (从该日起统计存入)"`
+	TargetDate   *time.Time              `json:"target_date" gorm:"comment:目标日期 (为空表示不限期)"`
+	ProgressMode SavingsGoalProgressMode `json:"progress_mode" gorm:"type:varchar(20);default:balance;comment:进度计算方式 (balance, contributions)"`
+	Description  string                  `json:"description" gorm:"type:varchar(255);comment:备注"`
+	IsActive     bool                    `json:"is_active" gorm:"default:true;comment:是否激活"`
+
+	// Associations
+	Accounts []SavingsGoalAccount `json:"accounts,omitempty" gorm:"foreignKey:GoalID"` // 关联的账户
+}
+
+// TableName 指定表名
+func (g *SavingsGoal) TableName() string {
+	return "bookkeeping_savings_goals"
+}
+
+// SavingsGoalAccount 储蓄目标关联的账户
+type SavingsGoalAccount struct {
+	global.GlyModel
+	GoalID    uint `json:"goal_id" gorm:"index;comment:储蓄目标ID"`
+	AccountID uint `json:"account_id" gorm:"index;comment:账户ID"`
+}
+
+// TableName 指定表名
+func (a *SavingsGoalAccount) TableName() string {
+	return "bookkeeping_savings_goal_accounts"
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// SplitMethod 分摊方式
+type SplitMethod string
+
+const (
+	SplitMethodEqual  SplitMethod = "equal"  // 平均分摊
+	SplitMethodShares SplitMethod = "shares" // 按份数分摊
+	SplitMethodExact  SplitMethod = "exact"  // 按指定金额分摊
+)
+
+// SplitGroup AA分摊账单组（一次旅行、一次聚餐等）
+type SplitGroup struct {
+	global.GlyModel
+	UserID      uint   `json:"user_id" gorm:"index;comment:用户ID"`
+	Name        string `json:"name" gorm:"type:varchar(100);not null;comment:名称"`
+	Description string `json:"description" gorm:"type:varchar(255);comment:描述"`
+
+	// Associations
+	Members []SplitMember `json:"members" gorm:"foreignKey:GroupID"`
+}
+
+// TableName 指定表名
+func (g *SplitGroup) TableName() string {
+	return "bookkeeping_split_groups"
+}
+
+// SplitMember 账单组成员，每个账单组有且只有一个代表用户本人的成员 (IsSelf)
+type SplitMember struct {
+	global.GlyModel
+	UserID  uint   `json:"user_id" gorm:"index;comment:用户ID"`
+	GroupID uint   `json:"group_id" gorm:"index;comment:账单组ID"`
+	Name    string `json:"name" gorm:"type:varchar(100);not null;comment:成员名称"`
+	IsSelf  bool   `json:"is_self" gorm:"default:false;comment:是否为用户本人"`
+}
+
+// TableName 指定表名
+func (m *SplitMember) TableName() string {
+	return "bookkeeping_split_members"
+}
+
+// SplitExpense 账单组中的一笔共同支出
+// 只有用户本人承担的部分计入分类统计，本人垫付的其他成员份额不计入收支统计
+type SplitExpense struct {
+	global.GlyModel
+	UserID               uint        `json:"user_id" gorm:"index;comment:用户ID"`
+	GroupID              uint        `json:"group_id" gorm:"index;comment:账单组ID"`
+	PayerMemberID        uint        `json:"payer_member_id" gorm:"comment:付款成员ID"`
+	Title                string      `json:"title" gorm:"type:varchar(100);not null;comment:标题"`
+	Amount               float64     `json:"amount" gorm:"type:decimal(10,2);not null;comment:总金额"`
+	ExpenseDate          time.Time   `json:"expense_date" gorm:"not null;comment:消费日期"`
+	Method               SplitMethod `json:"method" gorm:"type:varchar(20);not null;comment:分摊方式 (equal, shares, exact)"`
+	CategoryID           uint        `json:"category_id" gorm:"comment:本人份额的支出分类ID"`
+	AccountID            *uint       `json:"account_id" gorm:"comment:本人付款或记账的账户ID"`
+	OwnTransactionID     *uint       `json:"own_transaction_id" gorm:"comment:本人份额的支出流水ID"`
+	AdvanceTransactionID *uint       `json:"advance_transaction_id" gorm:"comment:本人垫付他人份额的流水ID"`
+	OffsetTransactionID  *uint       `json:"offset_transaction_id" gorm:"comment:他人代付本人份额时的抵消流水ID"`
+	Notes                string      `json:"notes" gorm:"type:varchar(255);comment:备注"`
+
+	// Associations
+	Shares []SplitShare `json:"shares" gorm:"foreignKey:ExpenseID"`
+}
+
+// TableName 指定表名
+func (e *SplitExpense) TableName() string {
+	return "bookkeeping_split_expenses"
+}
+
+// SplitShare 共同支出中每个成员承担的份额
+type SplitShare struct {
+	global.GlyModel
+	ExpenseID uint    `json:"expense_id" gorm:"index;comment:共同支出ID"`
+	MemberID  uint    `json:"member_id" gorm:"index;comment:成员ID"`
+	Weight    float64 `json:"weight" gorm:"type:decimal(10,4);default:0;comment:份数 (按份数分摊时使用)"`
+	Amount    float64 `json:"amount" gorm:"type:decimal(10,2);not null;comment:承担金额"`
+}
+
+// TableName 指定表名
+func (s *SplitShare) TableName() string {
+	return "bookkeeping_split_shares"
+}
+
+// SplitSettlement 成员之间的结算转账
+// 涉及用户本人的结算会在本人账户上生成不计入收支统计的资金流水
+type SplitSettlement struct {
+	global.GlyModel
+	UserID         uint      `json:"user_id" gorm:"index;comment:用户ID"`
+	GroupID        uint      `json:"group_id" gorm:"index;comment:账单组ID"`
+	FromMemberID   uint      `json:"from_member_id" gorm:"comment:付款成员ID"`
+	ToMemberID     uint      `json:"to_member_id" gorm:"comment:收款成员ID"`
+	Amount         float64   `json:"amount" gorm:"type:decimal(10,2);not null;comment:金额"`
+	SettlementDate time.Time `json:"settlement_date" gorm:"not null;comment:结算日期"`
+	AccountID      *uint     `json:"account_id" gorm:"comment:本人收付款账户ID"`
+	TransactionID  *uint     `json:"transaction_id" gorm:"comment:对应的资金流水ID"`
+	Notes          string    `json:"notes" gorm:"type:varchar(255);comment:备注"`
+}
+
+// TableName 指定表名
+func (s *SplitSettlement) TableName() string {
+	return "bookkeeping_split_settlements"
+}
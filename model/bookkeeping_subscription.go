@@ -0,0 +1,32 @@
+package model
+
+import "github.com/dotdancer/gogofly/global"
+
+// SubscriptionStatus 用户对检测出的订阅的处理结果
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusTracked   SubscriptionStatus = "tracked"   // 已确认为订阅，持续跟踪扣费和涨价
+	SubscriptionStatusDismissed SubscriptionStatus = "dismissed" // 已忽略，不再作为候选订阅
+)
+
+// Subscription 用户确认或忽略的订阅，按收款方识别
+// 扣费记录、最近扣费日期和下次预计扣费日期每次查询时从交易记录重新分析，这里只保存确认时的快照
+type Subscription struct {
+	global.GlyModel
+	UserID     uint               `json:"user_id" gorm:"uniqueIndex:idx_subscription_payee;comment:用户ID"`
+	PayeeKey   string             `json:"-" gorm:"type:varchar(100);uniqueIndex:idx_subscription_payee;comment:规范化的收款方 (小写、合并空白)，用于匹配交易"`
+	Payee      string             `json:"payee" gorm:"type:varchar(100);comment:收款方"`
+	Name       string             `json:"name" gorm:"type:varchar(100);comment:订阅名称"`
+	Status     SubscriptionStatus `json:"status" gorm:"type:varchar(20);index;comment:状态 (tracked, dismissed)"`
+	Frequency  BillFrequency      `json:"frequency" gorm:"type:varchar(20);comment:扣费频率 (weekly, monthly, quarterly, yearly)"`
+	Amount     float64            `json:"amount" gorm:"type:decimal(12,2);comment:确认时的每期扣费金额"`
+	CategoryID uint               `json:"category_id" gorm:"index;comment:支出分类ID"`
+	AccountID  uint               `json:"account_id" gorm:"index;comment:扣费账户ID"`
+	Notes      string             `json:"notes" gorm:"type:varchar(255);comment:备注"`
+}
+
+// TableName 指定表名
+func (s *Subscription) TableName() string {
+	return "bookkeeping_subscriptions"
+}
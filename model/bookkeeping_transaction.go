@@ -1,10 +1,14 @@
 package model
 
 import (
+	"errors"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/dotdancer/gogofly/global"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionType 定义交易类型
@@ -19,77 +23,168 @@ const (
 // Transaction 交易流水模型
 type Transaction struct {
 	global.GlyModel
-	UserID          uint            `json:"user_id" gorm:"index;comment:用户ID"`
-	AccountID       uint            `json:"account_id" gorm:"index;comment:账户ID"`
-	Type            TransactionType `json:"type" gorm:"type:varchar(50);not null;comment:交易类型 (income, expense, transfer)"`
-	Amount          float64         `json:"amount" gorm:"type:decimal(10,2);not null;comment:金额"`
-	TransactionDate time.Time       `json:"transaction_date" gorm:"not null;comment:交易日期"`
-	CategoryID      uint            `json:"category_id" gorm:"index;comment:分类ID"`
-	PayeePayer      string          `json:"payee_payer" gorm:"type:varchar(100);comment:收款方/付款方"`
-	Notes           string          `json:"notes" gorm:"type:varchar(255);comment:备注"`
+	UserID           uint            `json:"user_id" gorm:"index;comment:用户ID"`
+	AccountID        uint            `json:"account_id" gorm:"index;comment:账户ID"`
+	Type             TransactionType `json:"type" gorm:"type:varchar(50);not null;comment:交易类型 (income, expense, transfer)"`
+	Amount           float64         `json:"amount" gorm:"type:decimal(10,2);not null;comment:金额"`
+	TransactionDate  time.Time       `json:"transaction_date" gorm:"not null;comment:交易日期"`
+	CategoryID       uint            `json:"category_id" gorm:"index;comment:分类ID"`
+	PayeePayer       string          `json:"payee_payer" gorm:"type:varchar(100);comment:收款方/付款方"`
+	Notes            string          `json:"notes" gorm:"type:varchar(255);comment:备注"`
+	Tags             string          `json:"tags" gorm:"type:varchar(255);comment:标签 (逗号分隔)"`
+	ExcludeFromStats bool            `json:"exclude_from_stats" gorm:"default:false;comment:是否不计入收支统计"` // 投资买卖等业务生成的资金流水只影响余额
 
 	// Associations
 	Account  Account  `json:"account" gorm:"foreignKey:AccountID"`
 	Category Category `json:"category" gorm:"foreignKey:CategoryID"` // Category model will be created next
+
+	previous *Transaction // 更新或删除前的交易状态，由钩子在同一数据库事务内使用
 }
 
+// BalanceDeltaSQL 汇总交易对账户余额影响值的SQL表达式
+// 收入增加余额，支出和转账（视为转出）减少余额
+const BalanceDeltaSQL = "COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE -amount END), 0)"
+
 // TableName 指定表名
 func (t *Transaction) TableName() string {
 	return "bookkeeping_transactions"
 }
 
-// AfterSave 钩子，在保存交易（创建或更新）后，更新关联账户的余额
-func (t *Transaction) AfterSave(tx *gorm.DB) (err error) {
-	return t.UpdateAccountBalance(tx)
+// BalanceDelta 返回该交易对所属账户余额的影响值
+func (t *Transaction) BalanceDelta() float64 {
+	if t.Type == TransactionTypeIncome {
+		return t.Amount
+	}
+	return -t.Amount
 }
 
-// AfterDelete 钩子，在删除交易后，反向更新关联账户的余额
-func (t *Transaction) AfterDelete(tx *gorm.DB) (err error) {
-	// 当删除一笔交易时，需要将金额反向操作以恢复账户余额
-	// 例如，删除一笔支出，则账户余额增加；删除一笔收入，则账户余额减少
-	originalAmount := t.Amount
-	switch t.Type {
-	case TransactionTypeExpense:
-		t.Amount = -originalAmount // 支出变负数，相当于增加余额
-	case TransactionTypeIncome:
-		t.Amount = originalAmount // 收入变正数，相当于减少余额
-	case TransactionTypeTransfer:
-		// 对于转账，删除时需要更复杂的逻辑，取决于这是转出还是转入
-		// 简单处理：假设转账记录的是转出，则删除时余额增加
-		// 如果有明确的转出账户和转入账户，则需要分别处理
-		// 此处简化为，如果删除的是转账，则认为是从该账户转出，所以余额增加
-		// 如果需要更精确，Transaction模型应包含 FromAccountID 和 ToAccountID
-		t.Amount = -originalAmount
-	default:
-		return nil // 其他类型不处理
-	}
-
-	err = t.UpdateAccountBalance(tx)
-	// 恢复原始金额，以免影响后续可能的其他操作
-	t.Amount = originalAmount
+// AfterCreate 钩子，在创建交易后，将交易的影响值累加到关联账户的余额
+func (t *Transaction) AfterCreate(tx *gorm.DB) (err error) {
+	if err = InvalidateBalanceSnapshots(tx, t.AccountID, t.TransactionDate); err != nil {
+		return err
+	}
+	return applyBalanceDeltas(tx, map[uint]float64{t.AccountID: t.BalanceDelta()})
+}
+
+// BeforeUpdate 钩子，锁定并记录更新前的交易，以便更新后按差额调整余额
+func (t *Transaction) BeforeUpdate(tx *gorm.DB) (err error) {
+	t.previous, err = lockTransaction(tx, t.ID)
 	return err
 }
 
-// UpdateAccountBalance 更新账户余额的辅助函数
-func (t *Transaction) UpdateAccountBalance(tx *gorm.DB) error {
-	var account Account
-	if err := tx.First(&account, t.AccountID).Error; err != nil {
+// AfterUpdate 钩子，在更新交易后，从原账户扣回原影响值，并向新账户累加新影响值
+// 可以同时处理账户、类型、金额和日期的变更
+func (t *Transaction) AfterUpdate(tx *gorm.DB) (err error) {
+	previous := t.previous
+	t.previous = nil
+	if previous == nil {
+		return nil
+	}
+
+	var current Transaction
+	if err = tx.First(&current, previous.ID).Error; err != nil {
+		return err
+	}
+
+	// 原账户在原交易日期之后、新账户在新交易日期之后的余额快照均已失效
+	if err = InvalidateBalanceSnapshots(tx, previous.AccountID, previous.TransactionDate); err != nil {
+		return err
+	}
+	if err = InvalidateBalanceSnapshots(tx, current.AccountID, current.TransactionDate); err != nil {
 		return err
 	}
 
-	var totalIncome float64
-	var totalExpense float64
-	var totalTransferOut float64 // 从该账户转出
-	var totalTransferIn float64  // 转入该账户 (如果Transaction模型支持ToAccountID)
+	deltas := map[uint]float64{previous.AccountID: -previous.BalanceDelta()}
+	deltas[current.AccountID] += current.BalanceDelta()
+	return applyBalanceDeltas(tx, deltas)
+}
 
-	tx.Model(&Transaction{}).Where("account_id = ? AND type = ?", t.AccountID, TransactionTypeIncome).Select("COALESCE(SUM(amount), 0)").Row().Scan(&totalIncome)
-	tx.Model(&Transaction{}).Where("account_id = ? AND type = ?", t.AccountID, TransactionTypeExpense).Select("COALESCE(SUM(amount), 0)").Row().Scan(&totalExpense)
-	// 假设当前Transaction模型中的AccountID代表的是转出账户
-	tx.Model(&Transaction{}).Where("account_id = ? AND type = ?", t.AccountID, TransactionTypeTransfer).Select("COALESCE(SUM(amount), 0)").Row().Scan(&totalTransferOut)
-	// 如果Transaction模型有ToAccountID, 则需要查询转入该账户的金额
-	// tx.Model(&Transaction{}).Where("to_account_id = ? AND type = ?", t.AccountID, TransactionTypeTransfer).Select("COALESCE(SUM(amount), 0)").Row().Scan(&totalTransferIn)
+// BeforeDelete 钩子，锁定并记录待删除的交易，调用方传入的模型可能只包含主键
+func (t *Transaction) BeforeDelete(tx *gorm.DB) (err error) {
+	t.previous, err = lockTransaction(tx, t.ID)
+	return err
+}
 
-	account.CurrentBalance = account.InitialBalance + totalIncome - totalExpense - totalTransferOut + totalTransferIn
+// AfterDelete 钩子，在删除交易后，从关联账户的余额中扣回该交易的影响值
+func (t *Transaction) AfterDelete(tx *gorm.DB) (err error) {
+	previous := t.previous
+	t.previous = nil
+	if previous == nil {
+		return nil
+	}
 
-	return tx.Save(&account).Error
+	if err = InvalidateBalanceSnapshots(tx, previous.AccountID, previous.TransactionDate); err != nil {
+		return err
+	}
+	return applyBalanceDeltas(tx, map[uint]float64{previous.AccountID: -previous.BalanceDelta()})
+}
+
+// lockTransaction 以 SELECT ... FOR UPDATE 读取交易的当前状态，未指定主键或记录不存在时返回nil
+func lockTransaction(tx *gorm.DB, id uint) (*Transaction, error) {
+	if id == 0 {
+		return nil, nil
+	}
+
+	var previous Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&previous, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
+// applyBalanceDeltas 将余额变动累加到各账户
+// 先按账户ID顺序对账户行加锁，避免并发写入互相覆盖，也避免同时涉及多个账户的更新之间出现死锁
+func applyBalanceDeltas(tx *gorm.DB, deltas map[uint]float64) error {
+	ids := make([]uint, 0, len(deltas))
+	for id, delta := range deltas {
+		if math.Abs(delta) >= 0.005 {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var accounts []Account
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		Where("id IN ?", ids).Order("id").Find(&accounts).Error; err != nil {
+		return err
+	}
+	if len(accounts) != len(ids) {
+		return gorm.ErrRecordNotFound
+	}
+
+	for _, id := range ids {
+		if err := tx.Model(&Account{}).Where("id = ?", id).
+			UpdateColumn("current_balance", gorm.Expr("current_balance + ?", deltas[id])).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecalculateAccountBalance 根据初始余额和全部交易重新计算账户的当前余额
+// 只在显式要求时调用（如余额校正、账户合并），返回重算前后的余额
+func RecalculateAccountBalance(tx *gorm.DB, accountID uint) (previous float64, current float64, err error) {
+	var account Account
+	if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, accountID).Error; err != nil {
+		return 0, 0, err
+	}
+
+	var delta float64
+	if err = tx.Model(&Transaction{}).Where("account_id = ?", accountID).
+		Select(BalanceDeltaSQL).Scan(&delta).Error; err != nil {
+		return 0, 0, err
+	}
+
+	current = math.Round((account.InitialBalance+delta)*100) / 100
+	if err = tx.Model(&account).UpdateColumn("current_balance", current).Error; err != nil {
+		return 0, 0, err
+	}
+	return account.CurrentBalance, current, nil
 }
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+)
+
+// UserSetting 用户的记账偏好设置，每个用户一条记录，没有记录时使用默认值
+type UserSetting struct {
+	global.GlyModel
+	UserID                 uint       `json:"user_id" gorm:"uniqueIndex;comment:用户ID"`
+	MonthStartDay          int        `json:"month_start_day" gorm:"default:0;comment:每月起始日 (1-28)，按月、季度、年计算的预算周期从该日开始，0 表示按预算开始日期"`
+	EnvelopeMode           bool       `json:"envelope_mode" gorm:"default:false;comment:是否开启信封预算 (零基预算) 模式"`
+	EnvelopeStartDate      *time.Time `json:"envelope_start_date" gorm:"comment:首次开启信封模式的月份第一天，从该月起的收入计入待分配"`
+	EnvelopeOpeningAmount  float64    `json:"envelope_opening_amount" gorm:"type:decimal(12,2);default:0;comment:开启信封模式时已有的待分配金额"`
+	NotifyEmail            string     `json:"notify_email" gorm:"type:varchar(100);comment:接收邮件通知的地址，为空时使用账号邮箱"`
+	NotifyWebhookURL       string     `json:"notify_webhook_url" gorm:"type:varchar(255);comment:接收Webhook通知的地址"`
+	NotifyWebhookSecret    string     `json:"-" gorm:"type:varchar(100);comment:Webhook签名密钥"`
+	LargeTransactionAmount float64    `json:"large_transaction_amount" gorm:"type:decimal(12,2);default:0;comment:达到该金额的收支交易产生大额交易通知，0 表示不通知"`
+}
+
+// TableName 指定表名
+func (s *UserSetting) TableName() string {
+	return "bookkeeping_user_settings"
+}
@@ -14,6 +14,21 @@ func InitBookkeepingRouter() {
 		transactionApi := api.BookkeepingTransactionApi{}
 		statisticsApi := api.StatisticsAPI{}
 		budgetApi := api.BookkeepingBudgetApi{}
+		investmentApi := api.BookkeepingInvestmentApi{}
+		loanApi := api.BookkeepingLoanApi{}
+		debtApi := api.BookkeepingDebtApi{}
+		splitApi := api.BookkeepingSplitApi{}
+		ledgerApi := api.BookkeepingLedgerApi{}
+		templateApi := api.BookkeepingCategoryTemplateApi{}
+		settingApi := api.BookkeepingSettingApi{}
+		goalApi := api.BookkeepingSavingsGoalApi{}
+		envelopeApi := api.BookkeepingEnvelopeApi{}
+		notificationApi := api.BookkeepingNotificationApi{}
+		billApi := api.BookkeepingBillApi{}
+		subscriptionApi := api.BookkeepingSubscriptionApi{}
+
+		// 账单日历订阅，持有订阅密钥即可访问，供日历应用定时拉取
+		rgPublic.GET("/bk/calendar/:token", billApi.Calendar)
 
 		// 所有记账相关接口都需要认证
 		bookkeepingRouter := rgAuth.Group("bk")
@@ -22,22 +37,41 @@ func InitBookkeepingRouter() {
 		// 分类管理路由
 		categoryRouter := bookkeepingRouter.Group("categories")
 		{
-			categoryRouter.POST("", categoryApi.CreateCategory)            // 创建分类
-			categoryRouter.GET("", categoryApi.ListCategories)             // 获取分类列表 (层级)
-			categoryRouter.GET("/flat", categoryApi.ListAllCategoriesFlat) // 获取所有分类列表 (扁平)
-			categoryRouter.GET("/:id", categoryApi.GetCategory)            // 获取单个分类信息
-			categoryRouter.PUT("/:id", categoryApi.UpdateCategory)         // 更新分类信息
-			categoryRouter.DELETE("/:id", categoryApi.DeleteCategory)      // 删除分类
+			categoryRouter.POST("", categoryApi.CreateCategory)              // 创建分类
+			categoryRouter.GET("", categoryApi.ListCategories)               // 获取分类列表 (层级)
+			categoryRouter.GET("/flat", categoryApi.ListAllCategoriesFlat)   // 获取所有分类列表 (扁平)
+			categoryRouter.PUT("/sort", categoryApi.SortCategories)          // 批量调整同级分类顺序
+			categoryRouter.PUT("/tree", categoryApi.ReorderCategoryTree)     // 按新的树结构整体调整分类
+			categoryRouter.GET("/:id", categoryApi.GetCategory)              // 获取单个分类信息
+			categoryRouter.PUT("/:id", categoryApi.UpdateCategory)           // 更新分类信息
+			categoryRouter.DELETE("/:id", categoryApi.DeleteCategory)        // 删除分类
+			categoryRouter.POST("/:id/archive", categoryApi.ArchiveCategory) // 归档分类
+			categoryRouter.POST("/:id/reopen", categoryApi.ReopenCategory)   // 重新启用分类
+			categoryRouter.POST("/:id/merge", categoryApi.MergeCategory)     // 合并到其他分类
+		}
+
+		// 分类模板路由
+		templateRouter := bookkeepingRouter.Group("category-templates")
+		{
+			templateRouter.GET("", templateApi.ListTemplates)            // 获取分类模板列表
+			templateRouter.GET("/export", templateApi.ExportTemplate)    // 导出当前分类树为模板
+			templateRouter.GET("/:id", templateApi.GetTemplate)          // 获取分类模板详情
+			templateRouter.POST("/:id/apply", templateApi.ApplyTemplate) // 应用分类模板
 		}
 
 		// 账户管理路由
 		accountRouter := bookkeepingRouter.Group("accounts")
 		{
-			accountRouter.POST("", accountApi.CreateAccount)       // 创建账户
-			accountRouter.GET("", accountApi.ListAccounts)         // 获取账户列表
-			accountRouter.GET("/:id", accountApi.GetAccount)       // 获取单个账户信息
-			accountRouter.PUT("/:id", accountApi.UpdateAccount)    // 更新账户信息
-			accountRouter.DELETE("/:id", accountApi.DeleteAccount) // 删除账户
+			accountRouter.POST("", accountApi.CreateAccount)                               // 创建账户
+			accountRouter.GET("", accountApi.ListAccounts)                                 // 获取账户列表
+			accountRouter.GET("/:id", accountApi.GetAccount)                               // 获取单个账户信息
+			accountRouter.PUT("/:id", accountApi.UpdateAccount)                            // 更新账户信息
+			accountRouter.DELETE("/:id", accountApi.DeleteAccount)                         // 删除账户
+			accountRouter.POST("/:id/archive", accountApi.ArchiveAccount)                  // 归档（关闭）账户
+			accountRouter.POST("/:id/reopen", accountApi.ReopenAccount)                    // 重新启用账户
+			accountRouter.GET("/:id/balance", accountApi.GetAccountBalance)                // 获取账户在指定日期的余额
+			accountRouter.GET("/:id/balance-history", accountApi.GetAccountBalanceHistory) // 获取账户余额历史序列
+			accountRouter.POST("/:id/recalculate", accountApi.RecalculateAccountBalance)   // 按全部交易重新计算账户余额
 		}
 
 		// 交易流水管理路由
@@ -57,6 +91,8 @@ func InitBookkeepingRouter() {
 			statisticsRouter.GET("/category-summary", statisticsApi.GetCategorySummary)            // 分类汇总
 			statisticsRouter.GET("/account-summary", statisticsApi.GetAccountSummary)              // 账户余额汇总
 			statisticsRouter.GET("/monthly-trend", statisticsApi.GetMonthlyTrend)                  // 月度收支趋势
+			statisticsRouter.GET("/net-worth", statisticsApi.GetNetWorth)                          // 净资产
+			statisticsRouter.GET("/net-worth-trend", statisticsApi.GetNetWorthTrend)               // 月度净资产趋势
 		}
 
 		// 预算管理路由
@@ -66,10 +102,157 @@ func InitBookkeepingRouter() {
 			budgetRouter.GET("", budgetApi.ListBudgets)                              // 获取预算列表
 			budgetRouter.GET("/active-progress", budgetApi.ListActiveBudgetProgress) // 获取激活的预算进度列表
 			budgetRouter.GET("/alerts", budgetApi.CheckBudgetAlerts)                 // 获取预算提醒
+			budgetRouter.GET("/history", budgetApi.ListBudgetHistory)                // 获取所有预算的历史执行情况
+			budgetRouter.GET("/matrix", budgetApi.GetBudgetMatrix)                   // 获取预算与实际对比表
 			budgetRouter.GET("/:id", budgetApi.GetBudget)                            // 获取单个预算信息
 			budgetRouter.GET("/:id/progress", budgetApi.GetBudgetProgress)           // 获取预算进度
+			budgetRouter.GET("/:id/history", budgetApi.GetBudgetHistory)             // 获取预算历史执行情况
+			budgetRouter.GET("/:id/events", budgetApi.ListBudgetEvents)              // 获取预算跨过阈值的记录
 			budgetRouter.PUT("/:id", budgetApi.UpdateBudget)                         // 更新预算信息
 			budgetRouter.DELETE("/:id", budgetApi.DeleteBudget)                      // 删除预算
 		}
+
+		// 信封预算路由
+		envelopeRouter := bookkeepingRouter.Group("envelopes")
+		{
+			envelopeRouter.GET("", envelopeApi.GetMonth)         // 获取信封月度概览
+			envelopeRouter.POST("", envelopeApi.CreateEnvelope)  // 创建信封
+			envelopeRouter.GET("/moves", envelopeApi.ListMoves)  // 获取资金移动记录
+			envelopeRouter.POST("/moves", envelopeApi.MoveMoney) // 分配或移动信封资金
+		}
+
+		// 储蓄目标路由
+		goalRouter := bookkeepingRouter.Group("savings-goals")
+		{
+			goalRouter.POST("", goalApi.CreateGoal)       // 创建储蓄目标
+			goalRouter.GET("", goalApi.ListGoals)         // 获取储蓄目标列表及进度
+			goalRouter.GET("/:id", goalApi.GetGoal)       // 获取储蓄目标详情及进度
+			goalRouter.PUT("/:id", goalApi.UpdateGoal)    // 更新储蓄目标
+			goalRouter.DELETE("/:id", goalApi.DeleteGoal) // 删除储蓄目标
+		}
+
+		// 投资管理路由
+		investmentRouter := bookkeepingRouter.Group("investments")
+		{
+			investmentRouter.POST("/securities", investmentApi.CreateSecurity)                   // 创建证券
+			investmentRouter.GET("/securities", investmentApi.ListSecurities)                    // 获取证券列表
+			investmentRouter.GET("/securities/:id/prices", investmentApi.ListPrices)             // 获取证券历史价格
+			investmentRouter.POST("/prices", investmentApi.SavePrices)                           // 批量保存证券价格
+			investmentRouter.POST("/prices/import", investmentApi.ImportPrices)                  // 通过CSV导入证券价格
+			investmentRouter.POST("/accounts/:id/trades", investmentApi.CreateTrade)             // 记录投资交易
+			investmentRouter.GET("/accounts/:id/trades", investmentApi.ListTrades)               // 获取投资交易列表
+			investmentRouter.DELETE("/accounts/:id/trades/:trade_id", investmentApi.DeleteTrade) // 删除投资交易
+			investmentRouter.GET("/accounts/:id/valuation", investmentApi.GetValuation)          // 获取投资账户估值
+		}
+
+		// 贷款管理路由
+		loanRouter := bookkeepingRouter.Group("loans")
+		{
+			loanRouter.POST("", loanApi.CreateLoan)                                     // 创建贷款
+			loanRouter.GET("", loanApi.ListLoans)                                       // 获取贷款列表
+			loanRouter.GET("/:id", loanApi.GetLoan)                                     // 获取贷款详情
+			loanRouter.PUT("/:id", loanApi.UpdateLoan)                                  // 更新贷款
+			loanRouter.DELETE("/:id", loanApi.DeleteLoan)                               // 删除贷款
+			loanRouter.GET("/:id/schedule", loanApi.GetSchedule)                        // 获取还款计划
+			loanRouter.GET("/:id/repayments", loanApi.ListRepayments)                   // 获取还款记录
+			loanRouter.POST("/:id/repayments", loanApi.Repay)                           // 按期还款
+			loanRouter.POST("/:id/prepayments", loanApi.Prepay)                         // 提前还款
+			loanRouter.DELETE("/:id/repayments/:repayment_id", loanApi.DeleteRepayment) // 撤销最近一次还款
+		}
+
+		// 个人借贷路由
+		debtRouter := bookkeepingRouter.Group("debts")
+		{
+			debtRouter.POST("/counterparties", debtApi.CreateCounterparty)              // 创建联系人
+			debtRouter.GET("/counterparties", debtApi.ListCounterparties)               // 获取联系人列表
+			debtRouter.PUT("/counterparties/:id", debtApi.UpdateCounterparty)           // 更新联系人
+			debtRouter.DELETE("/counterparties/:id", debtApi.DeleteCounterparty)        // 删除联系人
+			debtRouter.POST("", debtApi.CreateDebt)                                     // 记录借出或借入
+			debtRouter.GET("", debtApi.ListDebts)                                       // 获取借贷记录列表
+			debtRouter.GET("/summary", debtApi.GetSummary)                              // 按联系人汇总未结清借贷
+			debtRouter.GET("/overdue", debtApi.ListOverdue)                             // 获取逾期借贷
+			debtRouter.GET("/:id", debtApi.GetDebt)                                     // 获取借贷详情
+			debtRouter.DELETE("/:id", debtApi.DeleteDebt)                               // 删除借贷记录
+			debtRouter.POST("/:id/repayments", debtApi.AddRepayment)                    // 记录还款
+			debtRouter.DELETE("/:id/repayments/:repayment_id", debtApi.DeleteRepayment) // 撤销还款
+		}
+
+		// 账单提醒路由
+		billRouter := bookkeepingRouter.Group("bills")
+		{
+			billRouter.POST("", billApi.CreateBill)                               // 创建账单
+			billRouter.GET("", billApi.ListBills)                                 // 获取账单列表
+			billRouter.GET("/upcoming", billApi.ListUpcoming)                     // 获取即将付款列表 (含逾期)
+			billRouter.GET("/calendar", billApi.GetCalendarFeed)                  // 获取账单日历订阅地址
+			billRouter.POST("/calendar/reset", billApi.ResetCalendarFeed)         // 重置账单日历订阅地址
+			billRouter.GET("/:id", billApi.GetBill)                               // 获取账单详情
+			billRouter.PUT("/:id", billApi.UpdateBill)                            // 更新账单
+			billRouter.DELETE("/:id", billApi.DeleteBill)                         // 删除账单
+			billRouter.POST("/:id/pay", billApi.PayBill)                          // 标记账单已付
+			billRouter.GET("/:id/payments", billApi.ListPayments)                 // 获取账单付款记录
+			billRouter.DELETE("/:id/payments/:payment_id", billApi.DeletePayment) // 撤销账单付款
+		}
+
+		// 订阅检测路由
+		subscriptionRouter := bookkeepingRouter.Group("subscriptions")
+		{
+			subscriptionRouter.GET("/candidates", subscriptionApi.ListCandidates)    // 检测候选订阅
+			subscriptionRouter.POST("/confirm", subscriptionApi.ConfirmSubscription) // 确认候选订阅
+			subscriptionRouter.POST("/dismiss", subscriptionApi.DismissSubscription) // 忽略候选订阅
+			subscriptionRouter.GET("", subscriptionApi.ListSubscriptions)            // 获取已确认或已忽略的订阅
+			subscriptionRouter.PUT("/:id", subscriptionApi.UpdateSubscription)       // 更新订阅
+			subscriptionRouter.DELETE("/:id", subscriptionApi.DeleteSubscription)    // 删除订阅
+		}
+
+		// AA分摊路由
+		splitRouter := bookkeepingRouter.Group("splits")
+		{
+			splitRouter.POST("/groups", splitApi.CreateGroup)                                       // 创建账单组
+			splitRouter.GET("/groups", splitApi.ListGroups)                                         // 获取账单组列表
+			splitRouter.GET("/groups/:id", splitApi.GetGroup)                                       // 获取账单组详情
+			splitRouter.PUT("/groups/:id", splitApi.UpdateGroup)                                    // 更新账单组
+			splitRouter.DELETE("/groups/:id", splitApi.DeleteGroup)                                 // 删除账单组
+			splitRouter.POST("/groups/:id/members", splitApi.AddMember)                             // 添加成员
+			splitRouter.DELETE("/groups/:id/members/:member_id", splitApi.RemoveMember)             // 移除成员
+			splitRouter.POST("/groups/:id/expenses", splitApi.CreateExpense)                        // 记录共同支出
+			splitRouter.GET("/groups/:id/expenses", splitApi.ListExpenses)                          // 获取共同支出列表
+			splitRouter.DELETE("/groups/:id/expenses/:expense_id", splitApi.DeleteExpense)          // 删除共同支出
+			splitRouter.GET("/groups/:id/balances", splitApi.GetBalances)                           // 获取成员结余和结算建议
+			splitRouter.POST("/groups/:id/settlements", splitApi.CreateSettlement)                  // 记录结算
+			splitRouter.GET("/groups/:id/settlements", splitApi.ListSettlements)                    // 获取结算记录
+			splitRouter.DELETE("/groups/:id/settlements/:settlement_id", splitApi.DeleteSettlement) // 删除结算记录
+		}
+
+		// 记账设置路由
+		settingRouter := bookkeepingRouter.Group("settings")
+		{
+			settingRouter.GET("", settingApi.GetSettings)    // 获取记账设置
+			settingRouter.PUT("", settingApi.UpdateSettings) // 更新记账设置
+		}
+
+		// 通知中心路由
+		notificationRouter := bookkeepingRouter.Group("notifications")
+		{
+			notificationRouter.GET("", notificationApi.ListNotifications)             // 获取通知列表
+			notificationRouter.GET("/stream", notificationApi.Stream)                 // 订阅通知推送 (SSE)
+			notificationRouter.POST("/read-all", notificationApi.MarkAllRead)         // 全部标记已读
+			notificationRouter.GET("/preferences", notificationApi.GetPreferences)    // 获取通知偏好
+			notificationRouter.PUT("/preferences", notificationApi.UpdatePreferences) // 更新通知偏好
+			notificationRouter.POST("/:id/read", notificationApi.MarkRead)            // 标记通知已读
+			notificationRouter.DELETE("/:id", notificationApi.DeleteNotification)     // 删除通知
+		}
+
+		// 管理员路由，只有配置的管理员用户可以访问
+		adminRouter := bookkeepingRouter.Group("admin")
+		adminRouter.Use(middleware.AdminAuth())
+		{
+			adminRouter.GET("/ledger/check", ledgerApi.Check)                         // 检查账本一致性
+			adminRouter.POST("/ledger/repair", ledgerApi.Repair)                      // 检查并修复账本
+			adminRouter.GET("/category-templates", templateApi.AdminListTemplates)    // 获取所有分类模板
+			adminRouter.POST("/category-templates", templateApi.CreateTemplate)       // 创建分类模板
+			adminRouter.GET("/category-templates/:id", templateApi.AdminGetTemplate)  // 获取分类模板详情
+			adminRouter.PUT("/category-templates/:id", templateApi.UpdateTemplate)    // 更新分类模板
+			adminRouter.DELETE("/category-templates/:id", templateApi.DeleteTemplate) // 删除分类模板
+		}
 	})
 }
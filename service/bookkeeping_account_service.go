@@ -2,12 +2,15 @@ package service
 
 import (
 	"errors"
+	"math"
+	"time"
 
 	"github.com/dotdancer/gogofly/global"
 	"github.com/dotdancer/gogofly/model"
 	"github.com/dotdancer/gogofly/service/dto"
 	"github.com/jinzhu/copier"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BookkeepingAccountService 结构体定义了账户管理的服务层
@@ -33,6 +36,14 @@ func (s *BookkeepingAccountService) CreateAccount(userID uint, req dto.CreateAcc
 	}
 
 	account.UserID = userID
+	if account.Nature == "" {
+		account.Nature = model.DefaultAccountNature(account.Type)
+	}
+
+	// 资产账户的初始余额不能为负数，负债账户以负数表示欠款
+	if account.Nature == model.AccountNatureAsset && account.InitialBalance < 0 {
+		return response, errors.New("资产账户的初始余额不能为负数")
+	}
 
 	// 如果设置为默认账户，需要将其他账户的默认标志设为false
 	if account.IsDefault {
@@ -57,18 +68,26 @@ func (s *BookkeepingAccountService) CreateAccount(userID uint, req dto.CreateAcc
 	// 格式化时间
 	response.CreatedAt = account.CreatedAt.Format("2006-01-02 15:04:05")
 	response.UpdatedAt = account.UpdatedAt.Format("2006-01-02 15:04:05")
+	if account.ClosedAt != nil {
+		response.ClosedAt = account.ClosedAt.Format("2006-01-02")
+	}
 
 	return response, nil
 }
 
 // ListAccounts 获取用户的所有账户
 // userID: 当前操作的用户ID
-func (s *BookkeepingAccountService) ListAccounts(userID uint) ([]dto.AccountResponse, error) {
+// includeArchived: 是否包含已归档（关闭）的账户
+func (s *BookkeepingAccountService) ListAccounts(userID uint, includeArchived bool) ([]dto.AccountResponse, error) {
 	var accounts []model.Account
 	var response []dto.AccountResponse
 
-	// 查询用户的所有账户
-	if err := global.DB.Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+	// 查询用户的所有账户，默认不包含已归档账户
+	query := global.DB.Where("user_id = ?", userID)
+	if !includeArchived {
+		query = query.Where("is_archived = ?", false)
+	}
+	if err := query.Find(&accounts).Error; err != nil {
 		global.Logger.Error("Failed to list accounts: " + err.Error())
 		return nil, errors.New("获取账户列表失败：数据库错误")
 	}
@@ -84,6 +103,9 @@ func (s *BookkeepingAccountService) ListAccounts(userID uint) ([]dto.AccountResp
 		// 格式化时间
 		accountResponse.CreatedAt = account.CreatedAt.Format("2006-01-02 15:04:05")
 		accountResponse.UpdatedAt = account.UpdatedAt.Format("2006-01-02 15:04:05")
+		if account.ClosedAt != nil {
+			accountResponse.ClosedAt = account.ClosedAt.Format("2006-01-02")
+		}
 
 		response = append(response, accountResponse)
 	}
@@ -116,6 +138,9 @@ func (s *BookkeepingAccountService) GetAccount(userID uint, accountID uint) (dto
 	// 格式化时间
 	response.CreatedAt = account.CreatedAt.Format("2006-01-02 15:04:05")
 	response.UpdatedAt = account.UpdatedAt.Format("2006-01-02 15:04:05")
+	if account.ClosedAt != nil {
+		response.ClosedAt = account.ClosedAt.Format("2006-01-02")
+	}
 
 	return response, nil
 }
@@ -146,17 +171,31 @@ func (s *BookkeepingAccountService) UpdateAccount(userID uint, accountID uint, r
 		account.Name = *req.Name
 	}
 
-	// 更新其他字段
-	if req.Type != nil {
+	// 更新其他字段，修改账户类型且未指定账户性质时按新类型重新推断
+	if req.Type != nil && *req.Type != account.Type {
 		account.Type = *req.Type
+		if req.Nature == nil {
+			account.Nature = model.DefaultAccountNature(account.Type)
+		}
 	}
 
 	if req.Remark != nil {
 		account.Remark = *req.Remark
 	}
 
+	if req.Nature != nil {
+		account.Nature = *req.Nature
+	}
+
+	if req.ExcludeFromNetWorth != nil {
+		account.ExcludeFromNetWorth = *req.ExcludeFromNetWorth
+	}
+
 	// 如果设置为默认账户，需要将其他账户的默认标志设为false
 	if req.IsDefault != nil {
+		if *req.IsDefault && account.IsArchived {
+			return response, errors.New("已归档的账户不能设置为默认账户")
+		}
 		if *req.IsDefault && !account.IsDefault {
 			if err := global.DB.Model(&model.Account{}).Where("user_id = ? AND is_default = ? AND id != ?", userID, true, accountID).Update("is_default", false).Error; err != nil {
 				global.Logger.Error("Failed to update other accounts' default flag: " + err.Error())
@@ -166,8 +205,8 @@ func (s *BookkeepingAccountService) UpdateAccount(userID uint, accountID uint, r
 		account.IsDefault = *req.IsDefault
 	}
 
-	// 保存更新
-	if err := global.DB.Save(&account).Error; err != nil {
+	// 保存更新，余额只由交易钩子增量维护，避免用读取时的旧值覆盖并发写入的余额
+	if err := global.DB.Omit("current_balance").Save(&account).Error; err != nil {
 		global.Logger.Error("Failed to update account: " + err.Error())
 		return response, errors.New("更新账户失败：数据库错误")
 	}
@@ -181,6 +220,9 @@ func (s *BookkeepingAccountService) UpdateAccount(userID uint, accountID uint, r
 	// 格式化时间
 	response.CreatedAt = account.CreatedAt.Format("2006-01-02 15:04:05")
 	response.UpdatedAt = account.UpdatedAt.Format("2006-01-02 15:04:05")
+	if account.ClosedAt != nil {
+		response.ClosedAt = account.ClosedAt.Format("2006-01-02")
+	}
 
 	return response, nil
 }
@@ -207,7 +249,7 @@ func (s *BookkeepingAccountService) DeleteAccount(userID uint, accountID uint) e
 	}
 
 	if count > 0 {
-		return errors.New("该账户存在关联的交易记录，无法删除")
+		return errors.New("该账户存在关联的交易记录，无法删除，可以将其归档")
 	}
 
 	// 删除账户
@@ -218,3 +260,295 @@ func (s *BookkeepingAccountService) DeleteAccount(userID uint, accountID uint) e
 
 	return nil
 }
+
+// ArchiveAccount 归档（关闭）账户
+// 归档后的账户默认不在账户列表中显示，也不能再记录新的交易，但历史交易仍参与统计
+// userID: 当前操作的用户ID
+// accountID: 要归档的账户ID
+// req: 归档请求数据
+func (s *BookkeepingAccountService) ArchiveAccount(userID uint, accountID uint, req dto.ArchiveAccountRequest) (dto.AccountResponse, error) {
+	var account model.Account
+	var response dto.AccountResponse
+
+	if err := global.DB.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, errors.New("账户不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get account for archive: " + err.Error())
+		return response, errors.New("归档账户失败：数据库错误")
+	}
+
+	if account.IsArchived {
+		return response, errors.New("该账户已归档")
+	}
+
+	closeDate := time.Now()
+	if req.CloseDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.CloseDate)
+		if err != nil {
+			return response, errors.New("关闭日期格式错误，请使用YYYY-MM-DD格式")
+		}
+		closeDate = parsed
+	}
+
+	account.IsArchived = true
+	account.ClosedAt = &closeDate
+	// 已关闭的账户不能作为默认账户
+	account.IsDefault = false
+
+	if err := global.DB.Omit("current_balance").Save(&account).Error; err != nil {
+		global.Logger.Error("Failed to archive account: " + err.Error())
+		return response, errors.New("归档账户失败：数据库错误")
+	}
+
+	return s.GetAccount(userID, accountID)
+}
+
+// ReopenAccount 重新启用已归档的账户
+// userID: 当前操作的用户ID
+// accountID: 要重新启用的账户ID
+func (s *BookkeepingAccountService) ReopenAccount(userID uint, accountID uint) (dto.AccountResponse, error) {
+	var account model.Account
+	var response dto.AccountResponse
+
+	if err := global.DB.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, errors.New("账户不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get account for reopen: " + err.Error())
+		return response, errors.New("重新启用账户失败：数据库错误")
+	}
+
+	if !account.IsArchived {
+		return response, errors.New("该账户未归档")
+	}
+
+	if err := global.DB.Model(&account).Updates(map[string]interface{}{
+		"is_archived": false,
+		"closed_at":   nil,
+	}).Error; err != nil {
+		global.Logger.Error("Failed to reopen account: " + err.Error())
+		return response, errors.New("重新启用账户失败：数据库错误")
+	}
+
+	return s.GetAccount(userID, accountID)
+}
+
+// RecalculateBalance 按初始余额和全部交易重新计算账户的当前余额
+// 日常记账只增量维护余额，只有在需要校正时才显式调用全量重算
+// userID: 当前操作的用户ID
+// accountID: 要重算的账户ID
+func (s *BookkeepingAccountService) RecalculateBalance(userID uint, accountID uint) (dto.RecalculateBalanceResponse, error) {
+	var account model.Account
+	var response dto.RecalculateBalanceResponse
+
+	if err := global.DB.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, errors.New("账户不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get account for recalculation: " + err.Error())
+		return response, errors.New("重新计算余额失败：数据库错误")
+	}
+
+	var previous, current float64
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		previous, current, err = model.RecalculateAccountBalance(tx, account.ID)
+		return err
+	})
+	if err != nil {
+		global.Logger.Error("Failed to recalculate account balance: " + err.Error())
+		return response, errors.New("重新计算余额失败：数据库错误")
+	}
+
+	response.AccountID = account.ID
+	response.AccountName = account.Name
+	response.PreviousBalance = previous
+	response.CurrentBalance = current
+	response.Difference = math.Round((current-previous)*100) / 100
+
+	return response, nil
+}
+
+// accountReferences 引用账户的记录，合并账户时统一迁移到目标账户
+// 新增引用账户的模型时需要在此登记
+var accountReferences = []struct {
+	name   string
+	model  interface{}
+	column string
+}{
+	{"transactions", &model.Transaction{}, "account_id"},
+	{"investment_trades", &model.InvestmentTrade{}, "account_id"},
+	{"investment_lots", &model.InvestmentLot{}, "account_id"},
+	{"loans", &model.Loan{}, "account_id"},
+	{"loan_repayments", &model.LoanRepayment{}, "from_account_id"},
+	{"debts", &model.Debt{}, "account_id"},
+	{"debt_repayments", &model.DebtRepayment{}, "account_id"},
+	{"split_expenses", &model.SplitExpense{}, "account_id"},
+	{"split_settlements", &model.SplitSettlement{}, "account_id"},
+	{"budget_accounts", &model.BudgetAccount{}, "account_id"},
+	{"savings_goal_accounts", &model.SavingsGoalAccount{}, "account_id"},
+	{"bills", &model.Bill{}, "account_id"},
+	{"bill_payments", &model.BillPayment{}, "account_id"},
+	{"subscriptions", &model.Subscription{}, "account_id"},
+}
+
+// accountLinks 关联账户的范围记录，owner 为所属的预算或储蓄目标
+// 合并时同一预算或目标已关联目标账户的，源账户的关联直接删除，避免迁移后出现重复关联
+var accountLinks = []struct {
+	name  string
+	model interface{}
+	owner string
+}{
+	{"budget_accounts", &model.BudgetAccount{}, "budget_id"},
+	{"savings_goal_accounts", &model.SavingsGoalAccount{}, "goal_id"},
+}
+
+// MergeAccount 将源账户合并到目标账户
+// 源账户的交易及其他关联记录全部迁移到目标账户，目标账户的初始余额加上源账户的初始余额后按全部交易重算余额，
+// 源账户随后归档或删除；全部操作在一个数据库事务中完成。DryRun时只返回合并预览
+// userID: 当前操作的用户ID
+// sourceID: 被合并的源账户ID
+// req: 合并请求数据
+func (s *BookkeepingAccountService) MergeAccount(userID uint, sourceID uint, req dto.MergeAccountRequest) (dto.MergeAccountResponse, error) {
+	response := dto.MergeAccountResponse{
+		DryRun:       req.DryRun,
+		SourceAction: req.SourceAction,
+		MovedRecords: make(map[string]int64, len(accountReferences)),
+	}
+	if response.SourceAction == "" {
+		response.SourceAction = "archive"
+	}
+	if sourceID == req.TargetAccountID {
+		return response, errors.New("源账户和目标账户不能相同")
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		// 按ID顺序锁定两个账户，避免与交易钩子的余额更新并发冲突
+		var accounts []model.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND user_id = ?", []uint{sourceID, req.TargetAccountID}, userID).
+			Order("id").Find(&accounts).Error; err != nil {
+			return err
+		}
+		if len(accounts) != 2 {
+			return newBizError("账户不存在或不属于您")
+		}
+		source, target := accounts[0], accounts[1]
+		if source.ID != sourceID {
+			source, target = target, source
+		}
+		if target.IsArchived {
+			return newBizError("目标账户已归档，不能合并到已归档的账户")
+		}
+		if source.Nature != target.Nature {
+			return newBizError("资产账户和负债账户不能合并")
+		}
+
+		response.SourceAccountID = source.ID
+		response.SourceAccountName = source.Name
+		response.TargetAccountID = target.ID
+		response.TargetAccountName = target.Name
+		response.SourceBalance = source.CurrentBalance
+		response.TargetBalanceBefore = target.CurrentBalance
+
+		for _, ref := range accountReferences {
+			var count int64
+			if err := tx.Model(ref.model).Where(ref.column+" = ?", source.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			response.MovedRecords[ref.name] = count
+		}
+		duplicates := make(map[string][]uint, len(accountLinks))
+		for _, link := range accountLinks {
+			var owners []uint
+			if err := tx.Model(link.model).Where("account_id = ?", target.ID).Pluck(link.owner, &owners).Error; err != nil {
+				return err
+			}
+			if len(owners) == 0 {
+				continue
+			}
+			var ids []uint
+			if err := tx.Model(link.model).Where("account_id = ? AND "+link.owner+" IN ?", source.ID, owners).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			duplicates[link.name] = ids
+			response.MovedRecords[link.name] -= int64(len(ids))
+		}
+
+		if req.DryRun {
+			var delta float64
+			if err := tx.Model(&model.Transaction{}).Where("account_id IN ?", []uint{source.ID, target.ID}).
+				Select(model.BalanceDeltaSQL).Scan(&delta).Error; err != nil {
+				return err
+			}
+			response.TargetBalanceAfter = math.Round((target.InitialBalance+source.InitialBalance+delta)*100) / 100
+			return nil
+		}
+
+		for _, link := range accountLinks {
+			if ids := duplicates[link.name]; len(ids) > 0 {
+				if err := tx.Unscoped().Where("id IN ?", ids).Delete(link.model).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// 迁移关联记录，只修改账户ID，余额在最后统一重算，跳过交易钩子
+		for _, ref := range accountReferences {
+			if err := tx.Model(ref.model).Where(ref.column+" = ?", source.ID).UpdateColumn(ref.column, target.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// 两个账户的历史余额均已变化，删除全部余额快照
+		if err := tx.Where("account_id IN ?", []uint{source.ID, target.ID}).Delete(&model.AccountBalanceSnapshot{}).Error; err != nil {
+			return err
+		}
+
+		targetUpdates := map[string]interface{}{"initial_balance": target.InitialBalance + source.InitialBalance}
+		if source.IsDefault {
+			targetUpdates["is_default"] = true
+		}
+		if err := tx.Model(&target).UpdateColumns(targetUpdates).Error; err != nil {
+			return err
+		}
+		_, balance, err := model.RecalculateAccountBalance(tx, target.ID)
+		if err != nil {
+			return err
+		}
+		response.TargetBalanceAfter = balance
+
+		sourceUpdates := map[string]interface{}{
+			"initial_balance": 0,
+			"current_balance": 0,
+			"is_default":      false,
+		}
+		if response.SourceAction == "archive" {
+			sourceUpdates["is_archived"] = true
+			sourceUpdates["closed_at"] = time.Now()
+		}
+		if err := tx.Model(&source).UpdateColumns(sourceUpdates).Error; err != nil {
+			return err
+		}
+		if response.SourceAction == "delete" {
+			return tx.Delete(&source).Error
+		}
+		return nil
+	})
+	if err != nil {
+		global.Logger.Error("Failed to merge account: " + err.Error())
+		return response, userFacingError(err, "合并账户失败：数据库错误")
+	}
+
+	return response, nil
+}
+
+// BackfillAccountNature 为新增账户性质字段之前创建的信用卡和贷款账户补写负债性质
+// 字段由 AutoMigrate 以默认值 asset 添加，只应在字段刚添加时执行一次，避免覆盖用户手动设置的性质
+func (s *BookkeepingAccountService) BackfillAccountNature() error {
+	return global.DB.Model(&model.Account{}).
+		Where("type IN ?", []model.AccountType{model.AccountTypeCreditCard, model.AccountTypeLoan}).
+		Update("nature", model.AccountNatureLiability).Error
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// BookkeepingBalanceService 账户历史余额服务
+// 基于交易流水和月末余额快照计算任意日期的账户余额
+type BookkeepingBalanceService struct{}
+
+// dailyDelta 某一天内交易对余额的影响值
+type dailyDelta struct {
+	Day   string
+	Delta float64
+}
+
+// GetBalanceAt 获取账户在指定日期结束时的余额
+// userID: 当前操作的用户ID
+// accountID: 账户ID
+// date: 日期 (YYYY-MM-DD)，为空时表示今天
+func (s *BookkeepingBalanceService) GetBalanceAt(userID, accountID uint, date string) (dto.AccountBalanceResponse, error) {
+	var response dto.AccountBalanceResponse
+
+	account, err := s.findAccount(userID, accountID)
+	if err != nil {
+		return response, err
+	}
+
+	day := time.Now()
+	if date != "" {
+		if day, err = time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			return response, errors.New("日期格式错误，请使用YYYY-MM-DD格式")
+		}
+	}
+
+	if err := s.refreshSnapshots(global.DB, &account); err != nil {
+		global.Logger.Error("Failed to refresh balance snapshots: " + err.Error())
+	}
+
+	balance, err := s.balanceAt(global.DB, &account, day)
+	if err != nil {
+		global.Logger.Error("Failed to calculate balance at date: " + err.Error())
+		return response, errors.New("计算历史余额失败：数据库错误")
+	}
+
+	response.AccountID = account.ID
+	response.AccountName = account.Name
+	response.Date = day.Format("2006-01-02")
+	response.Balance = balance
+	return response, nil
+}
+
+// GetBalanceHistory 获取账户在一段时间内的余额序列
+// userID: 当前操作的用户ID
+// accountID: 账户ID
+// query: 起止日期及粒度 (day, month)
+func (s *BookkeepingBalanceService) GetBalanceHistory(userID, accountID uint, query dto.BalanceHistoryQuery) (dto.AccountBalanceHistoryResponse, error) {
+	var response dto.AccountBalanceHistoryResponse
+
+	account, err := s.findAccount(userID, accountID)
+	if err != nil {
+		return response, err
+	}
+
+	start, end, err := parseDateRange(query.StartDate, query.EndDate)
+	if err != nil {
+		return response, err
+	}
+	if query.Interval == "" {
+		query.Interval = "day"
+	}
+	if query.Interval == "day" && end.Sub(start) > 366*24*time.Hour {
+		return response, errors.New("按日查询的时间范围不能超过一年")
+	}
+
+	if err := s.refreshSnapshots(global.DB, &account); err != nil {
+		global.Logger.Error("Failed to refresh balance snapshots: " + err.Error())
+	}
+
+	points, err := s.balanceSeries(global.DB, &account, start, end, query.Interval)
+	if err != nil {
+		global.Logger.Error("Failed to calculate balance history: " + err.Error())
+		return response, errors.New("计算余额历史失败：数据库错误")
+	}
+
+	response.AccountID = account.ID
+	response.AccountName = account.Name
+	response.Interval = query.Interval
+	response.StartDate = start.Format("2006-01-02")
+	response.EndDate = end.Format("2006-01-02")
+	response.Items = points
+	return response, nil
+}
+
+// findAccount 查询属于当前用户的账户
+func (s *BookkeepingBalanceService) findAccount(userID, accountID uint) (model.Account, error) {
+	var account model.Account
+	if err := global.DB.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return account, errors.New("账户不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get account: " + err.Error())
+		return account, errors.New("获取账户信息失败：数据库错误")
+	}
+	return account, nil
+}
+
+// balanceAt 计算账户在指定日期结束时的余额
+// 先找到该日期之前最近的一个快照，再叠加快照之后的交易
+func (s *BookkeepingBalanceService) balanceAt(db *gorm.DB, account *model.Account, day time.Time) (float64, error) {
+	base := account.InitialBalance
+	query := db.Model(&model.Transaction{}).
+		Where("account_id = ? AND transaction_date < ?", account.ID, day.AddDate(0, 0, 1).Format("2006-01-02"))
+
+	var snapshot model.AccountBalanceSnapshot
+	err := db.Where("account_id = ? AND snapshot_date <= ?", account.ID, day.Format("2006-01-02")).
+		Order("snapshot_date DESC").First(&snapshot).Error
+	if err == nil {
+		base = snapshot.Balance
+		query = query.Where("transaction_date >= ?", snapshot.SnapshotDate.AddDate(0, 0, 1).Format("2006-01-02"))
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	var delta float64
+	if err := query.Select(model.BalanceDeltaSQL).Scan(&delta).Error; err != nil {
+		return 0, err
+	}
+	return base + delta, nil
+}
+
+// dailyDeltas 按天汇总一段时间内交易对余额的影响值
+func (s *BookkeepingBalanceService) dailyDeltas(db *gorm.DB, accountID uint, start, end time.Time) (map[string]float64, error) {
+	var rows []dailyDelta
+	err := db.Model(&model.Transaction{}).
+		Select("DATE_FORMAT(transaction_date, '%Y-%m-%d') AS day, "+model.BalanceDeltaSQL+" AS delta").
+		Where("account_id = ? AND transaction_date >= ? AND transaction_date < ?",
+			accountID, start.Format("2006-01-02"), end.AddDate(0, 0, 1).Format("2006-01-02")).
+		Group("day").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		deltas[row.Day] = row.Delta
+	}
+	return deltas, nil
+}
+
+// balanceSeries 计算一段时间内逐日或逐月（月末）的余额
+func (s *BookkeepingBalanceService) balanceSeries(db *gorm.DB, account *model.Account, start, end time.Time, interval string) ([]dto.BalancePoint, error) {
+	balance, err := s.balanceAt(db, account, start.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	deltas, err := s.dailyDeltas(db, account.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]dto.BalancePoint, 0)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		balance += deltas[key]
+
+		isMonthEnd := day.AddDate(0, 0, 1).Day() == 1
+		if interval == "day" || isMonthEnd || day.Equal(end) {
+			label := key
+			if interval == "month" {
+				label = day.Format("2006-01")
+			}
+			points = append(points, dto.BalancePoint{Date: label, Balance: balance})
+		}
+	}
+
+	return points, nil
+}
+
+// refreshSnapshots 为账户补齐截止到上个月末的月末余额快照
+// 交易变动时会删除变动日期之后的快照，这里负责按需重建
+func (s *BookkeepingBalanceService) refreshSnapshots(db *gorm.DB, account *model.Account) error {
+	now := time.Now()
+	lastMonthEnd := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, 0, -1)
+
+	// 确定补齐的起点：最近一个快照之后，或者第一笔交易所在月份
+	var start time.Time
+	var latest model.AccountBalanceSnapshot
+	err := db.Where("account_id = ?", account.ID).Order("snapshot_date DESC").First(&latest).Error
+	if err == nil {
+		start = latest.SnapshotDate.AddDate(0, 0, 1)
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		var first model.Transaction
+		if err := db.Where("account_id = ?", account.ID).Order("transaction_date ASC").First(&first).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		start = time.Date(first.TransactionDate.Year(), first.TransactionDate.Month(), 1, 0, 0, 0, 0, time.Local)
+	} else {
+		return err
+	}
+
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	if start.After(lastMonthEnd) {
+		return nil
+	}
+
+	points, err := s.balanceSeries(db, account, start, lastMonthEnd, "month")
+	if err != nil {
+		return err
+	}
+
+	snapshots := make([]model.AccountBalanceSnapshot, 0, len(points))
+	for _, point := range points {
+		month, err := time.ParseInLocation("2006-01", point.Date, time.Local)
+		if err != nil {
+			return err
+		}
+		snapshots = append(snapshots, model.AccountBalanceSnapshot{
+			UserID:       account.UserID,
+			AccountID:    account.ID,
+			SnapshotDate: month.AddDate(0, 1, -1),
+			Balance:      point.Balance,
+		})
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return db.Create(&snapshots).Error
+}
+
+// parseDateRange 解析起止日期 (YYYY-MM-DD)，结束日期为空时默认今天，开始日期为空时默认结束日期前30天
+func parseDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if endDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("结束日期格式错误，请使用YYYY-MM-DD格式")
+		}
+		end = parsed
+	}
+
+	start := end.AddDate(0, 0, -30)
+	if startDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("开始日期格式错误，请使用YYYY-MM-DD格式")
+		}
+		start = parsed
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, errors.New("开始日期不能晚于结束日期")
+	}
+	return start, end, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// billCalendarPath 账单日历订阅的公开路径前缀，持有密钥即可访问
+const billCalendarPath = "/api/v1/public/bk/calendar/"
+
+// billCalendarMonths 账单日历包含今天之后多少个月内的到期项目，逾期未付的项目全部包含
+const billCalendarMonths = 12
+
+// GetCalendarFeed 获取账单日历的订阅地址，首次获取时生成订阅密钥
+func (s *BookkeepingBillService) GetCalendarFeed(userID uint) (*dto.BillCalendarFeedResponse, error) {
+	var feed model.BillCalendarFeed
+	err := global.DB.Where("user_id = ?", userID).First(&feed).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		token, tokenErr := newCalendarToken()
+		if tokenErr != nil {
+			global.Logger.Error("Failed to generate calendar token: " + tokenErr.Error())
+			return nil, errors.New("获取日历订阅地址失败：生成密钥错误")
+		}
+		// 并发请求同时生成时以先写入的密钥为准
+		feed = model.BillCalendarFeed{UserID: userID, Token: token}
+		if err = global.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&feed).Error; err == nil {
+			err = global.DB.Where("user_id = ?", userID).First(&feed).Error
+		}
+	}
+	if err != nil {
+		global.Logger.Error("Failed to get calendar feed: " + err.Error())
+		return nil, errors.New("获取日历订阅地址失败：数据库错误")
+	}
+
+	return s.calendarFeedToResponse(&feed), nil
+}
+
+// ResetCalendarFeed 重新生成账单日历的订阅密钥，原订阅地址立即失效
+func (s *BookkeepingBillService) ResetCalendarFeed(userID uint) (*dto.BillCalendarFeedResponse, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		global.Logger.Error("Failed to generate calendar token: " + err.Error())
+		return nil, errors.New("重置日历订阅地址失败：生成密钥错误")
+	}
+
+	feed := model.BillCalendarFeed{UserID: userID, Token: token}
+	if err := global.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "updated_at"}),
+	}).Create(&feed).Error; err != nil {
+		global.Logger.Error("Failed to reset calendar feed: " + err.Error())
+		return nil, errors.New("重置日历订阅地址失败：数据库错误")
+	}
+	if err := global.DB.Where("user_id = ?", userID).First(&feed).Error; err != nil {
+		global.Logger.Error("Failed to get calendar feed: " + err.Error())
+		return nil, errors.New("重置日历订阅地址失败：数据库错误")
+	}
+
+	return s.calendarFeedToResponse(&feed), nil
+}
+
+// RenderCalendar 按订阅密钥生成 iCalendar (RFC 5545) 格式的账单日历
+// 包含所有逾期未付的项目和今天之后12个月内到期的账单、贷款还款和借入还款，每个到期日为一个全天事件
+func (s *BookkeepingBillService) RenderCalendar(token string) ([]byte, error) {
+	var feed model.BillCalendarFeed
+	if err := global.DB.Where("token = ?", token).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("日历不存在")
+		}
+		global.Logger.Error("Failed to get calendar feed: " + err.Error())
+		return nil, errors.New("获取日历失败：数据库错误")
+	}
+
+	now := time.Now()
+	today := truncateToDay(now)
+	to := today.AddDate(0, billCalendarMonths, 0)
+	bills, err := dueBills(global.DB, feed.UserID, time.Time{}, to)
+	if err != nil {
+		global.Logger.Error("Failed to list due bills: " + err.Error())
+		return nil, errors.New("获取日历失败：数据库错误")
+	}
+
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//gogofly//Bookkeeping Bills//ZH")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:"+escapeICSText("账单到期"))
+	writeICSLine(&b, "X-PUBLISHED-TTL:PT1H")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, bill := range bills {
+		dueDate := bill.DueDate.Format("20060102")
+		summary := fmt.Sprintf("%s %.2f", bill.Name, bill.Amount)
+		description := fmt.Sprintf("应付 %.2f", bill.Amount)
+		if bill.Estimated {
+			summary = fmt.Sprintf("%s 约%.2f", bill.Name, bill.Amount)
+			description = fmt.Sprintf("预估应付 %.2f，以实际金额为准", bill.Amount)
+		}
+		if bill.DueDate.Before(today) {
+			summary = "[逾期] " + summary
+		}
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, fmt.Sprintf("UID:%s-%d-%s@gogofly", bill.Source, bill.RefID, dueDate))
+		writeICSLine(&b, "DTSTAMP:"+stamp)
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+dueDate)
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+bill.DueDate.AddDate(0, 0, 1).Format("20060102"))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(summary))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICSText(description))
+		writeICSLine(&b, "TRANSP:TRANSPARENT")
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+
+	return []byte(b.String()), nil
+}
+
+// calendarFeedToResponse 辅助函数，将日历订阅转换为响应对象，完整地址由接口层按请求的服务地址补充
+func (s *BookkeepingBillService) calendarFeedToResponse(feed *model.BillCalendarFeed) *dto.BillCalendarFeedResponse {
+	return &dto.BillCalendarFeedResponse{
+		Path:        billCalendarPath + feed.Token + ".ics",
+		GeneratedAt: feed.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// newCalendarToken 生成64位十六进制的随机订阅密钥
+func newCalendarToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// escapeICSText 转义 iCalendar 文本值中的反斜杠、分号、逗号和换行
+func escapeICSText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(text)
+}
+
+// writeICSLine 写入一行内容，超过75字节的行按 RFC 5545 折行 (续行以空格开头)，不拆分多字节字符
+func writeICSLine(b *strings.Builder, line string) {
+	const limit = 75
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+}
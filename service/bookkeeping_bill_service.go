@@ -0,0 +1,683 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// billMaxOccurrences 推算到期日期时最多向后推算的期数，防止长期的每周账单推算过多
+const billMaxOccurrences = 1000
+
+// BookkeepingBillService 账单与付款提醒服务
+type BookkeepingBillService struct{}
+
+// CreateBill 创建账单，下一期到期日期为首次到期日期
+func (s *BookkeepingBillService) CreateBill(userID uint, req dto.CreateBillRequest) (*dto.BillResponse, error) {
+	firstDueDate, err := time.ParseInLocation("2006-01-02", req.FirstDueDate, time.Local)
+	if err != nil {
+		return nil, errors.New("首次到期日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	bill := model.Bill{
+		UserID:        userID,
+		Name:          strings.TrimSpace(req.Name),
+		Payee:         strings.TrimSpace(req.Payee),
+		Amount:        roundCent(req.Amount),
+		AmountType:    model.BillAmountFixed,
+		CategoryID:    req.CategoryID,
+		AccountID:     req.AccountID,
+		Frequency:     model.BillFrequency(req.Frequency),
+		IntervalCount: req.IntervalCount,
+		FirstDueDate:  firstDueDate,
+		IsActive:      true,
+		Notes:         req.Notes,
+	}
+	if req.AmountType != "" {
+		bill.AmountType = model.BillAmountType(req.AmountType)
+	}
+	if bill.IntervalCount <= 0 {
+		bill.IntervalCount = 1
+	}
+	if bill.Payee == "" {
+		bill.Payee = bill.Name
+	}
+	if req.EndDate != "" {
+		endDate, err := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+		if err != nil {
+			return nil, errors.New("最后到期日期格式错误，请使用YYYY-MM-DD格式")
+		}
+		bill.EndDate = &endDate
+	}
+	if bill.EndDate != nil && bill.EndDate.Before(bill.FirstDueDate) {
+		return nil, errors.New("最后到期日期不能早于首次到期日期")
+	}
+
+	if err := s.checkCategory(userID, bill.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := s.checkAccount(global.DB, userID, bill.AccountID); err != nil {
+		return nil, userFacingError(err, "获取账户信息失败：数据库错误")
+	}
+
+	bill.NextDueDate = billNextDueDate(&bill)
+	if err := global.DB.Create(&bill).Error; err != nil {
+		global.Logger.Error("Failed to create bill: " + err.Error())
+		return nil, errors.New("创建账单失败：数据库错误")
+	}
+
+	return s.GetBill(userID, bill.ID)
+}
+
+// ListBills 获取用户的账单列表，按下一期到期日期升序，已全部付清的排在最后
+func (s *BookkeepingBillService) ListBills(userID uint, query dto.BillQuery) ([]dto.BillResponse, error) {
+	db := global.DB.Preload("Category").Preload("Account").Where("user_id = ?", userID)
+	if query.IsActive != nil {
+		db = db.Where("is_active = ?", *query.IsActive)
+	}
+
+	var bills []model.Bill
+	if err := db.Order("next_due_date IS NULL, next_due_date ASC, id ASC").Find(&bills).Error; err != nil {
+		global.Logger.Error("Failed to list bills: " + err.Error())
+		return nil, errors.New("获取账单列表失败：数据库错误")
+	}
+
+	now := time.Now()
+	responses := make([]dto.BillResponse, 0, len(bills))
+	for i := range bills {
+		responses = append(responses, s.billToResponse(&bills[i], now))
+	}
+	return responses, nil
+}
+
+// GetBill 获取账单详情
+func (s *BookkeepingBillService) GetBill(userID, billID uint) (*dto.BillResponse, error) {
+	bill, err := s.findBill(userID, billID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.billToResponse(&bill, time.Now())
+	return &response, nil
+}
+
+// UpdateBill 更新账单
+// 修改频率、间隔或首次到期日期后，已付期数按新的到期计划重新推算：原下一期到期日期之前的各期视为已付
+func (s *BookkeepingBillService) UpdateBill(userID, billID uint, req dto.UpdateBillRequest) (*dto.BillResponse, error) {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var bill model.Bill
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", billID, userID).First(&bill).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("账单不存在或不属于您")
+			}
+			return err
+		}
+		scheduleChanged := false
+
+		if req.Name != nil {
+			bill.Name = strings.TrimSpace(*req.Name)
+			if bill.Name == "" {
+				return newBizError("账单名称不能为空")
+			}
+		}
+		if req.Payee != nil {
+			bill.Payee = strings.TrimSpace(*req.Payee)
+			if bill.Payee == "" {
+				bill.Payee = bill.Name
+			}
+		}
+		if req.Amount != nil {
+			bill.Amount = roundCent(*req.Amount)
+		}
+		if req.AmountType != nil {
+			bill.AmountType = model.BillAmountType(*req.AmountType)
+		}
+		if req.CategoryID != nil && *req.CategoryID != bill.CategoryID {
+			if err := s.checkCategory(userID, *req.CategoryID); err != nil {
+				return newBizError(err.Error())
+			}
+			bill.CategoryID = *req.CategoryID
+		}
+		if req.AccountID != nil && *req.AccountID != bill.AccountID {
+			if err := s.checkAccount(tx, userID, *req.AccountID); err != nil {
+				return err
+			}
+			bill.AccountID = *req.AccountID
+		}
+		if req.Frequency != nil && model.BillFrequency(*req.Frequency) != bill.Frequency {
+			bill.Frequency = model.BillFrequency(*req.Frequency)
+			scheduleChanged = true
+		}
+		if req.IntervalCount != nil && *req.IntervalCount != bill.IntervalCount {
+			bill.IntervalCount = *req.IntervalCount
+			scheduleChanged = true
+		}
+		if req.FirstDueDate != nil {
+			firstDueDate, err := time.ParseInLocation("2006-01-02", *req.FirstDueDate, time.Local)
+			if err != nil {
+				return newBizError("首次到期日期格式错误，请使用YYYY-MM-DD格式")
+			}
+			if !firstDueDate.Equal(truncateToDay(bill.FirstDueDate)) {
+				bill.FirstDueDate = firstDueDate
+				scheduleChanged = true
+			}
+		}
+		if req.EndDate != nil {
+			bill.EndDate = nil
+			if *req.EndDate != "" {
+				endDate, err := time.ParseInLocation("2006-01-02", *req.EndDate, time.Local)
+				if err != nil {
+					return newBizError("最后到期日期格式错误，请使用YYYY-MM-DD格式")
+				}
+				bill.EndDate = &endDate
+			}
+		}
+		if bill.EndDate != nil && bill.EndDate.Before(truncateToDay(bill.FirstDueDate)) {
+			return newBizError("最后到期日期不能早于首次到期日期")
+		}
+		if req.IsActive != nil {
+			bill.IsActive = *req.IsActive
+		}
+		if req.Notes != nil {
+			bill.Notes = *req.Notes
+		}
+
+		if scheduleChanged && bill.PaidCount > 0 {
+			anchor, err := s.scheduleAnchor(tx, &bill)
+			if err != nil {
+				return err
+			}
+			bill.PaidCount = billOccurrencesBefore(&bill, anchor)
+		}
+		bill.NextDueDate = billNextDueDate(&bill)
+
+		return tx.Model(&bill).Select("name", "payee", "amount", "amount_type", "category_id", "account_id", "frequency",
+			"interval_count", "first_due_date", "end_date", "paid_count", "next_due_date", "is_active", "notes").
+			Updates(&bill).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to update bill: " + err.Error())
+		return nil, userFacingError(err, "更新账单失败：数据库错误")
+	}
+
+	return s.GetBill(userID, billID)
+}
+
+// DeleteBill 删除账单及其付款记录，付款时生成的支出流水作为实际发生的支出保留
+func (s *BookkeepingBillService) DeleteBill(userID, billID uint) error {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var bill model.Bill
+		if err := tx.Where("id = ? AND user_id = ?", billID, userID).First(&bill).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("账单不存在或不属于您")
+			}
+			return err
+		}
+		if err := tx.Where("bill_id = ?", bill.ID).Delete(&model.BillPayment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&bill).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete bill: " + err.Error())
+		return userFacingError(err, "删除账单失败：数据库错误")
+	}
+	return nil
+}
+
+// PayBill 标记账单下一期已付
+// 从付款账户生成一笔计入收支统计的支出，记录付款后账单移到下一期，全部付清后下一期到期日期为空
+func (s *BookkeepingBillService) PayBill(userID, billID uint, req dto.PayBillRequest) (*dto.BillPaymentResponse, error) {
+	paidDate := truncateToDay(time.Now())
+	if req.PaidDate != "" {
+		var err error
+		if paidDate, err = time.ParseInLocation("2006-01-02", req.PaidDate, time.Local); err != nil {
+			return nil, errors.New("付款日期格式错误，请使用YYYY-MM-DD格式")
+		}
+	}
+
+	var payment model.BillPayment
+	var transaction model.Transaction
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var bill model.Bill
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", billID, userID).First(&bill).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("账单不存在或不属于您")
+			}
+			return err
+		}
+		if !bill.IsActive {
+			return newBizError("该账单已暂停，请先启用")
+		}
+		if bill.NextDueDate == nil {
+			return newBizError("该账单已全部付清")
+		}
+
+		accountID := bill.AccountID
+		if req.AccountID != 0 {
+			accountID = req.AccountID
+		}
+		if err := s.checkAccount(tx, userID, accountID); err != nil {
+			return err
+		}
+		// 账单分类可能在创建账单后被归档，付款流水同样不能使用已归档的分类
+		if err := s.checkCategory(userID, bill.CategoryID); err != nil {
+			return newBizError(err.Error())
+		}
+		amount := bill.Amount
+		if req.Amount > 0 {
+			amount = roundCent(req.Amount)
+		}
+
+		period := bill.PaidCount + 1
+		notes := req.Notes
+		if notes == "" {
+			notes = fmt.Sprintf("%s %s 账单", bill.Name, bill.NextDueDate.Format("2006-01-02"))
+		}
+		transaction = model.Transaction{
+			UserID:          userID,
+			AccountID:       accountID,
+			Type:            model.TransactionTypeExpense,
+			Amount:          amount,
+			TransactionDate: paidDate,
+			CategoryID:      bill.CategoryID,
+			PayeePayer:      bill.Payee,
+			Notes:           notes,
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+
+		payment = model.BillPayment{
+			UserID:        userID,
+			BillID:        bill.ID,
+			Period:        period,
+			DueDate:       *bill.NextDueDate,
+			PaidDate:      paidDate,
+			Amount:        amount,
+			AccountID:     accountID,
+			TransactionID: &transaction.ID,
+			Notes:         req.Notes,
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+
+		bill.PaidCount = period
+		bill.NextDueDate = billNextDueDate(&bill)
+		return tx.Model(&bill).Select("paid_count", "next_due_date").Updates(&bill).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to pay bill: " + err.Error())
+		return nil, userFacingError(err, "记录付款失败：数据库错误")
+	}
+
+	response := s.paymentToResponse(&payment)
+
+	// 付款生成的支出与手工记账一样检查大额交易和受影响的预算
+	if err := global.DB.Preload("Account").Preload("Category").First(&transaction, transaction.ID).Error; err != nil {
+		global.Logger.Error("Failed to reload transaction: " + err.Error())
+		return &response, nil
+	}
+	notifyLargeTransaction(&transaction)
+	response.BudgetImpact = evaluateBudgetImpact(userID, []model.Transaction{transaction})
+
+	return &response, nil
+}
+
+// DeletePayment 撤销账单最近一次付款
+// 删除付款记录和付款时生成的支出流水，账单回到该次付款对应的一期，只能从最近一次付款开始依次撤销
+func (s *BookkeepingBillService) DeletePayment(userID, billID, paymentID uint) (*dto.BillResponse, error) {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var bill model.Bill
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", billID, userID).First(&bill).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("账单不存在或不属于您")
+			}
+			return err
+		}
+
+		var payment model.BillPayment
+		if err := tx.Where("bill_id = ?", bill.ID).Order("period DESC, id DESC").First(&payment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("付款记录不存在")
+			}
+			return err
+		}
+		if payment.ID != paymentID {
+			return newBizError("只能撤销最近一次付款")
+		}
+
+		if err := tx.Delete(&payment).Error; err != nil {
+			return err
+		}
+		if payment.TransactionID != nil {
+			if err := deleteTransactions(tx, userID, []uint{*payment.TransactionID}); err != nil {
+				return err
+			}
+		}
+
+		// 按付款对应的到期日期推算已付期数，付款后修改过到期计划时同样适用
+		bill.PaidCount = billOccurrencesBefore(&bill, truncateToDay(payment.DueDate))
+		bill.NextDueDate = billNextDueDate(&bill)
+		return tx.Model(&bill).Select("paid_count", "next_due_date").Updates(&bill).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete bill payment: " + err.Error())
+		return nil, userFacingError(err, "撤销付款失败：数据库错误")
+	}
+
+	return s.GetBill(userID, billID)
+}
+
+// ListPayments 获取账单的付款记录，按期数倒序
+func (s *BookkeepingBillService) ListPayments(userID, billID uint) ([]dto.BillPaymentResponse, error) {
+	if _, err := s.findBill(userID, billID); err != nil {
+		return nil, err
+	}
+
+	var payments []model.BillPayment
+	if err := global.DB.Where("bill_id = ? AND user_id = ?", billID, userID).
+		Order("period DESC, id DESC").Find(&payments).Error; err != nil {
+		global.Logger.Error("Failed to list bill payments: " + err.Error())
+		return nil, errors.New("获取付款记录失败：数据库错误")
+	}
+
+	responses := make([]dto.BillPaymentResponse, 0, len(payments))
+	for i := range payments {
+		responses = append(responses, s.paymentToResponse(&payments[i]))
+	}
+	return responses, nil
+}
+
+// ListUpcoming 获取未来 days 天内需要付款的账单、贷款还款和借入还款，包括所有已逾期未付的项目
+func (s *BookkeepingBillService) ListUpcoming(userID uint, days int) (*dto.UpcomingPaymentsResponse, error) {
+	today := truncateToDay(time.Now())
+	to := today.AddDate(0, 0, days+1).Add(-time.Nanosecond)
+
+	// 开始时间为零值，包含所有逾期未付的项目
+	bills, err := dueBills(global.DB, userID, time.Time{}, to)
+	if err != nil {
+		global.Logger.Error("Failed to list due bills: " + err.Error())
+		return nil, errors.New("获取即将付款列表失败：数据库错误")
+	}
+
+	response := &dto.UpcomingPaymentsResponse{
+		From:  today.Format("2006-01-02"),
+		To:    to.Format("2006-01-02"),
+		Items: make([]dto.UpcomingPaymentItem, 0, len(bills)),
+	}
+	for _, bill := range bills {
+		daysUntil := daysBetween(today, bill.DueDate)
+		item := dto.UpcomingPaymentItem{
+			Source:      bill.Source,
+			RefID:       bill.RefID,
+			Name:        bill.Name,
+			DueDate:     bill.DueDate.Format("2006-01-02"),
+			Amount:      roundCent(bill.Amount),
+			IsEstimated: bill.Estimated,
+			IsOverdue:   daysUntil < 0,
+			DaysUntil:   daysUntil,
+		}
+		if item.IsOverdue {
+			response.OverdueCount++
+			response.OverdueAmount += item.Amount
+		}
+		response.TotalAmount += item.Amount
+		response.Items = append(response.Items, item)
+	}
+	response.OverdueAmount = roundCent(response.OverdueAmount)
+	response.TotalAmount = roundCent(response.TotalAmount)
+	return response, nil
+}
+
+// scheduleAnchor 修改到期计划时用于重新推算已付期数的日期：原下一期到期日期，已全部付清时为最后一次付款对应到期日期的次日
+func (s *BookkeepingBillService) scheduleAnchor(tx *gorm.DB, bill *model.Bill) (time.Time, error) {
+	if bill.NextDueDate != nil {
+		return truncateToDay(*bill.NextDueDate), nil
+	}
+	var payment model.BillPayment
+	if err := tx.Where("bill_id = ?", bill.ID).Order("due_date DESC").First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return truncateToDay(bill.FirstDueDate), nil
+		}
+		return time.Time{}, err
+	}
+	return truncateToDay(payment.DueDate).AddDate(0, 0, 1), nil
+}
+
+// checkCategory 校验账单分类必须是当前用户未归档的支出分类
+func (s *BookkeepingBillService) checkCategory(userID, categoryID uint) error {
+	var category model.Category
+	if err := global.DB.Where("id = ? AND user_id = ?", categoryID, userID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("分类不存在或不属于您")
+		}
+		return errors.New("获取分类信息失败：数据库错误")
+	}
+	if category.Type != model.CategoryTypeExpense {
+		return errors.New("账单分类必须是支出分类")
+	}
+	if category.IsArchived {
+		return errors.New("该分类已归档，不能用于账单")
+	}
+	return nil
+}
+
+// checkAccount 校验付款账户属于当前用户且未归档
+func (s *BookkeepingBillService) checkAccount(tx *gorm.DB, userID, accountID uint) error {
+	var account model.Account
+	if err := tx.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newBizError("付款账户不存在或不属于您")
+		}
+		return err
+	}
+	if account.IsArchived {
+		return newBizError("付款账户已归档，不能用于付款")
+	}
+	return nil
+}
+
+// findBill 查询属于当前用户的账单
+func (s *BookkeepingBillService) findBill(userID, billID uint) (model.Bill, error) {
+	var bill model.Bill
+	if err := global.DB.Preload("Category").Preload("Account").
+		Where("id = ? AND user_id = ?", billID, userID).First(&bill).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return bill, errors.New("账单不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get bill: " + err.Error())
+		return bill, errors.New("获取账单失败：数据库错误")
+	}
+	return bill, nil
+}
+
+// billToResponse 辅助函数，将账单模型转换为响应对象
+func (s *BookkeepingBillService) billToResponse(bill *model.Bill, now time.Time) dto.BillResponse {
+	response := dto.BillResponse{
+		ID:            bill.ID,
+		Name:          bill.Name,
+		Payee:         bill.Payee,
+		Amount:        bill.Amount,
+		AmountType:    string(bill.AmountType),
+		CategoryID:    bill.CategoryID,
+		CategoryName:  bill.Category.Name,
+		AccountID:     bill.AccountID,
+		AccountName:   bill.Account.Name,
+		Frequency:     string(bill.Frequency),
+		IntervalCount: bill.IntervalCount,
+		FirstDueDate:  bill.FirstDueDate.Format("2006-01-02"),
+		PaidCount:     bill.PaidCount,
+		IsActive:      bill.IsActive,
+		Notes:         bill.Notes,
+		CreatedAt:     bill.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if bill.EndDate != nil {
+		response.EndDate = bill.EndDate.Format("2006-01-02")
+	}
+	if bill.NextDueDate != nil {
+		response.NextDueDate = bill.NextDueDate.Format("2006-01-02")
+		today := truncateToDay(now)
+		dueDay := truncateToDay(*bill.NextDueDate)
+		if bill.IsActive && dueDay.Before(today) {
+			response.IsOverdue = true
+			response.DaysOverdue = daysBetween(dueDay, today)
+		}
+	}
+	return response
+}
+
+// paymentToResponse 辅助函数，将付款记录模型转换为响应对象
+func (s *BookkeepingBillService) paymentToResponse(payment *model.BillPayment) dto.BillPaymentResponse {
+	return dto.BillPaymentResponse{
+		ID:            payment.ID,
+		BillID:        payment.BillID,
+		Period:        payment.Period,
+		DueDate:       payment.DueDate.Format("2006-01-02"),
+		PaidDate:      payment.PaidDate.Format("2006-01-02"),
+		Amount:        payment.Amount,
+		AccountID:     payment.AccountID,
+		TransactionID: payment.TransactionID,
+		Notes:         payment.Notes,
+		CreatedAt:     payment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// billDueDate 账单第 n 期 (从0开始) 的到期日期，每一期都从首次到期日期推算，避免月末日期逐月漂移
+func billDueDate(bill *model.Bill, n int) time.Time {
+	first := truncateToDay(bill.FirstDueDate)
+	interval := bill.IntervalCount
+	if interval <= 0 {
+		interval = 1
+	}
+	switch bill.Frequency {
+	case model.BillFrequencyWeekly:
+		return first.AddDate(0, 0, 7*interval*n)
+	case model.BillFrequencyMonthly:
+		return addMonthsClamped(first, interval*n)
+	case model.BillFrequencyQuarterly:
+		return addMonthsClamped(first, 3*interval*n)
+	case model.BillFrequencyYearly:
+		return addMonthsClamped(first, 12*interval*n)
+	}
+	return first
+}
+
+// billHasOccurrence 账单是否存在第 n 期 (从0开始)：一次性账单只有一期，设置了最后到期日期的账单不超过该日期
+func billHasOccurrence(bill *model.Bill, n int) bool {
+	if n < 0 || n >= billMaxOccurrences {
+		return false
+	}
+	if bill.Frequency == model.BillFrequencyOnce {
+		return n == 0
+	}
+	if bill.EndDate != nil && billDueDate(bill, n).After(truncateToDay(*bill.EndDate)) {
+		return false
+	}
+	return true
+}
+
+// billNextDueDate 已付期数之后下一期的到期日期，全部付清时返回空
+func billNextDueDate(bill *model.Bill) *time.Time {
+	if !billHasOccurrence(bill, bill.PaidCount) {
+		return nil
+	}
+	dueDate := billDueDate(bill, bill.PaidCount)
+	return &dueDate
+}
+
+// billOccurrencesBefore 到期日期早于 anchor 的期数
+func billOccurrencesBefore(bill *model.Bill, anchor time.Time) int {
+	n := 0
+	for billHasOccurrence(bill, n) && billDueDate(bill, n).Before(anchor) {
+		n++
+	}
+	return n
+}
+
+// billUnpaidOccurrence 账单未付的一期
+type billUnpaidOccurrence struct {
+	Period  int       // 期数 (从1开始)
+	DueDate time.Time // 到期日期
+}
+
+// billUnpaidOccurrences 启用的账单在 [from, to] 之间到期且未付的各期
+func billUnpaidOccurrences(bill *model.Bill, from, to time.Time) []billUnpaidOccurrence {
+	var result []billUnpaidOccurrence
+	if !bill.IsActive {
+		return result
+	}
+	for n := bill.PaidCount; billHasOccurrence(bill, n); n++ {
+		dueDate := billDueDate(bill, n)
+		if dueDate.After(to) {
+			break
+		}
+		if dueDate.Before(from) {
+			continue
+		}
+		result = append(result, billUnpaidOccurrence{Period: n + 1, DueDate: dueDate})
+	}
+	return result
+}
+
+// billDueBills 启用的账单在 [from, to] 之间到期且未付的各期
+func billDueBills(db *gorm.DB, userID uint, from, to time.Time) ([]dueBill, error) {
+	var bills []model.Bill
+	if err := db.Where("user_id = ? AND is_active = ? AND next_due_date IS NOT NULL AND next_due_date <= ?", userID, true, to).
+		Find(&bills).Error; err != nil {
+		return nil, err
+	}
+
+	var result []dueBill
+	for i := range bills {
+		bill := &bills[i]
+		for _, occurrence := range billUnpaidOccurrences(bill, from, to) {
+			result = append(result, dueBill{
+				Source:    "bill",
+				RefID:     bill.ID,
+				Name:      bill.Name,
+				DueDate:   occurrence.DueDate,
+				Amount:    bill.Amount,
+				Estimated: bill.AmountType == model.BillAmountEstimated,
+			})
+		}
+	}
+	return result, nil
+}
+
+// billUpcomingExpenses 启用的账单在 [from, to] 之间到期且未付的各期，用于预算预测
+func billUpcomingExpenses(db *gorm.DB, userID uint, from, to time.Time) ([]upcomingExpense, error) {
+	var bills []model.Bill
+	if err := db.Where("user_id = ? AND is_active = ? AND next_due_date IS NOT NULL AND next_due_date <= ?", userID, true, to).
+		Find(&bills).Error; err != nil {
+		return nil, err
+	}
+
+	var result []upcomingExpense
+	for i := range bills {
+		bill := &bills[i]
+		for _, occurrence := range billUnpaidOccurrences(bill, from, to) {
+			result = append(result, upcomingExpense{
+				Source:     "bill",
+				Name:       fmt.Sprintf("%s 第%d期", bill.Name, occurrence.Period),
+				Date:       occurrence.DueDate,
+				Amount:     bill.Amount,
+				CategoryID: bill.CategoryID,
+				AccountID:  bill.AccountID,
+				Payee:      bill.Payee,
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListBudgetEvents 获取预算跨过阈值的记录，按时间倒序
+func (s *BookkeepingBudgetService) ListBudgetEvents(userID, budgetID uint) ([]dto.BudgetThresholdEventResponse, error) {
+	var budget model.Budget
+	if err := global.DB.Select("id").Where("id = ? AND user_id = ?", budgetID, userID).First(&budget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("预算不存在")
+		}
+		global.Logger.Error("Failed to get budget: " + err.Error())
+		return nil, errors.New("获取预算阈值记录失败：数据库错误")
+	}
+
+	var events []model.BudgetThresholdEvent
+	if err := global.DB.Where("budget_id = ? AND user_id = ?", budgetID, userID).
+		Order("period_start DESC, id DESC").Find(&events).Error; err != nil {
+		global.Logger.Error("Failed to list budget threshold events: " + err.Error())
+		return nil, errors.New("获取预算阈值记录失败：数据库错误")
+	}
+
+	responses := make([]dto.BudgetThresholdEventResponse, 0, len(events))
+	for i := range events {
+		event := &events[i]
+		responses = append(responses, dto.BudgetThresholdEventResponse{
+			ID:              event.ID,
+			BudgetID:        event.BudgetID,
+			PeriodStart:     event.PeriodStart,
+			Level:           string(event.Level),
+			TransactionID:   event.TransactionID,
+			SpentAmount:     event.SpentAmount,
+			EffectiveAmount: event.EffectiveAmount,
+			UsageRate:       event.UsageRate,
+			CreatedAt:       event.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// evaluateBudgetImpact 交易写入提交后重新计算受影响预算的当前周期进度，返回本次写入使预算新跨过的阈值
+// 受影响的预算是当前周期和统计范围包含任一写入交易的激活预算；批量写入 (如导入) 时传入全部交易，只计算一次。
+// 新跨过的阈值会记录为预算阈值事件并产生通知；计算失败只记录日志，不影响已提交的写入
+func evaluateBudgetImpact(userID uint, transactions []model.Transaction) []dto.BudgetImpact {
+	var transactionIDs []uint
+	for i := range transactions {
+		if transactions[i].Type == model.TransactionTypeExpense && !transactions[i].ExcludeFromStats {
+			transactionIDs = append(transactionIDs, transactions[i].ID)
+		}
+	}
+	if len(transactionIDs) == 0 {
+		return nil
+	}
+
+	var budgets []model.Budget
+	if err := global.DB.Preload("Category").Preload("Categories").Preload("Accounts").
+		Where("user_id = ? AND is_active = ? AND kind = ?", userID, true, model.BudgetKindExpense).
+		Find(&budgets).Error; err != nil {
+		global.Logger.Error("Failed to list budgets for impact evaluation: " + err.Error())
+		return nil
+	}
+
+	budgetService := BookkeepingBudgetService{}
+	var impacts []dto.BudgetImpact
+	for i := range budgets {
+		budget := &budgets[i]
+		start, end, err := budgetService.calculateCurrentPeriod(budget)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to calculate current period for budget %d: %s", budget.ID, err.Error()))
+			continue
+		}
+
+		// 写入的交易中计入该预算当前周期的交易
+		query, err := budgetService.budgetExpenseQuery(userID, budget, start, end)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to build expense query for budget %d: %s", budget.ID, err.Error()))
+			continue
+		}
+		var matchedIDs []uint
+		if err := query.Where("id IN ?", transactionIDs).Order("id").Pluck("id", &matchedIDs).Error; err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to match transactions for budget %d: %s", budget.ID, err.Error()))
+			continue
+		}
+		if len(matchedIDs) == 0 {
+			continue
+		}
+
+		progress, err := budgetService.budgetCurrentProgress(userID, budget, start, end)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to calculate progress for budget %d: %s", budget.ID, err.Error()))
+			continue
+		}
+		transactionID := matchedIDs[len(matchedIDs)-1]
+		level, created, err := recordBudgetThreshold(progress, &transactionID)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to record threshold event for budget %d: %s", budget.ID, err.Error()))
+			continue
+		}
+		if !created {
+			continue
+		}
+
+		impacts = append(impacts, dto.BudgetImpact{
+			BudgetID:        budget.ID,
+			Name:            budget.Name,
+			Level:           string(level),
+			NotifyRate:      progress.NotifyRate,
+			UsageRate:       progress.UsageRate,
+			SpentAmount:     progress.SpentAmount,
+			EffectiveAmount: progress.EffectiveAmount,
+			PeriodStart:     start,
+			PeriodEnd:       end,
+		})
+	}
+	return impacts
+}
+
+// budgetCurrentProgress 计算预算在当前周期 [start, end] 的进度，不包含支出预测
+func (s *BookkeepingBudgetService) budgetCurrentProgress(userID uint, budget *model.Budget, start, end time.Time) (*dto.BudgetProgressResponse, error) {
+	spentAmount, err := s.budgetSpentAmount(userID, budget, start, end)
+	if err != nil {
+		return nil, err
+	}
+	rollover, err := s.budgetRollover(userID, budget, start)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := s.budgetCurrentAmount(budget, start)
+	if err != nil {
+		return nil, err
+	}
+	progress := s.buildBudgetProgress(budget, start, end, amount, spentAmount, rollover)
+	return &progress, nil
+}
+
+// recordBudgetThreshold 预算当前周期达到阈值时记录阈值事件，首次记录时产生通知
+// 返回达到的级别以及是否为新记录；每个预算每个周期每个级别只记录一次
+func recordBudgetThreshold(progress *dto.BudgetProgressResponse, transactionID *uint) (model.BudgetThresholdLevel, bool, error) {
+	level := budgetThresholdLevel(progress)
+	if level == "" {
+		return level, false, nil
+	}
+
+	// 已经超支的周期回落到提醒阈值以内 (如删除或修改了交易) 时，不再记录提醒
+	if level == model.BudgetThresholdWarning {
+		var exceeded int64
+		if err := global.DB.Model(&model.BudgetThresholdEvent{}).
+			Where("budget_id = ? AND period_start = ? AND level = ?", progress.ID, progress.CurrentPeriod.StartDate, model.BudgetThresholdExceeded).
+			Count(&exceeded).Error; err != nil {
+			return level, false, err
+		}
+		if exceeded > 0 {
+			return level, false, nil
+		}
+	}
+
+	event := model.BudgetThresholdEvent{
+		UserID:          progress.UserID,
+		BudgetID:        progress.ID,
+		PeriodStart:     progress.CurrentPeriod.StartDate,
+		Level:           level,
+		TransactionID:   transactionID,
+		SpentAmount:     progress.SpentAmount,
+		EffectiveAmount: progress.EffectiveAmount,
+		UsageRate:       progress.UsageRate,
+	}
+	result := global.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+	if result.Error != nil {
+		return level, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return level, false, nil
+	}
+
+	notifyBudgetThreshold(progress, level)
+	return level, true, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+)
+
+// budgetPacingTolerance 实际支出与按时间均匀花费的金额相差在该比例以内视为符合计划
+const budgetPacingTolerance = 0.1
+
+// 预算花费节奏
+const (
+	budgetPacingAhead   = "ahead"    // 花得比计划快
+	budgetPacingOnTrack = "on_track" // 符合计划
+	budgetPacingBehind  = "behind"   // 花得比计划慢
+)
+
+// budgetForecast 根据本周期至今的日均支出和已知的未来支出，预测周期结束时的支出
+// 收入目标按日均收入预测周期结束时的收入，判断能否达到目标
+func (s *BookkeepingBudgetService) budgetForecast(userID uint, budget *model.Budget, progress *dto.BudgetProgressResponse) (*dto.BudgetForecast, error) {
+	periodStart := progress.CurrentPeriod.StartDate
+	periodEnd := progress.CurrentPeriod.EndDate
+	effectiveAmount := progress.EffectiveAmount
+	spent := progress.SpentAmount
+
+	totalDays := daysBetween(periodStart, periodEnd.Add(time.Second))
+	now := time.Now().In(periodStart.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, periodStart.Location())
+
+	// 已经过去的天数 (含今天)，周期尚未开始时从周期第一天开始预测
+	elapsedDays := 0
+	from := periodStart
+	if !today.Before(periodStart) {
+		elapsedDays = daysBetween(periodStart, today) + 1
+		if elapsedDays > totalDays {
+			elapsedDays = totalDays
+		}
+		from = today
+	}
+	futureDays := totalDays - elapsedDays
+
+	forecast := &dto.BudgetForecast{
+		Upcoming: []dto.BudgetUpcomingItem{},
+		Pacing:   budgetPacingOnTrack,
+	}
+	var dailyRate float64
+	if elapsedDays > 0 {
+		dailyRate = spent / float64(elapsedDays)
+		forecast.DailyBurnRate = roundCent(dailyRate)
+	}
+
+	upcoming, err := s.budgetUpcomingExpenses(userID, budget, from, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range upcoming {
+		forecast.UpcomingAmount += item.Amount
+		forecast.Upcoming = append(forecast.Upcoming, dto.BudgetUpcomingItem{
+			Source: item.Source,
+			Name:   item.Name,
+			Date:   item.Date,
+			Amount: item.Amount,
+		})
+	}
+	forecast.UpcomingAmount = roundCent(forecast.UpcomingAmount)
+
+	forecast.ProjectedSpend = roundCent(spent + dailyRate*float64(futureDays) + forecast.UpcomingAmount)
+	if budgetOverspent(budget, forecast.ProjectedSpend, effectiveAmount) {
+		forecast.WillOverspend = true
+		forecast.ProjectedOverspend = roundCent(forecast.ProjectedSpend - effectiveAmount)
+	} else if budget.Kind == model.BudgetKindIncome && forecast.ProjectedSpend < effectiveAmount {
+		forecast.WillMissTarget = true
+		forecast.ProjectedShortfall = roundCent(effectiveAmount - forecast.ProjectedSpend)
+	}
+
+	// 逐日累加，找到累计支出第一次超过可用金额的日期 (已经超支时为今天)
+	if forecast.WillOverspend {
+		next := 0
+		var scheduled float64
+		for day := from; !day.After(periodEnd); day = day.AddDate(0, 0, 1) {
+			for next < len(upcoming) && upcoming[next].Date.Before(day.AddDate(0, 0, 1)) {
+				scheduled += upcoming[next].Amount
+				next++
+			}
+			if spent+dailyRate*float64(daysBetween(from, day))+scheduled > effectiveAmount {
+				date := day
+				forecast.ProjectedOverspendDate = &date
+				break
+			}
+		}
+	}
+
+	// 剩余每天可花的金额 (收入目标为每天还需要的收入)，包含今天
+	daysLeft := futureDays + 1
+	if elapsedDays == 0 {
+		daysLeft = totalDays
+	}
+	if available := effectiveAmount - spent - forecast.UpcomingAmount; available > 0 && daysLeft > 0 {
+		if budget.Kind == model.BudgetKindIncome {
+			forecast.RequiredDailyIncome = roundCent(available / float64(daysLeft))
+		} else {
+			forecast.SafeDailyAllowance = roundCent(available / float64(daysLeft))
+		}
+	}
+
+	// 与按时间均匀花费相比判断花费节奏
+	if totalDays > 0 {
+		forecast.ExpectedSpendToDate = roundCent(effectiveAmount * float64(elapsedDays) / float64(totalDays))
+	}
+	switch {
+	case spent > forecast.ExpectedSpendToDate*(1+budgetPacingTolerance):
+		forecast.Pacing = budgetPacingAhead
+	case spent < forecast.ExpectedSpendToDate*(1-budgetPacingTolerance):
+		forecast.Pacing = budgetPacingBehind
+	}
+
+	return forecast, nil
+}
+
+// budgetUpcomingExpenses 获取在预算统计范围内的已知未来支出，收入目标不统计未来支出
+func (s *BookkeepingBudgetService) budgetUpcomingExpenses(userID uint, budget *model.Budget, from, to time.Time) ([]upcomingExpense, error) {
+	if budget.Kind == model.BudgetKindIncome {
+		return nil, nil
+	}
+	items, err := upcomingExpenses(global.DB, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := resolveBudgetScope(global.DB, userID, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []upcomingExpense
+	for _, item := range items {
+		if scope.matchesUpcoming(item) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
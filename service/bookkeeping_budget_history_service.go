@@ -0,0 +1,313 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// GetBudgetHistory 获取单个预算从开始日期以来每个周期的预算金额、实际支出和差额
+func (s *BookkeepingBudgetService) GetBudgetHistory(userID, budgetID uint) (*dto.BudgetHistoryResponse, error) {
+	var budget model.Budget
+	if err := global.DB.Where("id = ? AND user_id = ?", budgetID, userID).First(&budget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("预算不存在")
+		}
+		global.Logger.Error("Failed to get budget: " + err.Error())
+		return nil, errors.New("获取预算历史失败：数据库错误")
+	}
+
+	history, err := s.budgetHistory(userID, &budget)
+	if err != nil {
+		global.Logger.Error("Failed to calculate budget history: " + err.Error())
+		return nil, errors.New("获取预算历史失败：数据库错误")
+	}
+	return history, nil
+}
+
+// ListBudgetHistory 获取用户所有预算的历史执行情况
+func (s *BookkeepingBudgetService) ListBudgetHistory(userID uint) ([]dto.BudgetHistoryResponse, error) {
+	var budgets []model.Budget
+	if err := global.DB.Where("user_id = ?", userID).Order("id ASC").Find(&budgets).Error; err != nil {
+		global.Logger.Error("Failed to list budgets: " + err.Error())
+		return nil, errors.New("获取预算历史失败：数据库错误")
+	}
+
+	result := make([]dto.BudgetHistoryResponse, 0, len(budgets))
+	for i := range budgets {
+		history, err := s.budgetHistory(userID, &budgets[i])
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to calculate history for budget %d: %s", budgets[i].ID, err.Error()))
+			return nil, errors.New("获取预算历史失败：数据库错误")
+		}
+		result = append(result, *history)
+	}
+	return result, nil
+}
+
+// GetBudgetMatrix 获取指定年份所有预算按月的预算与实际支出对比
+// 周期不是自然月的预算按天数比例分摊到各月，预算开始之前和结束之后的月份不统计
+func (s *BookkeepingBudgetService) GetBudgetMatrix(userID uint, year int, includeInactive bool) (*dto.BudgetMatrixResponse, error) {
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	// 停用已过结束日期的预算
+	if err := deactivateExpiredBudgets(global.DB, userID); err != nil {
+		global.Logger.Error("Failed to deactivate expired budgets: " + err.Error())
+		return nil, errors.New("获取预算对比失败：数据库错误")
+	}
+
+	query := global.DB.Where("user_id = ?", userID)
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	var budgets []model.Budget
+	if err := query.Order("id ASC").Find(&budgets).Error; err != nil {
+		global.Logger.Error("Failed to list budgets: " + err.Error())
+		return nil, errors.New("获取预算对比失败：数据库错误")
+	}
+
+	result := &dto.BudgetMatrixResponse{
+		Year:    year,
+		Budgets: make([]dto.BudgetMatrixRow, 0, len(budgets)),
+	}
+	for i := range budgets {
+		row, err := s.budgetMatrixRow(userID, &budgets[i], year)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to calculate matrix for budget %d: %s", budgets[i].ID, err.Error()))
+			return nil, errors.New("获取预算对比失败：数据库错误")
+		}
+		result.Budgets = append(result.Budgets, row)
+	}
+	return result, nil
+}
+
+// budgetHistory 计算预算所有历史周期以及当前周期的执行情况
+func (s *BookkeepingBudgetService) budgetHistory(userID uint, budget *model.Budget) (*dto.BudgetHistoryResponse, error) {
+	history := &dto.BudgetHistoryResponse{
+		BudgetID: budget.ID,
+		Name:     budget.Name,
+		Type:     string(budget.Type),
+		Kind:     string(budget.Kind),
+		Period:   string(budget.Period),
+		Periods:  []dto.BudgetHistoryPeriod{},
+	}
+
+	currentStart, currentEnd, err := s.calculateCurrentPeriod(budget)
+	if err != nil {
+		return nil, err
+	}
+	periods := budgetPeriodsBefore(budget, currentStart)
+	if !currentStart.Before(budgetFirstPeriodStart(budget.StartDate)) {
+		periods = append(periods, budgetPeriodRange{Start: currentStart, End: currentEnd})
+	}
+
+	spent, err := s.budgetSpentByPeriod(userID, budget, periods)
+	if err != nil {
+		return nil, err
+	}
+	amountAt, err := budgetAmountFunc(global.DB, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, period := range periods {
+		amount := amountAt(period.Start)
+		history.Periods = append(history.Periods, dto.BudgetHistoryPeriod{
+			StartDate:    period.Start,
+			EndDate:      period.End,
+			Amount:       amount,
+			Spent:        spent[i],
+			Variance:     roundCent(amount - spent[i]),
+			IsOverBudget: budgetOverspent(budget, spent[i], amount),
+			IsCurrent:    period.Start.Equal(currentStart),
+		})
+	}
+	return history, nil
+}
+
+// budgetMatrixRow 计算单个预算在指定年份每个月的预算金额和实际支出
+func (s *BookkeepingBudgetService) budgetMatrixRow(userID uint, budget *model.Budget, year int) (dto.BudgetMatrixRow, error) {
+	row := dto.BudgetMatrixRow{
+		BudgetID:   budget.ID,
+		Name:       budget.Name,
+		Type:       string(budget.Type),
+		Kind:       string(budget.Kind),
+		Period:     string(budget.Period),
+		CategoryID: budget.CategoryID,
+		Months:     make([]dto.BudgetMatrixCell, 12),
+	}
+
+	first := budgetFirstPeriodStart(budget.StartDate)
+	loc := first.Location()
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	yearEnd := yearStart.AddDate(1, 0, 0)
+
+	// 各月的统计范围，预算开始之前和结束之后的部分不统计
+	end, hasEnd := budgetEndTime(budget)
+	var ranges []budgetPeriodRange
+	var rangeMonths []int
+	for month := 0; month < 12; month++ {
+		monthStart := yearStart.AddDate(0, month, 0)
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		row.Months[month].Month = monthStart.Format("2006-01")
+		if !monthEnd.After(first) || (hasEnd && !monthStart.Before(end)) {
+			continue
+		}
+		if monthStart.Before(first) {
+			monthStart = first
+		}
+		if hasEnd && monthEnd.After(end) {
+			monthEnd = end
+		}
+		ranges = append(ranges, budgetPeriodRange{Start: monthStart, End: monthEnd.Add(-time.Second)})
+		rangeMonths = append(rangeMonths, month)
+	}
+
+	spent, err := s.budgetSpentByPeriod(userID, budget, ranges)
+	if err != nil {
+		return row, err
+	}
+	for i, month := range rangeMonths {
+		row.Months[month].Actual = spent[i]
+	}
+
+	// 将与本年有交集的每个预算周期按天数分摊到各月
+	amountAt, err := budgetAmountFunc(global.DB, budget)
+	if err != nil {
+		return row, err
+	}
+	for _, period := range budgetPeriodsUntil(budget, yearEnd) {
+		start, next := period.Start, period.End.Add(time.Second)
+		if next.After(yearStart) {
+			amount := amountAt(start)
+			periodDays := daysBetween(start, next)
+			for month := 0; month < 12; month++ {
+				monthStart := yearStart.AddDate(0, month, 0)
+				monthEnd := monthStart.AddDate(0, 1, 0)
+				overlapStart, overlapEnd := start, next
+				if monthStart.After(overlapStart) {
+					overlapStart = monthStart
+				}
+				if monthEnd.Before(overlapEnd) {
+					overlapEnd = monthEnd
+				}
+				if overlapEnd.After(overlapStart) && periodDays > 0 {
+					row.Months[month].Budgeted += amount * float64(daysBetween(overlapStart, overlapEnd)) / float64(periodDays)
+				}
+			}
+		}
+	}
+
+	for month := range row.Months {
+		cell := &row.Months[month]
+		cell.Budgeted = roundCent(cell.Budgeted)
+		cell.Variance = roundCent(cell.Budgeted - cell.Actual)
+		cell.IsOverBudget = budgetOverspent(budget, cell.Actual, cell.Budgeted)
+		row.Total.Budgeted += cell.Budgeted
+		row.Total.Actual += cell.Actual
+	}
+	row.Total.Month = fmt.Sprintf("%d", year)
+	row.Total.Budgeted = roundCent(row.Total.Budgeted)
+	row.Total.Actual = roundCent(row.Total.Actual)
+	row.Total.Variance = roundCent(row.Total.Budgeted - row.Total.Actual)
+	row.Total.IsOverBudget = budgetOverspent(budget, row.Total.Actual, row.Total.Budgeted)
+
+	return row, nil
+}
+
+// recordAmountVersion 记录预算金额的新版本，从修改后预算 (updated) 的当前周期开始生效
+// 没有版本记录的旧预算会先补记原金额作为第一个版本，保证之前的周期仍使用原金额
+func (s *BookkeepingBudgetService) recordAmountVersion(tx *gorm.DB, budget, updated *model.Budget, amount float64) error {
+	first := budgetFirstPeriodStart(updated.StartDate)
+	effectiveFrom, _, err := s.calculateCurrentPeriod(updated)
+	if err != nil {
+		return err
+	}
+	if effectiveFrom.Before(first) {
+		effectiveFrom = first
+	}
+
+	var count int64
+	if err := tx.Model(&model.BudgetAmountVersion{}).Where("budget_id = ?", budget.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 && effectiveFrom.After(first) {
+		if err := tx.Create(&model.BudgetAmountVersion{
+			UserID:        budget.UserID,
+			BudgetID:      budget.ID,
+			Amount:        budget.Amount,
+			EffectiveFrom: first,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	// 同一周期内多次修改只保留最后一次
+	if err := tx.Where("budget_id = ? AND effective_from >= ?", budget.ID, effectiveFrom).Delete(&model.BudgetAmountVersion{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.BudgetAmountVersion{
+		UserID:        budget.UserID,
+		BudgetID:      budget.ID,
+		Amount:        amount,
+		EffectiveFrom: effectiveFrom,
+	}).Error
+}
+
+// budgetAmountFunc 返回按周期开始时间获取预算金额的函数，普通预算按金额版本取值，信封取该月分配的金额
+func budgetAmountFunc(db *gorm.DB, budget *model.Budget) (func(periodStart time.Time) float64, error) {
+	if budget.IsEnvelope {
+		assigned, err := envelopeAssignedByMonth(db, budget.ID)
+		if err != nil {
+			return nil, err
+		}
+		return func(periodStart time.Time) float64 {
+			return assigned[envelopeMonthKey(periodStart)]
+		}, nil
+	}
+
+	versions, err := budgetAmountVersions(db, budget.ID)
+	if err != nil {
+		return nil, err
+	}
+	return func(periodStart time.Time) float64 {
+		return budgetAmountAt(budget, versions, periodStart)
+	}, nil
+}
+
+// budgetAmountVersions 获取预算的金额版本，按生效日期升序
+func budgetAmountVersions(db *gorm.DB, budgetID uint) ([]model.BudgetAmountVersion, error) {
+	var versions []model.BudgetAmountVersion
+	if err := db.Where("budget_id = ?", budgetID).Order("effective_from ASC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// budgetAmountAt 获取从 periodStart 开始的周期所使用的预算金额
+// 取生效日期不晚于周期开始的最后一个版本；早于所有版本时使用第一个版本，没有版本记录时使用预算当前金额
+func budgetAmountAt(budget *model.Budget, versions []model.BudgetAmountVersion, periodStart time.Time) float64 {
+	if len(versions) == 0 {
+		return budget.Amount
+	}
+	amount := versions[0].Amount
+	for _, version := range versions {
+		if version.EffectiveFrom.After(periodStart) {
+			break
+		}
+		amount = version.Amount
+	}
+	return amount
+}
+
+// daysBetween 两个零点时间之间的天数，按四舍五入处理夏令时造成的误差
+func daysBetween(start, end time.Time) int {
+	return int(end.Sub(start).Hours()/24 + 0.5)
+}
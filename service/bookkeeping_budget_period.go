@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"gorm.io/gorm"
+)
+
+// budgetPeriodRange 一个预算周期的起止时间
+type budgetPeriodRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// budgetPeriodMonths 按月计算的周期包含的月数，按天计算的周期返回0
+func budgetPeriodMonths(period model.BudgetPeriod) int {
+	switch period {
+	case model.BudgetPeriodMonthly:
+		return 1
+	case model.BudgetPeriodQuarterly:
+		return 3
+	case model.BudgetPeriodYearly:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// budgetPeriodDays 按天计算的周期包含的天数，按月计算的周期和一次性预算返回0
+func budgetPeriodDays(budget *model.Budget) int {
+	switch budget.Period {
+	case model.BudgetPeriodWeekly:
+		return 7
+	case model.BudgetPeriodBiweekly:
+		return 14
+	case model.BudgetPeriodCustom:
+		return budget.PeriodDays
+	default:
+		return 0
+	}
+}
+
+// validateBudgetPeriod 校验预算周期的配置：自定义周期需要天数，一次性预算需要结束日期，结束日期不能早于开始日期
+func validateBudgetPeriod(budget *model.Budget) error {
+	switch budget.Period {
+	case model.BudgetPeriodWeekly, model.BudgetPeriodBiweekly, model.BudgetPeriodMonthly,
+		model.BudgetPeriodQuarterly, model.BudgetPeriodYearly:
+	case model.BudgetPeriodCustom:
+		if budget.PeriodDays <= 0 {
+			return errors.New("自定义周期必须指定周期天数")
+		}
+	case model.BudgetPeriodOnce:
+		if budget.EndDate == nil {
+			return errors.New("一次性预算必须指定结束日期")
+		}
+	default:
+		return errors.New("不支持的预算周期类型")
+	}
+
+	if budget.EndDate != nil && budget.EndDate.Before(budgetFirstPeriodStart(budget.StartDate)) {
+		return errors.New("结束日期不能早于开始日期")
+	}
+	return nil
+}
+
+// budgetFirstPeriodStart 预算第一个周期的开始时间，从开始日期的零点开始
+func budgetFirstPeriodStart(startDate time.Time) time.Time {
+	return time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+}
+
+// budgetEndTime 预算结束日期次日零点，没有结束日期时返回 false
+func budgetEndTime(budget *model.Budget) (time.Time, bool) {
+	if budget.EndDate == nil {
+		return time.Time{}, false
+	}
+	end := budget.EndDate.In(budget.StartDate.Location())
+	return time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, end.Location()), true
+}
+
+// addMonthsClamped 增加若干个月，目标月份没有对应日期时 (如31日) 取当月最后一天
+func addMonthsClamped(t time.Time, months int) time.Time {
+	target := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := target.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(target.Year(), target.Month(), day, 0, 0, 0, 0, t.Location())
+}
+
+// budgetPeriodStart 第 n 个周期 (从0开始) 的开始时间，每个周期都从第一个周期推算，避免月末日期逐月漂移
+func budgetPeriodStart(budget *model.Budget, n int) time.Time {
+	first := budgetFirstPeriodStart(budget.StartDate)
+	if n == 0 {
+		return first
+	}
+	if months := budgetPeriodMonths(budget.Period); months > 0 {
+		return addMonthsClamped(first, n*months)
+	}
+	if days := budgetPeriodDays(budget); days > 0 {
+		return first.AddDate(0, 0, n*days)
+	}
+
+	// 一次性预算只有一个周期，截止到结束日期；没有结束日期时按一天处理
+	if end, ok := budgetEndTime(budget); ok {
+		return end
+	}
+	return first.AddDate(0, 0, 1)
+}
+
+// budgetPeriod 第 n 个周期的起止时间，最后一个周期截止到结束日期
+func budgetPeriod(budget *model.Budget, n int) budgetPeriodRange {
+	start := budgetPeriodStart(budget, n)
+	next := budgetPeriodStart(budget, n+1)
+	if end, ok := budgetEndTime(budget); ok && next.After(end) {
+		next = end
+	}
+	return budgetPeriodRange{Start: start, End: next.Add(-time.Second)}
+}
+
+// budgetPeriodIndex 包含时间 t 的周期序号，t 早于第一个周期时返回0
+func budgetPeriodIndex(budget *model.Budget, t time.Time) int {
+	first := budgetFirstPeriodStart(budget.StartDate)
+	if t.Before(first) || budget.Period == model.BudgetPeriodOnce {
+		return 0
+	}
+
+	var n int
+	if months := budgetPeriodMonths(budget.Period); months > 0 {
+		n = ((t.Year()-first.Year())*12 + int(t.Month()) - int(first.Month())) / months
+	} else if days := budgetPeriodDays(budget); days > 0 {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, first.Location())
+		n = daysBetween(first, day) / days
+	}
+
+	// 按月估算的序号可能因为日期差一个周期，向前后修正
+	for n > 0 && budgetPeriodStart(budget, n).After(t) {
+		n--
+	}
+	for !budgetPeriodStart(budget, n+1).After(t) {
+		n++
+	}
+	return n
+}
+
+// budgetPeriodsUntil 列出预算从开始日期起开始时间早于 until 的所有周期，有结束日期时不超过结束日期
+func budgetPeriodsUntil(budget *model.Budget, until time.Time) []budgetPeriodRange {
+	end, hasEnd := budgetEndTime(budget)
+	var periods []budgetPeriodRange
+	for n := 0; ; n++ {
+		period := budgetPeriod(budget, n)
+		if !period.Start.Before(until) || (hasEnd && !period.Start.Before(end)) {
+			break
+		}
+		// 周期配置无效时开始时间不再推进，避免死循环
+		if n > 0 && !period.Start.After(periods[n-1].Start) {
+			break
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+// budgetPeriodsBefore 列出预算从开始日期到 before 之前的所有历史周期，最后一个周期截止到 before 前一秒
+func budgetPeriodsBefore(budget *model.Budget, before time.Time) []budgetPeriodRange {
+	periods := budgetPeriodsUntil(budget, before)
+	if last := len(periods) - 1; last >= 0 && !periods[last].End.Before(before) {
+		periods[last].End = before.Add(-time.Second)
+	}
+	return periods
+}
+
+// calculateCurrentPeriod 计算当前预算周期的开始和结束日期
+// 预算尚未开始时为第一个周期，已经结束时为最后一个周期
+func (s *BookkeepingBudgetService) calculateCurrentPeriod(budget *model.Budget) (time.Time, time.Time, error) {
+	if err := validateBudgetPeriod(budget); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	now := time.Now()
+	if end, ok := budgetEndTime(budget); ok && !now.Before(end) {
+		now = end.Add(-time.Second)
+	}
+	period := budgetPeriod(budget, budgetPeriodIndex(budget, now))
+	return period.Start, period.End, nil
+}
+
+// alignToMonthStartDay 将按月计算的预算开始日期对齐到不晚于该日期的每月起始日
+func alignToMonthStartDay(startDate time.Time, monthStartDay int) time.Time {
+	aligned := time.Date(startDate.Year(), startDate.Month(), monthStartDay, 0, 0, 0, 0, startDate.Location())
+	if startDate.Day() < monthStartDay {
+		aligned = aligned.AddDate(0, -1, 0)
+	}
+	return aligned
+}
+
+// budgetExpired 预算是否已过结束日期
+func budgetExpired(budget *model.Budget) bool {
+	end, ok := budgetEndTime(budget)
+	return ok && !time.Now().Before(end)
+}
+
+// alignBudgetStartDate 按月、季度、年计算的预算，开始日期对齐到用户设置的每月起始日
+// 信封按自然月分配资金，不做对齐
+func (s *BookkeepingBudgetService) alignBudgetStartDate(userID uint, budget *model.Budget) error {
+	if budgetPeriodMonths(budget.Period) == 0 || budget.IsEnvelope {
+		return nil
+	}
+	setting, err := userSetting(global.DB, userID)
+	if err != nil {
+		return err
+	}
+	if setting.MonthStartDay > 0 {
+		budget.StartDate = alignToMonthStartDay(budget.StartDate, setting.MonthStartDay)
+	}
+	return nil
+}
+
+// deactivateExpiredBudgets 停用结束日期已过的预算
+func deactivateExpiredBudgets(db *gorm.DB, userID uint) error {
+	today := truncateToDay(time.Now())
+	return db.Model(&model.Budget{}).
+		Where("user_id = ? AND is_active = ? AND end_date IS NOT NULL AND end_date < ?", userID, true, today).
+		Update("is_active", false).Error
+}
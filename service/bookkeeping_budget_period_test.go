@@ -0,0 +1,166 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dotdancer/gogofly/model"
+)
+
+const periodTimeLayout = "2006-01-02 15:04:05"
+
+func testBudget(t *testing.T, period model.BudgetPeriod, periodDays int, start, end string) *model.Budget {
+	t.Helper()
+	budget := &model.Budget{Period: period, PeriodDays: periodDays, StartDate: mustDate(t, start)}
+	if end != "" {
+		endDate := mustDate(t, end)
+		budget.EndDate = &endDate
+	}
+	return budget
+}
+
+func TestAddMonthsClamped(t *testing.T) {
+	tests := []struct {
+		date   string
+		months int
+		want   string
+	}{
+		{"2023-01-31", 1, "2023-02-28"},
+		{"2024-01-31", 1, "2024-02-29"},
+		{"2023-01-31", 2, "2023-03-31"},
+		{"2023-01-31", 3, "2023-04-30"},
+		{"2023-03-31", -1, "2023-02-28"},
+		{"2023-12-31", 2, "2024-02-29"},
+		{"2023-01-15", 12, "2024-01-15"},
+		{"2024-02-29", 12, "2025-02-28"},
+	}
+
+	for _, tt := range tests {
+		got := addMonthsClamped(mustDate(t, tt.date), tt.months)
+		if got.Format("2006-01-02") != tt.want {
+			t.Errorf("addMonthsClamped(%s, %d) = %s, want %s", tt.date, tt.months, got.Format("2006-01-02"), tt.want)
+		}
+	}
+}
+
+func TestBudgetPeriodIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget *model.Budget
+		at     string
+		want   int
+		start  string
+		end    string
+	}{
+		{"月末开始的月预算第一期", testBudget(t, model.BudgetPeriodMonthly, 0, "2023-01-31", ""), "2023-02-27", 0, "2023-01-31 00:00:00", "2023-02-27 23:59:59"},
+		{"月末开始的月预算二月取最后一天", testBudget(t, model.BudgetPeriodMonthly, 0, "2023-01-31", ""), "2023-02-28", 1, "2023-02-28 00:00:00", "2023-03-30 23:59:59"},
+		{"月末开始的月预算不随二月漂移", testBudget(t, model.BudgetPeriodMonthly, 0, "2023-01-31", ""), "2023-03-31", 2, "2023-03-31 00:00:00", "2023-04-29 23:59:59"},
+		{"开始日期之前", testBudget(t, model.BudgetPeriodMonthly, 0, "2023-01-31", ""), "2023-01-01", 0, "2023-01-31 00:00:00", "2023-02-27 23:59:59"},
+		{"季度预算第一期最后一天", testBudget(t, model.BudgetPeriodQuarterly, 0, "2024-01-15", ""), "2024-04-14", 0, "2024-01-15 00:00:00", "2024-04-14 23:59:59"},
+		{"季度预算第二期", testBudget(t, model.BudgetPeriodQuarterly, 0, "2024-01-15", ""), "2024-04-15", 1, "2024-04-15 00:00:00", "2024-07-14 23:59:59"},
+		{"季度预算跨年", testBudget(t, model.BudgetPeriodQuarterly, 0, "2024-11-30", ""), "2025-03-01", 1, "2025-02-28 00:00:00", "2025-05-29 23:59:59"},
+		{"10天自定义周期第一期", testBudget(t, model.BudgetPeriodCustom, 10, "2024-01-01", ""), "2024-01-10", 0, "2024-01-01 00:00:00", "2024-01-10 23:59:59"},
+		{"10天自定义周期第二期", testBudget(t, model.BudgetPeriodCustom, 10, "2024-01-01", ""), "2024-01-11", 1, "2024-01-11 00:00:00", "2024-01-20 23:59:59"},
+		{"10天自定义周期跨月", testBudget(t, model.BudgetPeriodCustom, 10, "2024-01-01", ""), "2024-02-15", 4, "2024-02-10 00:00:00", "2024-02-19 23:59:59"},
+		{"一次性预算只有一期", testBudget(t, model.BudgetPeriodOnce, 0, "2024-03-01", "2024-03-20"), "2024-03-10", 0, "2024-03-01 00:00:00", "2024-03-20 23:59:59"},
+		{"一次性预算结束之后仍为第一期", testBudget(t, model.BudgetPeriodOnce, 0, "2024-03-01", "2024-03-20"), "2024-06-01", 0, "2024-03-01 00:00:00", "2024-03-20 23:59:59"},
+		{"结束日期在周期中间时截止到结束日期", testBudget(t, model.BudgetPeriodMonthly, 0, "2024-01-10", "2024-03-20"), "2024-03-15", 2, "2024-03-10 00:00:00", "2024-03-20 23:59:59"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := mustDate(t, tt.at).Add(12 * time.Hour)
+			n := budgetPeriodIndex(tt.budget, at)
+			if n != tt.want {
+				t.Fatalf("budgetPeriodIndex() = %d, want %d", n, tt.want)
+			}
+			period := budgetPeriod(tt.budget, n)
+			if got := period.Start.Format(periodTimeLayout); got != tt.start {
+				t.Errorf("period start = %s, want %s", got, tt.start)
+			}
+			if got := period.End.Format(periodTimeLayout); got != tt.end {
+				t.Errorf("period end = %s, want %s", got, tt.end)
+			}
+		})
+	}
+}
+
+func TestBudgetPeriodsUntil(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget *model.Budget
+		until  string
+		want   [][2]string
+	}{
+		{
+			name:   "月末开始的月预算",
+			budget: testBudget(t, model.BudgetPeriodMonthly, 0, "2023-01-31", ""),
+			until:  "2023-04-30",
+			want: [][2]string{
+				{"2023-01-31 00:00:00", "2023-02-27 23:59:59"},
+				{"2023-02-28 00:00:00", "2023-03-30 23:59:59"},
+				{"2023-03-31 00:00:00", "2023-04-29 23:59:59"},
+			},
+		},
+		{
+			name:   "季度预算",
+			budget: testBudget(t, model.BudgetPeriodQuarterly, 0, "2024-01-01", ""),
+			until:  "2024-12-31",
+			want: [][2]string{
+				{"2024-01-01 00:00:00", "2024-03-31 23:59:59"},
+				{"2024-04-01 00:00:00", "2024-06-30 23:59:59"},
+				{"2024-07-01 00:00:00", "2024-09-30 23:59:59"},
+				{"2024-10-01 00:00:00", "2024-12-31 23:59:59"},
+			},
+		},
+		{
+			name:   "10天自定义周期",
+			budget: testBudget(t, model.BudgetPeriodCustom, 10, "2024-01-25", ""),
+			until:  "2024-02-20",
+			want: [][2]string{
+				{"2024-01-25 00:00:00", "2024-02-03 23:59:59"},
+				{"2024-02-04 00:00:00", "2024-02-13 23:59:59"},
+				{"2024-02-14 00:00:00", "2024-02-23 23:59:59"},
+			},
+		},
+		{
+			name:   "一次性预算",
+			budget: testBudget(t, model.BudgetPeriodOnce, 0, "2024-03-01", "2024-03-20"),
+			until:  "2025-01-01",
+			want: [][2]string{
+				{"2024-03-01 00:00:00", "2024-03-20 23:59:59"},
+			},
+		},
+		{
+			name:   "结束日期在周期中间",
+			budget: testBudget(t, model.BudgetPeriodMonthly, 0, "2024-01-10", "2024-03-20"),
+			until:  "2024-12-31",
+			want: [][2]string{
+				{"2024-01-10 00:00:00", "2024-02-09 23:59:59"},
+				{"2024-02-10 00:00:00", "2024-03-09 23:59:59"},
+				{"2024-03-10 00:00:00", "2024-03-20 23:59:59"},
+			},
+		},
+		{
+			name:   "开始日期之前没有周期",
+			budget: testBudget(t, model.BudgetPeriodMonthly, 0, "2024-01-10", ""),
+			until:  "2024-01-10",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods := budgetPeriodsUntil(tt.budget, mustDate(t, tt.until))
+			if len(periods) != len(tt.want) {
+				t.Fatalf("budgetPeriodsUntil() returned %d periods, want %d: %v", len(periods), len(tt.want), periods)
+			}
+			for i, period := range periods {
+				start, end := period.Start.Format(periodTimeLayout), period.End.Format(periodTimeLayout)
+				if start != tt.want[i][0] || end != tt.want[i][1] {
+					t.Errorf("period %d = [%s, %s], want [%s, %s]", i, start, end, tt.want[i][0], tt.want[i][1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// budgetScope 预算的统计范围，由分类、排除的分类、账户、标签和收款方共同决定，各条件同时满足才计入
+type budgetScope struct {
+	CategoryIDs        []uint   // 计入的分类 (已展开子分类并去掉排除的分类)，为 nil 表示不限分类
+	ExcludeCategoryIDs []uint   // 不限分类时排除的分类 (已展开子分类)
+	AccountIDs         []uint   // 限定的账户，为空表示不限账户
+	Tags               []string // 限定的标签，交易包含任一标签即计入
+	Payee              string   // 限定的收款方，包含匹配
+}
+
+// resolveBudgetScope 解析预算的统计范围，分类预算的 CategoryID 与额外计入的分类合并
+func resolveBudgetScope(db *gorm.DB, userID uint, budget *model.Budget) (*budgetScope, error) {
+	var categories []model.BudgetCategory
+	if err := db.Where("budget_id = ?", budget.ID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	var accounts []model.BudgetAccount
+	if err := db.Where("budget_id = ?", budget.ID).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	scope := &budgetScope{
+		Tags:  splitTags(budget.Tags),
+		Payee: budget.Payee,
+	}
+	for _, account := range accounts {
+		scope.AccountIDs = append(scope.AccountIDs, account.AccountID)
+	}
+
+	var included, excluded []uint
+	if budget.Type == model.BudgetTypeCategory && budget.CategoryID != nil {
+		included = append(included, *budget.CategoryID)
+	}
+	for _, category := range categories {
+		if category.Exclude {
+			excluded = append(excluded, category.CategoryID)
+		} else {
+			included = append(included, category.CategoryID)
+		}
+	}
+
+	excludedIDs, err := expandCategorySubtrees(db, userID, excluded)
+	if err != nil {
+		return nil, err
+	}
+	if len(included) == 0 {
+		scope.ExcludeCategoryIDs = excludedIDs
+		return scope, nil
+	}
+
+	includedIDs, err := expandCategorySubtrees(db, userID, included)
+	if err != nil {
+		return nil, err
+	}
+	isExcluded := make(map[uint]bool, len(excludedIDs))
+	for _, id := range excludedIDs {
+		isExcluded[id] = true
+	}
+	scope.CategoryIDs = make([]uint, 0, len(includedIDs))
+	for _, id := range includedIDs {
+		if !isExcluded[id] {
+			scope.CategoryIDs = append(scope.CategoryIDs, id)
+		}
+	}
+	return scope, nil
+}
+
+// expandCategorySubtrees 将分类展开为包含所有子分类的去重列表
+func expandCategorySubtrees(db *gorm.DB, userID uint, categoryIDs []uint) ([]uint, error) {
+	seen := make(map[uint]bool)
+	var result []uint
+	for _, categoryID := range categoryIDs {
+		subtree, err := categorySubtreeIDs(db, userID, categoryID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range subtree {
+			if !seen[id] {
+				seen[id] = true
+				result = append(result, id)
+			}
+		}
+	}
+	return result, nil
+}
+
+// apply 为交易查询加上统计范围的筛选条件
+func (scope *budgetScope) apply(query *gorm.DB) *gorm.DB {
+	if scope.CategoryIDs != nil {
+		query = query.Where("category_id IN ?", scope.CategoryIDs)
+	}
+	if len(scope.ExcludeCategoryIDs) > 0 {
+		query = query.Where("category_id NOT IN ?", scope.ExcludeCategoryIDs)
+	}
+	if len(scope.AccountIDs) > 0 {
+		query = query.Where("account_id IN ?", scope.AccountIDs)
+	}
+	query = whereAnyTag(query, "tags", scope.Tags)
+	if scope.Payee != "" {
+		query = query.Where("payee_payer LIKE ?", "%"+escapeLike(scope.Payee)+"%")
+	}
+	return query
+}
+
+// matchesUpcoming 已知的未来支出是否在统计范围内
+// 未来支出没有标签，限定标签的预算不计入；限定账户时付款账户未知的支出也不计入
+func (scope *budgetScope) matchesUpcoming(item upcomingExpense) bool {
+	if scope.CategoryIDs != nil && !containsUint(scope.CategoryIDs, item.CategoryID) {
+		return false
+	}
+	if containsUint(scope.ExcludeCategoryIDs, item.CategoryID) {
+		return false
+	}
+	if len(scope.AccountIDs) > 0 && !containsUint(scope.AccountIDs, item.AccountID) {
+		return false
+	}
+	if len(scope.Tags) > 0 {
+		return false
+	}
+	if scope.Payee != "" && !strings.Contains(strings.ToLower(item.Payee), strings.ToLower(scope.Payee)) {
+		return false
+	}
+	return true
+}
+
+// saveBudgetScope 保存预算额外计入、排除的分类和限定的账户，nil 表示保持不变，空数组表示清除
+// 分类和账户必须属于当前用户，同一分类不能同时计入和排除
+func saveBudgetScope(tx *gorm.DB, userID uint, budget *model.Budget, categoryIDs, excludeCategoryIDs, accountIDs *[]uint) error {
+	if categoryIDs != nil || excludeCategoryIDs != nil {
+		var existing []model.BudgetCategory
+		if err := tx.Where("budget_id = ?", budget.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		var included, excluded []uint
+		for _, category := range existing {
+			if category.Exclude {
+				excluded = append(excluded, category.CategoryID)
+			} else {
+				included = append(included, category.CategoryID)
+			}
+		}
+		if categoryIDs != nil {
+			included = uniqueUints(*categoryIDs)
+		}
+		if excludeCategoryIDs != nil {
+			excluded = uniqueUints(*excludeCategoryIDs)
+		}
+
+		for _, id := range excluded {
+			if containsUint(included, id) || (budget.Type == model.BudgetTypeCategory && budget.CategoryID != nil && *budget.CategoryID == id) {
+				return newBizError("同一分类不能同时计入和排除")
+			}
+		}
+		if err := ensureUserRecords(tx, &model.Category{}, userID, append(append([]uint{}, included...), excluded...), "指定的分类不存在"); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("budget_id = ?", budget.ID).Delete(&model.BudgetCategory{}).Error; err != nil {
+			return err
+		}
+		for _, id := range included {
+			if err := tx.Create(&model.BudgetCategory{BudgetID: budget.ID, CategoryID: id}).Error; err != nil {
+				return err
+			}
+		}
+		for _, id := range excluded {
+			if err := tx.Create(&model.BudgetCategory{BudgetID: budget.ID, CategoryID: id, Exclude: true}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if accountIDs != nil {
+		ids := uniqueUints(*accountIDs)
+		if err := ensureUserRecords(tx, &model.Account{}, userID, ids, "指定的账户不存在"); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("budget_id = ?", budget.ID).Delete(&model.BudgetAccount{}).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := tx.Create(&model.BudgetAccount{BudgetID: budget.ID, AccountID: id}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkBudgetCategoryKind 校验计入的分类与预算方向一致：支出预算只能选择支出分类，收入目标只能选择收入分类
+// 排除的分类不影响统计结果，不做校验
+func checkBudgetCategoryKind(tx *gorm.DB, budget *model.Budget) error {
+	var categoryIDs []uint
+	if err := tx.Model(&model.BudgetCategory{}).Where("budget_id = ? AND exclude = ?", budget.ID, false).
+		Pluck("category_id", &categoryIDs).Error; err != nil {
+		return err
+	}
+	if budget.Type == model.BudgetTypeCategory && budget.CategoryID != nil {
+		categoryIDs = append(categoryIDs, *budget.CategoryID)
+	}
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&model.Category{}).Where("id IN ? AND type <> ?", uniqueUints(categoryIDs), budgetTransactionType(budget)).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		if budget.Kind == model.BudgetKindIncome {
+			return newBizError("收入目标只能选择收入分类")
+		}
+		return newBizError("支出预算只能选择支出分类")
+	}
+	return nil
+}
+
+// deleteBudgetScope 删除预算的分类和账户范围
+func deleteBudgetScope(tx *gorm.DB, budgetID uint) error {
+	if err := tx.Where("budget_id = ?", budgetID).Delete(&model.BudgetCategory{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("budget_id = ?", budgetID).Delete(&model.BudgetAccount{}).Error
+}
+
+// fillBudgetScopeResponse 将预载的分类和账户范围填入响应
+func fillBudgetScopeResponse(budget *model.Budget, response *dto.BudgetResponse) {
+	response.CategoryIDs = nil
+	response.ExcludeCategoryIDs = nil
+	response.AccountIDs = nil
+	for _, category := range budget.Categories {
+		if category.Exclude {
+			response.ExcludeCategoryIDs = append(response.ExcludeCategoryIDs, category.CategoryID)
+		} else {
+			response.CategoryIDs = append(response.CategoryIDs, category.CategoryID)
+		}
+	}
+	for _, account := range budget.Accounts {
+		response.AccountIDs = append(response.AccountIDs, account.AccountID)
+	}
+	response.Tags = splitTags(budget.Tags)
+	response.Payee = budget.Payee
+}
+
+// ensureUserRecords 校验记录都存在且属于当前用户
+func ensureUserRecords(tx *gorm.DB, record interface{}, userID uint, ids []uint, notFound string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ids = uniqueUints(ids)
+	var count int64
+	if err := tx.Model(record).Where("id IN ? AND user_id = ?", ids, userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		return newBizError(notFound)
+	}
+	return nil
+}
+
+// uniqueUints 去掉重复的ID，保持原有顺序
+func uniqueUints(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// containsUint 判断ID是否在列表中
+func containsUint(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLike 转义 LIKE 查询中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/dotdancer/gogofly/global"
@@ -17,21 +18,29 @@ type BookkeepingBudgetService struct{}
 
 // CreateBudget 创建预算
 func (s *BookkeepingBudgetService) CreateBudget(userID uint, req dto.CreateBudgetRequest) (*dto.BudgetResponse, error) {
-	// 如果是分类预算，需要校验分类是否存在
+	// 如果是分类预算，需要校验分类是否存在，额外计入的分类在保存范围时校验
 	if req.Type == string(model.BudgetTypeCategory) {
-		if req.CategoryID == nil {
+		if req.CategoryID == nil && len(req.CategoryIDs) == 0 {
 			return nil, errors.New("分类预算必须指定分类ID")
 		}
-		var category model.Category
-		if err := global.DB.Where("id = ? AND user_id = ?", *req.CategoryID, userID).First(&category).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, errors.New("指定的分类不存在")
+		if req.CategoryID != nil {
+			var category model.Category
+			if err := global.DB.Where("id = ? AND user_id = ?", *req.CategoryID, userID).First(&category).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, errors.New("指定的分类不存在")
+				}
+				return nil, err
 			}
-			return nil, err
 		}
-	} else if req.CategoryID != nil {
+	} else {
 		// 如果不是分类预算，但传入了分类ID，则置为nil
 		req.CategoryID = nil
+		req.CategoryIDs = nil
+	}
+
+	tags, err := joinTags(req.Tags)
+	if err != nil {
+		return nil, err
 	}
 
 	// 创建预算
@@ -39,14 +48,21 @@ func (s *BookkeepingBudgetService) CreateBudget(userID uint, req dto.CreateBudge
 		UserID:      userID,
 		Name:        req.Name,
 		Type:        model.BudgetType(req.Type),
+		Kind:        model.BudgetKind(req.Kind),
 		Period:      model.BudgetPeriod(req.Period),
 		Amount:      req.Amount,
 		StartDate:   req.StartDate,
 		CategoryID:  req.CategoryID,
 		Description: req.Description,
+		Tags:        tags,
+		Payee:       req.Payee,
 	}
 
 	// 设置默认值
+	if budget.Kind == "" {
+		budget.Kind = model.BudgetKindExpense // 默认为支出预算
+	}
+
 	if req.NotifyRate != nil {
 		budget.NotifyRate = *req.NotifyRate
 	} else {
@@ -59,25 +75,66 @@ func (s *BookkeepingBudgetService) CreateBudget(userID uint, req dto.CreateBudge
 		budget.IsActive = true // 默认激活
 	}
 
-	// 保存到数据库
-	if err := global.DB.Create(&budget).Error; err != nil {
-		global.Logger.Error("Failed to create budget: " + err.Error())
-		return nil, errors.New("创建预算失败：数据库错误")
+	budget.RolloverMode = model.BudgetRolloverMode(req.RolloverMode)
+	if budget.RolloverMode == "" {
+		budget.RolloverMode = model.BudgetRolloverNone // 默认不结转
+	}
+	if req.RolloverCap != nil && *req.RolloverCap > 0 {
+		budget.RolloverCap = req.RolloverCap
 	}
 
-	// 转换为响应
-	var response dto.BudgetResponse
-	if err := s.budgetToResponse(&budget, &response); err != nil {
+	// 校验周期配置，按月计算的预算开始日期对齐到用户设置的每月起始日
+	if budget.Period == model.BudgetPeriodCustom {
+		budget.PeriodDays = req.PeriodDays
+	}
+	budget.EndDate = req.EndDate
+	if err := s.alignBudgetStartDate(userID, &budget); err != nil {
+		global.Logger.Error("Failed to align budget start date: " + err.Error())
+		return nil, errors.New("创建预算失败：数据库错误")
+	}
+	if err := validateBudgetPeriod(&budget); err != nil {
 		return nil, err
 	}
+	if budgetExpired(&budget) {
+		budget.IsActive = false // 结束日期已过的预算直接停用
+	}
 
-	return &response, nil
+	// 保存到数据库，同时记录初始金额版本和统计范围
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&budget).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.BudgetAmountVersion{
+			UserID:        userID,
+			BudgetID:      budget.ID,
+			Amount:        budget.Amount,
+			EffectiveFrom: budgetFirstPeriodStart(budget.StartDate),
+		}).Error; err != nil {
+			return err
+		}
+		if err := saveBudgetScope(tx, userID, &budget, &req.CategoryIDs, &req.ExcludeCategoryIDs, &req.AccountIDs); err != nil {
+			return err
+		}
+		return checkBudgetCategoryKind(tx, &budget)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create budget: " + err.Error())
+		return nil, userFacingError(err, "创建预算失败：数据库错误")
+	}
+
+	return s.GetBudget(userID, budget.ID)
 }
 
 // GetBudget 获取预算详情
 func (s *BookkeepingBudgetService) GetBudget(userID, budgetID uint) (*dto.BudgetResponse, error) {
+	// 停用已过结束日期的预算
+	if err := deactivateExpiredBudgets(global.DB, userID); err != nil {
+		global.Logger.Error("Failed to deactivate expired budgets: " + err.Error())
+		return nil, errors.New("获取预算失败：数据库错误")
+	}
+
 	var budget model.Budget
-	if err := global.DB.Preload("Category").Where("id = ? AND user_id = ?", budgetID, userID).First(&budget).Error; err != nil {
+	if err := global.DB.Preload("Category").Preload("Categories").Preload("Accounts").Where("id = ? AND user_id = ?", budgetID, userID).First(&budget).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("预算不存在")
 		}
@@ -95,9 +152,15 @@ func (s *BookkeepingBudgetService) GetBudget(userID, budgetID uint) (*dto.Budget
 
 // GetBudgetProgress 获取预算进度
 func (s *BookkeepingBudgetService) GetBudgetProgress(userID, budgetID uint) (*dto.BudgetProgressResponse, error) {
+	// 停用已过结束日期的预算
+	if err := deactivateExpiredBudgets(global.DB, userID); err != nil {
+		global.Logger.Error("Failed to deactivate expired budgets: " + err.Error())
+		return nil, errors.New("获取预算失败：数据库错误")
+	}
+
 	// 获取预算基本信息
 	var budget model.Budget
-	if err := global.DB.Preload("Category").Where("id = ? AND user_id = ?", budgetID, userID).First(&budget).Error; err != nil {
+	if err := global.DB.Preload("Category").Preload("Categories").Preload("Accounts").Where("id = ? AND user_id = ?", budgetID, userID).First(&budget).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("预算不存在")
 		}
@@ -106,67 +169,39 @@ func (s *BookkeepingBudgetService) GetBudgetProgress(userID, budgetID uint) (*dt
 	}
 
 	// 计算当前预算周期
-	currentPeriodStart, currentPeriodEnd, err := s.calculateCurrentPeriod(budget.StartDate, budget.Period)
+	currentPeriodStart, currentPeriodEnd, err := s.calculateCurrentPeriod(&budget)
 	if err != nil {
 		return nil, err
 	}
 
-	// 获取当前周期内的支出
-	var spentAmount float64
-	query := global.DB.Model(&model.Transaction{}).
-		Where("user_id = ? AND type = ? AND transaction_date BETWEEN ? AND ?", 
-			userID, model.TransactionTypeExpense, currentPeriodStart, currentPeriodEnd)
-
-	// 如果是分类预算，则只统计该分类的支出
-	if budget.Type == model.BudgetTypeCategory && budget.CategoryID != nil {
-		query = query.Where("category_id = ?", *budget.CategoryID)
-	}
-
-	if err := query.Select("COALESCE(SUM(amount), 0)").Scan(&spentAmount).Error; err != nil {
+	// 获取当前周期内的支出，分类预算包含所有子分类
+	spentAmount, err := s.budgetSpentAmount(userID, &budget, currentPeriodStart, currentPeriodEnd)
+	if err != nil {
 		global.Logger.Error("Failed to calculate spent amount: " + err.Error())
 		return nil, errors.New("计算预算进度失败：数据库错误")
 	}
 
-	// 计算进度
-	remainingAmount := budget.Amount - spentAmount
-	if remainingAmount < 0 {
-		remainingAmount = 0
+	// 计算从历史周期结转到当前周期的金额
+	rollover, err := s.budgetRollover(userID, &budget, currentPeriodStart)
+	if err != nil {
+		global.Logger.Error("Failed to calculate budget rollover: " + err.Error())
+		return nil, errors.New("计算预算进度失败：数据库错误")
 	}
 
-	usageRate := spentAmount / budget.Amount
-	isOverBudget := usageRate > 1.0
-
-	// 计算剩余天数
-	daysRemaining := int(math.Ceil(currentPeriodEnd.Sub(time.Now()).Hours() / 24))
-	if daysRemaining < 0 {
-		daysRemaining = 0
+	// 当前周期的预算金额，信封为本月分配的金额
+	amount, err := s.budgetCurrentAmount(&budget, currentPeriodStart)
+	if err != nil {
+		global.Logger.Error("Failed to get budget amount: " + err.Error())
+		return nil, errors.New("计算预算进度失败：数据库错误")
 	}
 
-	// 转换为响应
-	var response dto.BudgetProgressResponse
-	response.ID = budget.ID
-	response.UserID = budget.UserID
-	response.Name = budget.Name
-	response.Type = string(budget.Type)
-	response.Period = string(budget.Period)
-	response.Amount = budget.Amount
-	response.StartDate = budget.StartDate
-	response.CategoryID = budget.CategoryID
-	response.NotifyRate = budget.NotifyRate
-	response.Description = budget.Description
-	response.IsActive = budget.IsActive
-	response.CreatedAt = budget.CreatedAt
-	response.UpdatedAt = budget.UpdatedAt
-	response.Category = s.categoryToDTO(budget.Category)
-	
-	response.SpentAmount = spentAmount
-	response.RemainingAmount = remainingAmount
-	response.UsageRate = usageRate
-	response.IsOverBudget = isOverBudget
-	response.DaysRemaining = daysRemaining
-	response.CurrentPeriod.StartDate = currentPeriodStart
-	response.CurrentPeriod.EndDate = currentPeriodEnd
+	response := s.buildBudgetProgress(&budget, currentPeriodStart, currentPeriodEnd, amount, spentAmount, rollover)
 
+	// 预测周期结束时的支出
+	if response.Forecast, err = s.budgetForecast(userID, &budget, &response); err != nil {
+		global.Logger.Error("Failed to forecast budget spending: " + err.Error())
+		return nil, errors.New("计算预算进度失败：数据库错误")
+	}
 	return &response, nil
 }
 
@@ -182,10 +217,28 @@ func (s *BookkeepingBudgetService) UpdateBudget(userID, budgetID uint, req dto.U
 		return nil, errors.New("更新预算失败：数据库错误")
 	}
 
+	// 信封每月的金额来自分配，周期固定为自然月并结转全部余额
+	if budget.IsEnvelope && (req.Type != nil || req.Kind != nil || req.Period != nil || req.PeriodDays != nil ||
+		req.Amount != nil || req.StartDate != nil || req.EndDate != nil || req.ClearEndDate ||
+		req.RolloverMode != nil || req.RolloverCap != nil) {
+		return nil, errors.New("信封的类型、周期、金额和结转方式不能修改，请通过分配和移动资金调整")
+	}
+
 	// 如果请求更新预算类型为分类预算，需要校验分类是否存在
 	if req.Type != nil && *req.Type == string(model.BudgetTypeCategory) {
 		if req.CategoryID == nil && budget.CategoryID == nil {
-			return nil, errors.New("分类预算必须指定分类ID")
+			// 没有主分类时需要有额外计入的分类
+			var included int64
+			if req.CategoryIDs != nil {
+				included = int64(len(*req.CategoryIDs))
+			} else if err := global.DB.Model(&model.BudgetCategory{}).
+				Where("budget_id = ? AND exclude = ?", budget.ID, false).Count(&included).Error; err != nil {
+				global.Logger.Error("Failed to count budget categories: " + err.Error())
+				return nil, errors.New("更新预算失败：数据库错误")
+			}
+			if included == 0 {
+				return nil, errors.New("分类预算必须指定分类ID")
+			}
 		}
 		
 		categoryID := budget.CategoryID
@@ -204,6 +257,58 @@ func (s *BookkeepingBudgetService) UpdateBudget(userID, budgetID uint, req dto.U
 		}
 	}
 
+	// 按修改后的周期配置校验，按月计算的预算开始日期对齐到每月起始日
+	updated := budget
+	if req.Period != nil {
+		updated.Period = model.BudgetPeriod(*req.Period)
+	}
+	if req.PeriodDays != nil {
+		updated.PeriodDays = *req.PeriodDays
+	}
+	if updated.Period != model.BudgetPeriodCustom {
+		updated.PeriodDays = 0
+	}
+	if req.StartDate != nil {
+		updated.StartDate = *req.StartDate
+	}
+	if req.ClearEndDate {
+		updated.EndDate = nil
+	} else if req.EndDate != nil {
+		updated.EndDate = req.EndDate
+	}
+	if req.Period != nil || req.StartDate != nil {
+		if err := s.alignBudgetStartDate(userID, &updated); err != nil {
+			global.Logger.Error("Failed to align budget start date: " + err.Error())
+			return nil, errors.New("更新预算失败：数据库错误")
+		}
+	}
+	if err := validateBudgetPeriod(&updated); err != nil {
+		return nil, err
+	}
+	if req.IsActive != nil && *req.IsActive && budgetExpired(&updated) {
+		return nil, errors.New("预算已过结束日期，请先修改结束日期")
+	}
+
+	// 修改后的统计范围，总体预算不计入额外的分类
+	if req.Type != nil {
+		updated.Type = model.BudgetType(*req.Type)
+	}
+	if req.Kind != nil {
+		updated.Kind = model.BudgetKind(*req.Kind)
+	}
+	if updated.Type == model.BudgetTypeOverall {
+		updated.CategoryID = nil
+	} else if req.CategoryID != nil {
+		updated.CategoryID = req.CategoryID
+	}
+	categoryIDs := req.CategoryIDs
+	if updated.Type == model.BudgetTypeOverall && budget.Type != model.BudgetTypeOverall {
+		categoryIDs = &[]uint{}
+	}
+	scopeChanged := categoryIDs != nil || req.ExcludeCategoryIDs != nil || req.AccountIDs != nil
+	// 预算方向或计入的分类变化时，需要校验分类类型与预算方向一致
+	kindCheck := updated.Kind != budget.Kind || req.CategoryID != nil || categoryIDs != nil
+
 	// 更新预算字段
 	updates := make(map[string]interface{})
 
@@ -219,16 +324,32 @@ func (s *BookkeepingBudgetService) UpdateBudget(userID, budgetID uint, req dto.U
 		}
 	}
 
+	if req.Kind != nil {
+		updates["kind"] = updated.Kind
+	}
+
 	if req.Period != nil {
-		updates["period"] = model.BudgetPeriod(*req.Period)
+		updates["period"] = updated.Period
+	}
+
+	if updated.PeriodDays != budget.PeriodDays {
+		updates["period_days"] = updated.PeriodDays
 	}
 
 	if req.Amount != nil {
 		updates["amount"] = *req.Amount
 	}
 
-	if req.StartDate != nil {
-		updates["start_date"] = *req.StartDate
+	if req.StartDate != nil || !updated.StartDate.Equal(budget.StartDate) {
+		updates["start_date"] = updated.StartDate
+	}
+
+	if req.ClearEndDate || req.EndDate != nil {
+		if updated.EndDate != nil {
+			updates["end_date"] = *updated.EndDate
+		} else {
+			updates["end_date"] = nil
+		}
 	}
 
 	if req.CategoryID != nil {
@@ -250,15 +371,66 @@ func (s *BookkeepingBudgetService) UpdateBudget(userID, budgetID uint, req dto.U
 		updates["is_active"] = *req.IsActive
 	}
 
+	if req.RolloverMode != nil {
+		updates["rollover_mode"] = model.BudgetRolloverMode(*req.RolloverMode)
+	}
+
+	if req.RolloverCap != nil {
+		// 传0表示取消上限
+		if *req.RolloverCap > 0 {
+			updates["rollover_cap"] = *req.RolloverCap
+		} else {
+			updates["rollover_cap"] = nil
+		}
+	}
+
+	if req.Tags != nil {
+		tags, err := joinTags(*req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		updates["tags"] = tags
+	}
+
+	if req.Payee != nil {
+		updates["payee"] = *req.Payee
+	}
+
 	// 如果没有需要更新的字段，直接返回
-	if len(updates) == 0 {
+	if len(updates) == 0 && !scopeChanged {
 		return s.GetBudget(userID, budgetID)
 	}
 
-	// 更新数据库
-	if err := global.DB.Model(&budget).Updates(updates).Error; err != nil {
+	// 更新数据库，金额变化时记录新版本，从当前周期开始生效
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if req.Amount != nil && *req.Amount != budget.Amount {
+			if err := s.recordAmountVersion(tx, &budget, &updated, *req.Amount); err != nil {
+				return err
+			}
+		}
+		if scopeChanged {
+			if err := saveBudgetScope(tx, userID, &updated, categoryIDs, req.ExcludeCategoryIDs, req.AccountIDs); err != nil {
+				return err
+			}
+		}
+		if kindCheck {
+			if err := checkBudgetCategoryKind(tx, &updated); err != nil {
+				return err
+			}
+		}
+		if budget.IsEnvelope && (req.CategoryID != nil || categoryIDs != nil || req.ExcludeCategoryIDs != nil) {
+			if err := checkEnvelopeScopeOverlap(tx, userID, &updated); err != nil {
+				return err
+			}
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&budget).Updates(updates).Error
+	})
+	if err != nil {
 		global.Logger.Error("Failed to update budget: " + err.Error())
-		return nil, errors.New("更新预算失败：数据库错误")
+		return nil, userFacingError(err, "更新预算失败：数据库错误")
 	}
 
 	// 获取更新后的预算
@@ -277,8 +449,33 @@ func (s *BookkeepingBudgetService) DeleteBudget(userID, budgetID uint) error {
 		return errors.New("删除预算失败：数据库错误")
 	}
 
-	// 删除预算
-	if err := global.DB.Delete(&budget).Error; err != nil {
+	// 删除信封时剩余的可用余额退回待分配 (超支部分从待分配中补足)，分配记录保留用于计算待分配金额
+	var envelopeBalance float64
+	if budget.IsEnvelope {
+		available, err := envelopeAvailable(userID, &budget, envelopeMonthStart(time.Now()))
+		if err != nil {
+			global.Logger.Error("Failed to calculate envelope balance: " + err.Error())
+			return errors.New("删除预算失败：数据库错误")
+		}
+		envelopeBalance = available
+	}
+
+	// 删除预算及其金额版本、统计范围
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if envelopeBalance != 0 {
+			if err := settleEnvelopeBalance(tx, userID, budget.ID, envelopeBalance, "删除信封"); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("budget_id = ?", budget.ID).Delete(&model.BudgetAmountVersion{}).Error; err != nil {
+			return err
+		}
+		if err := deleteBudgetScope(tx, budget.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&budget).Error
+	})
+	if err != nil {
 		global.Logger.Error("Failed to delete budget: " + err.Error())
 		return errors.New("删除预算失败：数据库错误")
 	}
@@ -291,6 +488,12 @@ func (s *BookkeepingBudgetService) ListBudgets(userID uint, query dto.BudgetQuer
 	var budgets []model.Budget
 	var response dto.BudgetListResponse
 
+	// 停用已过结束日期的预算
+	if err := deactivateExpiredBudgets(global.DB, userID); err != nil {
+		global.Logger.Error("Failed to deactivate expired budgets: " + err.Error())
+		return response, errors.New("获取预算列表失败：数据库错误")
+	}
+
 	// 构建查询条件
 	db := global.DB.Model(&model.Budget{}).Where("user_id = ?", userID)
 
@@ -299,18 +502,28 @@ func (s *BookkeepingBudgetService) ListBudgets(userID uint, query dto.BudgetQuer
 		db = db.Where("type = ?", query.Type)
 	}
 
+	if query.Kind != "" {
+		db = db.Where("kind = ?", query.Kind)
+	}
+
 	if query.Period != "" {
 		db = db.Where("period = ?", query.Period)
 	}
 
 	if query.CategoryID > 0 {
-		db = db.Where("category_id = ?", query.CategoryID)
+		// 包含额外计入该分类的预算
+		db = db.Where("(category_id = ? OR id IN (?))", query.CategoryID,
+			global.DB.Model(&model.BudgetCategory{}).Select("budget_id").Where("category_id = ? AND exclude = ?", query.CategoryID, false))
 	}
 
 	if query.IsActive != nil {
 		db = db.Where("is_active = ?", *query.IsActive)
 	}
 
+	if query.IsEnvelope != nil {
+		db = db.Where("is_envelope = ?", *query.IsEnvelope)
+	}
+
 	// 计算总数
 	var total int64
 	if err := db.Count(&total).Error; err != nil {
@@ -320,7 +533,7 @@ func (s *BookkeepingBudgetService) ListBudgets(userID uint, query dto.BudgetQuer
 
 	// 分页查询
 	offset := (query.Page - 1) * query.PageSize
-	if err := db.Preload("Category").Order("id DESC").Offset(offset).Limit(query.PageSize).Find(&budgets).Error; err != nil {
+	if err := db.Preload("Category").Preload("Categories").Preload("Accounts").Order("id DESC").Offset(offset).Limit(query.PageSize).Find(&budgets).Error; err != nil {
 		global.Logger.Error("Failed to list budgets: " + err.Error())
 		return response, errors.New("获取预算列表失败：数据库错误")
 	}
@@ -344,9 +557,15 @@ func (s *BookkeepingBudgetService) ListBudgets(userID uint, query dto.BudgetQuer
 
 // ListActiveBudgetProgress 获取激活的预算进度列表
 func (s *BookkeepingBudgetService) ListActiveBudgetProgress(userID uint) ([]dto.BudgetProgressResponse, error) {
+	// 停用已过结束日期的预算
+	if err := deactivateExpiredBudgets(global.DB, userID); err != nil {
+		global.Logger.Error("Failed to deactivate expired budgets: " + err.Error())
+		return nil, errors.New("获取预算进度列表失败：数据库错误")
+	}
+
 	// 查询所有激活的预算
 	var budgets []model.Budget
-	if err := global.DB.Preload("Category").Where("user_id = ? AND is_active = ?", userID, true).Find(&budgets).Error; err != nil {
+	if err := global.DB.Preload("Category").Preload("Categories").Preload("Accounts").Where("user_id = ? AND is_active = ?", userID, true).Find(&budgets).Error; err != nil {
 		global.Logger.Error("Failed to list active budgets: " + err.Error())
 		return nil, errors.New("获取预算进度列表失败：数据库错误")
 	}
@@ -356,75 +575,49 @@ func (s *BookkeepingBudgetService) ListActiveBudgetProgress(userID uint) ([]dto.
 
 	for _, budget := range budgets {
 		// 计算当前预算周期
-		currentPeriodStart, currentPeriodEnd, err := s.calculateCurrentPeriod(budget.StartDate, budget.Period)
+		currentPeriodStart, currentPeriodEnd, err := s.calculateCurrentPeriod(&budget)
 		if err != nil {
 			global.Logger.Error(fmt.Sprintf("Failed to calculate current period for budget %d: %s", budget.ID, err.Error()))
 			continue
 		}
 
-		// 获取当前周期内的支出
-		var spentAmount float64
-		query := global.DB.Model(&model.Transaction{}).
-			Where("user_id = ? AND type = ? AND transaction_date BETWEEN ? AND ?", 
-				userID, model.TransactionTypeExpense, currentPeriodStart, currentPeriodEnd)
+		// 获取当前周期内的支出，分类预算包含所有子分类
+		spentAmount, err := s.budgetSpentAmount(userID, &budget, currentPeriodStart, currentPeriodEnd)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to calculate spent amount for budget %d: %s", budget.ID, err.Error()))
+			continue
+		}
 
-		// 如果是分类预算，则只统计该分类的支出
-		if budget.Type == model.BudgetTypeCategory && budget.CategoryID != nil {
-			query = query.Where("category_id = ?", *budget.CategoryID)
+		// 计算从历史周期结转到当前周期的金额
+		rollover, err := s.budgetRollover(userID, &budget, currentPeriodStart)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to calculate rollover for budget %d: %s", budget.ID, err.Error()))
+			continue
 		}
 
-		if err := query.Select("COALESCE(SUM(amount), 0)").Scan(&spentAmount).Error; err != nil {
-			global.Logger.Error(fmt.Sprintf("Failed to calculate spent amount for budget %d: %s", budget.ID, err.Error()))
+		// 当前周期的预算金额，信封为本月分配的金额
+		amount, err := s.budgetCurrentAmount(&budget, currentPeriodStart)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to get amount for budget %d: %s", budget.ID, err.Error()))
 			continue
 		}
 
-		// 计算进度
-		remainingAmount := budget.Amount - spentAmount
-		if remainingAmount < 0 {
-			remainingAmount = 0
-		}
-
-		usageRate := spentAmount / budget.Amount
-		isOverBudget := usageRate > 1.0
-
-		// 计算剩余天数
-		daysRemaining := int(math.Ceil(currentPeriodEnd.Sub(time.Now()).Hours() / 24))
-		if daysRemaining < 0 {
-			daysRemaining = 0
-		}
-
-		// 构建进度项
-		var progressItem dto.BudgetProgressResponse
-		progressItem.ID = budget.ID
-		progressItem.UserID = budget.UserID
-		progressItem.Name = budget.Name
-		progressItem.Type = string(budget.Type)
-		progressItem.Period = string(budget.Period)
-		progressItem.Amount = budget.Amount
-		progressItem.StartDate = budget.StartDate
-		progressItem.CategoryID = budget.CategoryID
-		progressItem.NotifyRate = budget.NotifyRate
-		progressItem.Description = budget.Description
-		progressItem.IsActive = budget.IsActive
-		progressItem.CreatedAt = budget.CreatedAt
-		progressItem.UpdatedAt = budget.UpdatedAt
-		progressItem.Category = s.categoryToDTO(budget.Category)
-		
-		progressItem.SpentAmount = spentAmount
-		progressItem.RemainingAmount = remainingAmount
-		progressItem.UsageRate = usageRate
-		progressItem.IsOverBudget = isOverBudget
-		progressItem.DaysRemaining = daysRemaining
-		progressItem.CurrentPeriod.StartDate = currentPeriodStart
-		progressItem.CurrentPeriod.EndDate = currentPeriodEnd
+		progressItem := s.buildBudgetProgress(&budget, currentPeriodStart, currentPeriodEnd, amount, spentAmount, rollover)
 
+		// 预测周期结束时的支出
+		if progressItem.Forecast, err = s.budgetForecast(userID, &budget, &progressItem); err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to forecast spending for budget %d: %s", budget.ID, err.Error()))
+			continue
+		}
 		progressItems = append(progressItems, progressItem)
 	}
 
 	return progressItems, nil
 }
 
-// CheckBudgetAlerts 检查预算提醒（达到或超过预算提醒阈值的预算）
+// CheckBudgetAlerts 检查预算提醒（达到或超过预算提醒阈值，或预计周期结束前会超支的预算）
+// 达到阈值的预算同时产生通知，后台扫描也会产生同样的通知，不需要客户端轮询
+// 收入目标没有超支的概念，不参与提醒
 func (s *BookkeepingBudgetService) CheckBudgetAlerts(userID uint) ([]dto.BudgetProgressResponse, error) {
 	// 获取所有激活的预算进度
 	progressItems, err := s.ListActiveBudgetProgress(userID)
@@ -435,9 +628,17 @@ func (s *BookkeepingBudgetService) CheckBudgetAlerts(userID uint) ([]dto.BudgetP
 	// 筛选出达到或超过阈值的预算
 	var alerts []dto.BudgetProgressResponse
 	for _, item := range progressItems {
-		if item.UsageRate >= item.NotifyRate {
+		if item.Kind == string(model.BudgetKindIncome) {
+			continue
+		}
+		if item.UsageRate >= item.NotifyRate || (item.Forecast != nil && item.Forecast.WillOverspend) {
 			alerts = append(alerts, item)
 		}
+
+		// 达到阈值时记录阈值事件并产生通知，同一周期同一级别只记录一次，重复查询不会重复通知
+		if _, _, err := recordBudgetThreshold(&item, nil); err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to record threshold event for budget %d: %s", item.ID, err.Error()))
+		}
 	}
 
 	return alerts, nil
@@ -453,20 +654,240 @@ func (s *BookkeepingBudgetService) budgetToResponse(budget *model.Budget, respon
 	response.UserID = budget.UserID
 	response.Name = budget.Name
 	response.Type = string(budget.Type)
+	response.Kind = string(budget.Kind)
 	response.Period = string(budget.Period)
+	response.PeriodDays = budget.PeriodDays
 	response.Amount = budget.Amount
 	response.StartDate = budget.StartDate
+	response.EndDate = budget.EndDate
 	response.CategoryID = budget.CategoryID
 	response.NotifyRate = budget.NotifyRate
 	response.Description = budget.Description
 	response.IsActive = budget.IsActive
+	response.RolloverMode = string(budget.RolloverMode)
+	response.RolloverCap = budget.RolloverCap
+	response.IsEnvelope = budget.IsEnvelope
 	response.CreatedAt = budget.CreatedAt
 	response.UpdatedAt = budget.UpdatedAt
 	response.Category = s.categoryToDTO(budget.Category)
+	fillBudgetScopeResponse(budget, response)
 
 	return nil
 }
 
+// budgetTransactionType 预算统计的交易类型，收入目标统计收入，支出预算统计支出
+func budgetTransactionType(budget *model.Budget) model.TransactionType {
+	if budget.Kind == model.BudgetKindIncome {
+		return model.TransactionTypeIncome
+	}
+	return model.TransactionTypeExpense
+}
+
+// budgetOverspent 周期内的支出是否超过预算金额，收入目标超过目标金额不算超支
+func budgetOverspent(budget *model.Budget, spent, amount float64) bool {
+	return budget.Kind != model.BudgetKindIncome && spent > amount
+}
+
+// budgetExpenseQuery 构建预算在指定时间范围内的支出 (收入目标为收入) 查询，按预算的统计范围 (分类及其子分类、排除的分类、账户、标签、收款方) 筛选
+func (s *BookkeepingBudgetService) budgetExpenseQuery(userID uint, budget *model.Budget, start, end time.Time) (*gorm.DB, error) {
+	query := global.DB.Model(&model.Transaction{}).
+		Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND transaction_date BETWEEN ? AND ?",
+			userID, budgetTransactionType(budget), false, start, end)
+
+	scope, err := resolveBudgetScope(global.DB, userID, budget)
+	if err != nil {
+		return nil, err
+	}
+	return scope.apply(query), nil
+}
+
+// budgetSpentAmount 计算预算在指定周期内的支出
+func (s *BookkeepingBudgetService) budgetSpentAmount(userID uint, budget *model.Budget, start, end time.Time) (float64, error) {
+	query, err := s.budgetExpenseQuery(userID, budget, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	var spentAmount float64
+	if err := query.Select("COALESCE(SUM(amount), 0)").Scan(&spentAmount).Error; err != nil {
+		return 0, err
+	}
+	return spentAmount, nil
+}
+
+// budgetSpentByPeriod 一次查询计算预算在多个连续周期内各自的支出，periods 需按时间升序排列
+func (s *BookkeepingBudgetService) budgetSpentByPeriod(userID uint, budget *model.Budget, periods []budgetPeriodRange) ([]float64, error) {
+	spent := make([]float64, len(periods))
+	if len(periods) == 0 {
+		return spent, nil
+	}
+
+	query, err := s.budgetExpenseQuery(userID, budget, periods[0].Start, periods[len(periods)-1].End)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		TransactionDate time.Time
+		Amount          float64
+	}
+	if err := query.Select("transaction_date, amount").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		// 找到第一个结束时间不早于交易时间的周期
+		i := sort.Search(len(periods), func(i int) bool {
+			return !periods[i].End.Before(row.TransactionDate)
+		})
+		if i < len(periods) && !row.TransactionDate.Before(periods[i].Start) {
+			spent[i] += row.Amount
+		}
+	}
+	for i := range spent {
+		spent[i] = roundCent(spent[i])
+	}
+	return spent, nil
+}
+
+// budgetRollover 按结转方式逐个周期推算结转到当前周期的金额，不结转的预算返回 nil
+// 每个周期的结余 = 预算金额 + 上期结转 - 支出，按结转方式取结余或超支部分，再按上限截断后结转到下一周期
+func (s *BookkeepingBudgetService) budgetRollover(userID uint, budget *model.Budget, currentPeriodStart time.Time) (*dto.BudgetRollover, error) {
+	if budget.RolloverMode == "" || budget.RolloverMode == model.BudgetRolloverNone {
+		return nil, nil
+	}
+
+	rollover := &dto.BudgetRollover{
+		Mode: string(budget.RolloverMode),
+		Cap:  budget.RolloverCap,
+	}
+
+	periods := budgetPeriodsBefore(budget, currentPeriodStart)
+	spent, err := s.budgetSpentByPeriod(userID, budget, periods)
+	if err != nil {
+		return nil, err
+	}
+	amountAt, err := budgetAmountFunc(global.DB, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	var carried float64
+	for i, period := range periods {
+		amount := amountAt(period.Start)
+		balance := roundCent(amount + carried - spent[i])
+		carriedOut, capped := rolloverAmount(balance, budget.RolloverMode, budget.RolloverCap)
+		rollover.Periods = append(rollover.Periods, dto.BudgetRolloverPeriod{
+			StartDate:  period.Start,
+			EndDate:    period.End,
+			Amount:     amount,
+			CarriedIn:  carried,
+			Spent:      spent[i],
+			Balance:    balance,
+			CarriedOut: carriedOut,
+			Capped:     capped,
+		})
+		carried = carriedOut
+	}
+	rollover.CarriedAmount = carried
+
+	return rollover, nil
+}
+
+// rolloverAmount 根据结转方式和上限计算一个周期结束时结转到下一周期的金额
+func rolloverAmount(balance float64, mode model.BudgetRolloverMode, cap *float64) (float64, bool) {
+	var carried float64
+	switch mode {
+	case model.BudgetRolloverSurplus:
+		carried = math.Max(balance, 0)
+	case model.BudgetRolloverDeficit:
+		carried = math.Min(balance, 0)
+	case model.BudgetRolloverBoth:
+		carried = balance
+	}
+
+	if cap != nil && math.Abs(carried) > *cap {
+		return math.Copysign(*cap, carried), true
+	}
+	return carried, false
+}
+
+// budgetCurrentAmount 从 periodStart 开始的当前周期的预算金额，信封为该月分配的金额
+func (s *BookkeepingBudgetService) budgetCurrentAmount(budget *model.Budget, periodStart time.Time) (float64, error) {
+	if !budget.IsEnvelope {
+		return budget.Amount, nil
+	}
+	assigned, err := envelopeAssignedByMonth(global.DB, budget.ID)
+	if err != nil {
+		return 0, err
+	}
+	return assigned[envelopeMonthKey(periodStart)], nil
+}
+
+// buildBudgetProgress 根据周期内的支出构建预算进度，amount 为当前周期的预算金额
+func (s *BookkeepingBudgetService) buildBudgetProgress(budget *model.Budget, periodStart, periodEnd time.Time, amount, spentAmount float64, rollover *dto.BudgetRollover) dto.BudgetProgressResponse {
+	// 实际可用金额包含结转金额
+	effectiveAmount := amount
+	if rollover != nil {
+		effectiveAmount = roundCent(effectiveAmount + rollover.CarriedAmount)
+	}
+
+	// 计算进度
+	remainingAmount := effectiveAmount - spentAmount
+	if remainingAmount < 0 {
+		remainingAmount = 0
+	}
+
+	// 结转的超支可能使可用金额小于等于0，此时视为已用完
+	usageRate := 1.0
+	if effectiveAmount > 0 {
+		usageRate = spentAmount / effectiveAmount
+	}
+	isOverBudget := budgetOverspent(budget, spentAmount, effectiveAmount)
+	isTargetReached := budget.Kind == model.BudgetKindIncome && spentAmount >= effectiveAmount
+
+	// 计算剩余天数
+	daysRemaining := int(math.Ceil(periodEnd.Sub(time.Now()).Hours() / 24))
+	if daysRemaining < 0 {
+		daysRemaining = 0
+	}
+
+	var progress dto.BudgetProgressResponse
+	progress.ID = budget.ID
+	progress.UserID = budget.UserID
+	progress.Name = budget.Name
+	progress.Type = string(budget.Type)
+	progress.Kind = string(budget.Kind)
+	progress.Period = string(budget.Period)
+	progress.PeriodDays = budget.PeriodDays
+	progress.Amount = amount
+	progress.StartDate = budget.StartDate
+	progress.EndDate = budget.EndDate
+	progress.CategoryID = budget.CategoryID
+	progress.NotifyRate = budget.NotifyRate
+	progress.Description = budget.Description
+	progress.IsActive = budget.IsActive
+	progress.RolloverMode = string(budget.RolloverMode)
+	progress.RolloverCap = budget.RolloverCap
+	progress.IsEnvelope = budget.IsEnvelope
+	progress.CreatedAt = budget.CreatedAt
+	progress.UpdatedAt = budget.UpdatedAt
+	progress.Category = s.categoryToDTO(budget.Category)
+	fillBudgetScopeResponse(budget, &progress.BudgetResponse)
+
+	progress.EffectiveAmount = effectiveAmount
+	progress.Rollover = rollover
+	progress.SpentAmount = spentAmount
+	progress.RemainingAmount = remainingAmount
+	progress.UsageRate = usageRate
+	progress.IsOverBudget = isOverBudget
+	progress.IsTargetReached = isTargetReached
+	progress.DaysRemaining = daysRemaining
+	progress.CurrentPeriod.StartDate = periodStart
+	progress.CurrentPeriod.EndDate = periodEnd
+
+	return progress
+}
+
 // 辅助方法：Category 模型转 DTO
 func (s *BookkeepingBudgetService) categoryToDTO(category *model.Category) *dto.Category {
 	if category == nil {
@@ -481,84 +902,3 @@ func (s *BookkeepingBudgetService) categoryToDTO(category *model.Category) *dto.
 		Icon:     category.Icon,
 	}
 }
-
-// 计算当前预算周期的开始和结束日期
-func (s *BookkeepingBudgetService) calculateCurrentPeriod(startDate time.Time, period model.BudgetPeriod) (time.Time, time.Time, error) {
-	now := time.Now()
-	var currentPeriodStart, currentPeriodEnd time.Time
-
-	switch period {
-	case model.BudgetPeriodWeekly:
-		// 找到当前周期的开始日期
-		// 计算自 startDate 以来过了多少个完整的7天周期
-		daysSinceStart := int(now.Sub(startDate).Hours() / 24)
-		completedWeeks := daysSinceStart / 7
-		
-		// 当前周期的开始日期 = 初始日期 + 完整周数 * 7天
-		currentPeriodStart = startDate.AddDate(0, 0, completedWeeks*7)
-		
-		// 如果当前周期已经过完，则加一个周期
-		if now.After(currentPeriodStart.AddDate(0, 0, 7)) {
-			currentPeriodStart = currentPeriodStart.AddDate(0, 0, 7)
-		}
-		
-		// 结束日期就是开始日期 + 7天 - 1秒
-		currentPeriodEnd = currentPeriodStart.AddDate(0, 0, 7).Add(-time.Second)
-
-	case model.BudgetPeriodMonthly:
-		// 找到当前周期的月份
-		// 计算自 startDate 以来过了多少个完整月
-		yearDiff := now.Year() - startDate.Year()
-		monthDiff := int(now.Month()) - int(startDate.Month())
-		totalMonths := yearDiff*12 + monthDiff
-		
-		// 当前周期的开始年月
-		startYear := startDate.Year() + totalMonths/12
-		startMonth := time.Month((int(startDate.Month()) + totalMonths%12) % 12)
-		if startMonth == 0 {
-			startMonth = 12
-			startYear--
-		}
-		
-		currentPeriodStart = time.Date(startYear, startMonth, startDate.Day(), 0, 0, 0, 0, startDate.Location())
-		
-		// 如果当前时间已经过了这个月的周期，则加一个月
-		if now.After(currentPeriodStart.AddDate(0, 1, 0)) {
-			if startMonth == 12 {
-				startYear++
-				startMonth = 1
-			} else {
-				startMonth++
-			}
-			currentPeriodStart = time.Date(startYear, startMonth, startDate.Day(), 0, 0, 0, 0, startDate.Location())
-		}
-		
-		// 处理月底的情况，确保月份日期合法
-		nextMonth := currentPeriodStart.Month() + 1
-		nextYear := currentPeriodStart.Year()
-		if nextMonth > 12 {
-			nextMonth = 1
-			nextYear++
-		}
-		
-		// 结束日期是下个月同一天减1秒
-		currentPeriodEnd = time.Date(nextYear, nextMonth, 1, 0, 0, 0, 0, startDate.Location()).AddDate(0, 0, -1).Add(24*time.Hour - time.Second)
-
-	case model.BudgetPeriodYearly:
-		// 找到当前周期的年份
-		yearsSinceStart := now.Year() - startDate.Year()
-		if now.Month() < startDate.Month() || (now.Month() == startDate.Month() && now.Day() < startDate.Day()) {
-			yearsSinceStart--
-		}
-		
-		currentPeriodStart = time.Date(startDate.Year()+yearsSinceStart, startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
-		
-		// 结束日期是开始日期加一年减1秒
-		currentPeriodEnd = currentPeriodStart.AddDate(1, 0, 0).Add(-time.Second)
-
-	default:
-		return time.Time{}, time.Time{}, errors.New("不支持的预算周期类型")
-	}
-
-	return currentPeriodStart, currentPeriodEnd, nil
-} 
\ No newline at end of file
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// categoryAmount 按分类汇总的金额和交易笔数 (只包含直接记在该分类下的交易)
+type categoryAmount struct {
+	CategoryID       uint
+	TotalAmount      float64
+	TransactionCount int
+}
+
+// categorySubtreeIDs 获取分类自身及其所有后代分类的ID，用于按分类树汇总金额
+func categorySubtreeIDs(db *gorm.DB, userID uint, categoryID uint) ([]uint, error) {
+	descendants, err := categoryDescendantIDs(db, userID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]uint{categoryID}, descendants...), nil
+}
+
+// buildCategorySummaryTree 将各分类的直接金额汇总为分类树：
+// 每个节点的总金额包含所有后代分类，没有交易的分类不出现在结果中，同级按总金额降序
+// 找不到对应分类的金额 (分类已被物理删除) 不参与汇总
+func buildCategorySummaryTree(categories []model.Category, amounts []categoryAmount) []*dto.CategorySummaryItem {
+	categoryMap := make(map[uint]model.Category, len(categories))
+	for _, category := range categories {
+		categoryMap[category.ID] = category
+	}
+
+	nodes := make(map[uint]*dto.CategorySummaryItem)
+	// node 获取分类对应的汇总节点，不存在时创建并挂到上级分类节点下
+	var node func(categoryID uint, visited map[uint]bool) *dto.CategorySummaryItem
+	node = func(categoryID uint, visited map[uint]bool) *dto.CategorySummaryItem {
+		if item, ok := nodes[categoryID]; ok {
+			return item
+		}
+		category := categoryMap[categoryID]
+		item := &dto.CategorySummaryItem{
+			CategoryID:   category.ID,
+			CategoryName: category.Name,
+			CategoryIcon: category.Icon,
+		}
+		nodes[categoryID] = item
+		visited[categoryID] = true
+
+		if category.ParentID != nil {
+			if _, ok := categoryMap[*category.ParentID]; ok && !visited[*category.ParentID] {
+				parentID := *category.ParentID
+				item.ParentID = &parentID
+				parent := node(parentID, visited)
+				parent.Children = append(parent.Children, item)
+			}
+		}
+		return item
+	}
+
+	var grandTotal float64
+	for _, amount := range amounts {
+		if _, ok := categoryMap[amount.CategoryID]; !ok {
+			continue
+		}
+		item := node(amount.CategoryID, map[uint]bool{})
+		item.OwnAmount += amount.TotalAmount
+		item.OwnTransactionCount += amount.TransactionCount
+		grandTotal += amount.TotalAmount
+	}
+
+	var roots []*dto.CategorySummaryItem
+	for _, item := range nodes {
+		if item.ParentID == nil {
+			roots = append(roots, item)
+		}
+	}
+
+	// 自底向上累加子树金额，计算占比并排序
+	var rollUp func(items []*dto.CategorySummaryItem)
+	rollUp = func(items []*dto.CategorySummaryItem) {
+		for _, item := range items {
+			rollUp(item.Children)
+			item.TotalAmount = item.OwnAmount
+			item.TransactionCount = item.OwnTransactionCount
+			for _, child := range item.Children {
+				item.TotalAmount += child.TotalAmount
+				item.TransactionCount += child.TransactionCount
+			}
+			item.TotalAmount = roundCent(item.TotalAmount)
+			if grandTotal != 0 {
+				item.Share = item.TotalAmount / grandTotal
+			}
+		}
+		sortCategorySummary(items)
+	}
+	rollUp(roots)
+
+	if roots == nil {
+		return []*dto.CategorySummaryItem{}
+	}
+	return roots
+}
+
+// sortCategorySummary 按总金额降序排列，金额相同时按分类ID排列保证结果稳定
+func sortCategorySummary(items []*dto.CategorySummaryItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].TotalAmount != items[j].TotalAmount {
+			return items[i].TotalAmount > items[j].TotalAmount
+		}
+		return items[i].CategoryID < items[j].CategoryID
+	})
+}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/dotdancer/gogofly/global"
 	"github.com/dotdancer/gogofly/model"
 	"github.com/dotdancer/gogofly/service/dto"
 	"github.com/jinzhu/copier"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BookkeepingCategoryService 结构体定义了分类管理的服务层
@@ -40,20 +43,13 @@ func (s *BookkeepingCategoryService) CreateCategory(userID uint, req dto.CreateC
 
 	category.UserID = userID
 
-	// 如果有父分类ID，校验父分类是否存在且属于当前用户
+	// 如果有父分类ID，校验父分类是否存在且属于当前用户，类型一致且不超过最大层级
 	if category.ParentID != nil && *category.ParentID != 0 {
-		var parentCategory model.Category
-		if err := global.DB.First(&parentCategory, "id = ? AND user_id = ?", *category.ParentID, userID).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return response, errors.New("指定的父分类不存在或不属于您")
-			}
-			global.Logger.Error("Failed to find parent category: " + err.Error())
-			return response, errors.New("创建分类失败：查询父分类错误")
-		}
-		// 确保父分类和子分类的类型一致
-		if parentCategory.Type != category.Type {
-			return response, errors.New("子分类类型必须与父分类类型一致")
+		if err := validateCategoryParent(global.DB, userID, &category, category.Type, *category.ParentID); err != nil {
+			return response, userFacingError(err, "创建分类失败：查询父分类错误")
 		}
+	} else {
+		category.ParentID = nil
 	}
 
 	if err := global.DB.Create(&category).Error; err != nil {
@@ -141,37 +137,38 @@ func (s *BookkeepingCategoryService) UpdateCategory(userID uint, categoryID uint
 		}
 	}
 
-	// 处理父分类ID的更新
+	newType := category.Type
+	if req.Type != "" { // 如果请求中也更新了类型，以请求中的为准
+		newType = req.Type
+	}
+
+	// 子分类必须与父分类类型一致，存在子分类时不能修改类型
+	if newType != category.Type {
+		var subCategoryCount int64
+		if err := global.DB.Model(&model.Category{}).Where("parent_id = ? AND user_id = ?", categoryID, userID).Count(&subCategoryCount).Error; err != nil {
+			global.Logger.Error("Failed to count sub categories: " + err.Error())
+			return response, errors.New("更新分类失败")
+		}
+		if subCategoryCount > 0 {
+			return response, errors.New("该分类下存在子分类，不能修改类型")
+		}
+	}
+
+	// 处理父分类ID的更新，客户端传递0表示移除父分类；未传递时保持原父分类
+	parentID := category.ParentID
 	if req.ParentID != nil {
-		if *req.ParentID == 0 { // 客户端可能传递0表示移除父分类
-			category.ParentID = nil
-		} else {
-			// 校验新的父分类是否存在且属于当前用户
-			var parentCategory model.Category
-			if err := global.DB.First(&parentCategory, "id = ? AND user_id = ?", *req.ParentID, userID).Error; err != nil {
-				return response, errors.New("指定的父分类不存在或不属于您")
-			}
-			// 确保父分类和子分类的类型一致
-			newType := category.Type
-			if req.Type != "" { // 如果请求中也更新了类型，以请求中的为准
-				newType = req.Type
-			}
-			if parentCategory.Type != newType {
-				return response, errors.New("子分类类型必须与父分类类型一致")
-			}
-			// 防止将分类设置为自身的子分类或其子分类的子分类 (循环依赖)
-			if *req.ParentID == category.ID {
-				return response, errors.New("不能将分类设置为自身的父分类")
-			}
-			// 更复杂的循环依赖检查可以遍历父级链条，此处简化
-			category.ParentID = req.ParentID
+		parentID = req.ParentID
+		if *req.ParentID == 0 {
+			parentID = nil
+		}
+	}
+	if parentID != nil && (req.ParentID != nil || newType != category.Type) {
+		// 校验父分类存在、类型一致、不会形成循环且不超过最大层级
+		if err := validateCategoryParent(global.DB, userID, &category, newType, *parentID); err != nil {
+			return response, userFacingError(err, "更新分类失败：查询父分类错误")
 		}
-	} else if req.ParentID == nil && category.ParentID != nil {
-		// 如果请求中没有ParentID字段，但数据库中存在，则不改变它
-		// 如果想显式移除父分类，客户端应传递 ParentID: null (或特定值如0，由DTO和service约定)
-		// 这里的逻辑是：如果DTO中ParentID字段未提供，则不更新模型的ParentID
-		// 如果DTO中ParentID字段提供了，即使是nil，也会尝试更新（上面已处理*req.ParentID == 0的情况）
 	}
+	category.ParentID = parentID
 
 	// 使用copier选择性更新字段，仅更新DTO中非nil的字段
 	// copier默认会覆盖，需要注意指针类型的处理
@@ -198,9 +195,16 @@ func (s *BookkeepingCategoryService) UpdateCategory(userID uint, categoryID uint
 }
 
 // DeleteCategory 删除分类
+// reassignTo 不为0时，先将该分类的交易、预算和子分类合并到指定分类再删除；为0时分类仍被使用则拒绝删除
 // userID: 当前操作的用户ID
 // categoryID: 要删除的分类ID
-func (s *BookkeepingCategoryService) DeleteCategory(userID uint, categoryID uint) error {
+// reassignTo: 接收交易、预算和子分类的分类ID
+func (s *BookkeepingCategoryService) DeleteCategory(userID uint, categoryID uint, reassignTo uint) error {
+	if reassignTo != 0 {
+		_, err := s.MergeCategory(userID, categoryID, dto.MergeCategoryRequest{TargetCategoryID: reassignTo})
+		return err
+	}
+
 	var category model.Category
 	if err := global.DB.First(&category, "id = ? AND user_id = ?", categoryID, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -214,14 +218,14 @@ func (s *BookkeepingCategoryService) DeleteCategory(userID uint, categoryID uint
 	var subCategoryCount int64
 	global.DB.Model(&model.Category{}).Where("parent_id = ? AND user_id = ?", categoryID, userID).Count(&subCategoryCount)
 	if subCategoryCount > 0 {
-		return errors.New("无法删除：该分类下存在子分类，请先删除或移动子分类")
+		return errors.New("无法删除：该分类下存在子分类，请先删除或移动子分类，或指定 reassign_to 合并到其他分类")
 	}
 
 	// 检查该分类是否被交易流水使用
 	var transactionCount int64
 	global.DB.Model(&model.Transaction{}).Where("category_id = ? AND user_id = ?", categoryID, userID).Count(&transactionCount)
 	if transactionCount > 0 {
-		return errors.New("无法删除：该分类已被交易流水使用")
+		return errors.New("无法删除：该分类已被交易流水使用，可以将其归档，或指定 reassign_to 合并到其他分类")
 	}
 
 	if err := global.DB.Delete(&category).Error; err != nil {
@@ -232,16 +236,525 @@ func (s *BookkeepingCategoryService) DeleteCategory(userID uint, categoryID uint
 	return nil
 }
 
+// categoryReferences 引用分类的记录，合并分类时统一迁移到目标分类
+// 新增引用分类的模型时需要在此登记
+var categoryReferences = []struct {
+	name   string
+	model  interface{}
+	column string
+}{
+	{"transactions", &model.Transaction{}, "category_id"},
+	{"budgets", &model.Budget{}, "category_id"},
+	{"budget_categories", &model.BudgetCategory{}, "category_id"},
+	{"loans", &model.Loan{}, "interest_category_id"},
+	{"split_expenses", &model.SplitExpense{}, "category_id"},
+	{"bills", &model.Bill{}, "category_id"},
+	{"subscriptions", &model.Subscription{}, "category_id"},
+}
+
+// MergeCategory 将源分类合并到同类型的目标分类
+// 源分类的交易、预算等关联记录迁移到目标分类，直接子分类移到目标分类下，随后删除源分类；
+// 目标分类下已有同名子分类时，源分类的子分类递归合并到该同名子分类；
+// 全部操作在一个数据库事务中完成。DryRun时只返回合并预览
+// userID: 当前操作的用户ID
+// sourceID: 被合并的源分类ID
+// req: 合并请求数据
+func (s *BookkeepingCategoryService) MergeCategory(userID uint, sourceID uint, req dto.MergeCategoryRequest) (dto.MergeCategoryResponse, error) {
+	response := dto.MergeCategoryResponse{
+		DryRun:              req.DryRun,
+		MovedRecords:        make(map[string]int64, len(categoryReferences)+1),
+		MergedSubCategories: []string{},
+	}
+	if sourceID == req.TargetCategoryID {
+		return response, errors.New("源分类和目标分类不能相同")
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var source, target model.Category
+		if err := tx.First(&source, "id = ? AND user_id = ?", sourceID, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("分类不存在")
+			}
+			return err
+		}
+		if err := tx.First(&target, "id = ? AND user_id = ?", req.TargetCategoryID, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("目标分类不存在")
+			}
+			return err
+		}
+		if source.Type != target.Type {
+			return newBizError("只能合并到相同类型的分类")
+		}
+		if target.IsArchived {
+			return newBizError("目标分类已归档，不能合并到已归档的分类")
+		}
+
+		// 子分类会移到目标分类下，目标分类不能是源分类的后代，否则会形成循环
+		descendants, err := categoryDescendantIDs(tx, userID, source.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range descendants {
+			if id == target.ID {
+				return newBizError("不能合并到自己的子分类")
+			}
+		}
+		// 子分类移到目标分类下后不能超过最大层级
+		targetDepth, err := categoryDepth(tx, userID, target.ID)
+		if err != nil {
+			return err
+		}
+		sourceHeight, err := categorySubtreeHeight(tx, userID, source.ID)
+		if err != nil {
+			return err
+		}
+		if targetDepth+sourceHeight-1 > maxCategoryDepth() {
+			return newBizError(fmt.Sprintf("合并后分类层级将超过%d层", maxCategoryDepth()))
+		}
+
+		response.SourceCategoryID = source.ID
+		response.SourceCategoryName = source.Name
+		response.TargetCategoryID = target.ID
+		response.TargetCategoryName = target.Name
+
+		response.MovedRecords["sub_categories"] = 0
+		return mergeCategoryInto(tx, userID, &source, &target, "", &response, req.DryRun)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to merge category: " + err.Error())
+		return response, userFacingError(err, "合并分类失败：数据库错误")
+	}
+
+	return response, nil
+}
+
+// mergeCategoryInto 将 source 的关联记录迁移到 target，子分类移到 target 下后删除 source
+// target 下已有同名子分类时递归合并到该子分类，避免同一父分类下出现同名分类；dryRun 时只统计不修改
+// path 为 source 相对于最初合并的源分类的路径，用于返回被合并的子分类
+func mergeCategoryInto(tx *gorm.DB, userID uint, source, target *model.Category, path string, response *dto.MergeCategoryResponse, dryRun bool) error {
+	// 源分类的信封会迁移到目标分类，目标分类也有信封时同一分类会出现两个信封，支出被重复扣减
+	var envelopes int64
+	if err := tx.Model(&model.Budget{}).Where("user_id = ? AND is_envelope = ? AND category_id IN ?", userID, true, []uint{source.ID, target.ID}).
+		Distinct("category_id").Count(&envelopes).Error; err != nil {
+		return err
+	}
+	if envelopes > 1 {
+		return newBizError(fmt.Sprintf("分类「%s」和「%s」都有信封，请先删除其中一个信封 (剩余资金会退回待分配) 后再合并", source.Name, target.Name))
+	}
+
+	for _, ref := range categoryReferences {
+		var count int64
+		if err := tx.Model(ref.model).Where(ref.column+" = ?", source.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		response.MovedRecords[ref.name] += count
+		// 只修改分类ID，不影响账户余额，跳过交易钩子
+		if !dryRun && count > 0 {
+			if err := tx.Model(ref.model).Where(ref.column+" = ?", source.ID).UpdateColumn(ref.column, target.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	var children, targetChildren []model.Category
+	if err := tx.Where("parent_id = ? AND user_id = ?", source.ID, userID).Order("sort_order ASC, id ASC").Find(&children).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("parent_id = ? AND user_id = ?", target.ID, userID).Find(&targetChildren).Error; err != nil {
+		return err
+	}
+	sameName := make(map[string]*model.Category, len(targetChildren))
+	for i := range targetChildren {
+		sameName[targetChildren[i].Name] = &targetChildren[i]
+	}
+
+	for i := range children {
+		child := &children[i]
+		childPath := child.Name
+		if path != "" {
+			childPath = path + "/" + child.Name
+		}
+		if existing, ok := sameName[child.Name]; ok {
+			if existing.IsArchived {
+				return newBizError(fmt.Sprintf("目标分类下的同名子分类「%s」已归档，请先恢复或重命名", child.Name))
+			}
+			response.MergedSubCategories = append(response.MergedSubCategories, childPath)
+			if err := mergeCategoryInto(tx, userID, child, existing, childPath, response, dryRun); err != nil {
+				return err
+			}
+			continue
+		}
+		response.MovedRecords["sub_categories"]++
+		if !dryRun {
+			if err := tx.Model(child).UpdateColumn("parent_id", target.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if dryRun {
+		return nil
+	}
+	return tx.Delete(source).Error
+}
+
+// MoveCategory 移动分类到新的父分类下（或移动为顶级分类）
+// 会拒绝形成循环、类型不一致或超过最大层级的移动
+// userID: 当前操作的用户ID
+// categoryID: 要移动的分类ID
+// req: 移动请求数据
+func (s *BookkeepingCategoryService) MoveCategory(userID uint, categoryID uint, req dto.MoveCategoryRequest) (dto.CategoryResponse, error) {
+	var response dto.CategoryResponse
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var category model.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, "id = ? AND user_id = ?", categoryID, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("分类不存在")
+			}
+			return err
+		}
+
+		var parentID *uint
+		if req.ParentID != nil && *req.ParentID != 0 {
+			if err := validateCategoryParent(tx, userID, &category, category.Type, *req.ParentID); err != nil {
+				return err
+			}
+			parentID = req.ParentID
+		}
+
+		siblings := tx.Model(&model.Category{}).Where("user_id = ? AND type = ? AND id != ?", userID, category.Type, category.ID)
+		if parentID != nil {
+			siblings = siblings.Where("parent_id = ?", *parentID)
+		} else {
+			siblings = siblings.Where("parent_id IS NULL")
+		}
+
+		var sameNameCount int64
+		if err := siblings.Session(&gorm.Session{}).Where("name = ?", category.Name).Count(&sameNameCount).Error; err != nil {
+			return err
+		}
+		if sameNameCount > 0 {
+			return newBizError("目标位置已存在同名分类")
+		}
+
+		sortOrder := 0
+		if req.SortOrder != nil {
+			sortOrder = *req.SortOrder
+		} else {
+			var maxSortOrder *int
+			if err := siblings.Session(&gorm.Session{}).Select("MAX(sort_order)").Scan(&maxSortOrder).Error; err != nil {
+				return err
+			}
+			if maxSortOrder != nil {
+				sortOrder = *maxSortOrder + 1
+			}
+		}
+
+		return tx.Model(&category).UpdateColumns(map[string]interface{}{
+			"parent_id":  parentID,
+			"sort_order": sortOrder,
+		}).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to move category: " + err.Error())
+		return response, userFacingError(err, "移动分类失败：数据库错误")
+	}
+
+	return s.GetCategoryByID(userID, categoryID)
+}
+
+// SortCategories 按给定顺序批量设置同级分类的排序值
+// userID: 当前操作的用户ID
+// req: 排序请求数据，分类必须属于同一父分类
+func (s *BookkeepingCategoryService) SortCategories(userID uint, req dto.SortCategoriesRequest) error {
+	var parentID *uint
+	if req.ParentID != nil && *req.ParentID != 0 {
+		parentID = req.ParentID
+	}
+
+	seen := make(map[uint]bool, len(req.CategoryIDs))
+	for _, id := range req.CategoryIDs {
+		if seen[id] {
+			return errors.New("分类ID重复")
+		}
+		seen[id] = true
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var categories []model.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND user_id = ?", req.CategoryIDs, userID).Find(&categories).Error; err != nil {
+			return err
+		}
+		if len(categories) != len(req.CategoryIDs) {
+			return newBizError("分类不存在或不属于您")
+		}
+		for _, category := range categories {
+			if !sameCategoryParent(category.ParentID, parentID) {
+				return newBizError("只能对同一父分类下的分类排序")
+			}
+		}
+
+		for i, id := range req.CategoryIDs {
+			if err := tx.Model(&model.Category{}).Where("id = ?", id).UpdateColumn("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		global.Logger.Error("Failed to sort categories: " + err.Error())
+		return userFacingError(err, "调整分类顺序失败：数据库错误")
+	}
+	return nil
+}
+
+// ReorderCategoryTree 按拖拽后的树结构整体调整分类的父分类和排序
+// 先在内存中应用新结构并校验循环、层级和同级重名，全部通过后在一个数据库事务中写入
+// userID: 当前操作的用户ID
+// req: 新的分类树
+func (s *BookkeepingCategoryService) ReorderCategoryTree(userID uint, req dto.ReorderCategoryTreeRequest) ([]dto.CategoryResponse, error) {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var categories []model.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND type = ?", userID, req.Type).Find(&categories).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*model.Category, len(categories))
+		for i := range categories {
+			byID[categories[i].ID] = &categories[i]
+		}
+
+		// 应用新的树结构，节点在数组中的位置即为排序
+		moved := make(map[uint]bool)
+		var apply func(nodes []dto.CategoryTreeNode, parentID *uint) error
+		apply = func(nodes []dto.CategoryTreeNode, parentID *uint) error {
+			for i, node := range nodes {
+				category, ok := byID[node.ID]
+				if !ok {
+					return newBizError(fmt.Sprintf("分类 %d 不存在、不属于您或类型不一致", node.ID))
+				}
+				if moved[node.ID] {
+					return newBizError(fmt.Sprintf("分类 %d 在树中重复出现", node.ID))
+				}
+				moved[node.ID] = true
+				category.ParentID = parentID
+				category.SortOrder = i
+				if err := apply(node.Children, &category.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err := apply(req.Nodes, nil); err != nil {
+			return err
+		}
+
+		// 未出现在树中的分类保持原位置，需要对整棵树重新校验
+		names := make(map[string]bool, len(categories))
+		for _, category := range categories {
+			depth := 0
+			visited := make(map[uint]bool)
+			for current := category; ; {
+				if visited[current.ID] {
+					return newBizError("调整后的分类树中存在循环引用")
+				}
+				visited[current.ID] = true
+				depth++
+				if current.ParentID == nil {
+					break
+				}
+				parent, ok := byID[*current.ParentID]
+				if !ok {
+					break
+				}
+				current = *parent
+			}
+			if depth > maxCategoryDepth() {
+				return newBizError(fmt.Sprintf("分类层级不能超过%d层", maxCategoryDepth()))
+			}
+
+			key := "/" + category.Name
+			if category.ParentID != nil {
+				key = fmt.Sprintf("%d/%s", *category.ParentID, category.Name)
+			}
+			if names[key] {
+				return newBizError("同一父分类下存在同名分类：" + category.Name)
+			}
+			names[key] = true
+		}
+
+		for id := range moved {
+			category := byID[id]
+			if err := tx.Model(&model.Category{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+				"parent_id":  category.ParentID,
+				"sort_order": category.SortOrder,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		global.Logger.Error("Failed to reorder category tree: " + err.Error())
+		return nil, userFacingError(err, "调整分类树失败：数据库错误")
+	}
+
+	topLevel := uint(0)
+	return s.ListCategories(userID, req.Type, &topLevel, true)
+}
+
+// ArchiveCategory 归档分类，其所有子分类一并归档
+// 归档后的分类默认不在分类列表中显示，也不能用于新的交易，但历史交易仍参与统计
+// userID: 当前操作的用户ID
+// categoryID: 要归档的分类ID
+func (s *BookkeepingCategoryService) ArchiveCategory(userID uint, categoryID uint) (dto.CategoryResponse, error) {
+	var category model.Category
+	var response dto.CategoryResponse
+
+	if err := global.DB.First(&category, "id = ? AND user_id = ?", categoryID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, errors.New("分类不存在")
+		}
+		global.Logger.Error("Failed to find category for archive: " + err.Error())
+		return response, errors.New("归档分类失败：未找到分类")
+	}
+
+	if category.IsArchived {
+		return response, errors.New("该分类已归档")
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		ids, err := categoryDescendantIDs(tx, userID, categoryID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, categoryID)
+		return tx.Model(&model.Category{}).Where("id IN ? AND is_archived = ?", ids, false).
+			Updates(map[string]interface{}{"is_archived": true, "archived_at": time.Now()}).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to archive category: " + err.Error())
+		return response, errors.New("归档分类失败")
+	}
+
+	return s.GetCategoryByID(userID, categoryID)
+}
+
+// ReopenCategory 重新启用已归档的分类，其所有子分类一并启用
+// userID: 当前操作的用户ID
+// categoryID: 要重新启用的分类ID
+func (s *BookkeepingCategoryService) ReopenCategory(userID uint, categoryID uint) (dto.CategoryResponse, error) {
+	var category model.Category
+	var response dto.CategoryResponse
+
+	if err := global.DB.First(&category, "id = ? AND user_id = ?", categoryID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, errors.New("分类不存在")
+		}
+		global.Logger.Error("Failed to find category for reopen: " + err.Error())
+		return response, errors.New("重新启用分类失败：未找到分类")
+	}
+
+	if !category.IsArchived {
+		return response, errors.New("该分类未归档")
+	}
+
+	// 父分类仍处于归档状态时，不能单独启用子分类
+	if category.ParentID != nil {
+		var parentCategory model.Category
+		if err := global.DB.First(&parentCategory, "id = ? AND user_id = ?", *category.ParentID, userID).Error; err == nil && parentCategory.IsArchived {
+			return response, errors.New("父分类已归档，请先重新启用父分类")
+		}
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		ids, err := categoryDescendantIDs(tx, userID, categoryID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, categoryID)
+		return tx.Model(&model.Category{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"is_archived": false, "archived_at": nil}).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to reopen category: " + err.Error())
+		return response, errors.New("重新启用分类失败")
+	}
+
+	return s.GetCategoryByID(userID, categoryID)
+}
+
+// ensureCategory 查找或创建当前用户指定名称和类型的顶级分类
+// 用于投资买卖等业务自动生成的资金流水，交易流水必须关联一个分类
+func ensureCategory(db *gorm.DB, userID uint, categoryType model.CategoryType, name string) (model.Category, error) {
+	var category model.Category
+	err := db.Where("user_id = ? AND name = ? AND type = ? AND parent_id IS NULL", userID, name, categoryType).First(&category).Error
+	if err == nil {
+		return category, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return category, err
+	}
+
+	category = model.Category{
+		UserID: userID,
+		Name:   name,
+		Type:   categoryType,
+	}
+	if err := db.Create(&category).Error; err != nil {
+		return category, err
+	}
+	return category, nil
+}
+
+// categoryDescendantIDs 获取分类的所有后代分类ID (不包含自身)
+func categoryDescendantIDs(db *gorm.DB, userID uint, categoryID uint) ([]uint, error) {
+	var descendants []uint
+	parents := []uint{categoryID}
+	visited := map[uint]bool{categoryID: true}
+
+	for len(parents) > 0 {
+		var children []uint
+		if err := db.Model(&model.Category{}).Where("user_id = ? AND parent_id IN ?", userID, parents).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+
+		parents = parents[:0]
+		for _, id := range children {
+			// 防止历史数据中存在循环引用导致死循环
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			descendants = append(descendants, id)
+			parents = append(parents, id)
+		}
+	}
+
+	return descendants, nil
+}
+
 // ListCategories 获取分类列表 (支持层级)
 // userID: 当前操作的用户ID
 // categoryType: 可选，按类型过滤 (income/expense)
 // parentID: 可选，获取指定父分类下的子分类，若为nil则获取顶级分类
-func (s *BookkeepingCategoryService) ListCategories(userID uint, categoryType model.CategoryType, parentID *uint) ([]dto.CategoryResponse, error) {
+// includeArchived: 是否包含已归档的分类
+func (s *BookkeepingCategoryService) ListCategories(userID uint, categoryType model.CategoryType, parentID *uint, includeArchived bool) ([]dto.CategoryResponse, error) {
 	var categories []model.Category
 	var responses []dto.CategoryResponse
 
 	query := global.DB.Where("user_id = ?", userID).Order("sort_order asc, created_at asc")
 
+	// 默认不返回已归档的分类，子分类的预加载同样过滤
+	var subCategoryConds []interface{}
+	if !includeArchived {
+		query = query.Where("is_archived = ?", false)
+		subCategoryConds = append(subCategoryConds, "is_archived = ?", false)
+	}
+
 	if categoryType != "" {
 		query = query.Where("type = ?", categoryType)
 	}
@@ -258,7 +771,7 @@ func (s *BookkeepingCategoryService) ListCategories(userID uint, categoryType mo
 		// 这里我们获取所有，然后在下面构建层级
 	}
 
-	if err := query.Preload("SubCategories").Find(&categories).Error; err != nil {
+	if err := query.Preload("SubCategories", subCategoryConds...).Find(&categories).Error; err != nil {
 		global.Logger.Error("Failed to list categories: " + err.Error())
 		return nil, errors.New("获取分类列表失败")
 	}
@@ -288,7 +801,7 @@ func (s *BookkeepingCategoryService) ListCategories(userID uint, categoryType mo
 				for i, sub := range cat.SubCategories {
 					subCategoryIDs[i] = sub.ID
 				}
-				global.DB.Preload("SubCategories").Where("id IN ?", subCategoryIDs).Order("sort_order asc, created_at asc").Find(&fullSubCategories)
+				global.DB.Preload("SubCategories", subCategoryConds...).Where("id IN ?", subCategoryIDs).Order("sort_order asc, created_at asc").Find(&fullSubCategories)
 				resp.SubCategories = mapCategoriesToResponse(fullSubCategories)
 			}
 			resps = append(resps, resp)
@@ -327,12 +840,17 @@ func (s *BookkeepingCategoryService) ListCategories(userID uint, categoryType mo
 // GetAllCategoriesFlat 获取所有分类的扁平列表 (无层级结构，主要用于选择框等)
 // userID: 当前操作的用户ID
 // categoryType: 可选，按类型过滤 (income/expense)
-func (s *BookkeepingCategoryService) GetAllCategoriesFlat(userID uint, categoryType model.CategoryType) ([]dto.CategoryResponse, error) {
+// includeArchived: 是否包含已归档的分类
+func (s *BookkeepingCategoryService) GetAllCategoriesFlat(userID uint, categoryType model.CategoryType, includeArchived bool) ([]dto.CategoryResponse, error) {
 	var categories []model.Category
 	var responses []dto.CategoryResponse
 
 	query := global.DB.Where("user_id = ?", userID).Order("type asc, sort_order asc, name asc")
 
+	if !includeArchived {
+		query = query.Where("is_archived = ?", false)
+	}
+
 	if categoryType != "" {
 		query = query.Where("type = ?", categoryType)
 	}
@@ -353,3 +871,114 @@ func (s *BookkeepingCategoryService) GetAllCategoriesFlat(userID uint, categoryT
 
 	return responses, nil
 }
+
+// defaultMaxCategoryDepth 未配置时分类树的最大层级
+const defaultMaxCategoryDepth = 3
+
+// maxCategoryDepth 分类树的最大层级，顶级分类为第1层
+func maxCategoryDepth() int {
+	if depth := global.Config.Bookkeeping.MaxCategoryDepth; depth > 0 {
+		return depth
+	}
+	return defaultMaxCategoryDepth
+}
+
+// validateCategoryParent 校验分类可以放到指定父分类下：
+// 父分类存在且属于当前用户、类型一致、不是分类自身或其后代，且移动后整棵子树不超过最大层级
+// category.ID 为0表示新建分类
+func validateCategoryParent(db *gorm.DB, userID uint, category *model.Category, categoryType model.CategoryType, parentID uint) error {
+	if category.ID != 0 && parentID == category.ID {
+		return newBizError("不能将分类设置为自身的父分类")
+	}
+
+	var parent model.Category
+	if err := db.First(&parent, "id = ? AND user_id = ?", parentID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newBizError("指定的父分类不存在或不属于您")
+		}
+		return err
+	}
+	if parent.Type != categoryType {
+		return newBizError("子分类类型必须与父分类类型一致")
+	}
+
+	height := 1
+	if category.ID != 0 {
+		descendants, err := categoryDescendantIDs(db, userID, category.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range descendants {
+			if id == parentID {
+				return newBizError("不能将分类移动到自己的子分类下")
+			}
+		}
+		if height, err = categorySubtreeHeight(db, userID, category.ID); err != nil {
+			return err
+		}
+	}
+
+	parentDepth, err := categoryDepth(db, userID, parentID)
+	if err != nil {
+		return err
+	}
+	if parentDepth+height > maxCategoryDepth() {
+		return newBizError(fmt.Sprintf("分类层级不能超过%d层", maxCategoryDepth()))
+	}
+	return nil
+}
+
+// categoryDepth 分类所在的层级，顶级分类为1
+func categoryDepth(db *gorm.DB, userID uint, categoryID uint) (int, error) {
+	depth := 0
+	visited := make(map[uint]bool)
+	for id := &categoryID; id != nil && *id != 0; {
+		// 防止历史数据中存在循环引用导致死循环
+		if visited[*id] {
+			return 0, newBizError("分类树中存在循环引用")
+		}
+		visited[*id] = true
+
+		var category model.Category
+		if err := db.Select("id", "parent_id").First(&category, "id = ? AND user_id = ?", *id, userID).Error; err != nil {
+			return 0, err
+		}
+		depth++
+		id = category.ParentID
+	}
+	return depth, nil
+}
+
+// categorySubtreeHeight 以分类为根的子树的层数，没有子分类时为1
+func categorySubtreeHeight(db *gorm.DB, userID uint, categoryID uint) (int, error) {
+	height := 1
+	level := []uint{categoryID}
+	visited := map[uint]bool{categoryID: true}
+
+	for {
+		var children []uint
+		if err := db.Model(&model.Category{}).Where("user_id = ? AND parent_id IN ?", userID, level).Pluck("id", &children).Error; err != nil {
+			return 0, err
+		}
+
+		level = level[:0]
+		for _, id := range children {
+			if !visited[id] {
+				visited[id] = true
+				level = append(level, id)
+			}
+		}
+		if len(level) == 0 {
+			return height, nil
+		}
+		height++
+	}
+}
+
+// sameCategoryParent 判断两个父分类ID是否相同，nil和0都表示顶级分类
+func sameCategoryParent(a, b *uint) bool {
+	if a == nil || *a == 0 {
+		return b == nil || *b == 0
+	}
+	return b != nil && *a == *b
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+)
+
+// builtinCategoryTemplates 系统内置的分类模板，启动时写入数据库 (已存在的不会覆盖，管理员的修改会保留)
+var builtinCategoryTemplates = []dto.SaveCategoryTemplateRequest{
+	{
+		Code:        "household",
+		Locale:      "zh-CN",
+		Name:        "家庭",
+		Description: "适合家庭日常记账，覆盖衣食住行、子女教育和人情往来",
+		Nodes: []dto.CategoryTemplateNode{
+			{Name: "工资", Type: model.CategoryTypeIncome, Icon: "salary"},
+			{Name: "奖金", Type: model.CategoryTypeIncome, Icon: "bonus"},
+			{Name: "理财收益", Type: model.CategoryTypeIncome, Icon: "investment"},
+			{Name: "其他收入", Type: model.CategoryTypeIncome, Icon: "other"},
+			{Name: "餐饮", Type: model.CategoryTypeExpense, Icon: "food", Children: []dto.CategoryTemplateNode{
+				{Name: "买菜", Icon: "groceries"},
+				{Name: "外出就餐", Icon: "restaurant"},
+				{Name: "零食饮料", Icon: "snack"},
+			}},
+			{Name: "居住", Type: model.CategoryTypeExpense, Icon: "home", Children: []dto.CategoryTemplateNode{
+				{Name: "房租房贷", Icon: "rent"},
+				{Name: "水电燃气", Icon: "utilities"},
+				{Name: "物业费", Icon: "property"},
+			}},
+			{Name: "交通", Type: model.CategoryTypeExpense, Icon: "transport", Children: []dto.CategoryTemplateNode{
+				{Name: "公共交通", Icon: "bus"},
+				{Name: "打车", Icon: "taxi"},
+				{Name: "加油停车", Icon: "fuel"},
+			}},
+			{Name: "购物", Type: model.CategoryTypeExpense, Icon: "shopping", Children: []dto.CategoryTemplateNode{
+				{Name: "日用品", Icon: "daily"},
+				{Name: "服饰", Icon: "clothes"},
+				{Name: "数码家电", Icon: "electronics"},
+			}},
+			{Name: "子女教育", Type: model.CategoryTypeExpense, Icon: "education"},
+			{Name: "医疗健康", Type: model.CategoryTypeExpense, Icon: "medical"},
+			{Name: "人情往来", Type: model.CategoryTypeExpense, Icon: "gift"},
+			{Name: "休闲娱乐", Type: model.CategoryTypeExpense, Icon: "entertainment"},
+			{Name: "其他支出", Type: model.CategoryTypeExpense, Icon: "other"},
+		},
+	},
+	{
+		Code:        "household",
+		Locale:      "en-US",
+		Name:        "Household",
+		Description: "Everyday family bookkeeping: food, housing, transport, children and gifts",
+		Nodes: []dto.CategoryTemplateNode{
+			{Name: "Salary", Type: model.CategoryTypeIncome, Icon: "salary"},
+			{Name: "Bonus", Type: model.CategoryTypeIncome, Icon: "bonus"},
+			{Name: "Investment Income", Type: model.CategoryTypeIncome, Icon: "investment"},
+			{Name: "Other Income", Type: model.CategoryTypeIncome, Icon: "other"},
+			{Name: "Food", Type: model.CategoryTypeExpense, Icon: "food", Children: []dto.CategoryTemplateNode{
+				{Name: "Groceries", Icon: "groceries"},
+				{Name: "Dining Out", Icon: "restaurant"},
+				{Name: "Snacks & Drinks", Icon: "snack"},
+			}},
+			{Name: "Housing", Type: model.CategoryTypeExpense, Icon: "home", Children: []dto.CategoryTemplateNode{
+				{Name: "Rent & Mortgage", Icon: "rent"},
+				{Name: "Utilities", Icon: "utilities"},
+				{Name: "Property Fees", Icon: "property"},
+			}},
+			{Name: "Transport", Type: model.CategoryTypeExpense, Icon: "transport", Children: []dto.CategoryTemplateNode{
+				{Name: "Public Transit", Icon: "bus"},
+				{Name: "Taxi", Icon: "taxi"},
+				{Name: "Fuel & Parking", Icon: "fuel"},
+			}},
+			{Name: "Shopping", Type: model.CategoryTypeExpense, Icon: "shopping", Children: []dto.CategoryTemplateNode{
+				{Name: "Household Supplies", Icon: "daily"},
+				{Name: "Clothing", Icon: "clothes"},
+				{Name: "Electronics", Icon: "electronics"},
+			}},
+			{Name: "Children & Education", Type: model.CategoryTypeExpense, Icon: "education"},
+			{Name: "Health", Type: model.CategoryTypeExpense, Icon: "medical"},
+			{Name: "Gifts", Type: model.CategoryTypeExpense, Icon: "gift"},
+			{Name: "Entertainment", Type: model.CategoryTypeExpense, Icon: "entertainment"},
+			{Name: "Other Expenses", Type: model.CategoryTypeExpense, Icon: "other"},
+		},
+	},
+	{
+		Code:        "student",
+		Locale:      "zh-CN",
+		Name:        "学生",
+		Description: "适合在校学生，记录生活费、兼职收入和学习开销",
+		Nodes: []dto.CategoryTemplateNode{
+			{Name: "生活费", Type: model.CategoryTypeIncome, Icon: "allowance"},
+			{Name: "兼职", Type: model.CategoryTypeIncome, Icon: "part-time"},
+			{Name: "奖学金", Type: model.CategoryTypeIncome, Icon: "scholarship"},
+			{Name: "其他收入", Type: model.CategoryTypeIncome, Icon: "other"},
+			{Name: "餐饮", Type: model.CategoryTypeExpense, Icon: "food", Children: []dto.CategoryTemplateNode{
+				{Name: "食堂", Icon: "canteen"},
+				{Name: "外卖", Icon: "takeout"},
+				{Name: "零食饮料", Icon: "snack"},
+			}},
+			{Name: "学习", Type: model.CategoryTypeExpense, Icon: "education", Children: []dto.CategoryTemplateNode{
+				{Name: "学费", Icon: "tuition"},
+				{Name: "书籍资料", Icon: "book"},
+				{Name: "培训考试", Icon: "exam"},
+			}},
+			{Name: "住宿", Type: model.CategoryTypeExpense, Icon: "home"},
+			{Name: "交通", Type: model.CategoryTypeExpense, Icon: "transport"},
+			{Name: "通讯网络", Type: model.CategoryTypeExpense, Icon: "phone"},
+			{Name: "购物", Type: model.CategoryTypeExpense, Icon: "shopping"},
+			{Name: "社交娱乐", Type: model.CategoryTypeExpense, Icon: "entertainment"},
+			{Name: "其他支出", Type: model.CategoryTypeExpense, Icon: "other"},
+		},
+	},
+	{
+		Code:        "student",
+		Locale:      "en-US",
+		Name:        "Student",
+		Description: "For students: allowance, part-time income and study costs",
+		Nodes: []dto.CategoryTemplateNode{
+			{Name: "Allowance", Type: model.CategoryTypeIncome, Icon: "allowance"},
+			{Name: "Part-time Job", Type: model.CategoryTypeIncome, Icon: "part-time"},
+			{Name: "Scholarship", Type: model.CategoryTypeIncome, Icon: "scholarship"},
+			{Name: "Other Income", Type: model.CategoryTypeIncome, Icon: "other"},
+			{Name: "Food", Type: model.CategoryTypeExpense, Icon: "food", Children: []dto.CategoryTemplateNode{
+				{Name: "Cafeteria", Icon: "canteen"},
+				{Name: "Takeout", Icon: "takeout"},
+				{Name: "Snacks & Drinks", Icon: "snack"},
+			}},
+			{Name: "Study", Type: model.CategoryTypeExpense, Icon: "education", Children: []dto.CategoryTemplateNode{
+				{Name: "Tuition", Icon: "tuition"},
+				{Name: "Books & Materials", Icon: "book"},
+				{Name: "Courses & Exams", Icon: "exam"},
+			}},
+			{Name: "Accommodation", Type: model.CategoryTypeExpense, Icon: "home"},
+			{Name: "Transport", Type: model.CategoryTypeExpense, Icon: "transport"},
+			{Name: "Phone & Internet", Type: model.CategoryTypeExpense, Icon: "phone"},
+			{Name: "Shopping", Type: model.CategoryTypeExpense, Icon: "shopping"},
+			{Name: "Social & Fun", Type: model.CategoryTypeExpense, Icon: "entertainment"},
+			{Name: "Other Expenses", Type: model.CategoryTypeExpense, Icon: "other"},
+		},
+	},
+	{
+		Code:        "freelancer",
+		Locale:      "zh-CN",
+		Name:        "自由职业",
+		Description: "适合自由职业者，区分项目收入和经营支出",
+		Nodes: []dto.CategoryTemplateNode{
+			{Name: "项目收入", Type: model.CategoryTypeIncome, Icon: "project", Children: []dto.CategoryTemplateNode{
+				{Name: "项目款", Icon: "invoice"},
+				{Name: "咨询费", Icon: "consulting"},
+				{Name: "稿费版税", Icon: "royalty"},
+			}},
+			{Name: "理财收益", Type: model.CategoryTypeIncome, Icon: "investment"},
+			{Name: "其他收入", Type: model.CategoryTypeIncome, Icon: "other"},
+			{Name: "经营支出", Type: model.CategoryTypeExpense, Icon: "business", Children: []dto.CategoryTemplateNode{
+				{Name: "设备软件", Icon: "electronics"},
+				{Name: "办公场地", Icon: "office"},
+				{Name: "推广营销", Icon: "marketing"},
+				{Name: "外包服务", Icon: "outsourcing"},
+			}},
+			{Name: "税费社保", Type: model.CategoryTypeExpense, Icon: "tax", Children: []dto.CategoryTemplateNode{
+				{Name: "个人所得税", Icon: "tax"},
+				{Name: "社保公积金", Icon: "insurance"},
+			}},
+			{Name: "餐饮", Type: model.CategoryTypeExpense, Icon: "food"},
+			{Name: "居住", Type: model.CategoryTypeExpense, Icon: "home"},
+			{Name: "交通差旅", Type: model.CategoryTypeExpense, Icon: "travel"},
+			{Name: "学习提升", Type: model.CategoryTypeExpense, Icon: "education"},
+			{Name: "医疗健康", Type: model.CategoryTypeExpense, Icon: "medical"},
+			{Name: "其他支出", Type: model.CategoryTypeExpense, Icon: "other"},
+		},
+	},
+	{
+		Code:        "freelancer",
+		Locale:      "en-US",
+		Name:        "Freelancer",
+		Description: "For freelancers: separates project income from business expenses",
+		Nodes: []dto.CategoryTemplateNode{
+			{Name: "Project Income", Type: model.CategoryTypeIncome, Icon: "project", Children: []dto.CategoryTemplateNode{
+				{Name: "Client Payments", Icon: "invoice"},
+				{Name: "Consulting", Icon: "consulting"},
+				{Name: "Royalties", Icon: "royalty"},
+			}},
+			{Name: "Investment Income", Type: model.CategoryTypeIncome, Icon: "investment"},
+			{Name: "Other Income", Type: model.CategoryTypeIncome, Icon: "other"},
+			{Name: "Business Expenses", Type: model.CategoryTypeExpense, Icon: "business", Children: []dto.CategoryTemplateNode{
+				{Name: "Equipment & Software", Icon: "electronics"},
+				{Name: "Workspace", Icon: "office"},
+				{Name: "Marketing", Icon: "marketing"},
+				{Name: "Subcontractors", Icon: "outsourcing"},
+			}},
+			{Name: "Taxes & Insurance", Type: model.CategoryTypeExpense, Icon: "tax", Children: []dto.CategoryTemplateNode{
+				{Name: "Income Tax", Icon: "tax"},
+				{Name: "Social Insurance", Icon: "insurance"},
+			}},
+			{Name: "Food", Type: model.CategoryTypeExpense, Icon: "food"},
+			{Name: "Housing", Type: model.CategoryTypeExpense, Icon: "home"},
+			{Name: "Travel", Type: model.CategoryTypeExpense, Icon: "travel"},
+			{Name: "Learning", Type: model.CategoryTypeExpense, Icon: "education"},
+			{Name: "Health", Type: model.CategoryTypeExpense, Icon: "medical"},
+			{Name: "Other Expenses", Type: model.CategoryTypeExpense, Icon: "other"},
+		},
+	},
+}
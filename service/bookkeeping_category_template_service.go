@@ -0,0 +1,564 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// defaultCategoryLocale 未配置 default-locale 时使用的语言
+const defaultCategoryLocale = "zh-CN"
+
+// BookkeepingCategoryTemplateService 分类模板服务
+type BookkeepingCategoryTemplateService struct{}
+
+// SeedBuiltinTemplates 写入系统内置的分类模板，已存在的同编码同语言模板保持不变
+func (s *BookkeepingCategoryTemplateService) SeedBuiltinTemplates() error {
+	for _, builtin := range builtinCategoryTemplates {
+		var count int64
+		if err := global.DB.Model(&model.CategoryTemplate{}).
+			Where("code = ? AND locale = ?", builtin.Code, builtin.Locale).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		template := model.CategoryTemplate{
+			Code:        builtin.Code,
+			Locale:      builtin.Locale,
+			Name:        builtin.Name,
+			Description: builtin.Description,
+			IsBuiltin:   true,
+			IsActive:    true,
+		}
+		err := global.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&template).Error; err != nil {
+				return err
+			}
+			return createTemplateItems(tx, template.ID, nil, "", builtin.Nodes)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTemplates 获取分类模板列表，locale 为空时返回所有语言；includeInactive 为 true 时包含停用的模板 (管理员)
+func (s *BookkeepingCategoryTemplateService) ListTemplates(locale string, includeInactive bool) ([]dto.CategoryTemplateResponse, error) {
+	query := global.DB.Model(&model.CategoryTemplate{}).Order("is_builtin desc, code asc, locale asc")
+	if locale != "" {
+		query = query.Where("locale = ?", locale)
+	}
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var templates []model.CategoryTemplate
+	if err := query.Find(&templates).Error; err != nil {
+		global.Logger.Error("Failed to list category templates: " + err.Error())
+		return nil, errors.New("获取分类模板列表失败")
+	}
+
+	itemCounts := make(map[uint]int)
+	if len(templates) > 0 {
+		ids := make([]uint, len(templates))
+		for i, template := range templates {
+			ids[i] = template.ID
+		}
+		var rows []struct {
+			TemplateID uint
+			Count      int
+		}
+		if err := global.DB.Model(&model.CategoryTemplateItem{}).
+			Select("template_id, COUNT(*) AS count").
+			Where("template_id IN ?", ids).
+			Group("template_id").
+			Scan(&rows).Error; err != nil {
+			global.Logger.Error("Failed to count category template items: " + err.Error())
+			return nil, errors.New("获取分类模板列表失败")
+		}
+		for _, row := range rows {
+			itemCounts[row.TemplateID] = row.Count
+		}
+	}
+
+	responses := make([]dto.CategoryTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		resp := toCategoryTemplateResponse(template)
+		resp.ItemCount = itemCounts[template.ID]
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// GetTemplate 获取分类模板详情 (包含分类树)；includeInactive 为 false 时停用的模板视为不存在
+func (s *BookkeepingCategoryTemplateService) GetTemplate(templateID uint, includeInactive bool) (*dto.CategoryTemplateResponse, error) {
+	template, err := findCategoryTemplate(global.DB, templateID, includeInactive)
+	if err != nil {
+		return nil, userFacingError(err, "获取分类模板失败")
+	}
+
+	resp := toCategoryTemplateResponse(template)
+	resp.ItemCount = len(template.Items)
+	resp.Nodes = templateNodesFromItems(template.Items)
+	return &resp, nil
+}
+
+// CreateTemplate 创建分类模板 (管理员)
+func (s *BookkeepingCategoryTemplateService) CreateTemplate(req dto.SaveCategoryTemplateRequest) (*dto.CategoryTemplateResponse, error) {
+	if err := validateTemplateNodes(req.Nodes); err != nil {
+		return nil, err
+	}
+
+	template := model.CategoryTemplate{
+		Code:        strings.TrimSpace(req.Code),
+		Locale:      strings.TrimSpace(req.Locale),
+		Name:        req.Name,
+		Description: req.Description,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ensureTemplateCodeAvailable(tx, template.Code, template.Locale, 0); err != nil {
+			return err
+		}
+		if err := tx.Create(&template).Error; err != nil {
+			return err
+		}
+		// IsActive 为 false 时 Create 会使用数据库默认值，需要单独写入
+		if !template.IsActive {
+			if err := tx.Model(&template).Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+		return createTemplateItems(tx, template.ID, nil, "", req.Nodes)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create category template: " + err.Error())
+		return nil, userFacingError(err, "创建分类模板失败")
+	}
+
+	return s.GetTemplate(template.ID, true)
+}
+
+// UpdateTemplate 更新分类模板 (管理员)，模板中的分类树整体替换
+func (s *BookkeepingCategoryTemplateService) UpdateTemplate(templateID uint, req dto.SaveCategoryTemplateRequest) (*dto.CategoryTemplateResponse, error) {
+	if err := validateTemplateNodes(req.Nodes); err != nil {
+		return nil, err
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		template, err := findCategoryTemplate(tx, templateID, true)
+		if err != nil {
+			return err
+		}
+
+		code := strings.TrimSpace(req.Code)
+		locale := strings.TrimSpace(req.Locale)
+		if template.IsBuiltin && (code != template.Code || locale != template.Locale) {
+			return newBizError("内置模板不能修改编码和语言")
+		}
+		if err := ensureTemplateCodeAvailable(tx, code, locale, template.ID); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"code":        code,
+			"locale":      locale,
+			"name":        req.Name,
+			"description": req.Description,
+		}
+		if req.IsActive != nil {
+			updates["is_active"] = *req.IsActive
+		}
+		if err := tx.Model(&template).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("template_id = ?", template.ID).Delete(&model.CategoryTemplateItem{}).Error; err != nil {
+			return err
+		}
+		return createTemplateItems(tx, template.ID, nil, "", req.Nodes)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to update category template: " + err.Error())
+		return nil, userFacingError(err, "更新分类模板失败")
+	}
+
+	return s.GetTemplate(templateID, true)
+}
+
+// DeleteTemplate 删除分类模板 (管理员)，内置模板只能停用
+func (s *BookkeepingCategoryTemplateService) DeleteTemplate(templateID uint) error {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		template, err := findCategoryTemplate(tx, templateID, true)
+		if err != nil {
+			return err
+		}
+		if template.IsBuiltin {
+			return newBizError("内置模板不能删除，可以将其停用")
+		}
+		if err := tx.Where("template_id = ?", template.ID).Delete(&model.CategoryTemplateItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&template).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete category template: " + err.Error())
+		return userFacingError(err, "删除分类模板失败")
+	}
+	return nil
+}
+
+// ApplyTemplate 将分类模板应用到用户的分类
+// 已存在的同级同名同类型分类会被复用 (其子分类继续合并)，超过最大层级的节点会被跳过，因此可以重复应用
+func (s *BookkeepingCategoryTemplateService) ApplyTemplate(userID uint, templateID uint) (*dto.ApplyCategoryTemplateResponse, error) {
+	var result *dto.ApplyCategoryTemplateResponse
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		template, err := findCategoryTemplate(tx, templateID, false)
+		if err != nil {
+			return err
+		}
+		result, err = applyCategoryTemplate(tx, userID, template)
+		return err
+	})
+	if err != nil {
+		global.Logger.Error("Failed to apply category template: " + err.Error())
+		return nil, userFacingError(err, "应用分类模板失败")
+	}
+	return result, nil
+}
+
+// ApplyDefaultTemplate 为新注册的用户应用配置的默认分类模板
+// 优先使用指定语言的版本，没有时使用任意一个启用的版本；未配置默认模板时不做任何处理
+func (s *BookkeepingCategoryTemplateService) ApplyDefaultTemplate(userID uint, locale string) error {
+	code := global.Config.Bookkeeping.DefaultCategoryTemplate
+	if code == "" {
+		return nil
+	}
+	if locale == "" {
+		locale = global.Config.Bookkeeping.DefaultLocale
+	}
+	if locale == "" {
+		locale = defaultCategoryLocale
+	}
+
+	var templates []model.CategoryTemplate
+	if err := global.DB.Where("code = ? AND is_active = ?", code, true).Order("id asc").Find(&templates).Error; err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		return fmt.Errorf("默认分类模板 %s 不存在或已停用", code)
+	}
+	template := templates[0]
+	for _, candidate := range templates {
+		if candidate.Locale == locale {
+			template = candidate
+			break
+		}
+	}
+
+	_, err := s.ApplyTemplate(userID, template.ID)
+	return err
+}
+
+// ExportTemplate 将用户当前的分类树 (不含已归档分类) 导出为分类模板格式，可以直接提交给管理员创建模板
+func (s *BookkeepingCategoryTemplateService) ExportTemplate(userID uint) (*dto.SaveCategoryTemplateRequest, error) {
+	var categories []model.Category
+	if err := global.DB.Where("user_id = ? AND is_archived = ?", userID, false).
+		Order("sort_order asc, created_at asc").
+		Find(&categories).Error; err != nil {
+		global.Logger.Error("Failed to export categories: " + err.Error())
+		return nil, errors.New("导出分类失败")
+	}
+
+	// 父分类已归档时，其子分类也不导出
+	children := make(map[uint][]model.Category)
+	var roots []model.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var build func(cats []model.Category, withType bool) []dto.CategoryTemplateNode
+	build = func(cats []model.Category, withType bool) []dto.CategoryTemplateNode {
+		nodes := make([]dto.CategoryTemplateNode, 0, len(cats))
+		for _, category := range cats {
+			node := dto.CategoryTemplateNode{
+				Name:     category.Name,
+				Icon:     category.Icon,
+				Children: build(children[category.ID], false),
+			}
+			if withType {
+				node.Type = category.Type
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	// 收入分类排在支出分类前面，与内置模板一致
+	sort.SliceStable(roots, func(i, j int) bool {
+		return roots[i].Type == model.CategoryTypeIncome && roots[j].Type != model.CategoryTypeIncome
+	})
+
+	locale := global.Config.Bookkeeping.DefaultLocale
+	if locale == "" {
+		locale = defaultCategoryLocale
+	}
+	return &dto.SaveCategoryTemplateRequest{
+		Code:   fmt.Sprintf("user-%d", userID),
+		Locale: locale,
+		Name:   "我的分类",
+		Nodes:  build(roots, true),
+	}, nil
+}
+
+// findCategoryTemplate 查找分类模板并预加载模板分类
+func findCategoryTemplate(db *gorm.DB, templateID uint, includeInactive bool) (model.CategoryTemplate, error) {
+	var template model.CategoryTemplate
+	query := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order asc, id asc")
+	})
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.First(&template, templateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return template, newBizError("分类模板不存在")
+		}
+		return template, err
+	}
+	return template, nil
+}
+
+// ensureTemplateCodeAvailable 同一编码同一语言只能有一个模板
+func ensureTemplateCodeAvailable(db *gorm.DB, code string, locale string, excludeID uint) error {
+	var count int64
+	if err := db.Model(&model.CategoryTemplate{}).
+		Where("code = ? AND locale = ? AND id <> ?", code, locale, excludeID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return newBizError(fmt.Sprintf("编码为 %s 的 %s 模板已存在", code, locale))
+	}
+	return nil
+}
+
+// validateTemplateNodes 校验模板分类树：顶级节点必须指定类型，子节点类型与父节点一致，
+// 同级节点不重名，且层级不超过分类树的最大层级
+func validateTemplateNodes(nodes []dto.CategoryTemplateNode) error {
+	maxDepth := maxCategoryDepth()
+
+	var walk func(nodes []dto.CategoryTemplateNode, parentType model.CategoryType, depth int) error
+	walk = func(nodes []dto.CategoryTemplateNode, parentType model.CategoryType, depth int) error {
+		if depth > maxDepth {
+			return newBizError(fmt.Sprintf("模板分类层级不能超过%d层", maxDepth))
+		}
+		names := make(map[string]bool)
+		for _, node := range nodes {
+			name := strings.TrimSpace(node.Name)
+			if name == "" {
+				return newBizError("模板分类名称不能为空")
+			}
+			nodeType := node.Type
+			if parentType == "" && nodeType == "" {
+				return newBizError(fmt.Sprintf("顶级模板分类 %s 必须指定类型", name))
+			}
+			if parentType != "" {
+				if nodeType != "" && nodeType != parentType {
+					return newBizError(fmt.Sprintf("模板分类 %s 的类型必须与父分类一致", name))
+				}
+				nodeType = parentType
+			}
+			key := string(nodeType) + "|" + name
+			if names[key] {
+				return newBizError(fmt.Sprintf("同一级下存在重名的模板分类：%s", name))
+			}
+			names[key] = true
+			if err := walk(node.Children, nodeType, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(nodes, "", 1)
+}
+
+// createTemplateItems 按树结构写入模板分类，子节点类型为空时沿用父节点类型
+func createTemplateItems(tx *gorm.DB, templateID uint, parentID *uint, parentType model.CategoryType, nodes []dto.CategoryTemplateNode) error {
+	for i, node := range nodes {
+		nodeType := node.Type
+		if nodeType == "" {
+			nodeType = parentType
+		}
+		item := model.CategoryTemplateItem{
+			TemplateID: templateID,
+			ParentID:   parentID,
+			Name:       strings.TrimSpace(node.Name),
+			Type:       nodeType,
+			Icon:       node.Icon,
+			SortOrder:  i,
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		if err := createTemplateItems(tx, templateID, &item.ID, nodeType, node.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateNodesFromItems 将模板分类 (已按 sort_order 排序) 还原为树结构
+func templateNodesFromItems(items []model.CategoryTemplateItem) []dto.CategoryTemplateNode {
+	children := make(map[uint][]model.CategoryTemplateItem)
+	var roots []model.CategoryTemplateItem
+	for _, item := range items {
+		if item.ParentID == nil {
+			roots = append(roots, item)
+		} else {
+			children[*item.ParentID] = append(children[*item.ParentID], item)
+		}
+	}
+
+	var build func(items []model.CategoryTemplateItem, withType bool) []dto.CategoryTemplateNode
+	build = func(items []model.CategoryTemplateItem, withType bool) []dto.CategoryTemplateNode {
+		nodes := make([]dto.CategoryTemplateNode, 0, len(items))
+		for _, item := range items {
+			node := dto.CategoryTemplateNode{
+				Name:     item.Name,
+				Icon:     item.Icon,
+				Children: build(children[item.ID], false),
+			}
+			if withType {
+				node.Type = item.Type
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build(roots, true)
+}
+
+// applyCategoryTemplate 在事务中按模板为用户创建分类
+func applyCategoryTemplate(tx *gorm.DB, userID uint, template model.CategoryTemplate) (*dto.ApplyCategoryTemplateResponse, error) {
+	result := &dto.ApplyCategoryTemplateResponse{
+		TemplateID:   template.ID,
+		TemplateName: template.Name,
+	}
+
+	// 用户现有的分类 (包含已归档的)，按 父分类|类型|名称 索引，同时记录每个位置的下一个排序值
+	var existing []model.Category
+	if err := tx.Where("user_id = ?", userID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	siblingKey := func(parentID uint, categoryType model.CategoryType) string {
+		return fmt.Sprintf("%d|%s", parentID, categoryType)
+	}
+	existingIDs := make(map[string]uint)
+	nextSort := make(map[string]int)
+	for _, category := range existing {
+		var parentID uint
+		if category.ParentID != nil {
+			parentID = *category.ParentID
+		}
+		key := siblingKey(parentID, category.Type)
+		existingIDs[key+"|"+category.Name] = category.ID
+		if category.SortOrder+1 > nextSort[key] {
+			nextSort[key] = category.SortOrder + 1
+		}
+	}
+
+	children := make(map[uint][]model.CategoryTemplateItem)
+	var roots []model.CategoryTemplateItem
+	for _, item := range template.Items {
+		if item.ParentID == nil {
+			roots = append(roots, item)
+		} else {
+			children[*item.ParentID] = append(children[*item.ParentID], item)
+		}
+	}
+
+	maxDepth := maxCategoryDepth()
+	var countSubtree func(item model.CategoryTemplateItem) int
+	countSubtree = func(item model.CategoryTemplateItem) int {
+		count := 1
+		for _, child := range children[item.ID] {
+			count += countSubtree(child)
+		}
+		return count
+	}
+
+	var apply func(items []model.CategoryTemplateItem, parentID uint, depth int) error
+	apply = func(items []model.CategoryTemplateItem, parentID uint, depth int) error {
+		for _, item := range items {
+			if depth > maxDepth {
+				result.TooDeep += countSubtree(item)
+				continue
+			}
+
+			key := siblingKey(parentID, item.Type)
+			categoryID, ok := existingIDs[key+"|"+item.Name]
+			if ok {
+				result.Existing++
+			} else {
+				category := model.Category{
+					UserID:    userID,
+					Name:      item.Name,
+					Type:      item.Type,
+					Icon:      item.Icon,
+					SortOrder: nextSort[key],
+				}
+				if parentID != 0 {
+					pid := parentID
+					category.ParentID = &pid
+				}
+				if err := tx.Create(&category).Error; err != nil {
+					return err
+				}
+				nextSort[key]++
+				existingIDs[key+"|"+item.Name] = category.ID
+				categoryID = category.ID
+				result.Created++
+			}
+
+			if err := apply(children[item.ID], categoryID, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := apply(roots, 0, 1); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// toCategoryTemplateResponse 将模板模型转换为响应体 (不包含分类树)
+func toCategoryTemplateResponse(template model.CategoryTemplate) dto.CategoryTemplateResponse {
+	return dto.CategoryTemplateResponse{
+		ID:          template.ID,
+		Code:        template.Code,
+		Locale:      template.Locale,
+		Name:        template.Name,
+		Description: template.Description,
+		IsBuiltin:   template.IsBuiltin,
+		IsActive:    template.IsActive,
+		CreatedAt:   template.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   template.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,619 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 个人借贷生成资金流水时使用的分类名称
+const (
+	debtLendCategoryName        = "借出款"
+	debtCollectCategoryName     = "收回借款"
+	debtBorrowCategoryName      = "借入款"
+	debtRepayBorrowCategoryName = "归还借款"
+)
+
+// BookkeepingDebtService 个人借贷（应收应付）服务
+type BookkeepingDebtService struct{}
+
+// CreateCounterparty 创建联系人
+func (s *BookkeepingDebtService) CreateCounterparty(userID uint, req dto.CreateCounterpartyRequest) (*dto.CounterpartyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+
+	var existing model.Counterparty
+	if err := global.DB.Where("user_id = ? AND name = ?", userID, name).First(&existing).Error; err == nil {
+		return nil, errors.New("该联系人已存在")
+	}
+
+	counterparty := model.Counterparty{
+		UserID: userID,
+		Name:   name,
+		Phone:  req.Phone,
+		Remark: req.Remark,
+	}
+	if err := global.DB.Create(&counterparty).Error; err != nil {
+		global.Logger.Error("Failed to create counterparty: " + err.Error())
+		return nil, errors.New("创建联系人失败：数据库错误")
+	}
+
+	response := s.counterpartyToResponse(&counterparty)
+	return &response, nil
+}
+
+// ListCounterparties 获取用户的联系人列表
+func (s *BookkeepingDebtService) ListCounterparties(userID uint) ([]dto.CounterpartyResponse, error) {
+	var counterparties []model.Counterparty
+	if err := global.DB.Where("user_id = ?", userID).Order("name ASC").Find(&counterparties).Error; err != nil {
+		global.Logger.Error("Failed to list counterparties: " + err.Error())
+		return nil, errors.New("获取联系人列表失败：数据库错误")
+	}
+
+	responses := make([]dto.CounterpartyResponse, 0, len(counterparties))
+	for i := range counterparties {
+		responses = append(responses, s.counterpartyToResponse(&counterparties[i]))
+	}
+	return responses, nil
+}
+
+// UpdateCounterparty 更新联系人信息
+func (s *BookkeepingDebtService) UpdateCounterparty(userID, counterpartyID uint, req dto.UpdateCounterpartyRequest) (*dto.CounterpartyResponse, error) {
+	var counterparty model.Counterparty
+	if err := global.DB.Where("id = ? AND user_id = ?", counterpartyID, userID).First(&counterparty).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("联系人不存在或不属于您")
+		}
+		return nil, errors.New("获取联系人失败：数据库错误")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name != counterparty.Name {
+			var existing model.Counterparty
+			if err := global.DB.Where("user_id = ? AND name = ? AND id != ?", userID, name, counterparty.ID).First(&existing).Error; err == nil {
+				return nil, errors.New("该联系人已存在")
+			}
+		}
+		counterparty.Name = name
+	}
+	if req.Phone != nil {
+		counterparty.Phone = *req.Phone
+	}
+	if req.Remark != nil {
+		counterparty.Remark = *req.Remark
+	}
+
+	if err := global.DB.Save(&counterparty).Error; err != nil {
+		global.Logger.Error("Failed to update counterparty: " + err.Error())
+		return nil, errors.New("更新联系人失败：数据库错误")
+	}
+
+	response := s.counterpartyToResponse(&counterparty)
+	return &response, nil
+}
+
+// DeleteCounterparty 删除联系人，存在借贷记录的联系人不能删除
+func (s *BookkeepingDebtService) DeleteCounterparty(userID, counterpartyID uint) error {
+	var counterparty model.Counterparty
+	if err := global.DB.Where("id = ? AND user_id = ?", counterpartyID, userID).First(&counterparty).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("联系人不存在或不属于您")
+		}
+		return errors.New("获取联系人失败：数据库错误")
+	}
+
+	var count int64
+	if err := global.DB.Model(&model.Debt{}).Where("counterparty_id = ?", counterparty.ID).Count(&count).Error; err != nil {
+		global.Logger.Error("Failed to count debts: " + err.Error())
+		return errors.New("删除联系人失败：数据库错误")
+	}
+	if count > 0 {
+		return errors.New("该联系人存在借贷记录，不能删除")
+	}
+
+	if err := global.DB.Delete(&counterparty).Error; err != nil {
+		global.Logger.Error("Failed to delete counterparty: " + err.Error())
+		return errors.New("删除联系人失败：数据库错误")
+	}
+	return nil
+}
+
+// CreateDebt 记录一笔借出或借入，同时在账户上生成不计入收支统计的资金流水
+func (s *BookkeepingDebtService) CreateDebt(userID uint, req dto.CreateDebtRequest) (*dto.DebtResponse, error) {
+	if req.CounterpartyID == 0 && strings.TrimSpace(req.CounterpartyName) == "" {
+		return nil, errors.New("请指定联系人")
+	}
+
+	debtDate, err := time.Parse("2006-01-02", req.DebtDate)
+	if err != nil {
+		return nil, errors.New("借贷日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	debt := model.Debt{
+		UserID:    userID,
+		Direction: model.DebtDirection(req.Direction),
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		DebtDate:  debtDate,
+		Status:    model.DebtStatusOpen,
+		Notes:     req.Notes,
+	}
+	if req.DueDate != "" {
+		dueDate, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			return nil, errors.New("还款日期格式错误，请使用YYYY-MM-DD格式")
+		}
+		if dueDate.Before(debtDate) {
+			return nil, errors.New("约定还款日期不能早于借贷日期")
+		}
+		debt.DueDate = &dueDate
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkAccount(tx, userID, req.AccountID); err != nil {
+			return err
+		}
+
+		counterparty, err := s.resolveCounterparty(tx, userID, req.CounterpartyID, req.CounterpartyName)
+		if err != nil {
+			return err
+		}
+		debt.CounterpartyID = counterparty.ID
+		debt.Counterparty = counterparty
+
+		transactionType, categoryType, categoryName := model.TransactionTypeExpense, model.CategoryTypeExpense, debtLendCategoryName
+		notes := fmt.Sprintf("借给%s", counterparty.Name)
+		if debt.Direction == model.DebtDirectionBorrow {
+			transactionType, categoryType, categoryName = model.TransactionTypeIncome, model.CategoryTypeIncome, debtBorrowCategoryName
+			notes = fmt.Sprintf("向%s借入", counterparty.Name)
+		}
+		transaction, err := s.createTransaction(tx, userID, debt.AccountID, transactionType, categoryType, categoryName, debt.Amount, debtDate, counterparty.Name, notes)
+		if err != nil {
+			return err
+		}
+		debt.TransactionID = &transaction.ID
+
+		return tx.Omit("Counterparty").Create(&debt).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create debt: " + err.Error())
+		return nil, userFacingError(err, "记录借贷失败：数据库错误")
+	}
+
+	response := s.debtToResponse(&debt, time.Now())
+	return &response, nil
+}
+
+// ListDebts 获取借贷记录列表
+func (s *BookkeepingDebtService) ListDebts(userID uint, query dto.DebtQuery) ([]dto.DebtResponse, error) {
+	db := global.DB.Preload("Counterparty").Where("user_id = ?", userID)
+	if query.CounterpartyID > 0 {
+		db = db.Where("counterparty_id = ?", query.CounterpartyID)
+	}
+	if query.Direction != "" {
+		db = db.Where("direction = ?", query.Direction)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var debts []model.Debt
+	if err := db.Order("debt_date DESC, id DESC").Find(&debts).Error; err != nil {
+		global.Logger.Error("Failed to list debts: " + err.Error())
+		return nil, errors.New("获取借贷记录失败：数据库错误")
+	}
+
+	now := time.Now()
+	responses := make([]dto.DebtResponse, 0, len(debts))
+	for i := range debts {
+		responses = append(responses, s.debtToResponse(&debts[i], now))
+	}
+	return responses, nil
+}
+
+// GetDebt 获取借贷记录详情（含还款记录）
+func (s *BookkeepingDebtService) GetDebt(userID, debtID uint) (*dto.DebtResponse, error) {
+	var debt model.Debt
+	if err := global.DB.Preload("Counterparty").Where("id = ? AND user_id = ?", debtID, userID).First(&debt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("借贷记录不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get debt: " + err.Error())
+		return nil, errors.New("获取借贷记录失败：数据库错误")
+	}
+
+	var repayments []model.DebtRepayment
+	if err := global.DB.Where("debt_id = ?", debt.ID).Order("repayment_date ASC, id ASC").Find(&repayments).Error; err != nil {
+		global.Logger.Error("Failed to list debt repayments: " + err.Error())
+		return nil, errors.New("获取还款记录失败：数据库错误")
+	}
+
+	response := s.debtToResponse(&debt, time.Now())
+	response.Repayments = make([]dto.DebtRepaymentResponse, 0, len(repayments))
+	for i := range repayments {
+		response.Repayments = append(response.Repayments, s.repaymentToResponse(&repayments[i]))
+	}
+	return &response, nil
+}
+
+// DeleteDebt 删除借贷记录，同时删除其还款记录和生成的资金流水
+func (s *BookkeepingDebtService) DeleteDebt(userID, debtID uint) error {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var debt model.Debt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", debtID, userID).First(&debt).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("借贷记录不存在或不属于您")
+			}
+			return err
+		}
+
+		var repayments []model.DebtRepayment
+		if err := tx.Where("debt_id = ?", debt.ID).Find(&repayments).Error; err != nil {
+			return err
+		}
+
+		transactionIDs := make([]uint, 0, len(repayments)+1)
+		if debt.TransactionID != nil {
+			transactionIDs = append(transactionIDs, *debt.TransactionID)
+		}
+		for _, repayment := range repayments {
+			if repayment.TransactionID != nil {
+				transactionIDs = append(transactionIDs, *repayment.TransactionID)
+			}
+		}
+		if err := deleteTransactions(tx, userID, transactionIDs); err != nil {
+			return err
+		}
+
+		if err := tx.Where("debt_id = ?", debt.ID).Delete(&model.DebtRepayment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&debt).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete debt: " + err.Error())
+		return userFacingError(err, "删除借贷记录失败：数据库错误")
+	}
+	return nil
+}
+
+// AddRepayment 记录一笔（部分）还款，还清后借贷记录标记为已结清
+func (s *BookkeepingDebtService) AddRepayment(userID, debtID uint, req dto.CreateDebtRepaymentRequest) (*dto.DebtResponse, error) {
+	repaymentDate, err := time.Parse("2006-01-02", req.RepaymentDate)
+	if err != nil {
+		return nil, errors.New("还款日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		var debt model.Debt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Counterparty").
+			Where("id = ? AND user_id = ?", debtID, userID).First(&debt).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("借贷记录不存在或不属于您")
+			}
+			return err
+		}
+		if debt.Status == model.DebtStatusSettled {
+			return newBizError("该借贷已结清")
+		}
+		amount := roundCent(req.Amount)
+		if amount > roundCent(debt.Outstanding()) {
+			return newBizError(fmt.Sprintf("还款金额不能超过未还金额 %.2f", debt.Outstanding()))
+		}
+		if err := s.checkAccount(tx, userID, req.AccountID); err != nil {
+			return err
+		}
+
+		// 借出的钱收回记为收入，借入的钱归还记为支出，均不计入收支统计
+		transactionType, categoryType, categoryName := model.TransactionTypeIncome, model.CategoryTypeIncome, debtCollectCategoryName
+		notes := fmt.Sprintf("%s还款", debt.Counterparty.Name)
+		if debt.Direction == model.DebtDirectionBorrow {
+			transactionType, categoryType, categoryName = model.TransactionTypeExpense, model.CategoryTypeExpense, debtRepayBorrowCategoryName
+			notes = fmt.Sprintf("还给%s", debt.Counterparty.Name)
+		}
+		if req.Notes != "" {
+			notes = req.Notes
+		}
+		transaction, err := s.createTransaction(tx, userID, req.AccountID, transactionType, categoryType, categoryName, amount, repaymentDate, debt.Counterparty.Name, notes)
+		if err != nil {
+			return err
+		}
+
+		repayment := model.DebtRepayment{
+			UserID:        userID,
+			DebtID:        debt.ID,
+			AccountID:     req.AccountID,
+			Amount:        amount,
+			RepaymentDate: repaymentDate,
+			TransactionID: &transaction.ID,
+			Notes:         req.Notes,
+		}
+		if err := tx.Create(&repayment).Error; err != nil {
+			return err
+		}
+
+		debt.RepaidAmount = roundCent(debt.RepaidAmount + amount)
+		if debt.RepaidAmount >= debt.Amount {
+			debt.Status = model.DebtStatusSettled
+		}
+		return tx.Model(&debt).Select("repaid_amount", "status").Updates(&debt).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to add debt repayment: " + err.Error())
+		return nil, userFacingError(err, "记录还款失败：数据库错误")
+	}
+
+	return s.GetDebt(userID, debtID)
+}
+
+// DeleteRepayment 撤销一笔还款，删除还款记录和生成的资金流水，已还金额回退，未还清时借贷记录恢复为未结清
+func (s *BookkeepingDebtService) DeleteRepayment(userID, debtID, repaymentID uint) (*dto.DebtResponse, error) {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var debt model.Debt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", debtID, userID).First(&debt).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("借贷记录不存在或不属于您")
+			}
+			return err
+		}
+
+		var repayment model.DebtRepayment
+		if err := tx.Where("id = ? AND debt_id = ?", repaymentID, debt.ID).First(&repayment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("还款记录不存在")
+			}
+			return err
+		}
+
+		if err := tx.Delete(&repayment).Error; err != nil {
+			return err
+		}
+		if repayment.TransactionID != nil {
+			if err := deleteTransactions(tx, userID, []uint{*repayment.TransactionID}); err != nil {
+				return err
+			}
+		}
+
+		debt.RepaidAmount = math.Max(roundCent(debt.RepaidAmount-repayment.Amount), 0)
+		if debt.RepaidAmount < debt.Amount {
+			debt.Status = model.DebtStatusOpen
+		}
+		return tx.Model(&debt).Select("repaid_amount", "status").Updates(&debt).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete debt repayment: " + err.Error())
+		return nil, userFacingError(err, "撤销还款失败：数据库错误")
+	}
+
+	return s.GetDebt(userID, debtID)
+}
+
+// GetSummary 按联系人汇总未结清的借贷余额
+func (s *BookkeepingDebtService) GetSummary(userID uint) (*dto.DebtSummaryResponse, error) {
+	var debts []model.Debt
+	if err := global.DB.Preload("Counterparty").Where("user_id = ? AND status = ?", userID, model.DebtStatusOpen).
+		Find(&debts).Error; err != nil {
+		global.Logger.Error("Failed to list open debts: " + err.Error())
+		return nil, errors.New("获取借贷汇总失败：数据库错误")
+	}
+
+	today := truncateToDay(time.Now())
+	items := make(map[uint]*dto.DebtSummaryItem)
+	response := &dto.DebtSummaryResponse{Items: []dto.DebtSummaryItem{}}
+	for i := range debts {
+		debt := &debts[i]
+		item, ok := items[debt.CounterpartyID]
+		if !ok {
+			item = &dto.DebtSummaryItem{CounterpartyID: debt.CounterpartyID, CounterpartyName: debt.Counterparty.Name}
+			items[debt.CounterpartyID] = item
+		}
+
+		outstanding := debt.Outstanding()
+		if debt.Direction == model.DebtDirectionLend {
+			item.Receivable += outstanding
+			response.TotalReceivable += outstanding
+		} else {
+			item.Payable += outstanding
+			response.TotalPayable += outstanding
+		}
+		item.OpenCount++
+		if debt.DueDate != nil && truncateToDay(*debt.DueDate).Before(today) {
+			item.OverdueCount++
+		}
+	}
+
+	for _, item := range items {
+		item.Receivable = roundCent(item.Receivable)
+		item.Payable = roundCent(item.Payable)
+		item.Net = roundCent(item.Receivable - item.Payable)
+		response.Items = append(response.Items, *item)
+	}
+	sort.Slice(response.Items, func(i, j int) bool {
+		return response.Items[i].CounterpartyName < response.Items[j].CounterpartyName
+	})
+	response.TotalReceivable = roundCent(response.TotalReceivable)
+	response.TotalPayable = roundCent(response.TotalPayable)
+	response.Net = roundCent(response.TotalReceivable - response.TotalPayable)
+
+	return response, nil
+}
+
+// ListOverdue 获取已过约定还款日期仍未结清的借贷记录，按逾期时间由长到短排序
+// direction: 可选，按方向筛选 (lend, borrow)
+func (s *BookkeepingDebtService) ListOverdue(userID uint, direction string) ([]dto.DebtResponse, error) {
+	now := time.Now()
+	db := global.DB.Preload("Counterparty").
+		Where("user_id = ? AND status = ? AND due_date IS NOT NULL AND due_date < ?",
+			userID, model.DebtStatusOpen, truncateToDay(now).Format("2006-01-02"))
+	if direction != "" {
+		db = db.Where("direction = ?", direction)
+	}
+
+	var debts []model.Debt
+	if err := db.Order("due_date ASC").Find(&debts).Error; err != nil {
+		global.Logger.Error("Failed to list overdue debts: " + err.Error())
+		return nil, errors.New("获取逾期借贷失败：数据库错误")
+	}
+
+	responses := make([]dto.DebtResponse, 0, len(debts))
+	for i := range debts {
+		responses = append(responses, s.debtToResponse(&debts[i], now))
+	}
+	return responses, nil
+}
+
+// resolveCounterparty 按ID查找联系人，未指定ID时按姓名查找，不存在则创建
+func (s *BookkeepingDebtService) resolveCounterparty(tx *gorm.DB, userID, counterpartyID uint, name string) (model.Counterparty, error) {
+	var counterparty model.Counterparty
+	if counterpartyID != 0 {
+		if err := tx.Where("id = ? AND user_id = ?", counterpartyID, userID).First(&counterparty).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return counterparty, newBizError("联系人不存在或不属于您")
+			}
+			return counterparty, err
+		}
+		return counterparty, nil
+	}
+
+	name = strings.TrimSpace(name)
+	err := tx.Where("user_id = ? AND name = ?", userID, name).First(&counterparty).Error
+	if err == nil {
+		return counterparty, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return counterparty, err
+	}
+
+	counterparty = model.Counterparty{UserID: userID, Name: name}
+	if err := tx.Create(&counterparty).Error; err != nil {
+		return counterparty, err
+	}
+	return counterparty, nil
+}
+
+// checkAccount 校验资金账户属于当前用户且未归档
+func (s *BookkeepingDebtService) checkAccount(tx *gorm.DB, userID, accountID uint) error {
+	var account model.Account
+	if err := tx.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newBizError("账户不存在或不属于您")
+		}
+		return err
+	}
+	if account.IsArchived {
+		return newBizError("该账户已归档，不能记录新的交易")
+	}
+	return nil
+}
+
+// createTransaction 生成不计入收支统计的借贷资金流水
+func (s *BookkeepingDebtService) createTransaction(tx *gorm.DB, userID, accountID uint, transactionType model.TransactionType, categoryType model.CategoryType, categoryName string, amount float64, date time.Time, payee, notes string) (*model.Transaction, error) {
+	category, err := ensureCategory(tx, userID, categoryType, categoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := model.Transaction{
+		UserID:           userID,
+		AccountID:        accountID,
+		Type:             transactionType,
+		Amount:           amount,
+		TransactionDate:  date,
+		CategoryID:       category.ID,
+		PayeePayer:       payee,
+		Notes:            notes,
+		ExcludeFromStats: true,
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// counterpartyToResponse 辅助函数，将联系人模型转换为响应对象
+func (s *BookkeepingDebtService) counterpartyToResponse(counterparty *model.Counterparty) dto.CounterpartyResponse {
+	return dto.CounterpartyResponse{
+		ID:        counterparty.ID,
+		Name:      counterparty.Name,
+		Phone:     counterparty.Phone,
+		Remark:    counterparty.Remark,
+		CreatedAt: counterparty.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// debtToResponse 辅助函数，将借贷记录模型转换为响应对象
+func (s *BookkeepingDebtService) debtToResponse(debt *model.Debt, now time.Time) dto.DebtResponse {
+	response := dto.DebtResponse{
+		ID:               debt.ID,
+		CounterpartyID:   debt.CounterpartyID,
+		CounterpartyName: debt.Counterparty.Name,
+		Direction:        string(debt.Direction),
+		AccountID:        debt.AccountID,
+		Amount:           debt.Amount,
+		RepaidAmount:     debt.RepaidAmount,
+		Outstanding:      roundCent(debt.Outstanding()),
+		DebtDate:         debt.DebtDate.Format("2006-01-02"),
+		Status:           string(debt.Status),
+		TransactionID:    debt.TransactionID,
+		Notes:            debt.Notes,
+		CreatedAt:        debt.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if debt.DueDate != nil {
+		response.DueDate = debt.DueDate.Format("2006-01-02")
+		today := truncateToDay(now)
+		dueDay := truncateToDay(*debt.DueDate)
+		if debt.Status == model.DebtStatusOpen && dueDay.Before(today) {
+			response.IsOverdue = true
+			response.DaysOverdue = int(today.Sub(dueDay).Hours() / 24)
+		}
+	}
+	return response
+}
+
+// repaymentToResponse 辅助函数，将还款记录模型转换为响应对象
+func (s *BookkeepingDebtService) repaymentToResponse(repayment *model.DebtRepayment) dto.DebtRepaymentResponse {
+	return dto.DebtRepaymentResponse{
+		ID:            repayment.ID,
+		DebtID:        repayment.DebtID,
+		AccountID:     repayment.AccountID,
+		Amount:        repayment.Amount,
+		RepaymentDate: repayment.RepaymentDate.Format("2006-01-02"),
+		TransactionID: repayment.TransactionID,
+		Notes:         repayment.Notes,
+		CreatedAt:     repayment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// deleteTransactions 逐条删除系统生成的资金流水，以便触发余额更新钩子
+func deleteTransactions(tx *gorm.DB, userID uint, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var transactions []model.Transaction
+	if err := tx.Where("id IN ? AND user_id = ?", ids, userID).Find(&transactions).Error; err != nil {
+		return err
+	}
+	for i := range transactions {
+		if err := tx.Delete(&transactions[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncateToDay 去掉时间部分，返回当天零点（本地时区）
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,494 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookkeepingEnvelopeService 信封预算 (零基预算) 服务
+// 收入进入待分配，每月从待分配中把钱分到各个信封，支出从对应信封中扣减，信封的可用余额逐月结转。
+// 信封是 IsEnvelope 为 true 的按月分类预算，结转方式固定为 both，每月的预算金额即该月的分配金额，
+// 因此预算的进度、结转、历史和对比表都可以直接用于信封
+type BookkeepingEnvelopeService struct{}
+
+// GetMonth 获取指定月份的信封概览，包括待分配金额和各信封的结转、分配、支出和可用余额
+func (s *BookkeepingEnvelopeService) GetMonth(userID uint, month string) (*dto.EnvelopeMonthResponse, error) {
+	setting, err := s.envelopeSetting(global.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	monthStart, err := parseEnvelopeMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	monthEnd := monthStart.AddDate(0, 1, 0).Add(-time.Second)
+
+	response := &dto.EnvelopeMonthResponse{
+		Month:     envelopeMonthKey(monthStart),
+		Envelopes: []dto.EnvelopeItem{},
+	}
+	if response.ReadyToAssign, err = readyToAssign(global.DB, setting); err != nil {
+		global.Logger.Error("Failed to calculate ready to assign: " + err.Error())
+		return nil, errors.New("获取信封概览失败：数据库错误")
+	}
+	if err := global.DB.Model(&model.Transaction{}).
+		Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND transaction_date BETWEEN ? AND ?",
+			userID, model.TransactionTypeIncome, false, monthStart, monthEnd).
+		Select("COALESCE(SUM(amount), 0)").Scan(&response.Income).Error; err != nil {
+		global.Logger.Error("Failed to sum monthly income: " + err.Error())
+		return nil, errors.New("获取信封概览失败：数据库错误")
+	}
+
+	// 该月已经创建的信封
+	var envelopes []model.Budget
+	if err := global.DB.Where("user_id = ? AND is_envelope = ? AND start_date <= ?", userID, true, monthEnd).
+		Order("id ASC").Find(&envelopes).Error; err != nil {
+		global.Logger.Error("Failed to list envelopes: " + err.Error())
+		return nil, errors.New("获取信封概览失败：数据库错误")
+	}
+	for i := range envelopes {
+		item, err := envelopeMonthItem(userID, &envelopes[i], monthStart)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to calculate envelope %d: %s", envelopes[i].ID, err.Error()))
+			return nil, errors.New("获取信封概览失败：数据库错误")
+		}
+		response.Assigned += item.Assigned
+		response.Spent += item.Spent
+		response.Available += item.Available
+		response.Envelopes = append(response.Envelopes, item)
+	}
+	response.Income = roundCent(response.Income)
+	response.Assigned = roundCent(response.Assigned)
+	response.Spent = roundCent(response.Spent)
+	response.Available = roundCent(response.Available)
+
+	return response, nil
+}
+
+// CreateEnvelope 创建信封，从本月开始按自然月分配资金
+func (s *BookkeepingEnvelopeService) CreateEnvelope(userID uint, req dto.CreateEnvelopeRequest) (*dto.BudgetResponse, error) {
+	if _, err := s.envelopeSetting(global.DB, userID); err != nil {
+		return nil, err
+	}
+
+	categoryID := req.CategoryID
+	budget := model.Budget{
+		UserID:       userID,
+		Name:         req.Name,
+		Type:         model.BudgetTypeCategory,
+		Kind:         model.BudgetKindExpense,
+		Period:       model.BudgetPeriodMonthly,
+		StartDate:    envelopeMonthStart(time.Now()),
+		CategoryID:   &categoryID,
+		NotifyRate:   0.8, // 默认80%提醒
+		Description:  req.Description,
+		IsActive:     true,
+		RolloverMode: model.BudgetRolloverBoth,
+		IsEnvelope:   true,
+	}
+	if req.NotifyRate != nil {
+		budget.NotifyRate = *req.NotifyRate
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ensureUserRecords(tx, &model.Category{}, userID, []uint{categoryID}, "指定的分类不存在"); err != nil {
+			return err
+		}
+		if err := tx.Create(&budget).Error; err != nil {
+			return err
+		}
+		if err := saveBudgetScope(tx, userID, &budget, &req.CategoryIDs, &req.ExcludeCategoryIDs, nil); err != nil {
+			return err
+		}
+		if err := checkBudgetCategoryKind(tx, &budget); err != nil {
+			return err
+		}
+		return checkEnvelopeScopeOverlap(tx, userID, &budget)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create envelope: " + err.Error())
+		return nil, userFacingError(err, "创建信封失败：数据库错误")
+	}
+
+	budgetService := BookkeepingBudgetService{}
+	return budgetService.GetBudget(userID, budget.ID)
+}
+
+// MoveMoney 分配或移动信封资金：来源为空时从待分配中分配，去向为空时退回待分配
+// 来源的可用金额 (待分配金额或信封当月的可用余额) 不足时拒绝
+func (s *BookkeepingEnvelopeService) MoveMoney(userID uint, req dto.EnvelopeMoveRequest) (*dto.EnvelopeMoveResponse, error) {
+	monthStart, err := parseEnvelopeMonth(req.Month)
+	if err != nil {
+		return nil, err
+	}
+	if req.FromBudgetID == nil && req.ToBudgetID == nil {
+		return nil, errors.New("来源和去向不能都是待分配")
+	}
+	if req.FromBudgetID != nil && req.ToBudgetID != nil && *req.FromBudgetID == *req.ToBudgetID {
+		return nil, errors.New("来源和去向不能是同一个信封")
+	}
+
+	move := model.EnvelopeMove{
+		UserID:       userID,
+		Month:        envelopeMonthKey(monthStart),
+		FromBudgetID: req.FromBudgetID,
+		ToBudgetID:   req.ToBudgetID,
+		Amount:       roundCent(req.Amount),
+		Note:         req.Note,
+	}
+	names := make(map[uint]string)
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定用户设置，同一用户的资金移动依次执行，避免并发时超额分配
+		var setting model.UserSetting
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&setting).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("请先在记账设置中开启信封预算模式")
+			}
+			return err
+		}
+		if !setting.EnvelopeMode {
+			return newBizError("请先在记账设置中开启信封预算模式")
+		}
+		if setting.EnvelopeStartDate != nil && monthStart.Before(*setting.EnvelopeStartDate) {
+			return newBizError("不能分配开启信封模式之前月份的资金")
+		}
+
+		// 校验信封存在且该月份已经创建
+		checkEnvelope := func(id uint) (model.Budget, error) {
+			envelope, err := findEnvelope(tx, userID, id)
+			if err != nil {
+				return envelope, err
+			}
+			if monthStart.Before(budgetFirstPeriodStart(envelope.StartDate)) {
+				return envelope, newBizError(fmt.Sprintf("信封 %s 在该月份尚未创建", envelope.Name))
+			}
+			names[envelope.ID] = envelope.Name
+			return envelope, nil
+		}
+		if req.ToBudgetID != nil {
+			if _, err := checkEnvelope(*req.ToBudgetID); err != nil {
+				return err
+			}
+		}
+
+		if req.FromBudgetID != nil {
+			envelope, err := checkEnvelope(*req.FromBudgetID)
+			if err != nil {
+				return err
+			}
+			available, err := envelopeAvailable(userID, &envelope, monthStart)
+			if err != nil {
+				return err
+			}
+			if move.Amount > available {
+				return newBizError(fmt.Sprintf("信封 %s 的可用余额不足 (%.2f)", envelope.Name, available))
+			}
+		} else {
+			ready, err := readyToAssign(tx, &setting)
+			if err != nil {
+				return err
+			}
+			if move.Amount > ready {
+				return newBizError(fmt.Sprintf("待分配金额不足 (%.2f)", ready))
+			}
+		}
+
+		return recordEnvelopeMove(tx, &move)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to move envelope money: " + err.Error())
+		return nil, userFacingError(err, "移动资金失败：数据库错误")
+	}
+
+	response := envelopeMoveToResponse(&move, names)
+	return &response, nil
+}
+
+// ListMoves 获取资金分配和移动记录，按时间倒序
+func (s *BookkeepingEnvelopeService) ListMoves(userID uint, query dto.EnvelopeMoveQuery) ([]dto.EnvelopeMoveResponse, error) {
+	db := global.DB.Where("user_id = ?", userID)
+	if query.Month != "" {
+		monthStart, err := parseEnvelopeMonth(query.Month)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("month = ?", envelopeMonthKey(monthStart))
+	}
+	if query.BudgetID > 0 {
+		db = db.Where("(from_budget_id = ? OR to_budget_id = ?)", query.BudgetID, query.BudgetID)
+	}
+
+	var moves []model.EnvelopeMove
+	if err := db.Order("id DESC").Find(&moves).Error; err != nil {
+		global.Logger.Error("Failed to list envelope moves: " + err.Error())
+		return nil, errors.New("获取资金移动记录失败：数据库错误")
+	}
+
+	// 已删除的信封也显示名称
+	var ids []uint
+	for _, move := range moves {
+		if move.FromBudgetID != nil {
+			ids = append(ids, *move.FromBudgetID)
+		}
+		if move.ToBudgetID != nil {
+			ids = append(ids, *move.ToBudgetID)
+		}
+	}
+	names := make(map[uint]string)
+	if len(ids) > 0 {
+		var budgets []model.Budget
+		if err := global.DB.Unscoped().Select("id, name").Where("id IN ?", uniqueUints(ids)).Find(&budgets).Error; err != nil {
+			global.Logger.Error("Failed to get envelope names: " + err.Error())
+			return nil, errors.New("获取资金移动记录失败：数据库错误")
+		}
+		for _, budget := range budgets {
+			names[budget.ID] = budget.Name
+		}
+	}
+
+	responses := make([]dto.EnvelopeMoveResponse, 0, len(moves))
+	for i := range moves {
+		responses = append(responses, envelopeMoveToResponse(&moves[i], names))
+	}
+	return responses, nil
+}
+
+// envelopeSetting 获取开启了信封模式的用户设置，未开启时返回错误
+func (s *BookkeepingEnvelopeService) envelopeSetting(db *gorm.DB, userID uint) (*model.UserSetting, error) {
+	setting, err := userSetting(db, userID)
+	if err != nil {
+		global.Logger.Error("Failed to get user setting: " + err.Error())
+		return nil, errors.New("获取设置失败：数据库错误")
+	}
+	if !setting.EnvelopeMode {
+		return nil, errors.New("请先在记账设置中开启信封预算模式")
+	}
+	return setting, nil
+}
+
+// findEnvelope 查询属于当前用户的信封
+func findEnvelope(tx *gorm.DB, userID, budgetID uint) (model.Budget, error) {
+	var envelope model.Budget
+	if err := tx.Where("id = ? AND user_id = ? AND is_envelope = ?", budgetID, userID, true).First(&envelope).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return envelope, newBizError("信封不存在")
+		}
+		return envelope, err
+	}
+	return envelope, nil
+}
+
+// checkEnvelopeScopeOverlap 校验信封的统计范围 (展开子分类后) 与用户的其他信封不重叠
+// 同一分类的支出只能从一个信封中扣减，否则同一笔支出会在多个信封中重复计算
+func checkEnvelopeScopeOverlap(tx *gorm.DB, userID uint, envelope *model.Budget) error {
+	// 锁定用户设置，同一用户的信封依次创建和修改，避免并发时产生重叠
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&model.UserSetting{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newBizError("请先在记账设置中开启信封预算模式")
+		}
+		return err
+	}
+
+	scope, err := resolveBudgetScope(tx, userID, envelope)
+	if err != nil {
+		return err
+	}
+	var others []model.Budget
+	if err := tx.Where("user_id = ? AND is_envelope = ? AND id <> ?", userID, true, envelope.ID).
+		Order("id ASC").Find(&others).Error; err != nil {
+		return err
+	}
+	for i := range others {
+		otherScope, err := resolveBudgetScope(tx, userID, &others[i])
+		if err != nil {
+			return err
+		}
+		for _, id := range scope.CategoryIDs {
+			if !containsUint(otherScope.CategoryIDs, id) {
+				continue
+			}
+			var category model.Category
+			if err := tx.Select("name").Where("id = ?", id).First(&category).Error; err != nil {
+				return err
+			}
+			return newBizError(fmt.Sprintf("分类 %s 已在信封 %s 的范围内，同一分类只能属于一个信封", category.Name, others[i].Name))
+		}
+	}
+	return nil
+}
+
+// envelopeMonthItem 计算信封在 monthStart 所在月份的结转、分配、支出和可用余额
+// 结转复用预算的结转计算 (both 方式，每月金额为分配金额)
+func envelopeMonthItem(userID uint, envelope *model.Budget, monthStart time.Time) (dto.EnvelopeItem, error) {
+	item := dto.EnvelopeItem{
+		BudgetID:   envelope.ID,
+		Name:       envelope.Name,
+		CategoryID: envelope.CategoryID,
+	}
+	if monthStart.Before(budgetFirstPeriodStart(envelope.StartDate)) {
+		return item, nil
+	}
+
+	budgetService := BookkeepingBudgetService{}
+	rollover, err := budgetService.budgetRollover(userID, envelope, monthStart)
+	if err != nil {
+		return item, err
+	}
+	if rollover != nil {
+		item.CarriedIn = rollover.CarriedAmount
+	}
+	if item.Assigned, err = budgetService.budgetCurrentAmount(envelope, monthStart); err != nil {
+		return item, err
+	}
+	monthEnd := monthStart.AddDate(0, 1, 0).Add(-time.Second)
+	if item.Spent, err = budgetService.budgetSpentAmount(userID, envelope, monthStart, monthEnd); err != nil {
+		return item, err
+	}
+	item.Spent = roundCent(item.Spent)
+	item.Available = roundCent(item.CarriedIn + item.Assigned - item.Spent)
+	item.IsOverspent = item.Available < 0
+	return item, nil
+}
+
+// envelopeAvailable 信封在 monthStart 所在月份的可用余额
+func envelopeAvailable(userID uint, envelope *model.Budget, monthStart time.Time) (float64, error) {
+	item, err := envelopeMonthItem(userID, envelope, monthStart)
+	if err != nil {
+		return 0, err
+	}
+	return item.Available, nil
+}
+
+// readyToAssign 待分配金额 = 开启信封模式时的金额 + 开启以来计入收支统计的收入 - 所有月份分配到信封的金额
+// 已删除信封的分配记录仍然保留，其中已花掉的部分不会退回待分配
+func readyToAssign(db *gorm.DB, setting *model.UserSetting) (float64, error) {
+	var income float64
+	if setting.EnvelopeStartDate != nil {
+		if err := db.Model(&model.Transaction{}).
+			Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND transaction_date >= ?",
+				setting.UserID, model.TransactionTypeIncome, false, *setting.EnvelopeStartDate).
+			Select("COALESCE(SUM(amount), 0)").Scan(&income).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	var assigned float64
+	if err := db.Model(&model.EnvelopeAllocation{}).Where("user_id = ?", setting.UserID).
+		Select("COALESCE(SUM(amount), 0)").Scan(&assigned).Error; err != nil {
+		return 0, err
+	}
+	return roundCent(setting.EnvelopeOpeningAmount + income - assigned), nil
+}
+
+// envelopeAssignedByMonth 信封各月的分配金额，按月份 (YYYY-MM) 索引
+func envelopeAssignedByMonth(db *gorm.DB, budgetID uint) (map[string]float64, error) {
+	var allocations []model.EnvelopeAllocation
+	if err := db.Where("budget_id = ?", budgetID).Find(&allocations).Error; err != nil {
+		return nil, err
+	}
+	assigned := make(map[string]float64, len(allocations))
+	for _, allocation := range allocations {
+		assigned[allocation.Month] += allocation.Amount
+	}
+	return assigned, nil
+}
+
+// recordEnvelopeMove 保存资金移动记录，并相应增减来源和去向信封当月的分配金额
+func recordEnvelopeMove(tx *gorm.DB, move *model.EnvelopeMove) error {
+	if move.FromBudgetID != nil {
+		if err := addEnvelopeAllocation(tx, move.UserID, *move.FromBudgetID, move.Month, -move.Amount); err != nil {
+			return err
+		}
+	}
+	if move.ToBudgetID != nil {
+		if err := addEnvelopeAllocation(tx, move.UserID, *move.ToBudgetID, move.Month, move.Amount); err != nil {
+			return err
+		}
+	}
+	return tx.Create(move).Error
+}
+
+// addEnvelopeAllocation 增减信封在某个月的分配金额
+func addEnvelopeAllocation(tx *gorm.DB, userID, budgetID uint, month string, delta float64) error {
+	var allocation model.EnvelopeAllocation
+	err := tx.Where("budget_id = ? AND month = ?", budgetID, month).First(&allocation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Create(&model.EnvelopeAllocation{
+			UserID:   userID,
+			BudgetID: budgetID,
+			Month:    month,
+			Amount:   delta,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Model(&allocation).UpdateColumn("amount", gorm.Expr("amount + ?", delta)).Error
+}
+
+// settleEnvelopeBalance 将信封本月的可用余额退回待分配，余额为负时从待分配中补足，用于删除信封
+func settleEnvelopeBalance(tx *gorm.DB, userID, budgetID uint, balance float64, note string) error {
+	move := model.EnvelopeMove{
+		UserID: userID,
+		Month:  envelopeMonthKey(time.Now()),
+		Amount: roundCent(balance),
+		Note:   note,
+	}
+	if balance > 0 {
+		move.FromBudgetID = &budgetID
+	} else {
+		move.ToBudgetID = &budgetID
+		move.Amount = -move.Amount
+	}
+	return recordEnvelopeMove(tx, &move)
+}
+
+// envelopeMoveToResponse 资金移动记录转换为响应对象
+func envelopeMoveToResponse(move *model.EnvelopeMove, names map[uint]string) dto.EnvelopeMoveResponse {
+	response := dto.EnvelopeMoveResponse{
+		ID:           move.ID,
+		Month:        move.Month,
+		FromBudgetID: move.FromBudgetID,
+		ToBudgetID:   move.ToBudgetID,
+		Amount:       move.Amount,
+		Note:         move.Note,
+		CreatedAt:    move.CreatedAt,
+	}
+	if move.FromBudgetID != nil {
+		response.FromName = names[*move.FromBudgetID]
+	}
+	if move.ToBudgetID != nil {
+		response.ToName = names[*move.ToBudgetID]
+	}
+	return response
+}
+
+// parseEnvelopeMonth 解析 YYYY-MM 格式的月份，返回该月第一天，为空时为本月
+func parseEnvelopeMonth(month string) (time.Time, error) {
+	if month == "" {
+		return envelopeMonthStart(time.Now()), nil
+	}
+	t, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("月份格式错误，请使用YYYY-MM格式")
+	}
+	return t, nil
+}
+
+// envelopeMonthStart t 所在自然月的第一天
+func envelopeMonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+}
+
+// envelopeMonthKey t 所在的月份 (YYYY-MM)
+func envelopeMonthKey(t time.Time) string {
+	return t.Format("2006-01")
+}
@@ -0,0 +1,27 @@
+package service
+
+import "errors"
+
+// bizError 业务校验错误，在数据库事务中返回时需要原样提示给用户
+type bizError struct {
+	msg string
+}
+
+// Error 实现error接口
+func (e *bizError) Error() string {
+	return e.msg
+}
+
+// newBizError 创建业务校验错误
+func newBizError(msg string) error {
+	return &bizError{msg: msg}
+}
+
+// userFacingError 业务校验错误原样返回，其他错误（数据库错误等）替换为通用提示
+func userFacingError(err error, fallback string) error {
+	var biz *bizError
+	if errors.As(err, &biz) {
+		return biz
+	}
+	return errors.New(fallback)
+}
@@ -0,0 +1,951 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 投资交易生成资金流水时使用的分类名称
+const (
+	investmentTradeCategoryName    = "投资买卖"
+	investmentDividendCategoryName = "投资收益"
+)
+
+// quantityEpsilon 持仓数量比较时允许的误差
+const quantityEpsilon = 1e-9
+
+// BookkeepingInvestmentService 投资账户持仓、交易和估值服务
+type BookkeepingInvestmentService struct{}
+
+// lotState 估值计算过程中的持仓批次
+type lotState struct {
+	quantity    float64
+	costPerUnit float64
+}
+
+// holdingState 估值计算过程中单个证券的持仓状态
+type holdingState struct {
+	lots         []lotState
+	realizedGain float64
+	dividends    float64
+	lastPrice    float64
+	lastDate     time.Time
+}
+
+// quantity 返回当前持仓数量
+func (h *holdingState) quantity() float64 {
+	var total float64
+	for _, lot := range h.lots {
+		total += lot.quantity
+	}
+	return total
+}
+
+// costBasis 返回当前持仓成本
+func (h *holdingState) costBasis() float64 {
+	var total float64
+	for _, lot := range h.lots {
+		total += lot.quantity * lot.costPerUnit
+	}
+	return total
+}
+
+// cashFlow 计算XIRR使用的现金流，投入为负，收回为正
+type cashFlow struct {
+	date   time.Time
+	amount float64
+}
+
+// CreateSecurity 创建证券
+func (s *BookkeepingInvestmentService) CreateSecurity(userID uint, req dto.CreateSecurityRequest) (*dto.SecurityResponse, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+
+	var existing model.Security
+	if err := global.DB.Where("user_id = ? AND symbol = ?", userID, symbol).First(&existing).Error; err == nil {
+		return nil, errors.New("该证券代码已存在")
+	}
+
+	security := model.Security{
+		UserID: userID,
+		Symbol: symbol,
+		Name:   req.Name,
+		Kind:   model.SecurityKind(req.Kind),
+		Remark: req.Remark,
+	}
+	if err := global.DB.Create(&security).Error; err != nil {
+		global.Logger.Error("Failed to create security: " + err.Error())
+		return nil, errors.New("创建证券失败：数据库错误")
+	}
+
+	response := s.securityToResponse(&security, nil)
+	return &response, nil
+}
+
+// ListSecurities 获取用户的证券列表（含最新价格）
+func (s *BookkeepingInvestmentService) ListSecurities(userID uint) ([]dto.SecurityResponse, error) {
+	var securities []model.Security
+	if err := global.DB.Where("user_id = ?", userID).Order("symbol ASC").Find(&securities).Error; err != nil {
+		global.Logger.Error("Failed to list securities: " + err.Error())
+		return nil, errors.New("获取证券列表失败：数据库错误")
+	}
+
+	responses := make([]dto.SecurityResponse, 0, len(securities))
+	for i := range securities {
+		price, err := s.latestPrice(securities[i].ID, time.Now())
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to get latest price for security %d: %s", securities[i].ID, err.Error()))
+		}
+		responses = append(responses, s.securityToResponse(&securities[i], price))
+	}
+
+	return responses, nil
+}
+
+// SavePrices 批量保存证券价格，同一证券同一日期的价格会被覆盖
+// source: 价格来源 (api, csv)
+func (s *BookkeepingInvestmentService) SavePrices(userID uint, items []dto.SecurityPriceItem, source string) (*dto.SavePricesResponse, error) {
+	var securities []model.Security
+	if err := global.DB.Where("user_id = ?", userID).Find(&securities).Error; err != nil {
+		global.Logger.Error("Failed to load securities: " + err.Error())
+		return nil, errors.New("保存价格失败：数据库错误")
+	}
+	byID := make(map[uint]uint, len(securities))
+	bySymbol := make(map[string]uint, len(securities))
+	for _, security := range securities {
+		byID[security.ID] = security.ID
+		bySymbol[security.Symbol] = security.ID
+	}
+
+	result := &dto.SavePricesResponse{}
+	prices := make([]model.SecurityPrice, 0, len(items))
+	for i, item := range items {
+		securityID, ok := byID[item.SecurityID]
+		if !ok {
+			securityID, ok = bySymbol[strings.ToUpper(strings.TrimSpace(item.Symbol))]
+		}
+		if !ok {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("第%d条：证券不存在", i+1))
+			continue
+		}
+
+		priceDate, err := time.Parse("2006-01-02", strings.TrimSpace(item.Date))
+		if err != nil {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("第%d条：日期格式错误，请使用YYYY-MM-DD格式", i+1))
+			continue
+		}
+		if item.Price <= 0 {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("第%d条：价格必须大于0", i+1))
+			continue
+		}
+
+		prices = append(prices, model.SecurityPrice{
+			UserID:     userID,
+			SecurityID: securityID,
+			PriceDate:  priceDate,
+			Price:      item.Price,
+			Source:     source,
+		})
+	}
+
+	if len(prices) > 0 {
+		err := global.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "security_id"}, {Name: "price_date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"price", "source", "updated_at"}),
+		}).CreateInBatches(&prices, 500).Error
+		if err != nil {
+			global.Logger.Error("Failed to save security prices: " + err.Error())
+			return nil, errors.New("保存价格失败：数据库错误")
+		}
+	}
+	result.Saved = len(prices)
+
+	return result, nil
+}
+
+// ImportPricesCSV 从CSV导入证券价格
+// CSV每行依次为：证券代码, 日期 (YYYY-MM-DD), 价格；首行为表头时自动跳过
+func (s *BookkeepingInvestmentService) ImportPricesCSV(userID uint, reader io.Reader) (*dto.SavePricesResponse, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, errors.New("CSV文件格式错误：" + err.Error())
+	}
+
+	items := make([]dto.SecurityPriceItem, 0, len(records))
+	for i, record := range records {
+		if len(record) < 3 {
+			continue
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			// 首行表头
+			if i == 0 {
+				continue
+			}
+			price = 0
+		}
+		items = append(items, dto.SecurityPriceItem{
+			Symbol: record[0],
+			Date:   record[1],
+			Price:  price,
+		})
+	}
+	if len(items) == 0 {
+		return nil, errors.New("CSV文件中没有有效的价格数据")
+	}
+
+	result, err := s.SavePrices(userID, items, "csv")
+	if err != nil {
+		return nil, err
+	}
+
+	// 导入完成通知
+	notifyImportCompleted(userID, "security_prices", "证券价格导入完成",
+		fmt.Sprintf("从CSV导入证券价格：保存 %d 条，跳过 %d 条", result.Saved, result.Skipped))
+	return result, nil
+}
+
+// ListPrices 获取证券的历史价格
+func (s *BookkeepingInvestmentService) ListPrices(userID, securityID uint, startDate, endDate string) ([]dto.SecurityPriceResponse, error) {
+	var security model.Security
+	if err := global.DB.Where("id = ? AND user_id = ?", securityID, userID).First(&security).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("证券不存在")
+		}
+		return nil, errors.New("获取价格失败：数据库错误")
+	}
+
+	query := global.DB.Where("security_id = ?", securityID)
+	if startDate != "" {
+		query = query.Where("price_date >= ?", startDate)
+	}
+	if endDate != "" {
+		query = query.Where("price_date <= ?", endDate)
+	}
+
+	var prices []model.SecurityPrice
+	if err := query.Order("price_date DESC").Limit(1000).Find(&prices).Error; err != nil {
+		global.Logger.Error("Failed to list security prices: " + err.Error())
+		return nil, errors.New("获取价格失败：数据库错误")
+	}
+
+	responses := make([]dto.SecurityPriceResponse, 0, len(prices))
+	for _, price := range prices {
+		responses = append(responses, dto.SecurityPriceResponse{
+			Date:   price.PriceDate.Format("2006-01-02"),
+			Price:  price.Price,
+			Source: price.Source,
+		})
+	}
+	return responses, nil
+}
+
+// CreateTrade 在投资账户中记录一笔投资交易
+// 买入形成新的持仓批次，卖出按先进先出扣减批次并计算已实现收益，拆股按比例调整批次数量和单位成本；
+// 买入、卖出和分红同时生成资金流水，买卖流水不计入收支统计
+func (s *BookkeepingInvestmentService) CreateTrade(userID, accountID uint, req dto.CreateInvestmentTradeRequest) (*dto.InvestmentTradeResponse, error) {
+	account, err := s.findInvestmentAccount(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsArchived {
+		return nil, errors.New("该账户已归档，不能记录新的交易")
+	}
+
+	var security model.Security
+	if err := global.DB.Where("id = ? AND user_id = ?", req.SecurityID, userID).First(&security).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("证券不存在")
+		}
+		return nil, errors.New("记录投资交易失败：数据库错误")
+	}
+
+	tradeDate, err := time.Parse("2006-01-02", req.TradeDate)
+	if err != nil {
+		return nil, errors.New("交易日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	trade := model.InvestmentTrade{
+		UserID:     userID,
+		AccountID:  account.ID,
+		SecurityID: security.ID,
+		Type:       model.InvestmentTradeType(req.Type),
+		TradeDate:  tradeDate,
+		Fee:        req.Fee,
+		Notes:      req.Notes,
+	}
+
+	switch trade.Type {
+	case model.InvestmentTradeBuy, model.InvestmentTradeSell:
+		if req.Quantity <= 0 || req.Price <= 0 {
+			return nil, errors.New("买入和卖出必须填写数量和成交价")
+		}
+		trade.Quantity = req.Quantity
+		trade.Price = req.Price
+		if trade.Type == model.InvestmentTradeBuy {
+			trade.Amount = math.Round((req.Quantity*req.Price+req.Fee)*100) / 100
+		} else {
+			trade.Amount = math.Round((req.Quantity*req.Price-req.Fee)*100) / 100
+		}
+	case model.InvestmentTradeDividend:
+		if req.Amount <= 0 {
+			return nil, errors.New("分红必须填写金额")
+		}
+		trade.Amount = req.Amount
+	case model.InvestmentTradeSplit:
+		if req.SplitRatio <= 0 {
+			return nil, errors.New("拆股必须填写拆股比例")
+		}
+		trade.SplitRatio = req.SplitRatio
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		var lotSales []model.InvestmentLotSale
+		switch trade.Type {
+		case model.InvestmentTradeSell:
+			gain, sales, err := s.consumeLots(tx, &trade)
+			if err != nil {
+				return err
+			}
+			trade.RealizedGain = gain
+			lotSales = sales
+		case model.InvestmentTradeSplit:
+			if err := s.splitLots(tx, &trade); err != nil {
+				return err
+			}
+		}
+
+		if trade.Type != model.InvestmentTradeSplit {
+			transaction, err := s.createCashTransaction(tx, &trade, &security, req.CategoryID)
+			if err != nil {
+				return err
+			}
+			trade.TransactionID = &transaction.ID
+		}
+
+		if err := tx.Create(&trade).Error; err != nil {
+			return err
+		}
+
+		if trade.Type == model.InvestmentTradeBuy {
+			lot := model.InvestmentLot{
+				UserID:            userID,
+				AccountID:         account.ID,
+				SecurityID:        security.ID,
+				TradeID:           trade.ID,
+				AcquiredDate:      trade.TradeDate,
+				OriginalQuantity:  trade.Quantity,
+				RemainingQuantity: trade.Quantity,
+				CostPerUnit:       trade.Amount / trade.Quantity,
+			}
+			if err := tx.Create(&lot).Error; err != nil {
+				return err
+			}
+		}
+		for i := range lotSales {
+			lotSales[i].TradeID = trade.ID
+		}
+		if len(lotSales) > 0 {
+			if err := tx.Create(&lotSales).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create investment trade: " + err.Error())
+		if errors.Is(err, errInsufficientHoldings) {
+			return nil, err
+		}
+		return nil, userFacingError(err, "记录投资交易失败：数据库错误")
+	}
+
+	trade.Security = &security
+	response := s.tradeToResponse(&trade)
+	return &response, nil
+}
+
+// errInsufficientHoldings 卖出数量超过交易日期的持仓
+var errInsufficientHoldings = errors.New("卖出数量超过交易日期的持仓")
+
+// consumeLots 按先进先出扣减交易日期当天及之前买入的持仓批次，返回卖出实现的收益和各批次的扣减明细 (未设置交易ID)
+// 补录的历史卖出不会扣减之后才买入的批次，与 replayTrades 按日期回放的持仓一致
+func (s *BookkeepingInvestmentService) consumeLots(tx *gorm.DB, trade *model.InvestmentTrade) (float64, []model.InvestmentLotSale, error) {
+	var lots []model.InvestmentLot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account_id = ? AND security_id = ? AND remaining_quantity > 0 AND acquired_date <= ?",
+			trade.AccountID, trade.SecurityID, trade.TradeDate).
+		Order("acquired_date ASC, id ASC").Find(&lots).Error; err != nil {
+		return 0, nil, err
+	}
+
+	states := make([]lotState, len(lots))
+	for i := range lots {
+		states[i] = lotState{quantity: lots[i].RemainingQuantity, costPerUnit: lots[i].CostPerUnit}
+	}
+	used, cost, shortfall := consumeLotsFIFO(states, trade.Quantity)
+	if shortfall > quantityEpsilon {
+		return 0, nil, errInsufficientHoldings
+	}
+	var sales []model.InvestmentLotSale
+	for i := range used {
+		if used[i] == 0 {
+			continue
+		}
+		if err := tx.Model(&lots[i]).Update("remaining_quantity", lots[i].RemainingQuantity-used[i]).Error; err != nil {
+			return 0, nil, err
+		}
+		sales = append(sales, model.InvestmentLotSale{LotID: lots[i].ID, Quantity: used[i]})
+	}
+
+	return math.Round((trade.Amount-cost)*100) / 100, sales, nil
+}
+
+// consumeLotsFIFO 按先进先出从批次 (按买入顺序排列) 中扣减 quantity
+// 返回每个批次扣减的数量、扣减部分的成本，以及持仓不足时未能扣减的数量
+func consumeLotsFIFO(lots []lotState, quantity float64) ([]float64, float64, float64) {
+	used := make([]float64, len(lots))
+	remaining := quantity
+	var cost float64
+	for i := range lots {
+		if remaining <= quantityEpsilon {
+			break
+		}
+		used[i] = math.Min(lots[i].quantity, remaining)
+		cost += used[i] * lots[i].costPerUnit
+		remaining -= used[i]
+	}
+	return used, cost, math.Max(remaining, 0)
+}
+
+// splitLots 按拆股比例调整交易日期当天及之前买入的持仓批次的数量和单位成本，之后买入的批次已是拆股后的价格
+func (s *BookkeepingInvestmentService) splitLots(tx *gorm.DB, trade *model.InvestmentTrade) error {
+	return tx.Model(&model.InvestmentLot{}).
+		Where("account_id = ? AND security_id = ? AND remaining_quantity > 0 AND acquired_date <= ?",
+			trade.AccountID, trade.SecurityID, trade.TradeDate).
+		Updates(map[string]interface{}{
+			"original_quantity":  gorm.Expr("original_quantity * ?", trade.SplitRatio),
+			"remaining_quantity": gorm.Expr("remaining_quantity * ?", trade.SplitRatio),
+			"cost_per_unit":      gorm.Expr("cost_per_unit / ?", trade.SplitRatio),
+		}).Error
+}
+
+// DeleteTrade 删除证券最近记录的一笔交易，撤销它对持仓批次的调整并删除生成的资金流水
+// 之后记录的交易可能依赖它调整后的批次，所以同一证券只能从最近记录的交易开始依次删除
+func (s *BookkeepingInvestmentService) DeleteTrade(userID, accountID, tradeID uint) error {
+	account, err := s.findInvestmentAccount(userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		var trade model.InvestmentTrade
+		if err := tx.Where("id = ? AND account_id = ? AND user_id = ?", tradeID, account.ID, userID).First(&trade).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("投资交易不存在")
+			}
+			return err
+		}
+		var latest model.InvestmentTrade
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_id = ? AND security_id = ?", trade.AccountID, trade.SecurityID).
+			Order("id DESC").First(&latest).Error; err != nil {
+			return err
+		}
+		if latest.ID != trade.ID {
+			return newBizError("只能删除该证券最近记录的一笔交易")
+		}
+
+		switch trade.Type {
+		case model.InvestmentTradeBuy:
+			if err := tx.Where("trade_id = ?", trade.ID).Delete(&model.InvestmentLot{}).Error; err != nil {
+				return err
+			}
+		case model.InvestmentTradeSell:
+			if err := s.restoreLots(tx, &trade); err != nil {
+				return err
+			}
+		case model.InvestmentTradeSplit:
+			if err := s.unsplitLots(tx, &trade); err != nil {
+				return err
+			}
+		}
+
+		if trade.TransactionID != nil {
+			if err := deleteTransactions(tx, userID, []uint{*trade.TransactionID}); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&trade).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete investment trade: " + err.Error())
+		return userFacingError(err, "删除投资交易失败：数据库错误")
+	}
+	return nil
+}
+
+// restoreLots 按扣减明细恢复卖出扣减的持仓批次
+func (s *BookkeepingInvestmentService) restoreLots(tx *gorm.DB, trade *model.InvestmentTrade) error {
+	var sales []model.InvestmentLotSale
+	if err := tx.Where("trade_id = ?", trade.ID).Find(&sales).Error; err != nil {
+		return err
+	}
+	if len(sales) == 0 {
+		return newBizError("该卖出交易没有批次扣减明细，不能删除")
+	}
+	for _, sale := range sales {
+		if err := tx.Model(&model.InvestmentLot{}).Where("id = ?", sale.LotID).
+			UpdateColumn("remaining_quantity", gorm.Expr("remaining_quantity + ?", sale.Quantity)).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("trade_id = ?", trade.ID).Delete(&model.InvestmentLotSale{}).Error
+}
+
+// unsplitLots 撤销拆股，与 splitLots 调整的是同一批持仓批次
+func (s *BookkeepingInvestmentService) unsplitLots(tx *gorm.DB, trade *model.InvestmentTrade) error {
+	return tx.Model(&model.InvestmentLot{}).
+		Where("account_id = ? AND security_id = ? AND remaining_quantity > 0 AND acquired_date <= ?",
+			trade.AccountID, trade.SecurityID, trade.TradeDate).
+		Updates(map[string]interface{}{
+			"original_quantity":  gorm.Expr("original_quantity / ?", trade.SplitRatio),
+			"remaining_quantity": gorm.Expr("remaining_quantity / ?", trade.SplitRatio),
+			"cost_per_unit":      gorm.Expr("cost_per_unit * ?", trade.SplitRatio),
+		}).Error
+}
+
+// createCashTransaction 生成投资交易对应的资金流水
+// 买入为支出、卖出为收入，均不计入收支统计；分红为收入，计入收支统计
+func (s *BookkeepingInvestmentService) createCashTransaction(tx *gorm.DB, trade *model.InvestmentTrade, security *model.Security, dividendCategoryID uint) (*model.Transaction, error) {
+	transaction := model.Transaction{
+		UserID:          trade.UserID,
+		AccountID:       trade.AccountID,
+		Amount:          trade.Amount,
+		TransactionDate: trade.TradeDate,
+		PayeePayer:      security.Name,
+	}
+
+	switch trade.Type {
+	case model.InvestmentTradeBuy:
+		category, err := ensureCategory(tx, trade.UserID, model.CategoryTypeExpense, investmentTradeCategoryName)
+		if err != nil {
+			return nil, err
+		}
+		transaction.Type = model.TransactionTypeExpense
+		transaction.CategoryID = category.ID
+		transaction.ExcludeFromStats = true
+		transaction.Notes = fmt.Sprintf("买入 %s %g份", security.Symbol, trade.Quantity)
+	case model.InvestmentTradeSell:
+		category, err := ensureCategory(tx, trade.UserID, model.CategoryTypeIncome, investmentTradeCategoryName)
+		if err != nil {
+			return nil, err
+		}
+		transaction.Type = model.TransactionTypeIncome
+		transaction.CategoryID = category.ID
+		transaction.ExcludeFromStats = true
+		transaction.Notes = fmt.Sprintf("卖出 %s %g份", security.Symbol, trade.Quantity)
+	case model.InvestmentTradeDividend:
+		categoryID := dividendCategoryID
+		if categoryID != 0 {
+			var category model.Category
+			if err := tx.Where("id = ? AND user_id = ? AND type = ?", categoryID, trade.UserID, model.CategoryTypeIncome).First(&category).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, newBizError("分类不存在或不是收入分类")
+				}
+				return nil, err
+			}
+			if category.IsArchived {
+				return nil, newBizError("该分类已归档，不能用于新的交易")
+			}
+		} else {
+			category, err := ensureCategory(tx, trade.UserID, model.CategoryTypeIncome, investmentDividendCategoryName)
+			if err != nil {
+				return nil, err
+			}
+			categoryID = category.ID
+		}
+		transaction.Type = model.TransactionTypeIncome
+		transaction.CategoryID = categoryID
+		transaction.Notes = fmt.Sprintf("%s 分红", security.Symbol)
+	}
+
+	if trade.Notes != "" {
+		transaction.Notes = trade.Notes
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// ListTrades 获取投资账户的交易记录
+// securityID: 可选，按证券筛选
+func (s *BookkeepingInvestmentService) ListTrades(userID, accountID, securityID uint) ([]dto.InvestmentTradeResponse, error) {
+	if _, err := s.findInvestmentAccount(userID, accountID); err != nil {
+		return nil, err
+	}
+
+	query := global.DB.Preload("Security").Where("user_id = ? AND account_id = ?", userID, accountID)
+	if securityID > 0 {
+		query = query.Where("security_id = ?", securityID)
+	}
+
+	var trades []model.InvestmentTrade
+	if err := query.Order("trade_date DESC, id DESC").Find(&trades).Error; err != nil {
+		global.Logger.Error("Failed to list investment trades: " + err.Error())
+		return nil, errors.New("获取投资交易失败：数据库错误")
+	}
+
+	responses := make([]dto.InvestmentTradeResponse, 0, len(trades))
+	for i := range trades {
+		responses = append(responses, s.tradeToResponse(&trades[i]))
+	}
+	return responses, nil
+}
+
+// GetValuation 获取投资账户在指定日期的估值
+// 包括持仓市值、持仓成本、已实现和未实现收益，以及资金加权年化收益率 (XIRR)
+// date: 估值日期 (YYYY-MM-DD)，为空时表示今天
+func (s *BookkeepingInvestmentService) GetValuation(userID, accountID uint, date string) (*dto.InvestmentValuationResponse, error) {
+	account, err := s.findInvestmentAccount(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if date != "" {
+		if day, err = time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			return nil, errors.New("日期格式错误，请使用YYYY-MM-DD格式")
+		}
+	}
+
+	var trades []model.InvestmentTrade
+	if err := global.DB.Preload("Security").
+		Where("account_id = ? AND trade_date < ?", account.ID, day.AddDate(0, 0, 1).Format("2006-01-02")).
+		Order("trade_date ASC, id ASC").Find(&trades).Error; err != nil {
+		global.Logger.Error("Failed to load investment trades: " + err.Error())
+		return nil, errors.New("计算投资估值失败：数据库错误")
+	}
+
+	balanceService := BookkeepingBalanceService{}
+	cashBalance, err := balanceService.balanceAt(global.DB, &account, day)
+	if err != nil {
+		global.Logger.Error("Failed to calculate cash balance: " + err.Error())
+		return nil, errors.New("计算投资估值失败：数据库错误")
+	}
+
+	response := &dto.InvestmentValuationResponse{
+		AccountID:   account.ID,
+		AccountName: account.Name,
+		Date:        day.Format("2006-01-02"),
+		CashBalance: cashBalance,
+		Holdings:    []dto.HoldingItem{},
+	}
+
+	holdings := s.replayTrades(trades)
+	securities := make(map[uint]*model.Security)
+	flows := make([]cashFlow, 0, len(trades)+1)
+	for i := range trades {
+		if trades[i].Security != nil {
+			securities[trades[i].SecurityID] = trades[i].Security
+		}
+		switch trades[i].Type {
+		case model.InvestmentTradeBuy:
+			flows = append(flows, cashFlow{date: trades[i].TradeDate, amount: -trades[i].Amount})
+		case model.InvestmentTradeSell, model.InvestmentTradeDividend:
+			flows = append(flows, cashFlow{date: trades[i].TradeDate, amount: trades[i].Amount})
+		}
+	}
+
+	securityIDs := make([]uint, 0, len(holdings))
+	for securityID := range holdings {
+		securityIDs = append(securityIDs, securityID)
+	}
+	sort.Slice(securityIDs, func(i, j int) bool { return securityIDs[i] < securityIDs[j] })
+
+	for _, securityID := range securityIDs {
+		holding := holdings[securityID]
+		response.RealizedGain += holding.realizedGain
+		response.Dividends += holding.dividends
+
+		quantity := holding.quantity()
+		if quantity <= quantityEpsilon {
+			continue
+		}
+
+		price, priceDate := holding.lastPrice, holding.lastDate
+		latest, err := s.latestPrice(securityID, day)
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to get latest price for security %d: %s", securityID, err.Error()))
+		} else if latest != nil && !latest.PriceDate.Before(priceDate) {
+			price, priceDate = latest.Price, latest.PriceDate
+		}
+
+		item := dto.HoldingItem{
+			SecurityID:  securityID,
+			Quantity:    quantity,
+			CostBasis:   math.Round(holding.costBasis()*100) / 100,
+			Price:       price,
+			PriceDate:   priceDate.Format("2006-01-02"),
+			MarketValue: math.Round(quantity*price*100) / 100,
+		}
+		if security, ok := securities[securityID]; ok {
+			item.Symbol = security.Symbol
+			item.Name = security.Name
+		}
+		item.UnrealizedGain = item.MarketValue - item.CostBasis
+
+		response.MarketValue += item.MarketValue
+		response.CostBasis += item.CostBasis
+		response.UnrealizedGain += item.UnrealizedGain
+		response.Holdings = append(response.Holdings, item)
+	}
+	response.TotalValue = response.CashBalance + response.MarketValue
+
+	if response.MarketValue > 0 {
+		flows = append(flows, cashFlow{date: day, amount: response.MarketValue})
+	}
+	if rate, ok := xirr(flows); ok {
+		response.XIRR = &rate
+	}
+
+	return response, nil
+}
+
+// holdingsMarketValue 计算用户各投资账户在指定日期的持仓市值，key为账户ID
+// 用于净资产统计，投资账户的价值为现金余额加持仓市值
+func (s *BookkeepingInvestmentService) holdingsMarketValue(userID uint, day time.Time) (map[uint]float64, error) {
+	var trades []model.InvestmentTrade
+	if err := global.DB.Where("user_id = ? AND trade_date < ?", userID, day.AddDate(0, 0, 1).Format("2006-01-02")).
+		Order("trade_date ASC, id ASC").Find(&trades).Error; err != nil {
+		return nil, err
+	}
+
+	tradesByAccount := make(map[uint][]model.InvestmentTrade)
+	for _, trade := range trades {
+		tradesByAccount[trade.AccountID] = append(tradesByAccount[trade.AccountID], trade)
+	}
+
+	values := make(map[uint]float64, len(tradesByAccount))
+	for accountID, accountTrades := range tradesByAccount {
+		for securityID, holding := range s.replayTrades(accountTrades) {
+			quantity := holding.quantity()
+			if quantity <= quantityEpsilon {
+				continue
+			}
+			price := holding.lastPrice
+			latest, err := s.latestPrice(securityID, day)
+			if err != nil {
+				return nil, err
+			}
+			if latest != nil && !latest.PriceDate.Before(holding.lastDate) {
+				price = latest.Price
+			}
+			values[accountID] += math.Round(quantity*price*100) / 100
+		}
+	}
+	return values, nil
+}
+
+// replayTrades 按时间顺序重放交易，得到每个证券的持仓批次和收益，key为证券ID
+func (s *BookkeepingInvestmentService) replayTrades(trades []model.InvestmentTrade) map[uint]*holdingState {
+	holdings := make(map[uint]*holdingState)
+	for _, trade := range trades {
+		holding, ok := holdings[trade.SecurityID]
+		if !ok {
+			holding = &holdingState{}
+			holdings[trade.SecurityID] = holding
+		}
+
+		switch trade.Type {
+		case model.InvestmentTradeBuy:
+			holding.lots = append(holding.lots, lotState{quantity: trade.Quantity, costPerUnit: trade.Amount / trade.Quantity})
+			holding.lastPrice, holding.lastDate = trade.Price, trade.TradeDate
+		case model.InvestmentTradeSell:
+			used, _, _ := consumeLotsFIFO(holding.lots, trade.Quantity)
+			for i := range used {
+				holding.lots[i].quantity -= used[i]
+			}
+			holding.realizedGain += trade.RealizedGain
+			holding.lastPrice, holding.lastDate = trade.Price, trade.TradeDate
+		case model.InvestmentTradeDividend:
+			holding.dividends += trade.Amount
+		case model.InvestmentTradeSplit:
+			for i := range holding.lots {
+				holding.lots[i].quantity *= trade.SplitRatio
+				holding.lots[i].costPerUnit /= trade.SplitRatio
+			}
+			holding.lastPrice /= trade.SplitRatio
+		}
+	}
+	return holdings
+}
+
+// latestPrice 获取证券在指定日期（含）之前的最新价格，没有价格时返回nil
+func (s *BookkeepingInvestmentService) latestPrice(securityID uint, day time.Time) (*model.SecurityPrice, error) {
+	var price model.SecurityPrice
+	err := global.DB.Where("security_id = ? AND price_date <= ?", securityID, day.Format("2006-01-02")).
+		Order("price_date DESC").First(&price).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+// findInvestmentAccount 查询属于当前用户的投资账户
+func (s *BookkeepingInvestmentService) findInvestmentAccount(userID, accountID uint) (model.Account, error) {
+	var account model.Account
+	if err := global.DB.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return account, errors.New("账户不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get account: " + err.Error())
+		return account, errors.New("获取账户信息失败：数据库错误")
+	}
+	if account.Type != model.AccountTypeInvestment {
+		return account, errors.New("该账户不是投资账户")
+	}
+	return account, nil
+}
+
+// securityToResponse 辅助函数，将证券模型转换为响应对象
+func (s *BookkeepingInvestmentService) securityToResponse(security *model.Security, price *model.SecurityPrice) dto.SecurityResponse {
+	response := dto.SecurityResponse{
+		ID:        security.ID,
+		Symbol:    security.Symbol,
+		Name:      security.Name,
+		Kind:      string(security.Kind),
+		Remark:    security.Remark,
+		CreatedAt: security.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if price != nil {
+		response.LatestPrice = price.Price
+		response.PriceDate = price.PriceDate.Format("2006-01-02")
+	}
+	return response
+}
+
+// tradeToResponse 辅助函数，将投资交易模型转换为响应对象
+func (s *BookkeepingInvestmentService) tradeToResponse(trade *model.InvestmentTrade) dto.InvestmentTradeResponse {
+	response := dto.InvestmentTradeResponse{
+		ID:            trade.ID,
+		AccountID:     trade.AccountID,
+		SecurityID:    trade.SecurityID,
+		Type:          string(trade.Type),
+		TradeDate:     trade.TradeDate.Format("2006-01-02"),
+		Quantity:      trade.Quantity,
+		Price:         trade.Price,
+		Fee:           trade.Fee,
+		Amount:        trade.Amount,
+		SplitRatio:    trade.SplitRatio,
+		RealizedGain:  trade.RealizedGain,
+		TransactionID: trade.TransactionID,
+		Notes:         trade.Notes,
+		CreatedAt:     trade.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if trade.Security != nil {
+		response.Symbol = trade.Security.Symbol
+		response.SecurityName = trade.Security.Name
+	}
+	return response
+}
+
+// xirr 计算不规则间隔现金流的年化内部收益率
+// 先用牛顿法迭代，不收敛时退回二分法；现金流中必须同时存在投入和收回
+func xirr(flows []cashFlow) (float64, bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+
+	hasPositive, hasNegative := false, false
+	first := flows[0].date
+	for _, flow := range flows {
+		if flow.amount > 0 {
+			hasPositive = true
+		}
+		if flow.amount < 0 {
+			hasNegative = true
+		}
+		if flow.date.Before(first) {
+			first = flow.date
+		}
+	}
+	if !hasPositive || !hasNegative {
+		return 0, false
+	}
+
+	npv := func(rate float64) (float64, float64) {
+		var value, derivative float64
+		for _, flow := range flows {
+			years := flow.date.Sub(first).Hours() / 24 / 365
+			factor := math.Pow(1+rate, years)
+			value += flow.amount / factor
+			derivative -= years * flow.amount / (factor * (1 + rate))
+		}
+		return value, derivative
+	}
+
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		value, derivative := npv(rate)
+		if math.Abs(value) < 1e-7 {
+			return rate, true
+		}
+		if derivative == 0 {
+			break
+		}
+		next := rate - value/derivative
+		if next <= -0.9999 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < 1e-10 {
+			return next, true
+		}
+		rate = next
+	}
+
+	// 二分法兜底
+	low, high := -0.9999, 100.0
+	lowValue, _ := npv(low)
+	highValue, _ := npv(high)
+	if lowValue*highValue > 0 {
+		return 0, false
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		midValue, _ := npv(mid)
+		if math.Abs(midValue) < 1e-7 {
+			return mid, true
+		}
+		if lowValue*midValue < 0 {
+			high = mid
+		} else {
+			low, lowValue = mid, midValue
+		}
+	}
+	return (low + high) / 2, true
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dotdancer/gogofly/model"
+)
+
+func mustDate(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		t.Fatalf("parse date %q: %v", value, err)
+	}
+	return date
+}
+
+func TestXirr(t *testing.T) {
+	type xirrFlow struct {
+		date   string
+		amount float64
+	}
+	tests := []struct {
+		name   string
+		flows  []xirrFlow
+		want   float64
+		wantOK bool
+	}{
+		{
+			name:   "一年收益10%",
+			flows:  []xirrFlow{{"2023-01-01", -1000}, {"2024-01-01", 1100}},
+			want:   0.1,
+			wantOK: true,
+		},
+		{
+			name:   "一年亏损50%",
+			flows:  []xirrFlow{{"2023-01-01", -1000}, {"2024-01-01", 500}},
+			want:   -0.5,
+			wantOK: true,
+		},
+		{
+			name:   "一年翻三倍",
+			flows:  []xirrFlow{{"2023-01-01", -100}, {"2024-01-01", 300}},
+			want:   2,
+			wantOK: true,
+		},
+		{
+			name:   "分批投入和分红",
+			flows:  []xirrFlow{{"2023-01-01", -1000}, {"2023-04-15", -500}, {"2023-09-30", 30}, {"2024-03-01", 1650}},
+			wantOK: true,
+		},
+		{
+			name:   "现金流乱序",
+			flows:  []xirrFlow{{"2024-01-01", 1100}, {"2023-01-01", -1000}},
+			want:   0.1,
+			wantOK: true,
+		},
+		{
+			name:   "只有投入",
+			flows:  []xirrFlow{{"2023-01-01", -1000}, {"2023-06-01", -500}},
+			wantOK: false,
+		},
+		{
+			name:   "只有收回",
+			flows:  []xirrFlow{{"2023-01-01", 1000}, {"2023-06-01", 500}},
+			wantOK: false,
+		},
+		{
+			name:   "只有一笔现金流",
+			flows:  []xirrFlow{{"2023-01-01", -1000}},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flows := make([]cashFlow, 0, len(tt.flows))
+			first := time.Time{}
+			for _, flow := range tt.flows {
+				date := mustDate(t, flow.date)
+				flows = append(flows, cashFlow{date: date, amount: flow.amount})
+				if first.IsZero() || date.Before(first) {
+					first = date
+				}
+			}
+
+			rate, ok := xirr(flows)
+			if ok != tt.wantOK {
+				t.Fatalf("xirr() ok = %v, want %v (rate %v)", ok, tt.wantOK, rate)
+			}
+			if !ok {
+				return
+			}
+			if tt.want != 0 && math.Abs(rate-tt.want) > 1e-6 {
+				t.Errorf("xirr() = %v, want %v", rate, tt.want)
+			}
+
+			// 收益率代回后净现值应为0
+			var npv float64
+			for _, flow := range flows {
+				years := flow.date.Sub(first).Hours() / 24 / 365
+				npv += flow.amount / math.Pow(1+rate, years)
+			}
+			if math.Abs(npv) > 1e-4 {
+				t.Errorf("npv at xirr() = %v, want 0", npv)
+			}
+		})
+	}
+}
+
+func TestConsumeLotsFIFO(t *testing.T) {
+	lots := []lotState{{quantity: 10, costPerUnit: 100}, {quantity: 5, costPerUnit: 120}}
+
+	tests := []struct {
+		name          string
+		lots          []lotState
+		quantity      float64
+		wantUsed      []float64
+		wantCost      float64
+		wantShortfall float64
+	}{
+		{"部分扣减第一批", lots, 4, []float64{4, 0}, 400, 0},
+		{"正好扣完第一批", lots, 10, []float64{10, 0}, 1000, 0},
+		{"跨批次部分扣减", lots, 12, []float64{10, 2}, 1240, 0},
+		{"全部卖出", lots, 15, []float64{10, 5}, 1600, 0},
+		{"超过持仓", lots, 20, []float64{10, 5}, 1600, 5},
+		{"跳过已扣完的批次", []lotState{{quantity: 0, costPerUnit: 100}, {quantity: 5, costPerUnit: 120}}, 3, []float64{0, 3}, 360, 0},
+		{"没有持仓", nil, 1, []float64{}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used, cost, shortfall := consumeLotsFIFO(tt.lots, tt.quantity)
+			if len(used) != len(tt.wantUsed) {
+				t.Fatalf("used = %v, want %v", used, tt.wantUsed)
+			}
+			for i := range used {
+				if math.Abs(used[i]-tt.wantUsed[i]) > quantityEpsilon {
+					t.Errorf("used = %v, want %v", used, tt.wantUsed)
+					break
+				}
+			}
+			if math.Abs(cost-tt.wantCost) > 1e-9 {
+				t.Errorf("cost = %v, want %v", cost, tt.wantCost)
+			}
+			if math.Abs(shortfall-tt.wantShortfall) > quantityEpsilon {
+				t.Errorf("shortfall = %v, want %v", shortfall, tt.wantShortfall)
+			}
+		})
+	}
+}
+
+func TestReplayTradesFIFOAndSplit(t *testing.T) {
+	buy := func(date string, quantity, amount float64) model.InvestmentTrade {
+		return model.InvestmentTrade{SecurityID: 1, Type: model.InvestmentTradeBuy, TradeDate: mustDate(t, date),
+			Quantity: quantity, Price: amount / quantity, Amount: amount}
+	}
+	sell := func(date string, quantity float64) model.InvestmentTrade {
+		return model.InvestmentTrade{SecurityID: 1, Type: model.InvestmentTradeSell, TradeDate: mustDate(t, date), Quantity: quantity}
+	}
+	split := func(date string, ratio float64) model.InvestmentTrade {
+		return model.InvestmentTrade{SecurityID: 1, Type: model.InvestmentTradeSplit, TradeDate: mustDate(t, date), SplitRatio: ratio}
+	}
+
+	tests := []struct {
+		name         string
+		trades       []model.InvestmentTrade
+		wantQuantity float64
+		wantCost     float64
+		wantLots     []float64
+	}{
+		{
+			name:         "部分卖出第一批",
+			trades:       []model.InvestmentTrade{buy("2023-01-01", 10, 1000), buy("2023-02-01", 5, 600), sell("2023-03-01", 4)},
+			wantQuantity: 11,
+			wantCost:     1200,
+			wantLots:     []float64{6, 5},
+		},
+		{
+			name:         "拆股后跨批次卖出",
+			trades:       []model.InvestmentTrade{buy("2023-01-01", 10, 1000), buy("2023-02-01", 5, 600), split("2023-03-01", 2), sell("2023-04-01", 24)},
+			wantQuantity: 6,
+			wantCost:     360,
+			wantLots:     []float64{0, 6},
+		},
+		{
+			name:         "卖出后拆股只调整剩余数量，成本不变",
+			trades:       []model.InvestmentTrade{buy("2023-01-01", 10, 1000), sell("2023-02-01", 4), split("2023-03-01", 3)},
+			wantQuantity: 18,
+			wantCost:     600,
+			wantLots:     []float64{18},
+		},
+		{
+			name:         "拆股之后买入的批次不调整",
+			trades:       []model.InvestmentTrade{buy("2023-01-01", 10, 1000), split("2023-02-01", 2), buy("2023-03-01", 5, 300)},
+			wantQuantity: 25,
+			wantCost:     1300,
+			wantLots:     []float64{20, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holdings := new(BookkeepingInvestmentService).replayTrades(tt.trades)
+			holding := holdings[1]
+			if holding == nil {
+				t.Fatal("replayTrades() returned no holding")
+			}
+			if math.Abs(holding.quantity()-tt.wantQuantity) > 1e-9 {
+				t.Errorf("quantity = %v, want %v", holding.quantity(), tt.wantQuantity)
+			}
+			if math.Abs(holding.costBasis()-tt.wantCost) > 1e-9 {
+				t.Errorf("cost basis = %v, want %v", holding.costBasis(), tt.wantCost)
+			}
+			if len(holding.lots) != len(tt.wantLots) {
+				t.Fatalf("lots = %v, want quantities %v", holding.lots, tt.wantLots)
+			}
+			for i := range holding.lots {
+				if math.Abs(holding.lots[i].quantity-tt.wantLots[i]) > 1e-9 {
+					t.Errorf("lot %d quantity = %v, want %v", i, holding.lots[i].quantity, tt.wantLots[i])
+				}
+			}
+		})
+	}
+}
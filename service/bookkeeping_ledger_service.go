@@ -0,0 +1,281 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// ledgerUncategorizedName 修复分类问题时交易归入的分类名称
+const ledgerUncategorizedName = "未分类"
+
+// BookkeepingLedgerService 账本一致性检查服务
+type BookkeepingLedgerService struct{}
+
+// ledgerTransactionRow 检查交易关联关系时的查询结果
+type ledgerTransactionRow struct {
+	TransactionID uint
+	UserID        uint
+	Type          model.TransactionType
+	AccountID     uint
+	CategoryID    uint
+	RefID         *uint   // 关联的账户或分类ID，为空表示记录不存在
+	RefUserID     *uint   // 关联记录所属的用户ID
+	RefDeleted    bool    // 关联记录是否已被删除
+	RefType       *string // 关联分类的类型
+}
+
+// Check 检查账本一致性，userID为0时检查所有用户
+// repair为true时修复可以自动修复的问题：恢复被删除的账户、将分类异常的交易归入"未分类"，最后重新计算余额
+func (s *BookkeepingLedgerService) Check(userID uint, repair bool) (*dto.LedgerCheckReport, error) {
+	report := &dto.LedgerCheckReport{
+		UserID:    userID,
+		Repair:    repair,
+		Issues:    make([]dto.LedgerIssue, 0),
+		CheckedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	scope := func(column string) func(db *gorm.DB) *gorm.DB {
+		return func(db *gorm.DB) *gorm.DB {
+			if userID != 0 {
+				return db.Where(column+" = ?", userID)
+			}
+			return db
+		}
+	}
+
+	var accountCount, transactionCount int64
+	if err := global.DB.Model(&model.Account{}).Scopes(scope("user_id")).Count(&accountCount).Error; err != nil {
+		global.Logger.Error("Failed to count accounts for ledger check: " + err.Error())
+		return nil, errors.New("账本检查失败：数据库错误")
+	}
+	if err := global.DB.Model(&model.Transaction{}).Scopes(scope("user_id")).Count(&transactionCount).Error; err != nil {
+		global.Logger.Error("Failed to count transactions for ledger check: " + err.Error())
+		return nil, errors.New("账本检查失败：数据库错误")
+	}
+	report.CheckedAccounts = int(accountCount)
+	report.CheckedTransactions = int(transactionCount)
+
+	checks := []func(func(string) func(*gorm.DB) *gorm.DB) ([]dto.LedgerIssue, error){
+		s.checkAccounts,
+		s.checkCategories,
+		s.checkBalances,
+	}
+	for _, check := range checks {
+		issues, err := check(scope)
+		if err != nil {
+			global.Logger.Error("Failed to run ledger check: " + err.Error())
+			return nil, errors.New("账本检查失败：数据库错误")
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+	report.IssueCount = len(report.Issues)
+
+	if repair {
+		s.repair(report)
+	}
+	return report, nil
+}
+
+// checkAccounts 检查交易关联的账户是否存在且属于同一用户
+func (s *BookkeepingLedgerService) checkAccounts(scope func(string) func(*gorm.DB) *gorm.DB) ([]dto.LedgerIssue, error) {
+	var rows []ledgerTransactionRow
+	err := global.DB.Table("bookkeeping_transactions AS t").
+		Select("t.id AS transaction_id, t.user_id, t.type, t.account_id, t.category_id, a.id AS ref_id, a.user_id AS ref_user_id, a.deleted_at IS NOT NULL AS ref_deleted").
+		Joins("LEFT JOIN bookkeeping_accounts AS a ON a.id = t.account_id").
+		Where("t.deleted_at IS NULL").
+		Where("a.id IS NULL OR a.deleted_at IS NOT NULL OR a.user_id <> t.user_id").
+		Scopes(scope("t.user_id")).
+		Order("t.id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]dto.LedgerIssue, 0, len(rows))
+	for _, row := range rows {
+		issue := dto.LedgerIssue{
+			Type:          dto.LedgerIssueMissingAccount,
+			UserID:        row.UserID,
+			AccountID:     row.AccountID,
+			TransactionID: row.TransactionID,
+		}
+		switch {
+		case row.RefID == nil:
+			issue.Description = fmt.Sprintf("交易 %d 关联的账户 %d 不存在", row.TransactionID, row.AccountID)
+			issue.Suggestion = "将交易改到有效的账户，或删除该交易"
+		case row.RefUserID != nil && *row.RefUserID != row.UserID:
+			issue.Description = fmt.Sprintf("交易 %d 关联的账户 %d 属于其他用户", row.TransactionID, row.AccountID)
+			issue.Suggestion = "将交易改到本人的账户，或删除该交易"
+		default:
+			issue.Description = fmt.Sprintf("交易 %d 关联的账户 %d 已被删除", row.TransactionID, row.AccountID)
+			issue.Suggestion = "恢复被删除的账户并重新计算余额"
+			issue.Repairable = true
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// checkCategories 检查交易关联的分类是否存在、属于同一用户，且收支类型与交易类型一致
+func (s *BookkeepingLedgerService) checkCategories(scope func(string) func(*gorm.DB) *gorm.DB) ([]dto.LedgerIssue, error) {
+	var rows []ledgerTransactionRow
+	err := global.DB.Table("bookkeeping_transactions AS t").
+		Select("t.id AS transaction_id, t.user_id, t.type, t.account_id, t.category_id, c.id AS ref_id, c.user_id AS ref_user_id, c.deleted_at IS NOT NULL AS ref_deleted, c.type AS ref_type").
+		Joins("LEFT JOIN bookkeeping_categories AS c ON c.id = t.category_id").
+		Where("t.deleted_at IS NULL").
+		Where("c.id IS NULL OR c.deleted_at IS NOT NULL OR c.user_id <> t.user_id OR (t.type IN ? AND c.type <> t.type)",
+			[]model.TransactionType{model.TransactionTypeIncome, model.TransactionTypeExpense}).
+		Scopes(scope("t.user_id")).
+		Order("t.id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]dto.LedgerIssue, 0, len(rows))
+	for _, row := range rows {
+		issue := dto.LedgerIssue{
+			Type:          dto.LedgerIssueMissingCategory,
+			UserID:        row.UserID,
+			AccountID:     row.AccountID,
+			TransactionID: row.TransactionID,
+			CategoryID:    row.CategoryID,
+			Suggestion:    fmt.Sprintf("将交易归入\"%s\"分类后再手动调整", ledgerUncategorizedName),
+			Repairable:    true,
+		}
+		switch {
+		case row.RefID == nil:
+			issue.Description = fmt.Sprintf("交易 %d 关联的分类 %d 不存在", row.TransactionID, row.CategoryID)
+		case row.RefDeleted:
+			issue.Description = fmt.Sprintf("交易 %d 关联的分类 %d 已被删除", row.TransactionID, row.CategoryID)
+		case row.RefUserID != nil && *row.RefUserID != row.UserID:
+			issue.Description = fmt.Sprintf("交易 %d 关联的分类 %d 属于其他用户", row.TransactionID, row.CategoryID)
+		case row.RefType != nil:
+			issue.Type = dto.LedgerIssueCategoryTypeMismatch
+			issue.Description = fmt.Sprintf("交易 %d 的类型为 %s，但分类 %d 的类型为 %s", row.TransactionID, row.Type, row.CategoryID, *row.RefType)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// checkBalances 检查账户当前余额是否等于初始余额加上全部交易的影响值
+func (s *BookkeepingLedgerService) checkBalances(scope func(string) func(*gorm.DB) *gorm.DB) ([]dto.LedgerIssue, error) {
+	var accounts []model.Account
+	if err := global.DB.Scopes(scope("user_id")).Order("id").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	var sums []struct {
+		AccountID uint
+		Delta     float64
+	}
+	accountIDs := global.DB.Model(&model.Account{}).Select("id").Scopes(scope("user_id"))
+	if err := global.DB.Model(&model.Transaction{}).
+		Select("account_id, "+model.BalanceDeltaSQL+" AS delta").
+		Where("account_id IN (?)", accountIDs).
+		Group("account_id").Scan(&sums).Error; err != nil {
+		return nil, err
+	}
+	deltas := make(map[uint]float64, len(sums))
+	for _, sum := range sums {
+		deltas[sum.AccountID] = sum.Delta
+	}
+
+	issues := make([]dto.LedgerIssue, 0)
+	for _, account := range accounts {
+		expected := roundCent(account.InitialBalance + deltas[account.ID])
+		if math.Abs(expected-account.CurrentBalance) < 0.005 {
+			continue
+		}
+		issues = append(issues, dto.LedgerIssue{
+			Type:        dto.LedgerIssueBalanceMismatch,
+			UserID:      account.UserID,
+			AccountID:   account.ID,
+			Description: fmt.Sprintf("账户「%s」记录的当前余额为 %.2f，按交易记录计算应为 %.2f", account.Name, account.CurrentBalance, expected),
+			Suggestion:  "按初始余额和全部交易重新计算余额",
+			Repairable:  true,
+		})
+	}
+	return issues, nil
+}
+
+// repair 逐个修复可以自动修复的问题，每个问题在独立的数据库事务中修复，失败不影响其他问题
+// 余额在其他问题修复之后统一重新计算
+func (s *BookkeepingLedgerService) repair(report *dto.LedgerCheckReport) {
+	recalculate := make(map[uint]bool)
+	balanceIssues := make(map[uint]*dto.LedgerIssue)
+
+	for i := range report.Issues {
+		issue := &report.Issues[i]
+		if !issue.Repairable {
+			continue
+		}
+
+		var err error
+		switch issue.Type {
+		case dto.LedgerIssueMissingAccount:
+			err = global.DB.Unscoped().Model(&model.Account{}).
+				Where("id = ? AND user_id = ?", issue.AccountID, issue.UserID).
+				Update("deleted_at", nil).Error
+			recalculate[issue.AccountID] = true
+		case dto.LedgerIssueMissingCategory, dto.LedgerIssueCategoryTypeMismatch:
+			err = global.DB.Transaction(func(tx *gorm.DB) error {
+				return s.reassignUncategorized(tx, issue.UserID, issue.TransactionID)
+			})
+		case dto.LedgerIssueBalanceMismatch:
+			recalculate[issue.AccountID] = true
+			balanceIssues[issue.AccountID] = issue
+			continue
+		}
+		s.markRepaired(report, issue, err)
+	}
+
+	for accountID := range recalculate {
+		err := global.DB.Transaction(func(tx *gorm.DB) error {
+			_, _, err := model.RecalculateAccountBalance(tx, accountID)
+			return err
+		})
+		if issue, ok := balanceIssues[accountID]; ok {
+			s.markRepaired(report, issue, err)
+		} else if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to recalculate balance of restored account %d: %s", accountID, err.Error()))
+		}
+	}
+}
+
+// reassignUncategorized 将交易归入与交易类型一致的"未分类"分类，转账归入支出分类
+func (s *BookkeepingLedgerService) reassignUncategorized(tx *gorm.DB, userID, transactionID uint) error {
+	var transaction model.Transaction
+	if err := tx.Where("id = ? AND user_id = ?", transactionID, userID).First(&transaction).Error; err != nil {
+		return err
+	}
+
+	categoryType := model.CategoryTypeExpense
+	if transaction.Type == model.TransactionTypeIncome {
+		categoryType = model.CategoryTypeIncome
+	}
+	category, err := ensureCategory(tx, userID, categoryType, ledgerUncategorizedName)
+	if err != nil {
+		return err
+	}
+
+	// 只修改分类，不影响余额，跳过交易钩子
+	return tx.Model(&transaction).UpdateColumn("category_id", category.ID).Error
+}
+
+// markRepaired 记录问题的修复结果
+func (s *BookkeepingLedgerService) markRepaired(report *dto.LedgerCheckReport, issue *dto.LedgerIssue, err error) {
+	if err != nil {
+		global.Logger.Error(fmt.Sprintf("Failed to repair ledger issue %s: %s", issue.Type, err.Error()))
+		issue.RepairError = "修复失败：数据库错误"
+		return
+	}
+	issue.Repaired = true
+	report.RepairedCount++
+}
@@ -0,0 +1,733 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 贷款还款生成资金流水时使用的分类名称
+const (
+	loanRepaymentCategoryName = "贷款还款"
+	loanInterestCategoryName  = "贷款利息"
+)
+
+// BookkeepingLoanService 贷款管理服务
+type BookkeepingLoanService struct{}
+
+// CreateLoan 创建贷款
+// 未指定账户时自动创建一个贷款类型的负债账户，初始余额为负的贷款本金
+func (s *BookkeepingLoanService) CreateLoan(userID uint, req dto.CreateLoanRequest) (*dto.LoanResponse, error) {
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, errors.New("放款日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	if req.InterestCategoryID != nil {
+		if err := s.checkInterestCategory(userID, *req.InterestCategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	loan := model.Loan{
+		UserID:             userID,
+		Name:               req.Name,
+		Principal:          req.Principal,
+		AnnualRate:         req.AnnualRate,
+		TermMonths:         req.TermMonths,
+		Method:             model.LoanMethod(req.Method),
+		StartDate:          startDate,
+		InterestCategoryID: req.InterestCategoryID,
+		RemainingPrincipal: req.Principal,
+		RemainingTerm:      req.TermMonths,
+		Status:             model.LoanStatusActive,
+		Notes:              req.Notes,
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if req.AccountID != 0 {
+			var account model.Account
+			if err := tx.Where("id = ? AND user_id = ?", req.AccountID, userID).First(&account).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return newBizError("账户不存在或不属于您")
+				}
+				return err
+			}
+			if !account.IsLiability() {
+				return newBizError("贷款只能关联负债账户")
+			}
+			if account.IsArchived {
+				return newBizError("该账户已归档，不能关联贷款")
+			}
+			loan.Account = account
+		} else {
+			loan.Account = model.Account{
+				UserID:         userID,
+				Name:           req.Name,
+				Type:           model.AccountTypeLoan,
+				InitialBalance: -req.Principal,
+				Remark:         "创建贷款时自动创建",
+			}
+			if err := tx.Create(&loan.Account).Error; err != nil {
+				return err
+			}
+		}
+		loan.AccountID = loan.Account.ID
+
+		return tx.Omit("Account").Create(&loan).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create loan: " + err.Error())
+		return nil, userFacingError(err, "创建贷款失败：数据库错误")
+	}
+
+	response := s.loanToResponse(&loan)
+	return &response, nil
+}
+
+// ListLoans 获取用户的贷款列表
+// status: 可选，按状态筛选 (active, paid_off)
+func (s *BookkeepingLoanService) ListLoans(userID uint, status string) ([]dto.LoanResponse, error) {
+	query := global.DB.Preload("Account").Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var loans []model.Loan
+	if err := query.Order("start_date DESC").Find(&loans).Error; err != nil {
+		global.Logger.Error("Failed to list loans: " + err.Error())
+		return nil, errors.New("获取贷款列表失败：数据库错误")
+	}
+
+	responses := make([]dto.LoanResponse, 0, len(loans))
+	for i := range loans {
+		responses = append(responses, s.loanToResponse(&loans[i]))
+	}
+	return responses, nil
+}
+
+// GetLoan 获取单个贷款信息，包括剩余本金、累计已还利息和下一期还款
+func (s *BookkeepingLoanService) GetLoan(userID, loanID uint) (*dto.LoanResponse, error) {
+	loan, err := s.findLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.loanToResponse(&loan)
+	return &response, nil
+}
+
+// UpdateLoan 更新贷款的名称、利息分类和备注
+func (s *BookkeepingLoanService) UpdateLoan(userID, loanID uint, req dto.UpdateLoanRequest) (*dto.LoanResponse, error) {
+	loan, err := s.findLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Notes != nil {
+		updates["notes"] = *req.Notes
+	}
+	if req.InterestCategoryID != nil {
+		if *req.InterestCategoryID == 0 {
+			updates["interest_category_id"] = nil
+		} else {
+			if err := s.checkInterestCategory(userID, *req.InterestCategoryID); err != nil {
+				return nil, err
+			}
+			updates["interest_category_id"] = *req.InterestCategoryID
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := global.DB.Model(&loan).Updates(updates).Error; err != nil {
+			global.Logger.Error("Failed to update loan: " + err.Error())
+			return nil, errors.New("更新贷款失败：数据库错误")
+		}
+	}
+
+	return s.GetLoan(userID, loanID)
+}
+
+// DeleteLoan 删除贷款，已有还款记录的贷款需要先撤销还款
+// 关联的负债账户不会被删除
+func (s *BookkeepingLoanService) DeleteLoan(userID, loanID uint) error {
+	loan, err := s.findLoan(userID, loanID)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := global.DB.Model(&model.LoanRepayment{}).Where("loan_id = ?", loan.ID).Count(&count).Error; err != nil {
+		global.Logger.Error("Failed to count loan repayments: " + err.Error())
+		return errors.New("删除贷款失败：数据库错误")
+	}
+	if count > 0 {
+		return errors.New("该贷款已有还款记录，请先撤销还款后再删除")
+	}
+
+	if err := global.DB.Delete(&loan).Error; err != nil {
+		global.Logger.Error("Failed to delete loan: " + err.Error())
+		return errors.New("删除贷款失败：数据库错误")
+	}
+	return nil
+}
+
+// GetSchedule 获取贷款的完整还款计划
+// 已还部分取实际还款记录，未还部分按当前剩余本金和剩余期数推算
+func (s *BookkeepingLoanService) GetSchedule(userID, loanID uint) (*dto.LoanScheduleResponse, error) {
+	loan, err := s.findLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	var repayments []model.LoanRepayment
+	if err := global.DB.Where("loan_id = ?", loan.ID).Order("repayment_date ASC, id ASC").Find(&repayments).Error; err != nil {
+		global.Logger.Error("Failed to list loan repayments: " + err.Error())
+		return nil, errors.New("获取还款计划失败：数据库错误")
+	}
+
+	response := &dto.LoanScheduleResponse{
+		LoanID: loan.ID,
+		Method: string(loan.Method),
+		Items:  make([]dto.LoanScheduleItem, 0, len(repayments)+loan.RemainingTerm),
+	}
+
+	remaining := loan.Principal
+	for _, repayment := range repayments {
+		remaining = roundCent(remaining - repayment.Principal)
+		response.Items = append(response.Items, dto.LoanScheduleItem{
+			Period:             repayment.Period,
+			Kind:               string(repayment.Kind),
+			DueDate:            repayment.RepaymentDate.Format("2006-01-02"),
+			Payment:            repayment.Amount,
+			Principal:          repayment.Principal,
+			Interest:           repayment.Interest,
+			RemainingPrincipal: remaining,
+			Paid:               true,
+		})
+	}
+	response.Items = append(response.Items, projectLoanSchedule(&loan)...)
+
+	for _, item := range response.Items {
+		response.TotalPayment += item.Payment
+		response.TotalInterest += item.Interest
+	}
+	response.TotalPayment = roundCent(response.TotalPayment)
+	response.TotalInterest = roundCent(response.TotalInterest)
+
+	return response, nil
+}
+
+// ListRepayments 获取贷款的还款记录
+func (s *BookkeepingLoanService) ListRepayments(userID, loanID uint) ([]dto.LoanRepaymentResponse, error) {
+	loan, err := s.findLoan(userID, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	var repayments []model.LoanRepayment
+	if err := global.DB.Where("loan_id = ?", loan.ID).Order("repayment_date DESC, id DESC").Find(&repayments).Error; err != nil {
+		global.Logger.Error("Failed to list loan repayments: " + err.Error())
+		return nil, errors.New("获取还款记录失败：数据库错误")
+	}
+
+	responses := make([]dto.LoanRepaymentResponse, 0, len(repayments))
+	for i := range repayments {
+		responses = append(responses, s.repaymentToResponse(&repayments[i]))
+	}
+	return responses, nil
+}
+
+// Repay 按期还款
+// 本期应还本金和利息按还款计划计算：利息从还款账户记为支出并计入收支统计，
+// 本金从还款账户转入贷款账户，两笔流水均不计入收支统计
+func (s *BookkeepingLoanService) Repay(userID, loanID uint, req dto.LoanRepaymentRequest) (*dto.LoanRepaymentResponse, error) {
+	repaymentDate, err := time.Parse("2006-01-02", req.RepaymentDate)
+	if err != nil {
+		return nil, errors.New("还款日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	var repayment model.LoanRepayment
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		loan, err := s.lockActiveLoan(tx, userID, loanID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkFromAccount(tx, userID, &loan, req.FromAccountID); err != nil {
+			return err
+		}
+
+		principal, interest := loanNextPeriod(loan.Method, loan.RemainingPrincipal, loan.RemainingTerm, loanMonthlyRate(loan.AnnualRate))
+		repayment = model.LoanRepayment{
+			UserID:        userID,
+			LoanID:        loan.ID,
+			Kind:          model.LoanRepaymentRegular,
+			Period:        loan.PaidPeriods + 1,
+			RepaymentDate: repaymentDate,
+			Principal:     principal,
+			Interest:      interest,
+			Amount:        roundCent(principal + interest),
+			FromAccountID: req.FromAccountID,
+			Notes:         req.Notes,
+		}
+		if err := s.createRepaymentTransactions(tx, &loan, &repayment); err != nil {
+			return err
+		}
+		if err := tx.Create(&repayment).Error; err != nil {
+			return err
+		}
+
+		applyLoanRepayment(&loan, &repayment)
+		return s.saveLoanState(tx, &loan)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to repay loan: " + err.Error())
+		return nil, userFacingError(err, "还款失败：数据库错误")
+	}
+
+	response := s.repaymentToResponse(&repayment)
+	return &response, nil
+}
+
+// Prepay 提前还款，只归还本金
+// shorten_term: 月供（等额本金为每期本金）不变，缩短剩余期数；reduce_payment: 剩余期数不变，重新计算月供
+func (s *BookkeepingLoanService) Prepay(userID, loanID uint, req dto.LoanPrepaymentRequest) (*dto.LoanRepaymentResponse, error) {
+	repaymentDate, err := time.Parse("2006-01-02", req.RepaymentDate)
+	if err != nil {
+		return nil, errors.New("还款日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	var repayment model.LoanRepayment
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		loan, err := s.lockActiveLoan(tx, userID, loanID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkFromAccount(tx, userID, &loan, req.FromAccountID); err != nil {
+			return err
+		}
+
+		amount := roundCent(req.Amount)
+		if amount > loan.RemainingPrincipal {
+			return newBizError("提前还款金额不能超过剩余本金")
+		}
+
+		repayment = model.LoanRepayment{
+			UserID:         userID,
+			LoanID:         loan.ID,
+			Kind:           model.LoanRepaymentPrepayment,
+			RepaymentDate:  repaymentDate,
+			Principal:      amount,
+			Amount:         amount,
+			FromAccountID:  req.FromAccountID,
+			PrepaymentMode: model.LoanPrepaymentMode(req.Mode),
+			Notes:          req.Notes,
+		}
+		if err := s.createRepaymentTransactions(tx, &loan, &repayment); err != nil {
+			return err
+		}
+		if err := tx.Create(&repayment).Error; err != nil {
+			return err
+		}
+
+		applyLoanRepayment(&loan, &repayment)
+		return s.saveLoanState(tx, &loan)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to prepay loan: " + err.Error())
+		return nil, userFacingError(err, "提前还款失败：数据库错误")
+	}
+
+	response := s.repaymentToResponse(&repayment)
+	return &response, nil
+}
+
+// DeleteRepayment 撤销贷款最近一次还款 (按期还款或提前还款)
+// 删除还款记录和生成的本金、利息流水，贷款状态按剩余的还款记录从放款时重新推算，只能从最近一次还款开始依次撤销
+func (s *BookkeepingLoanService) DeleteRepayment(userID, loanID, repaymentID uint) (*dto.LoanResponse, error) {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var loan model.Loan
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", loanID, userID).First(&loan).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newBizError("贷款不存在或不属于您")
+			}
+			return err
+		}
+
+		var repayments []model.LoanRepayment
+		if err := tx.Where("loan_id = ?", loan.ID).Order("id ASC").Find(&repayments).Error; err != nil {
+			return err
+		}
+		if len(repayments) == 0 {
+			return newBizError("还款记录不存在")
+		}
+		last := repayments[len(repayments)-1]
+		if last.ID != repaymentID {
+			return newBizError("只能撤销最近一次还款")
+		}
+
+		ids := make([]uint, 0, 3)
+		for _, id := range []*uint{last.PrincipalTransactionID, last.LoanTransactionID, last.InterestTransactionID} {
+			if id != nil {
+				ids = append(ids, *id)
+			}
+		}
+		if err := deleteTransactions(tx, userID, ids); err != nil {
+			return err
+		}
+		if err := tx.Delete(&last).Error; err != nil {
+			return err
+		}
+
+		// 贷款本金、利率、期数和还款方式创建后不能修改，按剩余的还款记录重放即可得到撤销后的状态
+		loan.RemainingPrincipal = loan.Principal
+		loan.RemainingTerm = loan.TermMonths
+		loan.PaidPeriods = 0
+		loan.TotalInterestPaid = 0
+		loan.Status = model.LoanStatusActive
+		for i := range repayments[:len(repayments)-1] {
+			applyLoanRepayment(&loan, &repayments[i])
+		}
+		return s.saveLoanState(tx, &loan)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete loan repayment: " + err.Error())
+		return nil, userFacingError(err, "撤销还款失败：数据库错误")
+	}
+
+	return s.GetLoan(userID, loanID)
+}
+
+// applyLoanRepayment 按一笔还款更新贷款的剩余本金、剩余期数、已还期数和累计已还利息，结清状态由 saveLoanState 处理
+// 提前还款按缩短期数方式重算时使用还款前的剩余本金和期数
+func applyLoanRepayment(loan *model.Loan, repayment *model.LoanRepayment) {
+	remaining := roundCent(loan.RemainingPrincipal - repayment.Principal)
+	if repayment.Kind == model.LoanRepaymentPrepayment {
+		if repayment.PrepaymentMode == model.LoanPrepaymentShortenTerm && remaining > 0 {
+			loan.RemainingTerm = loanShortenedTerm(loan, remaining)
+		}
+	} else {
+		loan.RemainingTerm--
+		loan.PaidPeriods++
+		loan.TotalInterestPaid = roundCent(loan.TotalInterestPaid + repayment.Interest)
+	}
+	loan.RemainingPrincipal = remaining
+}
+
+// createRepaymentTransactions 生成还款对应的资金流水
+func (s *BookkeepingLoanService) createRepaymentTransactions(tx *gorm.DB, loan *model.Loan, repayment *model.LoanRepayment) error {
+	label := fmt.Sprintf("%s 第%d期", loan.Name, repayment.Period)
+	if repayment.Kind == model.LoanRepaymentPrepayment {
+		label = loan.Name + " 提前还款"
+	}
+
+	if repayment.Principal > 0 {
+		outCategory, err := ensureCategory(tx, loan.UserID, model.CategoryTypeExpense, loanRepaymentCategoryName)
+		if err != nil {
+			return err
+		}
+		inCategory, err := ensureCategory(tx, loan.UserID, model.CategoryTypeIncome, loanRepaymentCategoryName)
+		if err != nil {
+			return err
+		}
+
+		principalOut := model.Transaction{
+			UserID:           loan.UserID,
+			AccountID:        repayment.FromAccountID,
+			Type:             model.TransactionTypeExpense,
+			Amount:           repayment.Principal,
+			TransactionDate:  repayment.RepaymentDate,
+			CategoryID:       outCategory.ID,
+			PayeePayer:       loan.Name,
+			Notes:            label + "本金",
+			ExcludeFromStats: true,
+		}
+		if err := tx.Create(&principalOut).Error; err != nil {
+			return err
+		}
+		repayment.PrincipalTransactionID = &principalOut.ID
+
+		principalIn := model.Transaction{
+			UserID:           loan.UserID,
+			AccountID:        loan.AccountID,
+			Type:             model.TransactionTypeIncome,
+			Amount:           repayment.Principal,
+			TransactionDate:  repayment.RepaymentDate,
+			CategoryID:       inCategory.ID,
+			PayeePayer:       loan.Name,
+			Notes:            label + "本金",
+			ExcludeFromStats: true,
+		}
+		if err := tx.Create(&principalIn).Error; err != nil {
+			return err
+		}
+		repayment.LoanTransactionID = &principalIn.ID
+	}
+
+	if repayment.Interest > 0 {
+		var categoryID uint
+		if loan.InterestCategoryID != nil {
+			categoryID = *loan.InterestCategoryID
+		} else {
+			category, err := ensureCategory(tx, loan.UserID, model.CategoryTypeExpense, loanInterestCategoryName)
+			if err != nil {
+				return err
+			}
+			categoryID = category.ID
+		}
+
+		interest := model.Transaction{
+			UserID:          loan.UserID,
+			AccountID:       repayment.FromAccountID,
+			Type:            model.TransactionTypeExpense,
+			Amount:          repayment.Interest,
+			TransactionDate: repayment.RepaymentDate,
+			CategoryID:      categoryID,
+			PayeePayer:      loan.Name,
+			Notes:           label + "利息",
+		}
+		if err := tx.Create(&interest).Error; err != nil {
+			return err
+		}
+		repayment.InterestTransactionID = &interest.ID
+	}
+	return nil
+}
+
+// saveLoanState 保存贷款的还款状态，剩余本金归零时标记为已结清
+func (s *BookkeepingLoanService) saveLoanState(tx *gorm.DB, loan *model.Loan) error {
+	if loan.RemainingPrincipal <= 0 || loan.RemainingTerm <= 0 {
+		loan.RemainingPrincipal = 0
+		loan.RemainingTerm = 0
+		loan.Status = model.LoanStatusPaidOff
+	}
+	return tx.Model(loan).Select("remaining_principal", "remaining_term", "paid_periods", "total_interest_paid", "status").
+		Updates(loan).Error
+}
+
+// lockActiveLoan 加锁读取还款中的贷款
+func (s *BookkeepingLoanService) lockActiveLoan(tx *gorm.DB, userID, loanID uint) (model.Loan, error) {
+	var loan model.Loan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", loanID, userID).First(&loan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return loan, newBizError("贷款不存在或不属于您")
+		}
+		return loan, err
+	}
+	if loan.Status != model.LoanStatusActive {
+		return loan, newBizError("该贷款已结清")
+	}
+	return loan, nil
+}
+
+// checkFromAccount 校验还款账户
+func (s *BookkeepingLoanService) checkFromAccount(tx *gorm.DB, userID uint, loan *model.Loan, accountID uint) error {
+	if accountID == loan.AccountID {
+		return newBizError("还款账户不能是贷款账户本身")
+	}
+	var account model.Account
+	if err := tx.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newBizError("还款账户不存在或不属于您")
+		}
+		return err
+	}
+	if account.IsArchived {
+		return newBizError("还款账户已归档，不能用于还款")
+	}
+	return nil
+}
+
+// checkInterestCategory 校验利息分类必须是当前用户的支出分类
+func (s *BookkeepingLoanService) checkInterestCategory(userID, categoryID uint) error {
+	var category model.Category
+	if err := global.DB.Where("id = ? AND user_id = ?", categoryID, userID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("利息分类不存在或不属于您")
+		}
+		return errors.New("获取分类信息失败：数据库错误")
+	}
+	if category.Type != model.CategoryTypeExpense {
+		return errors.New("利息分类必须是支出分类")
+	}
+	if category.IsArchived {
+		return errors.New("利息分类已归档")
+	}
+	return nil
+}
+
+// findLoan 查询属于当前用户的贷款
+func (s *BookkeepingLoanService) findLoan(userID, loanID uint) (model.Loan, error) {
+	var loan model.Loan
+	if err := global.DB.Preload("Account").Where("id = ? AND user_id = ?", loanID, userID).First(&loan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return loan, errors.New("贷款不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get loan: " + err.Error())
+		return loan, errors.New("获取贷款信息失败：数据库错误")
+	}
+	return loan, nil
+}
+
+// loanToResponse 辅助函数，将贷款模型转换为响应对象
+func (s *BookkeepingLoanService) loanToResponse(loan *model.Loan) dto.LoanResponse {
+	response := dto.LoanResponse{
+		ID:                 loan.ID,
+		AccountID:          loan.AccountID,
+		AccountName:        loan.Account.Name,
+		Name:               loan.Name,
+		Principal:          loan.Principal,
+		AnnualRate:         loan.AnnualRate,
+		TermMonths:         loan.TermMonths,
+		Method:             string(loan.Method),
+		StartDate:          loan.StartDate.Format("2006-01-02"),
+		InterestCategoryID: loan.InterestCategoryID,
+		RemainingPrincipal: loan.RemainingPrincipal,
+		PrincipalPaid:      roundCent(loan.Principal - loan.RemainingPrincipal),
+		TotalInterestPaid:  loan.TotalInterestPaid,
+		PaidPeriods:        loan.PaidPeriods,
+		RemainingTerm:      loan.RemainingTerm,
+		Status:             string(loan.Status),
+		Notes:              loan.Notes,
+		CreatedAt:          loan.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	schedule := projectLoanSchedule(loan)
+	for _, item := range schedule {
+		response.RemainingInterest += item.Interest
+	}
+	response.RemainingInterest = roundCent(response.RemainingInterest)
+	if len(schedule) > 0 {
+		next := schedule[0]
+		response.NextPayment = &next
+	}
+	return response
+}
+
+// repaymentToResponse 辅助函数，将还款记录模型转换为响应对象
+func (s *BookkeepingLoanService) repaymentToResponse(repayment *model.LoanRepayment) dto.LoanRepaymentResponse {
+	return dto.LoanRepaymentResponse{
+		ID:                     repayment.ID,
+		LoanID:                 repayment.LoanID,
+		Kind:                   string(repayment.Kind),
+		Period:                 repayment.Period,
+		RepaymentDate:          repayment.RepaymentDate.Format("2006-01-02"),
+		Principal:              repayment.Principal,
+		Interest:               repayment.Interest,
+		Amount:                 repayment.Amount,
+		FromAccountID:          repayment.FromAccountID,
+		PrepaymentMode:         string(repayment.PrepaymentMode),
+		PrincipalTransactionID: repayment.PrincipalTransactionID,
+		InterestTransactionID:  repayment.InterestTransactionID,
+		LoanTransactionID:      repayment.LoanTransactionID,
+		Notes:                  repayment.Notes,
+		CreatedAt:              repayment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// projectLoanSchedule 按贷款当前的剩余本金和剩余期数推算未还的还款计划
+// 第N期的还款日为放款日后N个月
+func projectLoanSchedule(loan *model.Loan) []dto.LoanScheduleItem {
+	if loan.Status != model.LoanStatusActive || loan.RemainingTerm <= 0 || loan.RemainingPrincipal <= 0 {
+		return nil
+	}
+
+	rate := loanMonthlyRate(loan.AnnualRate)
+	remaining := loan.RemainingPrincipal
+	items := make([]dto.LoanScheduleItem, 0, loan.RemainingTerm)
+	for i := 0; i < loan.RemainingTerm; i++ {
+		period := loan.PaidPeriods + i + 1
+		principal, interest := loanNextPeriod(loan.Method, remaining, loan.RemainingTerm-i, rate)
+		remaining = roundCent(remaining - principal)
+		items = append(items, dto.LoanScheduleItem{
+			Period:             period,
+			Kind:               string(model.LoanRepaymentRegular),
+			DueDate:            loan.StartDate.AddDate(0, period, 0).Format("2006-01-02"),
+			Payment:            roundCent(principal + interest),
+			Principal:          principal,
+			Interest:           interest,
+			RemainingPrincipal: remaining,
+		})
+	}
+	return items
+}
+
+// loanMonthlyRate 年利率 (百分比) 换算为月利率
+func loanMonthlyRate(annualRate float64) float64 {
+	return annualRate / 100 / 12
+}
+
+// loanInstallment 等额本息每期还款额
+func loanInstallment(principal float64, term int, rate float64) float64 {
+	if rate == 0 {
+		return principal / float64(term)
+	}
+	factor := math.Pow(1+rate, float64(term))
+	return principal * rate * factor / (factor - 1)
+}
+
+// loanNextPeriod 计算下一期应还的本金和利息
+// remaining: 剩余本金；term: 包含本期在内的剩余期数；最后一期归还全部剩余本金
+func loanNextPeriod(method model.LoanMethod, remaining float64, term int, rate float64) (float64, float64) {
+	interest := roundCent(remaining * rate)
+	if term <= 1 {
+		return remaining, interest
+	}
+
+	var principal float64
+	switch method {
+	case model.LoanMethodEqualPrincipal:
+		principal = roundCent(remaining / float64(term))
+	default:
+		principal = roundCent(loanInstallment(remaining, term, rate) - interest)
+	}
+	if principal > remaining {
+		principal = remaining
+	}
+	return principal, interest
+}
+
+// loanShortenedTerm 提前还款后保持月供不变时的剩余期数
+func loanShortenedTerm(loan *model.Loan, remaining float64) int {
+	rate := loanMonthlyRate(loan.AnnualRate)
+
+	var term float64
+	switch loan.Method {
+	case model.LoanMethodEqualPrincipal:
+		term = remaining / (loan.RemainingPrincipal / float64(loan.RemainingTerm))
+	default:
+		payment := loanInstallment(loan.RemainingPrincipal, loan.RemainingTerm, rate)
+		if rate == 0 {
+			term = remaining / payment
+		} else {
+			term = -math.Log(1-remaining*rate/payment) / math.Log(1+rate)
+		}
+	}
+
+	result := int(math.Ceil(term - 1e-9))
+	if result < 1 {
+		result = 1
+	}
+	if result > loan.RemainingTerm {
+		result = loan.RemainingTerm
+	}
+	return result
+}
+
+// roundCent 金额四舍五入到分
+func roundCent(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
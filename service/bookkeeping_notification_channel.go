@@ -0,0 +1,282 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+)
+
+// defaultNotificationChannels 没有设置通知偏好时使用的推送渠道
+const defaultNotificationChannels = "push"
+
+// notificationTarget 用户接收通知的地址
+type notificationTarget struct {
+	UserID        uint
+	Email         string
+	WebhookURL    string
+	WebhookSecret string
+}
+
+// notificationChannel 站内通知之外的推送渠道，新增渠道时在 notificationChannels 中注册
+type notificationChannel struct {
+	name string
+	send func(target *notificationTarget, notification *dto.NotificationResponse) error
+}
+
+// notificationChannels 已注册的推送渠道，名称对应通知偏好中的 channels
+var notificationChannels = []notificationChannel{
+	{"email", sendNotificationEmail},
+	{"webhook", sendNotificationWebhook},
+	{"push", pushNotification},
+}
+
+// deliverNotification 按通知偏好把新通知推送到各渠道
+// 服务端推送直接发送，邮件和Webhook在后台发送，失败只记录日志，不影响站内通知
+func deliverNotification(userID uint, channels string, notification *dto.NotificationResponse) {
+	enabled := make(map[string]bool)
+	for _, name := range splitTags(channels) {
+		enabled[name] = true
+	}
+	if len(enabled) == 0 {
+		return
+	}
+
+	var target *notificationTarget
+	for _, channel := range notificationChannels {
+		if !enabled[channel.name] {
+			continue
+		}
+		if channel.name == "push" {
+			if err := channel.send(&notificationTarget{UserID: userID}, notification); err != nil {
+				global.Logger.Error(fmt.Sprintf("Failed to push notification %d: %s", notification.ID, err.Error()))
+			}
+			continue
+		}
+
+		if target == nil {
+			var err error
+			if target, err = loadNotificationTarget(userID); err != nil {
+				global.Logger.Error("Failed to load notification target: " + err.Error())
+				return
+			}
+		}
+		go func(channel notificationChannel) {
+			if err := channel.send(target, notification); err != nil {
+				global.Logger.Error(fmt.Sprintf("Failed to send notification %d via %s: %s", notification.ID, channel.name, err.Error()))
+			}
+		}(channel)
+	}
+}
+
+// loadNotificationTarget 读取用户接收通知的邮箱和Webhook地址，未设置通知邮箱时使用账号邮箱
+func loadNotificationTarget(userID uint) (*notificationTarget, error) {
+	setting, err := userSetting(global.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	target := &notificationTarget{
+		UserID:        userID,
+		Email:         setting.NotifyEmail,
+		WebhookURL:    setting.NotifyWebhookURL,
+		WebhookSecret: setting.NotifyWebhookSecret,
+	}
+	if target.Email == "" {
+		var user model.UserInfo
+		if err := global.DB.Select("email").First(&user, userID).Error; err != nil {
+			return nil, err
+		}
+		target.Email = user.Email
+	}
+	return target, nil
+}
+
+// sendNotificationEmail 通过配置的SMTP服务器发送邮件通知，未配置SMTP或用户没有邮箱时跳过
+func sendNotificationEmail(target *notificationTarget, notification *dto.NotificationResponse) error {
+	config := global.Config.Notification.Smtp
+	if config.Host == "" || target.Email == "" {
+		return nil
+	}
+	from := config.From
+	if from == "" {
+		from = config.Username
+	}
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	var message strings.Builder
+	message.WriteString("From: " + from + "\r\n")
+	message.WriteString("To: " + target.Email + "\r\n")
+	message.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", notification.Title) + "\r\n")
+	message.WriteString("Date: " + notification.CreatedAt.Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	message.WriteString(notification.Content + "\r\n")
+
+	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+	return smtp.SendMail(addr, auth, from, []string{target.Email}, []byte(message.String()))
+}
+
+// errWebhookAddressNotAllowed Webhook地址指向本机或内网
+var errWebhookAddressNotAllowed = errors.New("Webhook地址不能指向本机或内网地址")
+
+// validateWebhookURL 检查Webhook地址只使用 http 或 https，且主机不是本机或内网的IP地址
+// 域名在发送时解析，解析结果由 webhookClient 在连接时再次检查
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return errors.New("Webhook地址格式不正确")
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return errors.New("Webhook地址只支持 http 和 https")
+	}
+	if ip := net.ParseIP(parsed.Hostname()); ip != nil && !webhookIPAllowed(ip) {
+		return errWebhookAddressNotAllowed
+	}
+	return nil
+}
+
+// webhookIPAllowed 判断是否允许连接该IP，配置允许内网Webhook时不做限制
+func webhookIPAllowed(ip net.IP) bool {
+	if global.Config.Notification.WebhookAllowPrivate {
+		return true
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// webhookClient 发送Webhook的HTTP客户端，在建立连接时检查实际连接的IP，
+// 域名解析到本机或内网地址 (包括DNS重绑定和重定向) 时拒绝连接；不使用环境变量中的代理，避免绕过检查
+func webhookClient() *http.Client {
+	timeout := global.Config.Notification.WebhookTimeout
+	if timeout <= 0 {
+		timeout = 10
+	}
+	dialer := &net.Dialer{
+		Timeout: time.Duration(timeout) * time.Second,
+		Control: func(network, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !webhookIPAllowed(ip) {
+				return errWebhookAddressNotAllowed
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: time.Duration(timeout) * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: time.Duration(timeout) * time.Second,
+		},
+	}
+}
+
+// sendNotificationWebhook 以JSON格式POST通知到用户设置的Webhook地址
+// 设置了签名密钥时，X-Signature-256 请求头为请求体的 HMAC-SHA256 签名 (sha256=十六进制)
+func sendNotificationWebhook(target *notificationTarget, notification *dto.NotificationResponse) error {
+	if target.WebhookURL == "" {
+		return nil
+	}
+	if err := validateWebhookURL(target.WebhookURL); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, target.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Notification-Event", notification.Event)
+	if target.WebhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(target.WebhookSecret))
+		mac.Write(payload)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// pushNotification 推送给用户当前打开的服务端推送连接
+func pushNotification(target *notificationTarget, notification *dto.NotificationResponse) error {
+	notificationHub.publish(target.UserID, *notification)
+	return nil
+}
+
+// notificationSubscribers 服务端推送的订阅者，按用户分组
+// 订阅只保存在当前进程内，多实例部署时只能推送到连接在同一实例上的客户端
+type notificationSubscribers struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan dto.NotificationResponse]struct{}
+}
+
+var notificationHub = &notificationSubscribers{
+	subscribers: make(map[uint]map[chan dto.NotificationResponse]struct{}),
+}
+
+// subscribe 订阅用户的新通知，返回的函数用于取消订阅
+func (h *notificationSubscribers) subscribe(userID uint) (chan dto.NotificationResponse, func()) {
+	ch := make(chan dto.NotificationResponse, 16)
+
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan dto.NotificationResponse]struct{})
+	}
+	h.subscribers[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers[userID], ch)
+		if len(h.subscribers[userID]) == 0 {
+			delete(h.subscribers, userID)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// publish 把通知发送给用户的所有订阅者，订阅者来不及接收时丢弃，客户端可以通过通知列表补齐
+func (h *notificationSubscribers) publish(userID uint, notification dto.NotificationResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[userID] {
+		select {
+		case ch <- notification:
+		default:
+		}
+	}
+}
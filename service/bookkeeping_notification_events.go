@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+)
+
+// StartNotificationScanner 按配置的间隔在后台扫描所有用户的预算阈值和即将到期的账单，产生对应的通知
+// 每个事件都有去重键，重复扫描不会重复通知；间隔为0时不启动
+func StartNotificationScanner() {
+	interval := global.Config.Notification.ScanInterval
+	if interval <= 0 {
+		global.Logger.Info("Notification scanner is disabled in config.")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Minute)
+		defer ticker.Stop()
+		for {
+			scanNotifications()
+			<-ticker.C
+		}
+	}()
+}
+
+// scanNotifications 为每个用户检查预算阈值和即将到期的账单
+func scanNotifications() {
+	var userIDs []uint
+	if err := global.DB.Model(&model.UserInfo{}).Pluck("id", &userIDs).Error; err != nil {
+		global.Logger.Error("Failed to list users for notification scan: " + err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		checkBudgetThresholds(userID)
+		notifyBillsDue(userID, now)
+	}
+}
+
+// checkBudgetThresholds 记录达到提醒阈值或超支的支出预算并产生通知，每个预算每个周期每个级别只记录一次
+// 交易写入时会立即检查受影响的预算，这里补充检查其他原因 (如修改预算金额、信封移出资金) 导致的跨过阈值
+func checkBudgetThresholds(userID uint) {
+	budgetService := BookkeepingBudgetService{}
+	progressItems, err := budgetService.ListActiveBudgetProgress(userID)
+	if err != nil {
+		return
+	}
+	for i := range progressItems {
+		if _, _, err := recordBudgetThreshold(&progressItems[i], nil); err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to record threshold event for budget %d: %s", progressItems[i].ID, err.Error()))
+		}
+	}
+}
+
+// budgetThresholdLevel 预算当前周期达到的阈值级别，未达到提醒阈值时返回空字符串
+// 收入目标没有超支的概念；没有支出时不提醒，避免未分配资金的信封被当作已用完
+func budgetThresholdLevel(progress *dto.BudgetProgressResponse) model.BudgetThresholdLevel {
+	if progress.Kind == string(model.BudgetKindIncome) || progress.SpentAmount <= 0 {
+		return ""
+	}
+	if progress.IsOverBudget {
+		return model.BudgetThresholdExceeded
+	}
+	if progress.NotifyRate > 0 && progress.UsageRate >= progress.NotifyRate {
+		return model.BudgetThresholdWarning
+	}
+	return ""
+}
+
+// notifyBudgetThreshold 预算跨过阈值时产生通知
+func notifyBudgetThreshold(progress *dto.BudgetProgressResponse, level model.BudgetThresholdLevel) {
+	message := notificationMessage{
+		Event:    model.NotificationEventBudgetThreshold,
+		DedupKey: fmt.Sprintf("budget:%d:%s:%s", progress.ID, progress.CurrentPeriod.StartDate.Format("2006-01-02"), level),
+		RefType:  "budget",
+		RefID:    progress.ID,
+	}
+	if level == model.BudgetThresholdExceeded {
+		message.Title = "预算超支：" + progress.Name
+		message.Content = fmt.Sprintf("「%s」本周期已支出 %.2f，超出可用金额 %.2f 共 %.2f，周期剩余 %d 天",
+			progress.Name, progress.SpentAmount, progress.EffectiveAmount, roundCent(progress.SpentAmount-progress.EffectiveAmount), progress.DaysRemaining)
+	} else {
+		message.Title = "预算提醒：" + progress.Name
+		message.Content = fmt.Sprintf("「%s」本周期已使用 %.0f%%（%.2f / %.2f），剩余 %.2f，周期剩余 %d 天",
+			progress.Name, math.Floor(progress.UsageRate*100), progress.SpentAmount, progress.EffectiveAmount, progress.RemainingAmount, progress.DaysRemaining)
+	}
+	notify(progress.UserID, message)
+}
+
+// notifyBillsDue 为今天起提醒天数内到期的账单产生通知，每张账单每个到期日只通知一次
+func notifyBillsDue(userID uint, now time.Time) {
+	days := global.Config.Notification.DueReminderDays
+	if days < 0 {
+		days = 0
+	}
+	from := truncateToDay(now)
+	to := from.AddDate(0, 0, days+1).Add(-time.Nanosecond)
+
+	bills, err := dueBills(global.DB, userID, from, to)
+	if err != nil {
+		global.Logger.Error("Failed to list due bills: " + err.Error())
+		return
+	}
+	for _, bill := range bills {
+		when := "今天"
+		if remaining := daysBetween(from, bill.DueDate); remaining > 0 {
+			when = fmt.Sprintf("%d 天后 (%s)", remaining, bill.DueDate.Format("2006-01-02"))
+		}
+		notify(userID, notificationMessage{
+			Event:    model.NotificationEventBillDue,
+			DedupKey: fmt.Sprintf("bill:%s:%d:%s", bill.Source, bill.RefID, bill.DueDate.Format("2006-01-02")),
+			Title:    "账单到期：" + bill.Name,
+			Content:  fmt.Sprintf("%s 将于%s到期，应付 %.2f", bill.Name, when, bill.Amount),
+			RefType:  bill.Source,
+			RefID:    bill.RefID,
+		})
+	}
+}
+
+// notifyLargeTransaction 收支金额达到用户设置的大额交易金额时产生通知，每笔交易只通知一次
+// transaction 需要预加载账户和分类
+func notifyLargeTransaction(transaction *model.Transaction) {
+	if transaction.Type != model.TransactionTypeIncome && transaction.Type != model.TransactionTypeExpense {
+		return
+	}
+	setting, err := userSetting(global.DB, transaction.UserID)
+	if err != nil {
+		global.Logger.Error("Failed to get user setting: " + err.Error())
+		return
+	}
+	if setting.LargeTransactionAmount <= 0 || transaction.Amount < setting.LargeTransactionAmount {
+		return
+	}
+
+	kind := "支出"
+	if transaction.Type == model.TransactionTypeIncome {
+		kind = "收入"
+	}
+	notify(transaction.UserID, notificationMessage{
+		Event:    model.NotificationEventLargeTransaction,
+		DedupKey: fmt.Sprintf("transaction:%d", transaction.ID),
+		Title:    fmt.Sprintf("大额%s %.2f", kind, transaction.Amount),
+		Content: fmt.Sprintf("%s 在账户「%s」%s %.2f，分类「%s」",
+			transaction.TransactionDate.Format("2006-01-02"), transaction.Account.Name, kind, transaction.Amount, transaction.Category.Name),
+		RefType: "transaction",
+		RefID:   transaction.ID,
+	})
+}
+
+// notifyImportCompleted 导入完成时产生通知，每次导入单独通知
+func notifyImportCompleted(userID uint, source, title, content string) {
+	notify(userID, notificationMessage{
+		Event:    model.NotificationEventImportCompleted,
+		DedupKey: fmt.Sprintf("import:%s:%d", source, time.Now().UnixNano()),
+		Title:    title,
+		Content:  content,
+		RefType:  source,
+	})
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookkeepingNotificationService 通知中心服务
+// 预算阈值、账单到期、大额交易、导入完成等事件通过 notify 产生站内通知，并按用户的通知偏好推送到邮件、Webhook和服务端推送
+type BookkeepingNotificationService struct{}
+
+// ListNotifications 分页获取用户的站内通知，按时间倒序
+func (s *BookkeepingNotificationService) ListNotifications(userID uint, query dto.NotificationQuery) (dto.NotificationListResponse, error) {
+	var response dto.NotificationListResponse
+
+	db := global.DB.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if query.Event != "" {
+		db = db.Where("event = ?", query.Event)
+	}
+	if query.IsRead != nil {
+		db = db.Where("is_read = ?", *query.IsRead)
+	}
+
+	if err := db.Count(&response.Total).Error; err != nil {
+		global.Logger.Error("Failed to count notifications: " + err.Error())
+		return response, errors.New("获取通知列表失败：数据库错误")
+	}
+	if err := global.DB.Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&response.Unread).Error; err != nil {
+		global.Logger.Error("Failed to count unread notifications: " + err.Error())
+		return response, errors.New("获取通知列表失败：数据库错误")
+	}
+
+	var notifications []model.Notification
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("id DESC").Offset(offset).Limit(query.PageSize).Find(&notifications).Error; err != nil {
+		global.Logger.Error("Failed to list notifications: " + err.Error())
+		return response, errors.New("获取通知列表失败：数据库错误")
+	}
+
+	response.Items = make([]dto.NotificationResponse, 0, len(notifications))
+	for i := range notifications {
+		response.Items = append(response.Items, notificationToResponse(&notifications[i]))
+	}
+	return response, nil
+}
+
+// MarkRead 将一条通知标记为已读
+func (s *BookkeepingNotificationService) MarkRead(userID, notificationID uint) error {
+	var notification model.Notification
+	if err := global.DB.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("通知不存在")
+		}
+		global.Logger.Error("Failed to find notification: " + err.Error())
+		return errors.New("标记已读失败：数据库错误")
+	}
+	if notification.IsRead {
+		return nil
+	}
+
+	now := time.Now()
+	if err := global.DB.Model(&notification).Updates(map[string]interface{}{"is_read": true, "read_at": now}).Error; err != nil {
+		global.Logger.Error("Failed to mark notification as read: " + err.Error())
+		return errors.New("标记已读失败：数据库错误")
+	}
+	return nil
+}
+
+// MarkAllRead 将用户的全部未读通知标记为已读
+func (s *BookkeepingNotificationService) MarkAllRead(userID uint) error {
+	now := time.Now()
+	if err := global.DB.Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": now}).Error; err != nil {
+		global.Logger.Error("Failed to mark all notifications as read: " + err.Error())
+		return errors.New("标记已读失败：数据库错误")
+	}
+	return nil
+}
+
+// DeleteNotification 删除一条通知，删除后同一事件不会再次通知
+func (s *BookkeepingNotificationService) DeleteNotification(userID, notificationID uint) error {
+	result := global.DB.Where("id = ? AND user_id = ?", notificationID, userID).Delete(&model.Notification{})
+	if result.Error != nil {
+		global.Logger.Error("Failed to delete notification: " + result.Error.Error())
+		return errors.New("删除通知失败：数据库错误")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("通知不存在")
+	}
+	return nil
+}
+
+// GetPreferences 获取用户对各类事件的通知偏好，没有设置过的事件返回默认偏好
+func (s *BookkeepingNotificationService) GetPreferences(userID uint) ([]dto.NotificationPreferenceItem, error) {
+	var preferences []model.NotificationPreference
+	if err := global.DB.Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		global.Logger.Error("Failed to list notification preferences: " + err.Error())
+		return nil, errors.New("获取通知偏好失败：数据库错误")
+	}
+	saved := make(map[model.NotificationEvent]*model.NotificationPreference, len(preferences))
+	for i := range preferences {
+		saved[preferences[i].Event] = &preferences[i]
+	}
+
+	items := make([]dto.NotificationPreferenceItem, 0, len(model.NotificationEvents))
+	for _, event := range model.NotificationEvents {
+		preference := saved[event]
+		if preference == nil {
+			preference = defaultNotificationPreference(userID, event)
+		}
+		items = append(items, dto.NotificationPreferenceItem{
+			Event:    string(event),
+			Enabled:  preference.Enabled,
+			Channels: append([]string{}, splitTags(preference.Channels)...),
+		})
+	}
+	return items, nil
+}
+
+// UpdatePreferences 更新用户对指定事件的通知偏好
+func (s *BookkeepingNotificationService) UpdatePreferences(userID uint, req dto.UpdateNotificationPreferencesRequest) ([]dto.NotificationPreferenceItem, error) {
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range req.Preferences {
+			channels, err := joinTags(item.Channels)
+			if err != nil {
+				return err
+			}
+			preference := model.NotificationPreference{
+				UserID:   userID,
+				Event:    model.NotificationEvent(item.Event),
+				Enabled:  item.Enabled,
+				Channels: channels,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}},
+				DoUpdates: clause.AssignmentColumns([]string{"enabled", "channels", "updated_at"}),
+			}).Create(&preference).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		global.Logger.Error("Failed to update notification preferences: " + err.Error())
+		return nil, errors.New("更新通知偏好失败：数据库错误")
+	}
+
+	return s.GetPreferences(userID)
+}
+
+// Subscribe 订阅用户的新通知，用于服务端推送，返回的函数用于取消订阅
+func (s *BookkeepingNotificationService) Subscribe(userID uint) (<-chan dto.NotificationResponse, func()) {
+	return notificationHub.subscribe(userID)
+}
+
+// notificationMessage 要发送给用户的一条通知
+type notificationMessage struct {
+	Event    model.NotificationEvent
+	DedupKey string // 去重键，同一用户的同一去重键只通知一次
+	Title    string
+	Content  string
+	RefType  string
+	RefID    uint
+}
+
+// notify 为用户产生一条站内通知并推送到通知偏好中的渠道
+// 去重键已经通知过 (包括已删除的通知) 或用户关闭了该事件的通知时不做任何事；
+// 通知是业务操作的附带结果，失败只记录日志。需要在业务事务提交后调用，避免推送了最终回滚的事件
+func notify(userID uint, message notificationMessage) {
+	preference, err := notificationPreference(global.DB, userID, message.Event)
+	if err != nil {
+		global.Logger.Error("Failed to get notification preference: " + err.Error())
+		return
+	}
+	if !preference.Enabled {
+		return
+	}
+
+	notification := model.Notification{
+		UserID:   userID,
+		Event:    message.Event,
+		DedupKey: message.DedupKey,
+		Title:    truncateRunes(message.Title, 100),
+		Content:  truncateRunes(message.Content, 500),
+		RefType:  message.RefType,
+		RefID:    message.RefID,
+	}
+	// 软删除的通知仍然占用去重键，删除的通知不会再次出现
+	result := global.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&notification)
+	if result.Error != nil {
+		global.Logger.Error(fmt.Sprintf("Failed to create notification %s: %s", message.DedupKey, result.Error.Error()))
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	response := notificationToResponse(&notification)
+	deliverNotification(userID, preference.Channels, &response)
+}
+
+// notificationPreference 获取用户对某类事件的通知偏好，没有设置时返回默认偏好
+func notificationPreference(db *gorm.DB, userID uint, event model.NotificationEvent) (*model.NotificationPreference, error) {
+	var preference model.NotificationPreference
+	err := db.Where("user_id = ? AND event = ?", userID, event).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultNotificationPreference(userID, event), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// defaultNotificationPreference 默认产生所有事件的站内通知，并通过服务端推送发送
+func defaultNotificationPreference(userID uint, event model.NotificationEvent) *model.NotificationPreference {
+	return &model.NotificationPreference{
+		UserID:   userID,
+		Event:    event,
+		Enabled:  true,
+		Channels: defaultNotificationChannels,
+	}
+}
+
+// notificationToResponse 通知模型转 DTO
+func notificationToResponse(notification *model.Notification) dto.NotificationResponse {
+	return dto.NotificationResponse{
+		ID:        notification.ID,
+		Event:     string(notification.Event),
+		Title:     notification.Title,
+		Content:   notification.Content,
+		RefType:   notification.RefType,
+		RefID:     notification.RefID,
+		IsRead:    notification.IsRead,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// truncateRunes 按字符截断超过字段长度的文本
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// savingsGoalDaysPerMonth 换算每月存入金额时使用的平均每月天数
+const savingsGoalDaysPerMonth = 365.25 / 12
+
+// savingsGoalMaxProjectionDays 按当前存入速度超过100年才能达成时视为无法达成
+const savingsGoalMaxProjectionDays = 100 * 365
+
+// BookkeepingSavingsGoalService 储蓄目标服务
+type BookkeepingSavingsGoalService struct{}
+
+// CreateGoal 创建储蓄目标
+func (s *BookkeepingSavingsGoalService) CreateGoal(userID uint, req dto.CreateSavingsGoalRequest) (*dto.SavingsGoalResponse, error) {
+	goal := model.SavingsGoal{
+		UserID:       userID,
+		Name:         req.Name,
+		TargetAmount: req.TargetAmount,
+		StartDate:    truncateToDay(time.Now()),
+		TargetDate:   req.TargetDate,
+		ProgressMode: model.SavingsGoalProgressMode(req.ProgressMode),
+		Description:  req.Description,
+		IsActive:     true,
+	}
+	if req.StartDate != nil {
+		goal.StartDate = truncateToDay(*req.StartDate)
+	}
+	if goal.ProgressMode == "" {
+		goal.ProgressMode = model.SavingsGoalProgressBalance // 默认按账户余额计算
+	}
+	if req.IsActive != nil {
+		goal.IsActive = *req.IsActive
+	}
+	if err := validateSavingsGoal(&goal); err != nil {
+		return nil, err
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&goal).Error; err != nil {
+			return err
+		}
+		return saveSavingsGoalAccounts(tx, userID, goal.ID, req.AccountIDs)
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create savings goal: " + err.Error())
+		return nil, userFacingError(err, "创建储蓄目标失败：数据库错误")
+	}
+
+	return s.GetGoal(userID, goal.ID)
+}
+
+// GetGoal 获取储蓄目标详情及当前进度
+func (s *BookkeepingSavingsGoalService) GetGoal(userID, goalID uint) (*dto.SavingsGoalResponse, error) {
+	goal, err := s.findGoal(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.goalToResponse(&goal)
+	if err != nil {
+		global.Logger.Error("Failed to calculate savings goal progress: " + err.Error())
+		return nil, errors.New("计算储蓄目标进度失败：数据库错误")
+	}
+	return &response, nil
+}
+
+// ListGoals 获取储蓄目标列表及各自的进度
+func (s *BookkeepingSavingsGoalService) ListGoals(userID uint, query dto.SavingsGoalQuery) ([]dto.SavingsGoalResponse, error) {
+	db := global.DB.Preload("Accounts").Where("user_id = ?", userID)
+	if query.IsActive != nil {
+		db = db.Where("is_active = ?", *query.IsActive)
+	}
+
+	var goals []model.SavingsGoal
+	if err := db.Order("id DESC").Find(&goals).Error; err != nil {
+		global.Logger.Error("Failed to list savings goals: " + err.Error())
+		return nil, errors.New("获取储蓄目标列表失败：数据库错误")
+	}
+
+	responses := make([]dto.SavingsGoalResponse, 0, len(goals))
+	for i := range goals {
+		response, err := s.goalToResponse(&goals[i])
+		if err != nil {
+			global.Logger.Error(fmt.Sprintf("Failed to calculate progress for savings goal %d: %s", goals[i].ID, err.Error()))
+			return nil, errors.New("获取储蓄目标列表失败：数据库错误")
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// UpdateGoal 更新储蓄目标
+func (s *BookkeepingSavingsGoalService) UpdateGoal(userID, goalID uint, req dto.UpdateSavingsGoalRequest) (*dto.SavingsGoalResponse, error) {
+	goal, err := s.findGoal(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按修改后的日期校验
+	updated := goal
+	if req.StartDate != nil {
+		updated.StartDate = truncateToDay(*req.StartDate)
+	}
+	if req.ClearTargetDate {
+		updated.TargetDate = nil
+	} else if req.TargetDate != nil {
+		updated.TargetDate = req.TargetDate
+	}
+	if err := validateSavingsGoal(&updated); err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.TargetAmount != nil {
+		updates["target_amount"] = *req.TargetAmount
+	}
+	if req.StartDate != nil {
+		updates["start_date"] = updated.StartDate
+	}
+	if req.ClearTargetDate || req.TargetDate != nil {
+		if updated.TargetDate != nil {
+			updates["target_date"] = *updated.TargetDate
+		} else {
+			updates["target_date"] = nil
+		}
+	}
+	if req.ProgressMode != nil {
+		updates["progress_mode"] = model.SavingsGoalProgressMode(*req.ProgressMode)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	// 如果没有需要更新的字段，直接返回
+	if len(updates) == 0 && req.AccountIDs == nil {
+		return s.GetGoal(userID, goalID)
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if req.AccountIDs != nil {
+			if err := saveSavingsGoalAccounts(tx, userID, goal.ID, *req.AccountIDs); err != nil {
+				return err
+			}
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&goal).Updates(updates).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to update savings goal: " + err.Error())
+		return nil, userFacingError(err, "更新储蓄目标失败：数据库错误")
+	}
+
+	return s.GetGoal(userID, goalID)
+}
+
+// DeleteGoal 删除储蓄目标，不影响关联的账户和交易
+func (s *BookkeepingSavingsGoalService) DeleteGoal(userID, goalID uint) error {
+	goal, err := s.findGoal(userID, goalID)
+	if err != nil {
+		return err
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", goal.ID).Delete(&model.SavingsGoalAccount{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&goal).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete savings goal: " + err.Error())
+		return errors.New("删除储蓄目标失败：数据库错误")
+	}
+	return nil
+}
+
+// findGoal 查询属于当前用户的储蓄目标
+func (s *BookkeepingSavingsGoalService) findGoal(userID, goalID uint) (model.SavingsGoal, error) {
+	var goal model.SavingsGoal
+	if err := global.DB.Preload("Accounts").Where("id = ? AND user_id = ?", goalID, userID).First(&goal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return goal, errors.New("储蓄目标不存在")
+		}
+		global.Logger.Error("Failed to get savings goal: " + err.Error())
+		return goal, errors.New("获取储蓄目标失败：数据库错误")
+	}
+	return goal, nil
+}
+
+// goalToResponse 将储蓄目标转换为响应对象，同时计算当前进度
+func (s *BookkeepingSavingsGoalService) goalToResponse(goal *model.SavingsGoal) (dto.SavingsGoalResponse, error) {
+	response := dto.SavingsGoalResponse{
+		ID:           goal.ID,
+		Name:         goal.Name,
+		TargetAmount: goal.TargetAmount,
+		StartDate:    goal.StartDate,
+		TargetDate:   goal.TargetDate,
+		AccountIDs:   make([]uint, 0, len(goal.Accounts)),
+		ProgressMode: string(goal.ProgressMode),
+		Description:  goal.Description,
+		IsActive:     goal.IsActive,
+		CreatedAt:    goal.CreatedAt,
+		UpdatedAt:    goal.UpdatedAt,
+	}
+	for _, account := range goal.Accounts {
+		response.AccountIDs = append(response.AccountIDs, account.AccountID)
+	}
+
+	progress, err := savingsGoalProgress(global.DB, goal, response.AccountIDs)
+	if err != nil {
+		return response, err
+	}
+	response.Progress = progress
+	return response, nil
+}
+
+// savingsGoalProgress 计算储蓄目标的进度
+// 当前金额按进度计算方式取关联账户的余额合计或开始日期以来的净存入；
+// 预计达成日期按开始日期以来的日均净存入推算，每月需存入金额按剩余金额平摊到目标日期前的各月
+func savingsGoalProgress(db *gorm.DB, goal *model.SavingsGoal, accountIDs []uint) (dto.SavingsGoalProgress, error) {
+	var progress dto.SavingsGoalProgress
+	today := truncateToDay(time.Now())
+
+	// 开始日期以来关联账户的净存入，与账户余额的计算一致：收入增加，支出和转账 (视为转出) 减少
+	// 转账没有转入方，转入目标账户的资金以收入流水记录
+	if len(accountIDs) > 0 {
+		if err := db.Model(&model.Transaction{}).
+			Where("user_id = ? AND account_id IN ? AND transaction_date >= ?", goal.UserID, accountIDs, goal.StartDate).
+			Select(model.BalanceDeltaSQL).Scan(&progress.NetContributions).Error; err != nil {
+			return progress, err
+		}
+		progress.NetContributions = roundCent(progress.NetContributions)
+	}
+
+	progress.CurrentAmount = progress.NetContributions
+	if goal.ProgressMode != model.SavingsGoalProgressContributions && len(accountIDs) > 0 {
+		var balance float64
+		if err := db.Model(&model.Account{}).Where("id IN ? AND user_id = ?", accountIDs, goal.UserID).
+			Select("COALESCE(SUM(current_balance), 0)").Scan(&balance).Error; err != nil {
+			return progress, err
+		}
+		progress.CurrentAmount = roundCent(balance)
+	}
+
+	progress.RemainingAmount = roundCent(math.Max(goal.TargetAmount-progress.CurrentAmount, 0))
+	progress.Percentage = progress.CurrentAmount / goal.TargetAmount
+	progress.IsAchieved = progress.CurrentAmount >= goal.TargetAmount
+
+	// 开始日期 (含当天) 以来的日均净存入，目标尚未开始时不推算
+	var dailyRate float64
+	if elapsedDays := daysBetween(truncateToDay(goal.StartDate), today) + 1; elapsedDays > 0 {
+		dailyRate = progress.NetContributions / float64(elapsedDays)
+		progress.AverageMonthlyContribution = roundCent(dailyRate * savingsGoalDaysPerMonth)
+	}
+	if !progress.IsAchieved && dailyRate > 0 {
+		if days := math.Ceil(progress.RemainingAmount / dailyRate); days <= savingsGoalMaxProjectionDays {
+			date := today.AddDate(0, 0, int(days))
+			progress.ProjectedCompletionDate = &date
+		}
+	}
+
+	progress.OnTrack = progress.IsAchieved || progress.ProjectedCompletionDate != nil
+	if goal.TargetDate != nil {
+		targetDay := truncateToDay(*goal.TargetDate)
+		daysRemaining := daysBetween(today, targetDay)
+		if daysRemaining < 0 {
+			daysRemaining = 0
+		}
+		progress.DaysRemaining = &daysRemaining
+
+		if !progress.IsAchieved {
+			// 剩余不足一个月 (包括目标日期已过) 时需要一次存够
+			months := math.Max(float64(daysRemaining)/savingsGoalDaysPerMonth, 1)
+			progress.RequiredMonthlyContribution = roundCent(progress.RemainingAmount / months)
+			progress.OnTrack = progress.ProjectedCompletionDate != nil && !progress.ProjectedCompletionDate.After(targetDay)
+		}
+	}
+	return progress, nil
+}
+
+// validateSavingsGoal 校验储蓄目标的日期，目标日期不能早于开始日期
+func validateSavingsGoal(goal *model.SavingsGoal) error {
+	if goal.TargetDate != nil && truncateToDay(*goal.TargetDate).Before(truncateToDay(goal.StartDate)) {
+		return errors.New("目标日期不能早于开始日期")
+	}
+	return nil
+}
+
+// saveSavingsGoalAccounts 重新保存储蓄目标关联的账户，账户必须是属于当前用户的资产账户
+func saveSavingsGoalAccounts(tx *gorm.DB, userID, goalID uint, accountIDs []uint) error {
+	ids := uniqueUints(accountIDs)
+	if len(ids) == 0 {
+		return newBizError("储蓄目标至少需要关联一个账户")
+	}
+
+	var count int64
+	if err := tx.Model(&model.Account{}).
+		Where("id IN ? AND user_id = ? AND nature = ?", ids, userID, model.AccountNatureAsset).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		return newBizError("关联的账户不存在或不是资产账户")
+	}
+
+	if err := tx.Unscoped().Where("goal_id = ?", goalID).Delete(&model.SavingsGoalAccount{}).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := tx.Create(&model.SavingsGoalAccount{GoalID: goalID, AccountID: id}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// BookkeepingSettingService 记账偏好设置服务
+type BookkeepingSettingService struct{}
+
+// GetSettings 获取用户的记账偏好设置，没有设置过时返回默认值
+func (s *BookkeepingSettingService) GetSettings(userID uint) (*dto.UserSettingResponse, error) {
+	setting, err := userSetting(global.DB, userID)
+	if err != nil {
+		global.Logger.Error("Failed to get user setting: " + err.Error())
+		return nil, errors.New("获取设置失败：数据库错误")
+	}
+	return &dto.UserSettingResponse{
+		MonthStartDay:          setting.MonthStartDay,
+		EnvelopeMode:           setting.EnvelopeMode,
+		EnvelopeStartDate:      setting.EnvelopeStartDate,
+		EnvelopeOpeningAmount:  setting.EnvelopeOpeningAmount,
+		NotifyEmail:            setting.NotifyEmail,
+		NotifyWebhookURL:       setting.NotifyWebhookURL,
+		HasWebhookSecret:       setting.NotifyWebhookSecret != "",
+		LargeTransactionAmount: setting.LargeTransactionAmount,
+	}, nil
+}
+
+// UpdateSettings 更新用户的记账偏好设置
+// 修改每月起始日只影响之后新建或修改了周期、开始日期的预算，已有预算保留原来的开始日期，避免改写历史周期；
+// 首次开启信封模式时从本月开始计算待分配金额，之后关闭再开启保持原来的开始月份
+func (s *BookkeepingSettingService) UpdateSettings(userID uint, req dto.UpdateUserSettingRequest) (*dto.UserSettingResponse, error) {
+	if req.NotifyWebhookURL != nil && *req.NotifyWebhookURL != "" {
+		if err := validateWebhookURL(*req.NotifyWebhookURL); err != nil {
+			return nil, err
+		}
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		setting, err := userSetting(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if req.MonthStartDay != nil {
+			setting.MonthStartDay = *req.MonthStartDay
+		}
+
+		if req.EnvelopeMode != nil {
+			setting.EnvelopeMode = *req.EnvelopeMode
+			if setting.EnvelopeMode && setting.EnvelopeStartDate == nil {
+				start := envelopeMonthStart(time.Now())
+				setting.EnvelopeStartDate = &start
+			}
+		}
+		if req.EnvelopeOpeningAmount != nil {
+			setting.EnvelopeOpeningAmount = *req.EnvelopeOpeningAmount
+		}
+		if req.NotifyEmail != nil {
+			setting.NotifyEmail = *req.NotifyEmail
+		}
+		if req.NotifyWebhookURL != nil {
+			setting.NotifyWebhookURL = *req.NotifyWebhookURL
+		}
+		if req.NotifyWebhookSecret != nil {
+			setting.NotifyWebhookSecret = *req.NotifyWebhookSecret
+		}
+		if req.LargeTransactionAmount != nil {
+			setting.LargeTransactionAmount = *req.LargeTransactionAmount
+		}
+
+		return tx.Save(setting).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to update user setting: " + err.Error())
+		return nil, errors.New("更新设置失败：数据库错误")
+	}
+
+	return s.GetSettings(userID)
+}
+
+// userSetting 获取用户的设置记录，不存在时返回未保存的默认设置
+func userSetting(db *gorm.DB, userID uint) (*model.UserSetting, error) {
+	var setting model.UserSetting
+	err := db.Where("user_id = ?", userID).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.UserSetting{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
@@ -0,0 +1,862 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// AA分摊生成资金流水时使用的分类名称
+const (
+	splitAdvanceCategoryName    = "AA代付"
+	splitSettlementCategoryName = "AA结算"
+)
+
+// BookkeepingSplitService AA分摊账单服务
+type BookkeepingSplitService struct{}
+
+// CreateGroup 创建账单组，自动添加代表用户本人的成员
+func (s *BookkeepingSplitService) CreateGroup(userID uint, req dto.CreateSplitGroupRequest) (*dto.SplitGroupResponse, error) {
+	selfName := strings.TrimSpace(req.SelfName)
+	if selfName == "" {
+		selfName = "我"
+	}
+
+	names := map[string]bool{selfName: true}
+	members := []model.SplitMember{{UserID: userID, Name: selfName, IsSelf: true}}
+	for _, name := range req.Members {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if names[name] {
+			return nil, fmt.Errorf("成员名称重复：%s", name)
+		}
+		names[name] = true
+		members = append(members, model.SplitMember{UserID: userID, Name: name})
+	}
+
+	group := model.SplitGroup{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Members:     members,
+	}
+	if err := global.DB.Create(&group).Error; err != nil {
+		global.Logger.Error("Failed to create split group: " + err.Error())
+		return nil, errors.New("创建账单组失败：数据库错误")
+	}
+
+	return s.GetGroup(userID, group.ID)
+}
+
+// ListGroups 获取用户的账单组列表
+func (s *BookkeepingSplitService) ListGroups(userID uint) ([]dto.SplitGroupResponse, error) {
+	var groups []model.SplitGroup
+	if err := global.DB.Preload("Members").Where("user_id = ?", userID).Order("id DESC").Find(&groups).Error; err != nil {
+		global.Logger.Error("Failed to list split groups: " + err.Error())
+		return nil, errors.New("获取账单组列表失败：数据库错误")
+	}
+
+	responses := make([]dto.SplitGroupResponse, 0, len(groups))
+	for i := range groups {
+		response, err := s.groupToResponse(&groups[i])
+		if err != nil {
+			global.Logger.Error("Failed to calculate split group balances: " + err.Error())
+			return nil, errors.New("获取账单组列表失败：数据库错误")
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// GetGroup 获取账单组详情
+func (s *BookkeepingSplitService) GetGroup(userID, groupID uint) (*dto.SplitGroupResponse, error) {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.groupToResponse(&group)
+	if err != nil {
+		global.Logger.Error("Failed to calculate split group balances: " + err.Error())
+		return nil, errors.New("获取账单组失败：数据库错误")
+	}
+	return &response, nil
+}
+
+// UpdateGroup 更新账单组名称和描述
+func (s *BookkeepingSplitService) UpdateGroup(userID, groupID uint, req dto.UpdateSplitGroupRequest) (*dto.SplitGroupResponse, error) {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if len(updates) > 0 {
+		if err := global.DB.Model(&group).Updates(updates).Error; err != nil {
+			global.Logger.Error("Failed to update split group: " + err.Error())
+			return nil, errors.New("更新账单组失败：数据库错误")
+		}
+	}
+
+	return s.GetGroup(userID, groupID)
+}
+
+// DeleteGroup 删除账单组，存在支出或结算记录时不能删除
+func (s *BookkeepingSplitService) DeleteGroup(userID, groupID uint) error {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return err
+	}
+
+	var expenseCount, settlementCount int64
+	if err := global.DB.Model(&model.SplitExpense{}).Where("group_id = ?", group.ID).Count(&expenseCount).Error; err != nil {
+		global.Logger.Error("Failed to count split expenses: " + err.Error())
+		return errors.New("删除账单组失败：数据库错误")
+	}
+	if err := global.DB.Model(&model.SplitSettlement{}).Where("group_id = ?", group.ID).Count(&settlementCount).Error; err != nil {
+		global.Logger.Error("Failed to count split settlements: " + err.Error())
+		return errors.New("删除账单组失败：数据库错误")
+	}
+	if expenseCount > 0 || settlementCount > 0 {
+		return errors.New("请先删除账单组中的支出和结算记录")
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", group.ID).Delete(&model.SplitMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete split group: " + err.Error())
+		return errors.New("删除账单组失败：数据库错误")
+	}
+	return nil
+}
+
+// AddMember 向账单组添加成员
+func (s *BookkeepingSplitService) AddMember(userID, groupID uint, req dto.AddSplitMemberRequest) (*dto.SplitGroupResponse, error) {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	for _, member := range group.Members {
+		if member.Name == name {
+			return nil, errors.New("该成员已存在")
+		}
+	}
+
+	member := model.SplitMember{UserID: userID, GroupID: group.ID, Name: name}
+	if err := global.DB.Create(&member).Error; err != nil {
+		global.Logger.Error("Failed to add split member: " + err.Error())
+		return nil, errors.New("添加成员失败：数据库错误")
+	}
+
+	return s.GetGroup(userID, groupID)
+}
+
+// RemoveMember 移除账单组成员，本人以及已参与支出或结算的成员不能移除
+func (s *BookkeepingSplitService) RemoveMember(userID, groupID, memberID uint) error {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return err
+	}
+
+	var member *model.SplitMember
+	for i := range group.Members {
+		if group.Members[i].ID == memberID {
+			member = &group.Members[i]
+		}
+	}
+	if member == nil {
+		return errors.New("成员不存在")
+	}
+	if member.IsSelf {
+		return errors.New("不能移除本人")
+	}
+
+	var count int64
+	if err := global.DB.Model(&model.SplitShare{}).Where("member_id = ?", memberID).Count(&count).Error; err != nil {
+		return errors.New("移除成员失败：数据库错误")
+	}
+	if count == 0 {
+		if err := global.DB.Model(&model.SplitExpense{}).Where("payer_member_id = ?", memberID).Count(&count).Error; err != nil {
+			return errors.New("移除成员失败：数据库错误")
+		}
+	}
+	if count == 0 {
+		if err := global.DB.Model(&model.SplitSettlement{}).
+			Where("from_member_id = ? OR to_member_id = ?", memberID, memberID).Count(&count).Error; err != nil {
+			return errors.New("移除成员失败：数据库错误")
+		}
+	}
+	if count > 0 {
+		return errors.New("该成员已参与支出或结算，不能移除")
+	}
+
+	if err := global.DB.Delete(member).Error; err != nil {
+		global.Logger.Error("Failed to remove split member: " + err.Error())
+		return errors.New("移除成员失败：数据库错误")
+	}
+	return nil
+}
+
+// CreateExpense 记录一笔共同支出
+// 本人承担的份额记为支出并计入分类统计；本人付款时，替他人垫付的部分记为不计入收支统计的支出；
+// 他人付款时，本人份额记为支出的同时记一笔等额的不计入收支统计的收入，账户余额不变，欠款体现在账单组结余中
+func (s *BookkeepingSplitService) CreateExpense(userID, groupID uint, req dto.CreateSplitExpenseRequest) (*dto.SplitExpenseResponse, error) {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members := s.memberMap(&group)
+
+	payer, ok := members[req.PayerMemberID]
+	if !ok {
+		return nil, errors.New("付款成员不属于该账单组")
+	}
+
+	expenseDate, err := time.Parse("2006-01-02", req.ExpenseDate)
+	if err != nil {
+		return nil, errors.New("消费日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	shares, err := computeSplitShares(model.SplitMethod(req.Method), req.Amount, req.Shares, members)
+	if err != nil {
+		return nil, err
+	}
+
+	var ownShare float64
+	for _, share := range shares {
+		if members[share.MemberID].IsSelf {
+			ownShare = share.Amount
+		}
+	}
+
+	expense := model.SplitExpense{
+		UserID:        userID,
+		GroupID:       group.ID,
+		PayerMemberID: payer.ID,
+		Title:         req.Title,
+		Amount:        roundCent(req.Amount),
+		ExpenseDate:   expenseDate,
+		Method:        model.SplitMethod(req.Method),
+		CategoryID:    req.CategoryID,
+		Notes:         req.Notes,
+		Shares:        shares,
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		// 本人既不付款也不承担份额时，不影响本人账户
+		if payer.IsSelf || ownShare > 0 {
+			account, err := s.resolveAccount(tx, userID, req.AccountID)
+			if err != nil {
+				return err
+			}
+			expense.AccountID = &account.ID
+		}
+
+		if ownShare > 0 {
+			if err := s.checkExpenseCategory(tx, userID, req.CategoryID); err != nil {
+				return err
+			}
+			own := model.Transaction{
+				UserID:          userID,
+				AccountID:       *expense.AccountID,
+				Type:            model.TransactionTypeExpense,
+				Amount:          ownShare,
+				TransactionDate: expenseDate,
+				CategoryID:      req.CategoryID,
+				PayeePayer:      group.Name,
+				Notes:           req.Title,
+			}
+			if err := tx.Create(&own).Error; err != nil {
+				return err
+			}
+			expense.OwnTransactionID = &own.ID
+		}
+
+		if advance := roundCent(expense.Amount - ownShare); payer.IsSelf && advance > 0 {
+			category, err := ensureCategory(tx, userID, model.CategoryTypeExpense, splitAdvanceCategoryName)
+			if err != nil {
+				return err
+			}
+			transaction := model.Transaction{
+				UserID:           userID,
+				AccountID:        *expense.AccountID,
+				Type:             model.TransactionTypeExpense,
+				Amount:           advance,
+				TransactionDate:  expenseDate,
+				CategoryID:       category.ID,
+				PayeePayer:       group.Name,
+				Notes:            req.Title + " 替他人垫付",
+				ExcludeFromStats: true,
+			}
+			if err := tx.Create(&transaction).Error; err != nil {
+				return err
+			}
+			expense.AdvanceTransactionID = &transaction.ID
+		}
+
+		if !payer.IsSelf && ownShare > 0 {
+			category, err := ensureCategory(tx, userID, model.CategoryTypeIncome, splitAdvanceCategoryName)
+			if err != nil {
+				return err
+			}
+			transaction := model.Transaction{
+				UserID:           userID,
+				AccountID:        *expense.AccountID,
+				Type:             model.TransactionTypeIncome,
+				Amount:           ownShare,
+				TransactionDate:  expenseDate,
+				CategoryID:       category.ID,
+				PayeePayer:       payer.Name,
+				Notes:            fmt.Sprintf("%s %s代付", req.Title, payer.Name),
+				ExcludeFromStats: true,
+			}
+			if err := tx.Create(&transaction).Error; err != nil {
+				return err
+			}
+			expense.OffsetTransactionID = &transaction.ID
+		}
+
+		return tx.Create(&expense).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create split expense: " + err.Error())
+		return nil, userFacingError(err, "记录共同支出失败：数据库错误")
+	}
+
+	response := s.expenseToResponse(&expense, members)
+	return &response, nil
+}
+
+// ListExpenses 获取账单组的共同支出列表
+func (s *BookkeepingSplitService) ListExpenses(userID, groupID uint) ([]dto.SplitExpenseResponse, error) {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members := s.memberMap(&group)
+
+	var expenses []model.SplitExpense
+	if err := global.DB.Preload("Shares").Where("group_id = ?", group.ID).
+		Order("expense_date DESC, id DESC").Find(&expenses).Error; err != nil {
+		global.Logger.Error("Failed to list split expenses: " + err.Error())
+		return nil, errors.New("获取共同支出失败：数据库错误")
+	}
+
+	responses := make([]dto.SplitExpenseResponse, 0, len(expenses))
+	for i := range expenses {
+		responses = append(responses, s.expenseToResponse(&expenses[i], members))
+	}
+	return responses, nil
+}
+
+// DeleteExpense 删除共同支出及其生成的资金流水
+func (s *BookkeepingSplitService) DeleteExpense(userID, groupID, expenseID uint) error {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return err
+	}
+
+	var expense model.SplitExpense
+	if err := global.DB.Where("id = ? AND group_id = ?", expenseID, group.ID).First(&expense).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("共同支出不存在")
+		}
+		return errors.New("删除共同支出失败：数据库错误")
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		ids := make([]uint, 0, 3)
+		for _, id := range []*uint{expense.OwnTransactionID, expense.AdvanceTransactionID, expense.OffsetTransactionID} {
+			if id != nil {
+				ids = append(ids, *id)
+			}
+		}
+		if err := deleteTransactions(tx, userID, ids); err != nil {
+			return err
+		}
+		if err := tx.Where("expense_id = ?", expense.ID).Delete(&model.SplitShare{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&expense).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete split expense: " + err.Error())
+		return errors.New("删除共同支出失败：数据库错误")
+	}
+	return nil
+}
+
+// CreateSettlement 记录成员之间的结算转账
+// 本人付款时在本人账户记一笔支出，本人收款时记一笔收入，均不计入收支统计
+func (s *BookkeepingSplitService) CreateSettlement(userID, groupID uint, req dto.CreateSplitSettlementRequest) (*dto.SplitSettlementResponse, error) {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members := s.memberMap(&group)
+
+	from, ok := members[req.FromMemberID]
+	if !ok {
+		return nil, errors.New("付款成员不属于该账单组")
+	}
+	to, ok := members[req.ToMemberID]
+	if !ok {
+		return nil, errors.New("收款成员不属于该账单组")
+	}
+	if from.ID == to.ID {
+		return nil, errors.New("付款成员和收款成员不能相同")
+	}
+
+	settlementDate, err := time.Parse("2006-01-02", req.SettlementDate)
+	if err != nil {
+		return nil, errors.New("结算日期格式错误，请使用YYYY-MM-DD格式")
+	}
+
+	settlement := model.SplitSettlement{
+		UserID:         userID,
+		GroupID:        group.ID,
+		FromMemberID:   from.ID,
+		ToMemberID:     to.ID,
+		Amount:         roundCent(req.Amount),
+		SettlementDate: settlementDate,
+		Notes:          req.Notes,
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if from.IsSelf || to.IsSelf {
+			account, err := s.resolveAccount(tx, userID, req.AccountID)
+			if err != nil {
+				return err
+			}
+			settlement.AccountID = &account.ID
+
+			transactionType, categoryType := model.TransactionTypeExpense, model.CategoryTypeExpense
+			payee, notes := to.Name, fmt.Sprintf("%s 付给%s", group.Name, to.Name)
+			if to.IsSelf {
+				transactionType, categoryType = model.TransactionTypeIncome, model.CategoryTypeIncome
+				payee, notes = from.Name, fmt.Sprintf("%s 收到%s", group.Name, from.Name)
+			}
+			category, err := ensureCategory(tx, userID, categoryType, splitSettlementCategoryName)
+			if err != nil {
+				return err
+			}
+			transaction := model.Transaction{
+				UserID:           userID,
+				AccountID:        account.ID,
+				Type:             transactionType,
+				Amount:           settlement.Amount,
+				TransactionDate:  settlementDate,
+				CategoryID:       category.ID,
+				PayeePayer:       payee,
+				Notes:            notes,
+				ExcludeFromStats: true,
+			}
+			if err := tx.Create(&transaction).Error; err != nil {
+				return err
+			}
+			settlement.TransactionID = &transaction.ID
+		}
+		return tx.Create(&settlement).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to create split settlement: " + err.Error())
+		return nil, userFacingError(err, "记录结算失败：数据库错误")
+	}
+
+	response := s.settlementToResponse(&settlement, members)
+	return &response, nil
+}
+
+// ListSettlements 获取账单组的结算记录
+func (s *BookkeepingSplitService) ListSettlements(userID, groupID uint) ([]dto.SplitSettlementResponse, error) {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members := s.memberMap(&group)
+
+	var settlements []model.SplitSettlement
+	if err := global.DB.Where("group_id = ?", group.ID).Order("settlement_date DESC, id DESC").Find(&settlements).Error; err != nil {
+		global.Logger.Error("Failed to list split settlements: " + err.Error())
+		return nil, errors.New("获取结算记录失败：数据库错误")
+	}
+
+	responses := make([]dto.SplitSettlementResponse, 0, len(settlements))
+	for i := range settlements {
+		responses = append(responses, s.settlementToResponse(&settlements[i], members))
+	}
+	return responses, nil
+}
+
+// DeleteSettlement 删除结算记录及其生成的资金流水
+func (s *BookkeepingSplitService) DeleteSettlement(userID, groupID, settlementID uint) error {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return err
+	}
+
+	var settlement model.SplitSettlement
+	if err := global.DB.Where("id = ? AND group_id = ?", settlementID, group.ID).First(&settlement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("结算记录不存在")
+		}
+		return errors.New("删除结算记录失败：数据库错误")
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if settlement.TransactionID != nil {
+			if err := deleteTransactions(tx, userID, []uint{*settlement.TransactionID}); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&settlement).Error
+	})
+	if err != nil {
+		global.Logger.Error("Failed to delete split settlement: " + err.Error())
+		return errors.New("删除结算记录失败：数据库错误")
+	}
+	return nil
+}
+
+// GetBalances 获取账单组各成员的结余，以及结清所有欠款所需的最少转账
+func (s *BookkeepingSplitService) GetBalances(userID, groupID uint) (*dto.SplitBalanceResponse, error) {
+	group, err := s.findGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.memberBalances(&group)
+	if err != nil {
+		global.Logger.Error("Failed to calculate split group balances: " + err.Error())
+		return nil, errors.New("计算结余失败：数据库错误")
+	}
+
+	return &dto.SplitBalanceResponse{
+		GroupID:   group.ID,
+		Balances:  balances,
+		Transfers: minimalTransfers(balances),
+	}, nil
+}
+
+// memberBalances 计算各成员的垫付、应承担金额和结余 (含已结算)
+func (s *BookkeepingSplitService) memberBalances(group *model.SplitGroup) ([]dto.SplitMemberBalance, error) {
+	var expenses []model.SplitExpense
+	if err := global.DB.Preload("Shares").Where("group_id = ?", group.ID).Find(&expenses).Error; err != nil {
+		return nil, err
+	}
+	var settlements []model.SplitSettlement
+	if err := global.DB.Where("group_id = ?", group.ID).Find(&settlements).Error; err != nil {
+		return nil, err
+	}
+
+	index := make(map[uint]int, len(group.Members))
+	balances := make([]dto.SplitMemberBalance, 0, len(group.Members))
+	for _, member := range group.Members {
+		index[member.ID] = len(balances)
+		balances = append(balances, dto.SplitMemberBalance{MemberID: member.ID, MemberName: member.Name, IsSelf: member.IsSelf})
+	}
+
+	settled := make(map[uint]float64)
+	for _, expense := range expenses {
+		if i, ok := index[expense.PayerMemberID]; ok {
+			balances[i].Paid += expense.Amount
+		}
+		for _, share := range expense.Shares {
+			if i, ok := index[share.MemberID]; ok {
+				balances[i].Share += share.Amount
+			}
+		}
+	}
+	for _, settlement := range settlements {
+		settled[settlement.FromMemberID] += settlement.Amount
+		settled[settlement.ToMemberID] -= settlement.Amount
+	}
+
+	for i := range balances {
+		balances[i].Paid = roundCent(balances[i].Paid)
+		balances[i].Share = roundCent(balances[i].Share)
+		balances[i].Balance = roundCent(balances[i].Paid - balances[i].Share + settled[balances[i].MemberID])
+	}
+	return balances, nil
+}
+
+// resolveAccount 获取本人收付款账户，未指定时使用默认账户
+func (s *BookkeepingSplitService) resolveAccount(tx *gorm.DB, userID, accountID uint) (model.Account, error) {
+	var account model.Account
+	query := tx.Where("user_id = ?", userID)
+	if accountID != 0 {
+		query = query.Where("id = ?", accountID)
+	} else {
+		query = query.Where("is_default = ?", true)
+	}
+	if err := query.First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if accountID == 0 {
+				return account, newBizError("请指定账户，或先设置默认账户")
+			}
+			return account, newBizError("账户不存在或不属于您")
+		}
+		return account, err
+	}
+	if account.IsArchived {
+		return account, newBizError("该账户已归档，不能记录新的交易")
+	}
+	return account, nil
+}
+
+// checkExpenseCategory 校验本人份额的分类必须是当前用户未归档的支出分类
+func (s *BookkeepingSplitService) checkExpenseCategory(tx *gorm.DB, userID, categoryID uint) error {
+	if categoryID == 0 {
+		return newBizError("请指定本人份额的支出分类")
+	}
+	var category model.Category
+	if err := tx.Where("id = ? AND user_id = ?", categoryID, userID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return newBizError("分类不存在或不属于您")
+		}
+		return err
+	}
+	if category.Type != model.CategoryTypeExpense {
+		return newBizError("分类必须是支出分类")
+	}
+	if category.IsArchived {
+		return newBizError("该分类已归档，不能用于新的交易")
+	}
+	return nil
+}
+
+// findGroup 查询属于当前用户的账单组（含成员）
+func (s *BookkeepingSplitService) findGroup(userID, groupID uint) (model.SplitGroup, error) {
+	var group model.SplitGroup
+	if err := global.DB.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("is_self DESC, id ASC")
+	}).Where("id = ? AND user_id = ?", groupID, userID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return group, errors.New("账单组不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get split group: " + err.Error())
+		return group, errors.New("获取账单组失败：数据库错误")
+	}
+	return group, nil
+}
+
+// memberMap 按ID索引账单组成员
+func (s *BookkeepingSplitService) memberMap(group *model.SplitGroup) map[uint]model.SplitMember {
+	members := make(map[uint]model.SplitMember, len(group.Members))
+	for _, member := range group.Members {
+		members[member.ID] = member
+	}
+	return members
+}
+
+// groupToResponse 辅助函数，将账单组模型转换为响应对象
+func (s *BookkeepingSplitService) groupToResponse(group *model.SplitGroup) (dto.SplitGroupResponse, error) {
+	response := dto.SplitGroupResponse{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		Members:     make([]dto.SplitMemberResponse, 0, len(group.Members)),
+		CreatedAt:   group.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	for _, member := range group.Members {
+		response.Members = append(response.Members, dto.SplitMemberResponse{ID: member.ID, Name: member.Name, IsSelf: member.IsSelf})
+	}
+
+	balances, err := s.memberBalances(group)
+	if err != nil {
+		return response, err
+	}
+	for _, balance := range balances {
+		response.TotalExpense += balance.Paid
+		if balance.IsSelf {
+			response.SelfBalance = balance.Balance
+		}
+	}
+	response.TotalExpense = roundCent(response.TotalExpense)
+	return response, nil
+}
+
+// expenseToResponse 辅助函数，将共同支出模型转换为响应对象
+func (s *BookkeepingSplitService) expenseToResponse(expense *model.SplitExpense, members map[uint]model.SplitMember) dto.SplitExpenseResponse {
+	response := dto.SplitExpenseResponse{
+		ID:                   expense.ID,
+		GroupID:              expense.GroupID,
+		Title:                expense.Title,
+		Amount:               expense.Amount,
+		ExpenseDate:          expense.ExpenseDate.Format("2006-01-02"),
+		PayerMemberID:        expense.PayerMemberID,
+		PayerName:            members[expense.PayerMemberID].Name,
+		Method:               string(expense.Method),
+		CategoryID:           expense.CategoryID,
+		AccountID:            expense.AccountID,
+		OwnTransactionID:     expense.OwnTransactionID,
+		AdvanceTransactionID: expense.AdvanceTransactionID,
+		OffsetTransactionID:  expense.OffsetTransactionID,
+		Shares:               make([]dto.SplitShareResponse, 0, len(expense.Shares)),
+		Notes:                expense.Notes,
+		CreatedAt:            expense.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	for _, share := range expense.Shares {
+		member := members[share.MemberID]
+		if member.IsSelf {
+			response.OwnShare = share.Amount
+		}
+		response.Shares = append(response.Shares, dto.SplitShareResponse{
+			MemberID:   share.MemberID,
+			MemberName: member.Name,
+			Weight:     share.Weight,
+			Amount:     share.Amount,
+		})
+	}
+	return response
+}
+
+// settlementToResponse 辅助函数，将结算记录模型转换为响应对象
+func (s *BookkeepingSplitService) settlementToResponse(settlement *model.SplitSettlement, members map[uint]model.SplitMember) dto.SplitSettlementResponse {
+	return dto.SplitSettlementResponse{
+		ID:             settlement.ID,
+		GroupID:        settlement.GroupID,
+		FromMemberID:   settlement.FromMemberID,
+		FromName:       members[settlement.FromMemberID].Name,
+		ToMemberID:     settlement.ToMemberID,
+		ToName:         members[settlement.ToMemberID].Name,
+		Amount:         settlement.Amount,
+		SettlementDate: settlement.SettlementDate.Format("2006-01-02"),
+		AccountID:      settlement.AccountID,
+		TransactionID:  settlement.TransactionID,
+		Notes:          settlement.Notes,
+		CreatedAt:      settlement.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// computeSplitShares 按分摊方式计算每个成员承担的金额，以分为单位计算，尾差分给排在前面的成员
+func computeSplitShares(method model.SplitMethod, amount float64, items []dto.SplitShareItem, members map[uint]model.SplitMember) ([]model.SplitShare, error) {
+	seen := make(map[uint]bool, len(items))
+	for _, item := range items {
+		if _, ok := members[item.MemberID]; !ok {
+			return nil, errors.New("分摊成员不属于该账单组")
+		}
+		if seen[item.MemberID] {
+			return nil, errors.New("分摊成员重复")
+		}
+		seen[item.MemberID] = true
+	}
+
+	total := int64(math.Round(amount * 100))
+	cents := make([]int64, len(items))
+	shares := make([]model.SplitShare, len(items))
+
+	switch method {
+	case model.SplitMethodEqual:
+		n := int64(len(items))
+		for i := range items {
+			cents[i] = total / n
+			if int64(i) < total%n {
+				cents[i]++
+			}
+		}
+	case model.SplitMethodShares:
+		var weightSum float64
+		for _, item := range items {
+			if item.Value <= 0 {
+				return nil, errors.New("按份数分摊时每个成员的份数必须大于0")
+			}
+			weightSum += item.Value
+		}
+		var allocated int64
+		for i, item := range items {
+			cents[i] = int64(math.Floor(float64(total) * item.Value / weightSum))
+			allocated += cents[i]
+			shares[i].Weight = item.Value
+		}
+		for i := 0; allocated < total; i = (i + 1) % len(items) {
+			cents[i]++
+			allocated++
+		}
+	case model.SplitMethodExact:
+		var sum int64
+		for i, item := range items {
+			cents[i] = int64(math.Round(item.Value * 100))
+			sum += cents[i]
+		}
+		if sum != total {
+			return nil, fmt.Errorf("各成员金额之和 %.2f 与总金额 %.2f 不一致", float64(sum)/100, float64(total)/100)
+		}
+	default:
+		return nil, errors.New("不支持的分摊方式")
+	}
+
+	for i, item := range items {
+		shares[i].MemberID = item.MemberID
+		shares[i].Amount = float64(cents[i]) / 100
+	}
+	return shares, nil
+}
+
+// minimalTransfers 根据成员结余计算结清欠款的转账方案
+// 每次由欠款最多的成员向被欠款最多的成员转账，转账笔数不超过成员数减一
+func minimalTransfers(balances []dto.SplitMemberBalance) []dto.SplitTransfer {
+	type party struct {
+		id    uint
+		name  string
+		cents int64
+	}
+
+	var creditors, debtors []party
+	for _, balance := range balances {
+		cents := int64(math.Round(balance.Balance * 100))
+		if cents > 0 {
+			creditors = append(creditors, party{balance.MemberID, balance.MemberName, cents})
+		} else if cents < 0 {
+			debtors = append(debtors, party{balance.MemberID, balance.MemberName, -cents})
+		}
+	}
+	sort.SliceStable(creditors, func(i, j int) bool { return creditors[i].cents > creditors[j].cents })
+	sort.SliceStable(debtors, func(i, j int) bool { return debtors[i].cents > debtors[j].cents })
+
+	transfers := make([]dto.SplitTransfer, 0)
+	for i, j := 0, 0; i < len(debtors) && j < len(creditors); {
+		amount := debtors[i].cents
+		if creditors[j].cents < amount {
+			amount = creditors[j].cents
+		}
+		transfers = append(transfers, dto.SplitTransfer{
+			FromMemberID: debtors[i].id,
+			FromName:     debtors[i].name,
+			ToMemberID:   creditors[j].id,
+			ToName:       creditors[j].name,
+			Amount:       float64(amount) / 100,
+		})
+		debtors[i].cents -= amount
+		creditors[j].cents -= amount
+		if debtors[i].cents == 0 {
+			i++
+		}
+		if creditors[j].cents == 0 {
+			j++
+		}
+	}
+	return transfers
+}
@@ -66,8 +66,8 @@ func (s *StatisticsService) GetIncomeExpenseSummary(userID uint, rangeType strin
 
 	// 计算总收入
 	if err := global.DB.Model(&model.Transaction{}).
-		Where("user_id = ? AND type = ? AND transaction_date BETWEEN ? AND ?", 
-			userID, model.TransactionTypeIncome, start, end).
+		Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND transaction_date BETWEEN ? AND ?", 
+			userID, model.TransactionTypeIncome, false, start, end).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&totalIncome).Error; err != nil {
 		return nil, err
@@ -75,8 +75,8 @@ func (s *StatisticsService) GetIncomeExpenseSummary(userID uint, rangeType strin
 
 	// 计算总支出
 	if err := global.DB.Model(&model.Transaction{}).
-		Where("user_id = ? AND type = ? AND transaction_date BETWEEN ? AND ?", 
-			userID, model.TransactionTypeExpense, start, end).
+		Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND transaction_date BETWEEN ? AND ?", 
+			userID, model.TransactionTypeExpense, false, start, end).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&totalExpense).Error; err != nil {
 		return nil, err
@@ -92,33 +92,35 @@ func (s *StatisticsService) GetIncomeExpenseSummary(userID uint, rangeType strin
 	}, nil
 }
 
-// GetCategorySummary 获取指定时间范围内的分类汇总
+// GetCategorySummary 获取指定时间范围内的分类汇总，以分类树返回，子分类的金额同时计入所有上级分类
 func (s *StatisticsService) GetCategorySummary(userID uint, transactionType model.TransactionType, rangeType string, customStart, customEnd *time.Time) ([]*dto.CategorySummaryItem, error) {
 	start, end, err := s.GetTimeRange(rangeType, customStart, customEnd)
 	if err != nil {
 		return nil, err
 	}
 
-	var result []*dto.CategorySummaryItem
-
+	// 按分类统计直接记在各分类下的金额
+	var rows []categoryAmount
 	err = global.DB.Model(&model.Transaction{}).
-		Select("c.id as category_id, c.name as category_name, c.icon as category_icon, COALESCE(SUM(t.amount), 0) as total_amount, COUNT(t.id) as transaction_count").
-		Joins("JOIN bookkeeping_categories c ON t.category_id = c.id").
-		Where("t.user_id = ? AND t.type = ? AND t.transaction_date BETWEEN ? AND ?",
-			userID, transactionType, start, end).
-		Group("c.id, c.name, c.icon").
-		Order("total_amount DESC").
-		Table("bookkeeping_transactions t").
-		Scan(&result).Error
-
+		Select("category_id, COALESCE(SUM(amount), 0) as total_amount, COUNT(id) as transaction_count").
+		Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND transaction_date BETWEEN ? AND ?",
+			userID, transactionType, false, start, end).
+		Group("category_id").
+		Scan(&rows).Error
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return []*dto.CategorySummaryItem{}, nil
-		}
 		return nil, err
 	}
+	if len(rows) == 0 {
+		return []*dto.CategorySummaryItem{}, nil
+	}
 
-	return result, nil
+	// 已删除的分类下仍可能有交易，需要一并加载才能挂到正确的上级分类
+	var categories []model.Category
+	if err := global.DB.Unscoped().Where("user_id = ?", userID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return buildCategorySummaryTree(categories, rows), nil
 }
 
 // GetAccountSummary 获取账户余额汇总
@@ -135,17 +137,137 @@ func (s *StatisticsService) GetAccountSummary(userID uint) ([]*dto.AccountSummar
 
 	for _, account := range accounts {
 		result = append(result, &dto.AccountSummaryItem{
-			AccountID:      account.ID,
-			AccountName:    account.Name,
-			AccountType:    string(account.Type),
-			CurrentBalance: account.CurrentBalance,
-			InitialBalance: account.InitialBalance,
+			AccountID:           account.ID,
+			AccountName:         account.Name,
+			AccountType:         string(account.Type),
+			CurrentBalance:      account.CurrentBalance,
+			InitialBalance:      account.InitialBalance,
+			Nature:              string(account.Nature),
+			ExcludeFromNetWorth: account.ExcludeFromNetWorth,
 		})
 	}
 
 	return result, nil
 }
 
+// GetNetWorth 获取当前净资产（总资产、总负债及各账户明细）
+// 标记为不计入净资产的账户不参与统计，负债账户的欠款计入总负债，投资账户的持仓市值计入总资产
+func (s *StatisticsService) GetNetWorth(userID uint) (*dto.NetWorthResponse, error) {
+	var accounts []*model.Account
+	if err := global.DB.Where("user_id = ? AND exclude_from_net_worth = ?", userID, false).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	investmentService := BookkeepingInvestmentService{}
+	marketValues, err := investmentService.holdingsMarketValue(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.NetWorthResponse{
+		Assets:      []*dto.NetWorthAccountItem{},
+		Liabilities: []*dto.NetWorthAccountItem{},
+	}
+	for _, account := range accounts {
+		item := &dto.NetWorthAccountItem{
+			AccountID:   account.ID,
+			AccountName: account.Name,
+			AccountType: string(account.Type),
+			Nature:      string(account.Nature),
+			Balance:     account.CurrentBalance,
+			MarketValue: marketValues[account.ID],
+		}
+		if account.IsLiability() {
+			item.Value = -account.CurrentBalance
+			result.TotalLiabilities += item.Value
+			result.Liabilities = append(result.Liabilities, item)
+		} else {
+			item.Value = account.CurrentBalance + item.MarketValue
+			result.TotalAssets += item.Value
+			result.Assets = append(result.Assets, item)
+		}
+	}
+	result.NetWorth = result.TotalAssets - result.TotalLiabilities
+
+	return result, nil
+}
+
+// GetNetWorthTrend 获取月度净资产趋势，由各账户的交易历史推算每个月末的余额
+func (s *StatisticsService) GetNetWorthTrend(userID uint, monthsCount int) (*dto.NetWorthTrendResponse, error) {
+	if monthsCount <= 0 {
+		monthsCount = 12 // 默认显示12个月
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -monthsCount+1, 0)
+
+	var accounts []*model.Account
+	if err := global.DB.Where("user_id = ? AND exclude_from_net_worth = ?", userID, false).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	monthlyData := make([]*dto.NetWorthMonthlyData, 0, monthsCount)
+	for month := start; !month.After(today); month = month.AddDate(0, 1, 0) {
+		monthlyData = append(monthlyData, &dto.NetWorthMonthlyData{MonthLabel: month.Format("2006-01")})
+	}
+
+	// 各月末的投资持仓市值
+	investmentService := BookkeepingInvestmentService{}
+	monthlyMarketValues := make([]map[uint]float64, len(monthlyData))
+	for i, month := range monthlyData {
+		monthStart, _ := time.ParseInLocation("2006-01", month.MonthLabel, time.Local)
+		monthEnd := monthStart.AddDate(0, 1, -1)
+		if monthEnd.After(today) {
+			monthEnd = today
+		}
+		values, err := investmentService.holdingsMarketValue(userID, monthEnd)
+		if err != nil {
+			return nil, err
+		}
+		monthlyMarketValues[i] = values
+	}
+
+	balanceService := BookkeepingBalanceService{}
+	for _, account := range accounts {
+		if err := balanceService.refreshSnapshots(global.DB, account); err != nil {
+			global.Logger.Error("Failed to refresh balance snapshots: " + err.Error())
+		}
+
+		points, err := balanceService.balanceSeries(global.DB, account, start, today, "month")
+		if err != nil {
+			return nil, err
+		}
+
+		createdMonth := account.CreatedAt.Format("2006-01")
+		for i, point := range points {
+			if i >= len(monthlyData) {
+				break
+			}
+			// 账户创建之前，初始余额尚未存在
+			balance := point.Balance
+			if point.Date < createdMonth {
+				balance -= account.InitialBalance
+			}
+
+			if account.IsLiability() {
+				monthlyData[i].TotalLiabilities += -balance
+			} else {
+				monthlyData[i].TotalAssets += balance + monthlyMarketValues[i][account.ID]
+			}
+		}
+	}
+
+	for _, data := range monthlyData {
+		data.NetWorth = data.TotalAssets - data.TotalLiabilities
+	}
+
+	return &dto.NetWorthTrendResponse{
+		MonthsCount: monthsCount,
+		Data:        monthlyData,
+	}, nil
+}
+
 // GetMonthlyTrend 获取月度收支趋势
 func (s *StatisticsService) GetMonthlyTrend(userID uint, monthsCount int) (*dto.MonthlyTrendResponse, error) {
 	if monthsCount <= 0 {
@@ -167,15 +289,15 @@ func (s *StatisticsService) GetMonthlyTrend(userID uint, monthsCount int) (*dto.
 
 		// 查询收入
 		global.DB.Model(&model.Transaction{}).
-			Where("user_id = ? AND type = ? AND transaction_date >= ? AND transaction_date < ?",
-				userID, model.TransactionTypeIncome, currentMonth, nextMonth).
+			Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND transaction_date >= ? AND transaction_date < ?",
+				userID, model.TransactionTypeIncome, false, currentMonth, nextMonth).
 			Select("COALESCE(SUM(amount), 0)").
 			Scan(&monthlyIncome)
 
 		// 查询支出
 		global.DB.Model(&model.Transaction{}).
-			Where("user_id = ? AND type = ? AND transaction_date >= ? AND transaction_date < ?",
-				userID, model.TransactionTypeExpense, currentMonth, nextMonth).
+			Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND transaction_date >= ? AND transaction_date < ?",
+				userID, model.TransactionTypeExpense, false, currentMonth, nextMonth).
 			Select("COALESCE(SUM(amount), 0)").
 			Scan(&monthlyExpense)
 
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// 订阅检测的参数
+const (
+	subscriptionDefaultMonths     = 24   // 默认分析最近几个月的交易
+	subscriptionAmountTolerance   = 0.35 // 扣费金额与中位数相差在该比例以内视为同一订阅，可以容纳涨价
+	subscriptionRegularRatio      = 0.75 // 至少该比例的扣费间隔符合频率才视为定期扣费
+	subscriptionLapsedPeriods     = 2    // 超过几个扣费周期没有扣费视为已失效
+	subscriptionPriceIncreaseRate = 0.01 // 扣费金额上涨超过该比例才标记为涨价
+)
+
+// subscriptionFrequencies 可识别的扣费频率：标准间隔天数、允许的偏差天数和最少扣费次数
+var subscriptionFrequencies = []struct {
+	frequency  model.BillFrequency
+	days       float64
+	tolerance  float64
+	minCharges int
+	perYear    float64
+}{
+	{model.BillFrequencyWeekly, 7, 1.5, 4, 52},
+	{model.BillFrequencyMonthly, 30.44, 3.5, 3, 12},
+	{model.BillFrequencyQuarterly, 91.31, 8, 3, 4},
+	{model.BillFrequencyYearly, 365.25, 15, 2, 1},
+}
+
+// subscriptionPattern 一个收款方的定期扣费规律
+type subscriptionPattern struct {
+	PayeeKey         string
+	Payee            string              // 最近一次扣费记录中的收款方写法
+	Frequency        model.BillFrequency // 扣费频率
+	IntervalDays     float64             // 平均扣费间隔天数
+	PerYear          float64             // 每年扣费次数
+	Charges          []model.Transaction // 属于该订阅的扣费，按日期升序
+	Amount           float64             // 最近一次扣费金额
+	AverageAmount    float64
+	NextExpectedDate time.Time
+	IsLapsed         bool
+	PriceIncrease    *dto.SubscriptionPriceIncrease
+}
+
+// last 最近一次扣费
+func (p *subscriptionPattern) last() *model.Transaction {
+	return &p.Charges[len(p.Charges)-1]
+}
+
+// monthlyCost 按最近一次扣费金额估算的每月费用
+func (p *subscriptionPattern) monthlyCost() float64 {
+	return roundCent(p.Amount * p.PerYear / 12)
+}
+
+// annualCost 按最近一次扣费金额估算的每年费用
+func (p *subscriptionPattern) annualCost() float64 {
+	return roundCent(p.Amount * p.PerYear)
+}
+
+// subscriptionPayeeKey 规范化收款方，忽略大小写和多余空白
+func subscriptionPayeeKey(payee string) string {
+	return strings.ToLower(strings.Join(strings.Fields(payee), " "))
+}
+
+// subscriptionCharges 加载 since 之后有收款方的支出，按规范化的收款方分组
+// 不计入收支统计的资金流水 (如借贷、还款本金) 和账单付款生成的支出 (已作为账单跟踪) 不参与检测
+func subscriptionCharges(db *gorm.DB, userID uint, since time.Time) (map[string][]model.Transaction, error) {
+	billTransactions := db.Model(&model.BillPayment{}).Select("transaction_id").
+		Where("user_id = ? AND transaction_id IS NOT NULL", userID)
+
+	var transactions []model.Transaction
+	if err := db.Preload("Account").Preload("Category").
+		Where("user_id = ? AND type = ? AND exclude_from_stats = ? AND payee_payer <> '' AND transaction_date >= ?",
+			userID, model.TransactionTypeExpense, false, since).
+		Where("id NOT IN (?)", billTransactions).
+		Order("transaction_date ASC, id ASC").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]model.Transaction)
+	for i := range transactions {
+		key := subscriptionPayeeKey(transactions[i].PayeePayer)
+		if key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], transactions[i])
+	}
+	return groups, nil
+}
+
+// detectSubscription 分析同一收款方的支出 (按日期升序) 是否为金额相近、间隔规律的定期扣费
+// 与金额中位数相差过大的支出视为该收款方的其他消费，不参与分析
+func detectSubscription(key string, charges []model.Transaction, today time.Time) (*subscriptionPattern, bool) {
+	if len(charges) < 2 {
+		return nil, false
+	}
+
+	amounts := make([]float64, 0, len(charges))
+	for i := range charges {
+		amounts = append(amounts, charges[i].Amount)
+	}
+	median := medianFloat(amounts)
+	if median <= 0 {
+		return nil, false
+	}
+	var similar []model.Transaction
+	for i := range charges {
+		if math.Abs(charges[i].Amount-median) <= median*subscriptionAmountTolerance {
+			similar = append(similar, charges[i])
+		}
+	}
+	if len(similar) < 2 {
+		return nil, false
+	}
+
+	gaps := make([]float64, 0, len(similar)-1)
+	for i := 1; i < len(similar); i++ {
+		gaps = append(gaps, float64(daysBetween(truncateToDay(similar[i-1].TransactionDate), truncateToDay(similar[i].TransactionDate))))
+	}
+	medianGap := medianFloat(gaps)
+
+	for _, candidate := range subscriptionFrequencies {
+		if len(similar) < candidate.minCharges || math.Abs(medianGap-candidate.days) > candidate.tolerance {
+			continue
+		}
+		regular := 0
+		for _, gap := range gaps {
+			if math.Abs(gap-candidate.days) <= candidate.tolerance {
+				regular++
+			}
+		}
+		if float64(regular) < float64(len(gaps))*subscriptionRegularRatio {
+			return nil, false
+		}
+
+		pattern := &subscriptionPattern{
+			PayeeKey:  key,
+			Frequency: candidate.frequency,
+			PerYear:   candidate.perYear,
+			Charges:   similar,
+		}
+		var total, gapTotal float64
+		for i := range similar {
+			total += similar[i].Amount
+		}
+		for _, gap := range gaps {
+			gapTotal += gap
+		}
+		last := pattern.last()
+		pattern.Payee = strings.TrimSpace(last.PayeePayer)
+		pattern.Amount = roundCent(last.Amount)
+		pattern.AverageAmount = roundCent(total / float64(len(similar)))
+		pattern.IntervalDays = math.Round(gapTotal/float64(len(gaps))*10) / 10
+		pattern.NextExpectedDate = subscriptionChargeDate(candidate.frequency, truncateToDay(last.TransactionDate), 1)
+		lapsedAfter := truncateToDay(last.TransactionDate).AddDate(0, 0, int(math.Ceil(candidate.days*subscriptionLapsedPeriods+candidate.tolerance)))
+		pattern.IsLapsed = today.After(lapsedAfter)
+		pattern.PriceIncrease = subscriptionPriceIncrease(similar)
+		return pattern, true
+	}
+	return nil, false
+}
+
+// subscriptionChargeDate 按扣费频率推算最近一次扣费之后第 n 次扣费的日期，每次都从最近一次扣费推算，避免月末日期逐月漂移
+func subscriptionChargeDate(frequency model.BillFrequency, last time.Time, n int) time.Time {
+	switch frequency {
+	case model.BillFrequencyWeekly:
+		return last.AddDate(0, 0, 7*n)
+	case model.BillFrequencyQuarterly:
+		return addMonthsClamped(last, 3*n)
+	case model.BillFrequencyYearly:
+		return addMonthsClamped(last, 12*n)
+	}
+	return addMonthsClamped(last, n)
+}
+
+// subscriptionPriceIncrease 最近一次价格变化为上涨时返回涨价信息：从最后一次扣费向前找到第一笔金额不同的扣费作为涨价前的价格
+func subscriptionPriceIncrease(charges []model.Transaction) *dto.SubscriptionPriceIncrease {
+	current := roundCent(charges[len(charges)-1].Amount)
+	changedAt := len(charges) - 1
+	for changedAt > 0 && roundCent(charges[changedAt-1].Amount) == current {
+		changedAt--
+	}
+	if changedAt == 0 {
+		return nil
+	}
+	previous := roundCent(charges[changedAt-1].Amount)
+	if previous <= 0 || current-previous <= previous*subscriptionPriceIncreaseRate {
+		return nil
+	}
+	return &dto.SubscriptionPriceIncrease{
+		PreviousAmount: previous,
+		CurrentAmount:  current,
+		IncreaseAmount: roundCent(current - previous),
+		IncreaseRate:   math.Round((current-previous)/previous*10000) / 10000,
+		ChangedOn:      charges[changedAt].TransactionDate.Format("2006-01-02"),
+	}
+}
+
+// subscriptionUpcomingExpenses 已确认且未失效的订阅在 [from, to] 之间的预计扣费，用于预算预测
+func subscriptionUpcomingExpenses(db *gorm.DB, userID uint, from, to time.Time) ([]upcomingExpense, error) {
+	var subscriptions []model.Subscription
+	if err := db.Where("user_id = ? AND status = ?", userID, model.SubscriptionStatusTracked).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	if len(subscriptions) == 0 {
+		return nil, nil
+	}
+
+	today := truncateToDay(time.Now())
+	groups, err := subscriptionCharges(db, userID, today.AddDate(0, -subscriptionDefaultMonths, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []upcomingExpense
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		pattern, ok := detectSubscription(subscription.PayeeKey, groups[subscription.PayeeKey], today)
+		if !ok || pattern.IsLapsed {
+			continue
+		}
+		last := truncateToDay(pattern.last().TransactionDate)
+		for n := 1; ; n++ {
+			date := subscriptionChargeDate(pattern.Frequency, last, n)
+			if date.After(to) {
+				break
+			}
+			if date.Before(from) {
+				continue
+			}
+			result = append(result, upcomingExpense{
+				Source:     "subscription",
+				Name:       subscription.Name,
+				Date:       date,
+				Amount:     pattern.Amount,
+				CategoryID: pattern.last().CategoryID,
+				AccountID:  pattern.last().AccountID,
+				Payee:      pattern.Payee,
+			})
+		}
+	}
+	return result, nil
+}
+
+// medianFloat 中位数，values 会被排序
+func medianFloat(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
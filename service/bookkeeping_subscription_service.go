@@ -0,0 +1,328 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dotdancer/gogofly/global"
+	"github.com/dotdancer/gogofly/model"
+	"github.com/dotdancer/gogofly/service/dto"
+	"gorm.io/gorm"
+)
+
+// BookkeepingSubscriptionService 订阅检测与跟踪服务
+type BookkeepingSubscriptionService struct{}
+
+// ListCandidates 从最近若干个月的支出中检测候选订阅：同一收款方、金额相近、间隔规律的扣费
+// 已确认或已忽略的收款方、已失效 (超过两个扣费周期没有扣费) 的订阅不再返回；按每月费用从高到低排序
+func (s *BookkeepingSubscriptionService) ListCandidates(userID uint, query dto.SubscriptionCandidateQuery) (*dto.SubscriptionCandidateListResponse, error) {
+	months := query.Months
+	if months <= 0 {
+		months = subscriptionDefaultMonths
+	}
+
+	today := truncateToDay(time.Now())
+	groups, err := subscriptionCharges(global.DB, userID, today.AddDate(0, -months, 0))
+	if err != nil {
+		global.Logger.Error("Failed to load subscription charges: " + err.Error())
+		return nil, errors.New("检测订阅失败：数据库错误")
+	}
+
+	var decided []string
+	if err := global.DB.Model(&model.Subscription{}).Where("user_id = ?", userID).Pluck("payee_key", &decided).Error; err != nil {
+		global.Logger.Error("Failed to list subscriptions: " + err.Error())
+		return nil, errors.New("检测订阅失败：数据库错误")
+	}
+	skip := make(map[string]bool, len(decided))
+	for _, key := range decided {
+		skip[key] = true
+	}
+
+	response := &dto.SubscriptionCandidateListResponse{
+		Months: months,
+		Items:  []dto.SubscriptionCandidate{},
+	}
+	for key, charges := range groups {
+		if skip[key] {
+			continue
+		}
+		pattern, ok := detectSubscription(key, charges, today)
+		if !ok || pattern.IsLapsed {
+			continue
+		}
+		candidate := s.patternToCandidate(pattern)
+		response.MonthlyTotal += candidate.MonthlyCost
+		response.AnnualTotal += candidate.AnnualCost
+		response.Items = append(response.Items, candidate)
+	}
+	sort.Slice(response.Items, func(i, j int) bool {
+		if response.Items[i].MonthlyCost != response.Items[j].MonthlyCost {
+			return response.Items[i].MonthlyCost > response.Items[j].MonthlyCost
+		}
+		return response.Items[i].Payee < response.Items[j].Payee
+	})
+	response.MonthlyTotal = roundCent(response.MonthlyTotal)
+	response.AnnualTotal = roundCent(response.AnnualTotal)
+	return response, nil
+}
+
+// ConfirmSubscription 将候选订阅确认为跟踪的订阅，之前忽略过的收款方也可以确认
+func (s *BookkeepingSubscriptionService) ConfirmSubscription(userID uint, req dto.ConfirmSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	key := subscriptionPayeeKey(req.Payee)
+	if key == "" {
+		return nil, errors.New("收款方不能为空")
+	}
+
+	today := truncateToDay(time.Now())
+	groups, err := subscriptionCharges(global.DB, userID, today.AddDate(0, -subscriptionDefaultMonths, 0))
+	if err != nil {
+		global.Logger.Error("Failed to load subscription charges: " + err.Error())
+		return nil, errors.New("确认订阅失败：数据库错误")
+	}
+	pattern, ok := detectSubscription(key, groups[key], today)
+	if !ok {
+		return nil, errors.New("该收款方没有检测到定期扣费")
+	}
+
+	subscription, err := s.findByPayee(userID, key)
+	if err != nil {
+		return nil, errors.New("确认订阅失败：数据库错误")
+	}
+	if subscription.ID != 0 && subscription.Status == model.SubscriptionStatusTracked {
+		return nil, errors.New("该订阅已确认")
+	}
+
+	last := pattern.last()
+	subscription.UserID = userID
+	subscription.PayeeKey = key
+	subscription.Payee = pattern.Payee
+	subscription.Name = strings.TrimSpace(req.Name)
+	if subscription.Name == "" {
+		subscription.Name = pattern.Payee
+	}
+	subscription.Status = model.SubscriptionStatusTracked
+	subscription.Frequency = pattern.Frequency
+	subscription.Amount = pattern.Amount
+	subscription.CategoryID = last.CategoryID
+	subscription.AccountID = last.AccountID
+	subscription.Notes = req.Notes
+	if err := global.DB.Save(&subscription).Error; err != nil {
+		global.Logger.Error("Failed to confirm subscription: " + err.Error())
+		return nil, errors.New("确认订阅失败：数据库错误")
+	}
+
+	response := s.subscriptionToResponse(&subscription, pattern)
+	return &response, nil
+}
+
+// DismissSubscription 忽略候选订阅，该收款方不再作为候选返回；已确认的订阅也可以改为忽略
+func (s *BookkeepingSubscriptionService) DismissSubscription(userID uint, req dto.DismissSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	key := subscriptionPayeeKey(req.Payee)
+	if key == "" {
+		return nil, errors.New("收款方不能为空")
+	}
+
+	subscription, err := s.findByPayee(userID, key)
+	if err != nil {
+		return nil, errors.New("忽略订阅失败：数据库错误")
+	}
+	if subscription.ID != 0 && subscription.Status == model.SubscriptionStatusDismissed {
+		return nil, errors.New("该订阅已忽略")
+	}
+	if subscription.ID == 0 {
+		subscription = model.Subscription{
+			UserID:   userID,
+			PayeeKey: key,
+			Payee:    strings.TrimSpace(req.Payee),
+			Name:     strings.TrimSpace(req.Payee),
+		}
+	}
+	subscription.Status = model.SubscriptionStatusDismissed
+	if err := global.DB.Save(&subscription).Error; err != nil {
+		global.Logger.Error("Failed to dismiss subscription: " + err.Error())
+		return nil, errors.New("忽略订阅失败：数据库错误")
+	}
+
+	response := s.subscriptionToResponse(&subscription, nil)
+	return &response, nil
+}
+
+// ListSubscriptions 获取已确认 (默认) 或已忽略的订阅
+// 已确认的订阅从最近24个月的交易重新分析最近扣费、下次预计扣费和涨价，未失效订阅的费用计入合计
+func (s *BookkeepingSubscriptionService) ListSubscriptions(userID uint, query dto.SubscriptionQuery) (*dto.SubscriptionListResponse, error) {
+	status := model.SubscriptionStatusTracked
+	if query.Status != "" {
+		status = model.SubscriptionStatus(query.Status)
+	}
+
+	var subscriptions []model.Subscription
+	if err := global.DB.Where("user_id = ? AND status = ?", userID, status).Order("name ASC, id ASC").Find(&subscriptions).Error; err != nil {
+		global.Logger.Error("Failed to list subscriptions: " + err.Error())
+		return nil, errors.New("获取订阅列表失败：数据库错误")
+	}
+
+	response := &dto.SubscriptionListResponse{Items: make([]dto.SubscriptionResponse, 0, len(subscriptions))}
+	if len(subscriptions) == 0 {
+		return response, nil
+	}
+
+	var groups map[string][]model.Transaction
+	today := truncateToDay(time.Now())
+	if status == model.SubscriptionStatusTracked {
+		var err error
+		if groups, err = subscriptionCharges(global.DB, userID, today.AddDate(0, -subscriptionDefaultMonths, 0)); err != nil {
+			global.Logger.Error("Failed to load subscription charges: " + err.Error())
+			return nil, errors.New("获取订阅列表失败：数据库错误")
+		}
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		var pattern *subscriptionPattern
+		if status == model.SubscriptionStatusTracked {
+			pattern, _ = detectSubscription(subscription.PayeeKey, groups[subscription.PayeeKey], today)
+		}
+		item := s.subscriptionToResponse(subscription, pattern)
+		if status == model.SubscriptionStatusTracked && pattern == nil {
+			s.fillWithoutPattern(&item, subscription, groups[subscription.PayeeKey], today)
+		}
+		if status == model.SubscriptionStatusTracked && !item.IsLapsed {
+			response.MonthlyTotal += item.MonthlyCost
+			response.AnnualTotal += item.AnnualCost
+		}
+		response.Items = append(response.Items, item)
+	}
+	response.MonthlyTotal = roundCent(response.MonthlyTotal)
+	response.AnnualTotal = roundCent(response.AnnualTotal)
+	return response, nil
+}
+
+// UpdateSubscription 更新订阅的名称和备注
+func (s *BookkeepingSubscriptionService) UpdateSubscription(userID, subscriptionID uint, req dto.UpdateSubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	var subscription model.Subscription
+	if err := global.DB.Where("id = ? AND user_id = ?", subscriptionID, userID).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("订阅不存在或不属于您")
+		}
+		global.Logger.Error("Failed to get subscription: " + err.Error())
+		return nil, errors.New("获取订阅失败：数据库错误")
+	}
+
+	if req.Name != nil {
+		subscription.Name = strings.TrimSpace(*req.Name)
+		if subscription.Name == "" {
+			return nil, errors.New("订阅名称不能为空")
+		}
+	}
+	if req.Notes != nil {
+		subscription.Notes = *req.Notes
+	}
+	if err := global.DB.Model(&subscription).Select("name", "notes").Updates(&subscription).Error; err != nil {
+		global.Logger.Error("Failed to update subscription: " + err.Error())
+		return nil, errors.New("更新订阅失败：数据库错误")
+	}
+
+	response := s.subscriptionToResponse(&subscription, nil)
+	return &response, nil
+}
+
+// DeleteSubscription 删除已确认或已忽略的订阅，该收款方重新参与候选订阅检测
+func (s *BookkeepingSubscriptionService) DeleteSubscription(userID, subscriptionID uint) error {
+	result := global.DB.Unscoped().Where("id = ? AND user_id = ?", subscriptionID, userID).Delete(&model.Subscription{})
+	if result.Error != nil {
+		global.Logger.Error("Failed to delete subscription: " + result.Error.Error())
+		return errors.New("删除订阅失败：数据库错误")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("订阅不存在或不属于您")
+	}
+	return nil
+}
+
+// findByPayee 按规范化的收款方查找订阅，不存在时返回空的订阅
+func (s *BookkeepingSubscriptionService) findByPayee(userID uint, key string) (model.Subscription, error) {
+	var subscription model.Subscription
+	err := global.DB.Where("user_id = ? AND payee_key = ?", userID, key).First(&subscription).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		global.Logger.Error("Failed to get subscription: " + err.Error())
+		return subscription, err
+	}
+	return subscription, nil
+}
+
+// fillWithoutPattern 已确认的订阅不再检测到定期扣费时 (扣费次数不足或不再规律)，按确认时的频率和最近一次扣费补充信息
+func (s *BookkeepingSubscriptionService) fillWithoutPattern(item *dto.SubscriptionResponse, subscription *model.Subscription, charges []model.Transaction, today time.Time) {
+	perYear := 12.0
+	lapsedDays := 0.0
+	for _, candidate := range subscriptionFrequencies {
+		if candidate.frequency == subscription.Frequency {
+			perYear = candidate.perYear
+			lapsedDays = candidate.days*subscriptionLapsedPeriods + candidate.tolerance
+		}
+	}
+	item.MonthlyCost = roundCent(subscription.Amount * perYear / 12)
+	item.AnnualCost = roundCent(subscription.Amount * perYear)
+	item.IsLapsed = true
+	if len(charges) == 0 {
+		return
+	}
+
+	last := truncateToDay(charges[len(charges)-1].TransactionDate)
+	item.ChargeCount = len(charges)
+	item.LastChargeDate = last.Format("2006-01-02")
+	item.NextExpectedDate = subscriptionChargeDate(subscription.Frequency, last, 1).Format("2006-01-02")
+	item.IsLapsed = today.After(last.AddDate(0, 0, int(lapsedDays+0.5)))
+}
+
+// patternToCandidate 辅助函数，将检测出的扣费规律转换为候选订阅
+func (s *BookkeepingSubscriptionService) patternToCandidate(pattern *subscriptionPattern) dto.SubscriptionCandidate {
+	last := pattern.last()
+	return dto.SubscriptionCandidate{
+		Payee:            pattern.Payee,
+		Frequency:        string(pattern.Frequency),
+		IntervalDays:     pattern.IntervalDays,
+		Amount:           pattern.Amount,
+		AverageAmount:    pattern.AverageAmount,
+		MonthlyCost:      pattern.monthlyCost(),
+		AnnualCost:       pattern.annualCost(),
+		ChargeCount:      len(pattern.Charges),
+		FirstChargeDate:  pattern.Charges[0].TransactionDate.Format("2006-01-02"),
+		LastChargeDate:   last.TransactionDate.Format("2006-01-02"),
+		NextExpectedDate: pattern.NextExpectedDate.Format("2006-01-02"),
+		CategoryID:       last.CategoryID,
+		CategoryName:     last.Category.Name,
+		AccountID:        last.AccountID,
+		AccountName:      last.Account.Name,
+		PriceIncrease:    pattern.PriceIncrease,
+	}
+}
+
+// subscriptionToResponse 辅助函数，将订阅模型转换为响应对象，pattern 不为空时使用重新分析的扣费信息
+func (s *BookkeepingSubscriptionService) subscriptionToResponse(subscription *model.Subscription, pattern *subscriptionPattern) dto.SubscriptionResponse {
+	response := dto.SubscriptionResponse{
+		ID:         subscription.ID,
+		Name:       subscription.Name,
+		Payee:      subscription.Payee,
+		Status:     string(subscription.Status),
+		Frequency:  string(subscription.Frequency),
+		Amount:     subscription.Amount,
+		CategoryID: subscription.CategoryID,
+		AccountID:  subscription.AccountID,
+		Notes:      subscription.Notes,
+		CreatedAt:  subscription.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if pattern != nil {
+		response.Frequency = string(pattern.Frequency)
+		response.Amount = pattern.Amount
+		response.MonthlyCost = pattern.monthlyCost()
+		response.AnnualCost = pattern.annualCost()
+		response.ChargeCount = len(pattern.Charges)
+		response.LastChargeDate = pattern.last().TransactionDate.Format("2006-01-02")
+		response.NextExpectedDate = pattern.NextExpectedDate.Format("2006-01-02")
+		response.IsLapsed = pattern.IsLapsed
+		response.PriceIncrease = pattern.PriceIncrease
+	}
+	return response
+}
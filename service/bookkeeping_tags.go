@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxTagsLength 标签以逗号分隔保存，合计长度不能超过字段长度
+const maxTagsLength = 255
+
+// joinTags 去掉标签首尾空白、空标签和重复标签后以逗号连接保存，标签中的逗号视为分隔符
+func joinTags(tags []string) (string, error) {
+	seen := make(map[string]bool, len(tags))
+	var result []string
+	for _, tag := range tags {
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" || seen[part] {
+				continue
+			}
+			seen[part] = true
+			result = append(result, part)
+		}
+	}
+
+	joined := strings.Join(result, ",")
+	if len(joined) > maxTagsLength {
+		return "", errors.New("标签总长度不能超过255个字符")
+	}
+	return joined, nil
+}
+
+// splitTags 将保存的标签拆分为列表
+func splitTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
+
+// whereAnyTag 筛选标签中包含任一指定标签的记录
+func whereAnyTag(query *gorm.DB, column string, tags []string) *gorm.DB {
+	if len(tags) == 0 {
+		return query
+	}
+	conditions := make([]string, len(tags))
+	args := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		conditions[i] = "FIND_IN_SET(?, " + column + ") > 0"
+		args[i] = tag
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
@@ -31,6 +31,9 @@ func (s *BookkeepingTransactionService) CreateTransaction(userID uint, req dto.C
 		global.Logger.Error("Failed to find account: " + err.Error())
 		return response, errors.New("创建交易记录失败：无法验证账户")
 	}
+	if account.IsArchived {
+		return response, errors.New("该账户已归档，不能记录新的交易")
+	}
 
 	// 验证分类是否存在且属于当前用户
 	var category model.Category
@@ -41,6 +44,9 @@ func (s *BookkeepingTransactionService) CreateTransaction(userID uint, req dto.C
 		global.Logger.Error("Failed to find category: " + err.Error())
 		return response, errors.New("创建交易记录失败：无法验证分类")
 	}
+	if category.IsArchived {
+		return response, errors.New("该分类已归档，不能用于新的交易")
+	}
 
 	// 解析交易日期
 	transactionDate, err := time.Parse("2006-01-02", req.TransactionDate)
@@ -57,6 +63,9 @@ func (s *BookkeepingTransactionService) CreateTransaction(userID uint, req dto.C
 
 	transaction.UserID = userID
 	transaction.TransactionDate = transactionDate
+	if transaction.Tags, err = joinTags(req.Tags); err != nil {
+		return response, err
+	}
 
 	// 创建交易记录（在事务中进行，确保账户余额更新）
 	err = global.DB.Transaction(func(tx *gorm.DB) error {
@@ -77,11 +86,17 @@ func (s *BookkeepingTransactionService) CreateTransaction(userID uint, req dto.C
 		return response, errors.New("创建交易记录成功，但获取详情失败")
 	}
 
+	// 大额交易通知
+	notifyLargeTransaction(&transaction)
+
 	// 复制模型数据到响应
 	if err := s.transactionToResponse(&transaction, &response); err != nil {
 		return response, err
 	}
 
+	// 提交后立即检查受影响的预算，返回本次写入使预算跨过的阈值
+	response.BudgetImpact = evaluateBudgetImpact(userID, []model.Transaction{transaction})
+
 	return response, nil
 }
 
@@ -108,6 +123,10 @@ func (s *BookkeepingTransactionService) ListTransactions(userID uint, query dto.
 		db = db.Where("type = ?", query.Type)
 	}
 
+	if query.Tag != "" {
+		db = whereAnyTag(db, "tags", []string{query.Tag})
+	}
+
 	if query.StartDate != "" {
 		db = db.Where("transaction_date >= ?", query.StartDate)
 	}
@@ -144,6 +163,13 @@ func (s *BookkeepingTransactionService) ListTransactions(userID uint, query dto.
 		response.Items = append(response.Items, transactionResponse)
 	}
 
+	// 按单个账户筛选时，附带每笔交易后的账户余额
+	if query.AccountID > 0 && len(transactions) > 0 && len(response.Items) == len(transactions) {
+		if err := s.fillRunningBalances(query, transactions, response.Items); err != nil {
+			global.Logger.Error("Failed to calculate running balances: " + err.Error())
+		}
+	}
+
 	return response, nil
 }
 
@@ -187,6 +213,9 @@ func (s *BookkeepingTransactionService) UpdateTransaction(userID uint, transacti
 		global.Logger.Error("Failed to get transaction for update: " + err.Error())
 		return response, errors.New("更新交易记录失败：数据库错误")
 	}
+	if err := checkTransactionReferences(global.DB, transaction.ID); err != nil {
+		return response, userFacingError(err, "更新交易记录失败：数据库错误")
+	}
 
 	// 验证账户（如果更新）
 	if req.AccountID != nil && *req.AccountID != transaction.AccountID {
@@ -198,6 +227,9 @@ func (s *BookkeepingTransactionService) UpdateTransaction(userID uint, transacti
 			global.Logger.Error("Failed to find account: " + err.Error())
 			return response, errors.New("更新交易记录失败：无法验证账户")
 		}
+		if account.IsArchived {
+			return response, errors.New("该账户已归档，不能记录新的交易")
+		}
 		transaction.AccountID = *req.AccountID
 	}
 
@@ -211,6 +243,9 @@ func (s *BookkeepingTransactionService) UpdateTransaction(userID uint, transacti
 			global.Logger.Error("Failed to find category: " + err.Error())
 			return response, errors.New("更新交易记录失败：无法验证分类")
 		}
+		if category.IsArchived {
+			return response, errors.New("该分类已归档，不能用于新的交易")
+		}
 		transaction.CategoryID = *req.CategoryID
 	}
 
@@ -240,7 +275,16 @@ func (s *BookkeepingTransactionService) UpdateTransaction(userID uint, transacti
 		transaction.Notes = *req.Notes
 	}
 
+	if req.Tags != nil {
+		tags, err := joinTags(*req.Tags)
+		if err != nil {
+			return response, err
+		}
+		transaction.Tags = tags
+	}
+
 	// 保存更新（在事务中进行，确保账户余额更新）
+	// 原账户和新账户的余额差额及余额快照失效由交易模型的更新钩子处理
 	err := global.DB.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Save(&transaction).Error; err != nil {
 			return err
@@ -259,11 +303,17 @@ func (s *BookkeepingTransactionService) UpdateTransaction(userID uint, transacti
 		return response, errors.New("更新交易记录成功，但获取详情失败")
 	}
 
+	// 修改金额后达到大额交易金额时通知，每笔交易只通知一次
+	notifyLargeTransaction(&transaction)
+
 	// 复制模型数据到响应
 	if err := s.transactionToResponse(&transaction, &response); err != nil {
 		return response, err
 	}
 
+	// 提交后立即检查受影响的预算，返回本次写入使预算跨过的阈值
+	response.BudgetImpact = evaluateBudgetImpact(userID, []model.Transaction{transaction})
+
 	return response, nil
 }
 
@@ -280,6 +330,9 @@ func (s *BookkeepingTransactionService) DeleteTransaction(userID uint, transacti
 		global.Logger.Error("Failed to get transaction for deletion: " + err.Error())
 		return errors.New("删除交易记录失败：数据库错误")
 	}
+	if err := checkTransactionReferences(global.DB, transaction.ID); err != nil {
+		return userFacingError(err, "删除交易记录失败：数据库错误")
+	}
 
 	// 删除交易记录（在事务中进行，确保账户余额更新）
 	err := global.DB.Transaction(func(tx *gorm.DB) error {
@@ -297,6 +350,93 @@ func (s *BookkeepingTransactionService) DeleteTransaction(userID uint, transacti
 	return nil
 }
 
+// transactionReferences 由其他功能生成并引用的交易，这些交易与生成它的记录 (如账单已付期数) 保持一致，
+// 不能直接修改或删除，只能在生成它的功能中撤销或删除；生成交易的功能提供了撤销或删除后才在这里注册
+var transactionReferences = []struct {
+	name   string // 生成交易的功能，用于提示
+	model  interface{}
+	column string
+}{
+	{"账单付款", &model.BillPayment{}, "transaction_id"},
+	{"借贷记录", &model.Debt{}, "transaction_id"},
+	{"借贷还款", &model.DebtRepayment{}, "transaction_id"},
+	{"投资交易", &model.InvestmentTrade{}, "transaction_id"},
+	{"贷款还款", &model.LoanRepayment{}, "principal_transaction_id"},
+	{"贷款还款", &model.LoanRepayment{}, "interest_transaction_id"},
+	{"贷款还款", &model.LoanRepayment{}, "loan_transaction_id"},
+	{"AA分摊支出", &model.SplitExpense{}, "own_transaction_id"},
+	{"AA分摊支出", &model.SplitExpense{}, "advance_transaction_id"},
+	{"AA分摊支出", &model.SplitExpense{}, "offset_transaction_id"},
+	{"AA分摊结算", &model.SplitSettlement{}, "transaction_id"},
+}
+
+// checkTransactionReferences 交易被其他功能引用时返回业务错误
+func checkTransactionReferences(db *gorm.DB, transactionID uint) error {
+	for _, ref := range transactionReferences {
+		var count int64
+		if err := db.Model(ref.model).Where(ref.column+" = ?", transactionID).Count(&count).Error; err != nil {
+			global.Logger.Error("Failed to check transaction references: " + err.Error())
+			return err
+		}
+		if count > 0 {
+			return newBizError(fmt.Sprintf("该交易由%s生成，不能直接修改或删除，请在%s中撤销或删除", ref.name, ref.name))
+		}
+	}
+	return nil
+}
+
+// fillRunningBalances 计算按账户筛选后每笔交易之后的账户余额
+// transactions 按交易日期、ID倒序排列；先计算本页最早一笔之前的余额，再按时间顺序逐笔累加。
+// 筛选条件会跳过该账户的部分交易 (按分类、类型或标签筛选) 时，累加时需要包含被跳过的交易，
+// 因此一次查出本页时间范围内该账户的全部交易
+func (s *BookkeepingTransactionService) fillRunningBalances(query dto.TransactionQuery, transactions []model.Transaction, items []dto.TransactionResponse) error {
+	var account model.Account
+	if err := global.DB.First(&account, query.AccountID).Error; err != nil {
+		return err
+	}
+
+	oldest, newest := &transactions[len(transactions)-1], &transactions[0]
+	var delta float64
+	if err := global.DB.Model(&model.Transaction{}).
+		Where("account_id = ? AND (transaction_date < ? OR (transaction_date = ? AND id < ?))",
+			account.ID, oldest.TransactionDate, oldest.TransactionDate, oldest.ID).
+		Select(model.BalanceDeltaSQL).Scan(&delta).Error; err != nil {
+		return err
+	}
+	balance := account.InitialBalance + delta
+
+	contiguous := query.CategoryID == 0 && query.Type == "" && query.Tag == ""
+	if contiguous {
+		for i := len(transactions) - 1; i >= 0; i-- {
+			balance += transactions[i].BalanceDelta()
+			runningBalance := balance
+			items[i].RunningBalance = &runningBalance
+		}
+		return nil
+	}
+
+	var between []model.Transaction
+	if err := global.DB.Select("id", "type", "amount").
+		Where("account_id = ? AND (transaction_date > ? OR (transaction_date = ? AND id >= ?))",
+			account.ID, oldest.TransactionDate, oldest.TransactionDate, oldest.ID).
+		Where("(transaction_date < ? OR (transaction_date = ? AND id <= ?))",
+			newest.TransactionDate, newest.TransactionDate, newest.ID).
+		Order("transaction_date ASC, id ASC").Find(&between).Error; err != nil {
+		return err
+	}
+	balances := make(map[uint]float64, len(between))
+	for i := range between {
+		balance += between[i].BalanceDelta()
+		balances[between[i].ID] = balance
+	}
+	for i := range transactions {
+		runningBalance := balances[transactions[i].ID]
+		items[i].RunningBalance = &runningBalance
+	}
+
+	return nil
+}
+
 // transactionToResponse 辅助函数，将交易流水模型转换为响应对象
 func (s *BookkeepingTransactionService) transactionToResponse(transaction *model.Transaction, response *dto.TransactionResponse) error {
 	// 复制基本字段
@@ -305,8 +445,9 @@ func (s *BookkeepingTransactionService) transactionToResponse(transaction *model
 		return errors.New("转换交易记录数据失败")
 	}
 
-	// 格式化日期和时间
+	// 格式化日期和时间，拆分标签
 	response.TransactionDate = transaction.TransactionDate.Format("2006-01-02")
+	response.Tags = splitTags(transaction.Tags)
 	response.CreatedAt = transaction.CreatedAt.Format("2006-01-02 15:04:05")
 	response.UpdatedAt = transaction.UpdatedAt.Format("2006-01-02 15:04:05")
 
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dotdancer/gogofly/model"
+	"gorm.io/gorm"
+)
+
+// upcomingExpense 未来已知会发生的支出 (如贷款的下一期还款)，用于预算预测
+type upcomingExpense struct {
+	Source     string    // 来源 (loan, bill, subscription...)
+	Name       string    // 描述
+	Date       time.Time // 预计发生日期
+	Amount     float64   // 计入收支统计的金额
+	CategoryID uint      // 支出分类，0 表示未知
+	AccountID  uint      // 付款账户，0 表示未知
+	Payee      string    // 收款方
+}
+
+// upcomingExpenseProvider 提供用户在 [from, to] 之间已知的未来支出
+type upcomingExpenseProvider func(db *gorm.DB, userID uint, from, to time.Time) ([]upcomingExpense, error)
+
+// upcomingExpenseProviders 已注册的未来支出来源，新增周期性支出类型时在这里注册
+var upcomingExpenseProviders = []upcomingExpenseProvider{
+	loanUpcomingExpenses,
+	billUpcomingExpenses,
+	subscriptionUpcomingExpenses,
+}
+
+// upcomingExpenses 汇总所有来源在 [from, to] 之间的未来支出，按日期升序
+func upcomingExpenses(db *gorm.DB, userID uint, from, to time.Time) ([]upcomingExpense, error) {
+	var result []upcomingExpense
+	for _, provider := range upcomingExpenseProviders {
+		items, err := provider(db, userID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Date.Before(result[j].Date)
+	})
+	return result, nil
+}
+
+// loanUpcomingExpenses 按还款计划推算还款中贷款的未还利息 (本金部分不计入收支统计)
+func loanUpcomingExpenses(db *gorm.DB, userID uint, from, to time.Time) ([]upcomingExpense, error) {
+	var loans []model.Loan
+	if err := db.Where("user_id = ? AND status = ?", userID, model.LoanStatusActive).Find(&loans).Error; err != nil {
+		return nil, err
+	}
+	if len(loans) == 0 {
+		return nil, nil
+	}
+
+	// 未指定利息分类的贷款，还款时记入默认的"贷款利息"分类
+	var defaultCategoryID uint
+	var defaultCategory model.Category
+	err := db.Where("user_id = ? AND name = ? AND type = ? AND parent_id IS NULL", userID, loanInterestCategoryName, model.CategoryTypeExpense).
+		First(&defaultCategory).Error
+	if err == nil {
+		defaultCategoryID = defaultCategory.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var result []upcomingExpense
+	for i := range loans {
+		loan := &loans[i]
+		categoryID := defaultCategoryID
+		if loan.InterestCategoryID != nil {
+			categoryID = *loan.InterestCategoryID
+		}
+		for _, item := range projectLoanSchedule(loan) {
+			dueDate, err := time.ParseInLocation("2006-01-02", item.DueDate, time.Local)
+			if err != nil {
+				return nil, err
+			}
+			if dueDate.After(to) {
+				break
+			}
+			if dueDate.Before(from) || item.Interest <= 0 {
+				continue
+			}
+			result = append(result, upcomingExpense{
+				Source:     "loan",
+				Name:       fmt.Sprintf("%s 第%d期利息", loan.Name, item.Period),
+				Date:       dueDate,
+				Amount:     item.Interest,
+				CategoryID: categoryID,
+				Payee:      loan.Name,
+			})
+		}
+	}
+	return result, nil
+}
+
+// dueBill 即将到期需要用户付款的账单 (如贷款的下一期还款)，用于到期提醒、即将付款列表和账单日历
+type dueBill struct {
+	Source    string    // 来源 (loan, debt, bill...)
+	RefID     uint      // 来源记录ID
+	Name      string    // 描述
+	DueDate   time.Time // 到期日期
+	Amount    float64   // 应付金额
+	Estimated bool      // 金额是否为预估值
+}
+
+// dueBillProvider 提供用户在 [from, to] 之间到期的账单，from 为零值时包含所有逾期未付的账单
+type dueBillProvider func(db *gorm.DB, userID uint, from, to time.Time) ([]dueBill, error)
+
+// dueBillProviders 已注册的到期账单来源，新增需要按期付款的类型时在这里注册
+var dueBillProviders = []dueBillProvider{
+	loanDueBills,
+	debtDueBills,
+	billDueBills,
+}
+
+// dueBills 汇总所有来源在 [from, to] 之间到期的账单，按到期日期升序
+func dueBills(db *gorm.DB, userID uint, from, to time.Time) ([]dueBill, error) {
+	var result []dueBill
+	for _, provider := range dueBillProviders {
+		items, err := provider(db, userID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DueDate.Before(result[j].DueDate)
+	})
+	return result, nil
+}
+
+// loanDueBills 还款中贷款在 [from, to] 之间到期的各期还款 (本金加利息)
+func loanDueBills(db *gorm.DB, userID uint, from, to time.Time) ([]dueBill, error) {
+	var loans []model.Loan
+	if err := db.Where("user_id = ? AND status = ?", userID, model.LoanStatusActive).Find(&loans).Error; err != nil {
+		return nil, err
+	}
+
+	var result []dueBill
+	for i := range loans {
+		loan := &loans[i]
+		for _, item := range projectLoanSchedule(loan) {
+			dueDate, err := time.ParseInLocation("2006-01-02", item.DueDate, time.Local)
+			if err != nil {
+				return nil, err
+			}
+			if dueDate.After(to) {
+				break
+			}
+			if dueDate.Before(from) {
+				continue
+			}
+			result = append(result, dueBill{
+				Source:  "loan",
+				RefID:   loan.ID,
+				Name:    fmt.Sprintf("%s 第%d期还款", loan.Name, item.Period),
+				DueDate: dueDate,
+				Amount:  item.Payment,
+			})
+		}
+	}
+	return result, nil
+}
+
+// debtDueBills 未结清的借入在 [from, to] 之间到期的剩余应还金额
+func debtDueBills(db *gorm.DB, userID uint, from, to time.Time) ([]dueBill, error) {
+	query := db.Preload("Counterparty").
+		Where("user_id = ? AND direction = ? AND status = ? AND due_date IS NOT NULL AND due_date <= ?",
+			userID, model.DebtDirectionBorrow, model.DebtStatusOpen, to)
+	if !from.IsZero() {
+		query = query.Where("due_date >= ?", from)
+	}
+
+	var debts []model.Debt
+	if err := query.Find(&debts).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dueBill, 0, len(debts))
+	for i := range debts {
+		debt := &debts[i]
+		result = append(result, dueBill{
+			Source:  "debt",
+			RefID:   debt.ID,
+			Name:    fmt.Sprintf("归还 %s 的借款", debt.Counterparty.Name),
+			DueDate: truncateToDay(*debt.DueDate),
+			Amount:  debt.Outstanding(),
+		})
+	}
+	return result, nil
+}
@@ -6,33 +6,67 @@ import (
 
 // CreateAccountRequest 创建账户的请求体
 type CreateAccountRequest struct {
-	Name           string            `json:"name" binding:"required,min=1,max=100"`        // 账户名称
-	Type           model.AccountType `json:"type" binding:"required"`                      // 账户类型
-	InitialBalance float64           `json:"initial_balance" binding:"omitempty,min=0"`    // 初始余额
-	Remark         string            `json:"remark,omitempty" binding:"omitempty,max=255"` // 备注
-	IsDefault      bool              `json:"is_default,omitempty"`                         // 是否默认账户
+	Name                string              `json:"name" binding:"required,min=1,max=100"`                      // 账户名称
+	Type                model.AccountType   `json:"type" binding:"required"`                                    // 账户类型
+	InitialBalance      float64             `json:"initial_balance"`                                            // 初始余额 (负债账户可为负数，表示欠款)
+	Remark              string              `json:"remark,omitempty" binding:"omitempty,max=255"`               // 备注
+	IsDefault           bool                `json:"is_default,omitempty"`                                       // 是否默认账户
+	Nature              model.AccountNature `json:"nature,omitempty" binding:"omitempty,oneof=asset liability"` // 账户性质 (可选，默认按账户类型推断)
+	ExcludeFromNetWorth bool                `json:"exclude_from_net_worth,omitempty"`                           // 是否不计入净资产
 }
 
 // UpdateAccountRequest 更新账户的请求体
 type UpdateAccountRequest struct {
-	Name      *string            `json:"name,omitempty" binding:"omitempty,min=1,max=100"` // 账户名称
-	Type      *model.AccountType `json:"type,omitempty"`                                   // 账户类型
-	Remark    *string            `json:"remark,omitempty" binding:"omitempty,max=255"`     // 备注
-	IsDefault *bool              `json:"is_default,omitempty"`                             // 是否默认账户
+	Name                *string              `json:"name,omitempty" binding:"omitempty,min=1,max=100"`           // 账户名称
+	Type                *model.AccountType   `json:"type,omitempty"`                                             // 账户类型
+	Remark              *string              `json:"remark,omitempty" binding:"omitempty,max=255"`               // 备注
+	IsDefault           *bool                `json:"is_default,omitempty"`                                       // 是否默认账户
+	Nature              *model.AccountNature `json:"nature,omitempty" binding:"omitempty,oneof=asset liability"` // 账户性质
+	ExcludeFromNetWorth *bool                `json:"exclude_from_net_worth,omitempty"`                           // 是否不计入净资产
 }
 
 // AccountResponse 单个账户的响应体
 type AccountResponse struct {
-	ID             uint              `json:"id"`
-	Name           string            `json:"name"`
-	Type           model.AccountType `json:"type"`
-	InitialBalance float64           `json:"initial_balance"`
-	CurrentBalance float64           `json:"current_balance"`
-	Remark         string            `json:"remark,omitempty"`
-	IsDefault      bool              `json:"is_default"`
-	CreatedAt      string            `json:"created_at"`
-	UpdatedAt      string            `json:"updated_at"`
-	UserID         uint              `json:"user_id"`
+	ID                  uint                `json:"id"`
+	Name                string              `json:"name"`
+	Type                model.AccountType   `json:"type"`
+	InitialBalance      float64             `json:"initial_balance"`
+	CurrentBalance      float64             `json:"current_balance"`
+	Remark              string              `json:"remark,omitempty"`
+	IsDefault           bool                `json:"is_default"`
+	Nature              model.AccountNature `json:"nature"`
+	ExcludeFromNetWorth bool                `json:"exclude_from_net_worth"`
+	IsArchived          bool                `json:"is_archived"`
+	ClosedAt            string              `json:"closed_at,omitempty"` // 关闭日期 (YYYY-MM-DD)
+	CreatedAt           string              `json:"created_at"`
+	UpdatedAt           string              `json:"updated_at"`
+	UserID              uint                `json:"user_id"`
+}
+
+// ArchiveAccountRequest 归档（关闭）账户的请求体
+type ArchiveAccountRequest struct {
+	CloseDate string `json:"close_date,omitempty"` // 关闭日期 (YYYY-MM-DD)，默认今天
+}
+
+// MergeAccountRequest 合并账户的请求体，将路径中的源账户合并到目标账户
+type MergeAccountRequest struct {
+	TargetAccountID uint   `json:"target_account_id" binding:"required"`                             // 目标账户ID
+	SourceAction    string `json:"source_action,omitempty" binding:"omitempty,oneof=archive delete"` // 合并后源账户的处理方式，默认archive
+	DryRun          bool   `json:"dry_run,omitempty"`                                                // 只返回合并预览，不做修改
+}
+
+// MergeAccountResponse 合并账户的结果（或预览）
+type MergeAccountResponse struct {
+	DryRun              bool             `json:"dry_run"`
+	SourceAccountID     uint             `json:"source_account_id"`
+	SourceAccountName   string           `json:"source_account_name"`
+	TargetAccountID     uint             `json:"target_account_id"`
+	TargetAccountName   string           `json:"target_account_name"`
+	SourceAction        string           `json:"source_action"`         // 源账户的处理方式 (archive, delete)
+	MovedRecords        map[string]int64 `json:"moved_records"`         // 各类关联记录的迁移数量
+	SourceBalance       float64          `json:"source_balance"`        // 合并前源账户余额
+	TargetBalanceBefore float64          `json:"target_balance_before"` // 合并前目标账户余额
+	TargetBalanceAfter  float64          `json:"target_balance_after"`  // 合并后按全部交易重算的目标账户余额
 }
 
 // AccountListResponse 账户列表的响应体
@@ -40,3 +74,43 @@ type AccountListResponse struct {
 	Total int64             `json:"total"`
 	Items []AccountResponse `json:"items"`
 }
+
+// AccountBalanceResponse 账户在指定日期的余额
+type AccountBalanceResponse struct {
+	AccountID   uint    `json:"account_id"`
+	AccountName string  `json:"account_name"`
+	Date        string  `json:"date"`    // 日期 (YYYY-MM-DD)，余额为当日结束时的余额
+	Balance     float64 `json:"balance"` // 余额
+}
+
+// BalanceHistoryQuery 余额历史查询条件
+type BalanceHistoryQuery struct {
+	StartDate string `form:"start_date" json:"start_date"`                                 // 开始日期 (YYYY-MM-DD)
+	EndDate   string `form:"end_date" json:"end_date"`                                     // 结束日期 (YYYY-MM-DD)
+	Interval  string `form:"interval" json:"interval" binding:"omitempty,oneof=day month"` // 粒度 (day, month)
+}
+
+// BalancePoint 余额序列中的一个点
+type BalancePoint struct {
+	Date    string  `json:"date"`    // 日期 (按日为YYYY-MM-DD，按月为YYYY-MM)
+	Balance float64 `json:"balance"` // 当日（或当月月末）结束时的余额
+}
+
+// AccountBalanceHistoryResponse 账户余额历史响应
+type AccountBalanceHistoryResponse struct {
+	AccountID   uint           `json:"account_id"`
+	AccountName string         `json:"account_name"`
+	Interval    string         `json:"interval"`
+	StartDate   string         `json:"start_date"`
+	EndDate     string         `json:"end_date"`
+	Items       []BalancePoint `json:"items"`
+}
+
+// RecalculateBalanceResponse 重新计算账户余额的响应
+type RecalculateBalanceResponse struct {
+	AccountID       uint    `json:"account_id"`
+	AccountName     string  `json:"account_name"`
+	PreviousBalance float64 `json:"previous_balance"` // 重算前记录的当前余额
+	CurrentBalance  float64 `json:"current_balance"`  // 按初始余额和全部交易重算后的当前余额
+	Difference      float64 `json:"difference"`       // 差额 (重算后 - 重算前)，为0表示余额一致
+}
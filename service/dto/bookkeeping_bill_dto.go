@@ -0,0 +1,114 @@
+package dto
+
+// CreateBillRequest 创建账单的请求体
+type CreateBillRequest struct {
+	Name          string  `json:"name" binding:"required,max=100"`                                         // 账单名称
+	Payee         string  `json:"payee,omitempty" binding:"omitempty,max=100"`                             // 收款方，为空时使用账单名称
+	Amount        float64 `json:"amount" binding:"required,gt=0"`                                          // 每期金额，预估金额类型为预估值
+	AmountType    string  `json:"amount_type,omitempty" binding:"omitempty,oneof=fixed estimated"`         // 金额类型 (fixed: 固定金额, estimated: 预估金额)，默认 fixed
+	CategoryID    uint    `json:"category_id" binding:"required"`                                          // 支出分类ID
+	AccountID     uint    `json:"account_id" binding:"required"`                                           // 付款账户ID
+	Frequency     string  `json:"frequency" binding:"required,oneof=once weekly monthly quarterly yearly"` // 到期频率
+	IntervalCount int     `json:"interval_count,omitempty" binding:"omitempty,min=1,max=12"`               // 每几个频率周期到期一次，默认1
+	FirstDueDate  string  `json:"first_due_date" binding:"required"`                                       // 首次到期日期 (YYYY-MM-DD)
+	EndDate       string  `json:"end_date,omitempty"`                                                      // 最后到期日期 (YYYY-MM-DD)，为空表示长期
+	Notes         string  `json:"notes,omitempty" binding:"omitempty,max=255"`                             // 备注
+}
+
+// UpdateBillRequest 更新账单的请求体
+// 修改频率、间隔或首次到期日期后，已付期数按新的到期计划重新推算
+type UpdateBillRequest struct {
+	Name          *string  `json:"name,omitempty" binding:"omitempty,max=100"`
+	Payee         *string  `json:"payee,omitempty" binding:"omitempty,max=100"`
+	Amount        *float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	AmountType    *string  `json:"amount_type,omitempty" binding:"omitempty,oneof=fixed estimated"`
+	CategoryID    *uint    `json:"category_id,omitempty"`
+	AccountID     *uint    `json:"account_id,omitempty"`
+	Frequency     *string  `json:"frequency,omitempty" binding:"omitempty,oneof=once weekly monthly quarterly yearly"`
+	IntervalCount *int     `json:"interval_count,omitempty" binding:"omitempty,min=1,max=12"`
+	FirstDueDate  *string  `json:"first_due_date,omitempty"`
+	EndDate       *string  `json:"end_date,omitempty"` // 传空字符串表示取消最后到期日期
+	IsActive      *bool    `json:"is_active,omitempty"`
+	Notes         *string  `json:"notes,omitempty" binding:"omitempty,max=255"`
+}
+
+// BillQuery 账单列表查询条件
+type BillQuery struct {
+	IsActive *bool `form:"is_active"` // 是否启用
+}
+
+// BillResponse 账单的响应体
+type BillResponse struct {
+	ID            uint    `json:"id"`
+	Name          string  `json:"name"`
+	Payee         string  `json:"payee"`
+	Amount        float64 `json:"amount"`
+	AmountType    string  `json:"amount_type"`
+	CategoryID    uint    `json:"category_id"`
+	CategoryName  string  `json:"category_name"`
+	AccountID     uint    `json:"account_id"`
+	AccountName   string  `json:"account_name"`
+	Frequency     string  `json:"frequency"`
+	IntervalCount int     `json:"interval_count"`
+	FirstDueDate  string  `json:"first_due_date"`
+	EndDate       string  `json:"end_date,omitempty"`
+	PaidCount     int     `json:"paid_count"`              // 已付期数
+	NextDueDate   string  `json:"next_due_date,omitempty"` // 下一期到期日期，全部付清后为空
+	IsOverdue     bool    `json:"is_overdue"`              // 下一期是否已逾期
+	DaysOverdue   int     `json:"days_overdue,omitempty"`  // 逾期天数
+	IsActive      bool    `json:"is_active"`
+	Notes         string  `json:"notes,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// PayBillRequest 标记账单本期已付的请求体
+type PayBillRequest struct {
+	Amount    float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`   // 实际付款金额，默认为账单金额；预估金额类型建议填写
+	PaidDate  string  `json:"paid_date,omitempty"`                         // 付款日期 (YYYY-MM-DD)，默认今天
+	AccountID uint    `json:"account_id,omitempty"`                        // 付款账户ID，默认为账单的付款账户
+	Notes     string  `json:"notes,omitempty" binding:"omitempty,max=255"` // 备注
+}
+
+// BillPaymentResponse 账单付款记录的响应体
+type BillPaymentResponse struct {
+	ID            uint           `json:"id"`
+	BillID        uint           `json:"bill_id"`
+	Period        int            `json:"period"`   // 期数
+	DueDate       string         `json:"due_date"` // 该期的到期日期
+	PaidDate      string         `json:"paid_date"`
+	Amount        float64        `json:"amount"`
+	AccountID     uint           `json:"account_id"`
+	TransactionID *uint          `json:"transaction_id,omitempty"`
+	Notes         string         `json:"notes,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+	BudgetImpact  []BudgetImpact `json:"budget_impact,omitempty"` // 付款生成的支出使预算新跨过的阈值 (仅付款时返回)
+}
+
+// UpcomingPaymentItem 即将付款列表中的一项，包括账单、贷款还款和借入的到期还款
+type UpcomingPaymentItem struct {
+	Source      string  `json:"source"`       // 来源 (bill, loan, debt)
+	RefID       uint    `json:"ref_id"`       // 来源记录ID
+	Name        string  `json:"name"`         // 描述
+	DueDate     string  `json:"due_date"`     // 到期日期
+	Amount      float64 `json:"amount"`       // 应付金额
+	IsEstimated bool    `json:"is_estimated"` // 金额是否为预估值
+	IsOverdue   bool    `json:"is_overdue"`   // 是否已逾期
+	DaysUntil   int     `json:"days_until"`   // 距到期日的天数，逾期为负数
+}
+
+// UpcomingPaymentsResponse 即将付款列表的响应体
+type UpcomingPaymentsResponse struct {
+	From          string                `json:"from"`           // 今天
+	To            string                `json:"to"`             // 统计截止日期
+	OverdueCount  int                   `json:"overdue_count"`  // 逾期笔数
+	OverdueAmount float64               `json:"overdue_amount"` // 逾期金额合计
+	TotalAmount   float64               `json:"total_amount"`   // 全部应付金额合计 (含逾期)
+	Items         []UpcomingPaymentItem `json:"items"`
+}
+
+// BillCalendarFeedResponse 账单日历订阅地址的响应体
+type BillCalendarFeedResponse struct {
+	Path        string `json:"path"`         // 订阅路径，拼接服务地址即可在日历应用中订阅
+	URL         string `json:"url"`          // 按当前请求的服务地址生成的完整订阅地址
+	GeneratedAt string `json:"generated_at"` // 订阅密钥生成时间
+}
@@ -15,56 +15,137 @@ type Category struct {
 
 // CreateBudgetRequest 创建预算请求
 type CreateBudgetRequest struct {
-	Name        string    `json:"name" binding:"required"`                                   // 预算名称
-	Type        string    `json:"type" binding:"required,oneof=overall category"`            // 预算类型
-	Period      string    `json:"period" binding:"required,oneof=weekly monthly yearly"`     // 预算周期
-	Amount      float64   `json:"amount" binding:"required,gt=0"`                            // 预算金额
-	StartDate   time.Time `json:"start_date" binding:"required"`                             // 开始日期
-	CategoryID  *uint     `json:"category_id" binding:"omitempty,required_if=Type category"` // 分类ID
-	NotifyRate  *float64  `json:"notify_rate" binding:"omitempty,gte=0,lte=1"`               // 提醒阈值
-	Description string    `json:"description"`                                               // 备注
-	IsActive    *bool     `json:"is_active"`                                                 // 是否激活
+	Name               string     `json:"name" binding:"required"`                                                              // 预算名称
+	Type               string     `json:"type" binding:"required,oneof=overall category"`                                       // 预算类型
+	Kind               string     `json:"kind" binding:"omitempty,oneof=expense income"`                                        // 预算方向，默认 expense 支出预算，income 为收入目标
+	Period             string     `json:"period" binding:"required,oneof=weekly biweekly monthly quarterly yearly custom once"` // 预算周期
+	PeriodDays         int        `json:"period_days" binding:"omitempty,min=1,max=366"`                                        // 自定义周期的天数，周期为 custom 时必填
+	Amount             float64    `json:"amount" binding:"required,gt=0"`                                                       // 预算金额
+	StartDate          time.Time  `json:"start_date" binding:"required"`                                                        // 开始日期
+	EndDate            *time.Time `json:"end_date"`                                                                             // 结束日期，周期为 once 时必填，过了结束日期自动停用
+	CategoryID         *uint      `json:"category_id"`                                                                          // 分类ID，分类预算需要指定 category_id 或 category_ids
+	CategoryIDs        []uint     `json:"category_ids"`                                                                         // 额外计入的分类 (含子分类)
+	ExcludeCategoryIDs []uint     `json:"exclude_category_ids"`                                                                 // 排除的分类 (含子分类)
+	AccountIDs         []uint     `json:"account_ids"`                                                                          // 限定的账户，为空表示所有账户
+	Tags               []string   `json:"tags" binding:"omitempty,dive,max=50"`                                                 // 限定的标签，交易包含任一标签即计入
+	Payee              string     `json:"payee" binding:"omitempty,max=100"`                                                    // 限定的收款方 (包含匹配)
+	NotifyRate         *float64   `json:"notify_rate" binding:"omitempty,gte=0,lte=1"`                                          // 提醒阈值
+	Description        string     `json:"description"`                                                                          // 备注
+	IsActive           *bool      `json:"is_active"`                                                                            // 是否激活
+	RolloverMode       string     `json:"rollover_mode" binding:"omitempty,oneof=none surplus deficit both"`                    // 结转方式，默认不结转
+	RolloverCap        *float64   `json:"rollover_cap" binding:"omitempty,gte=0"`                                               // 结转金额上限 (可选)
 }
 
 // UpdateBudgetRequest 更新预算请求
 type UpdateBudgetRequest struct {
-	Name        *string    `json:"name"`                                                   // 预算名称
-	Type        *string    `json:"type" binding:"omitempty,oneof=overall category"`        // 预算类型
-	Period      *string    `json:"period" binding:"omitempty,oneof=weekly monthly yearly"` // 预算周期
-	Amount      *float64   `json:"amount" binding:"omitempty,gt=0"`                        // 预算金额
-	StartDate   *time.Time `json:"start_date"`                                             // 开始日期
-	CategoryID  *uint      `json:"category_id"`                                            // 分类ID
-	NotifyRate  *float64   `json:"notify_rate" binding:"omitempty,gte=0,lte=1"`            // 提醒阈值
-	Description *string    `json:"description"`                                            // 备注
-	IsActive    *bool      `json:"is_active"`                                              // 是否激活
+	Name               *string    `json:"name"`                                                                                  // 预算名称
+	Type               *string    `json:"type" binding:"omitempty,oneof=overall category"`                                       // 预算类型
+	Kind               *string    `json:"kind" binding:"omitempty,oneof=expense income"`                                         // 预算方向
+	Period             *string    `json:"period" binding:"omitempty,oneof=weekly biweekly monthly quarterly yearly custom once"` // 预算周期
+	PeriodDays         *int       `json:"period_days" binding:"omitempty,min=1,max=366"`                                         // 自定义周期的天数
+	Amount             *float64   `json:"amount" binding:"omitempty,gt=0"`                                                       // 预算金额
+	StartDate          *time.Time `json:"start_date"`                                                                            // 开始日期
+	EndDate            *time.Time `json:"end_date"`                                                                              // 结束日期
+	ClearEndDate       bool       `json:"clear_end_date"`                                                                        // 为 true 时取消结束日期 (一次性预算不能取消)
+	CategoryID         *uint      `json:"category_id"`                                                                           // 分类ID
+	CategoryIDs        *[]uint    `json:"category_ids"`                                                                          // 额外计入的分类，传空数组表示清除
+	ExcludeCategoryIDs *[]uint    `json:"exclude_category_ids"`                                                                  // 排除的分类，传空数组表示清除
+	AccountIDs         *[]uint    `json:"account_ids"`                                                                           // 限定的账户，传空数组表示清除
+	Tags               *[]string  `json:"tags" binding:"omitempty,dive,max=50"`                                                  // 限定的标签，传空数组表示清除
+	Payee              *string    `json:"payee" binding:"omitempty,max=100"`                                                     // 限定的收款方，传空字符串表示清除
+	NotifyRate         *float64   `json:"notify_rate" binding:"omitempty,gte=0,lte=1"`                                           // 提醒阈值
+	Description        *string    `json:"description"`                                                                           // 备注
+	IsActive           *bool      `json:"is_active"`                                                                             // 是否激活
+	RolloverMode       *string    `json:"rollover_mode" binding:"omitempty,oneof=none surplus deficit both"`                     // 结转方式
+	RolloverCap        *float64   `json:"rollover_cap" binding:"omitempty,gte=0"`                                                // 结转金额上限，传0表示取消上限
 }
 
 // BudgetResponse 预算信息响应
 type BudgetResponse struct {
-	ID          uint      `json:"id"`                 // 预算ID
-	UserID      uint      `json:"user_id"`            // 用户ID
-	Name        string    `json:"name"`               // 预算名称
-	Type        string    `json:"type"`               // 预算类型
-	Period      string    `json:"period"`             // 预算周期
-	Amount      float64   `json:"amount"`             // 预算金额
-	StartDate   time.Time `json:"start_date"`         // 开始日期
-	CategoryID  *uint     `json:"category_id"`        // 分类ID
-	NotifyRate  float64   `json:"notify_rate"`        // 提醒阈值
-	Description string    `json:"description"`        // 备注
-	IsActive    bool      `json:"is_active"`          // 是否激活
-	CreatedAt   time.Time `json:"created_at"`         // 创建时间
-	UpdatedAt   time.Time `json:"updated_at"`         // 更新时间
-	Category    *Category `json:"category,omitempty"` // 关联的分类
+	ID                 uint       `json:"id"`                             // 预算ID
+	UserID             uint       `json:"user_id"`                        // 用户ID
+	Name               string     `json:"name"`                           // 预算名称
+	Type               string     `json:"type"`                           // 预算类型
+	Kind               string     `json:"kind"`                           // 预算方向 (expense 支出预算，income 收入目标)
+	Period             string     `json:"period"`                         // 预算周期
+	PeriodDays         int        `json:"period_days,omitempty"`          // 自定义周期的天数
+	Amount             float64    `json:"amount"`                         // 预算金额
+	StartDate          time.Time  `json:"start_date"`                     // 开始日期
+	EndDate            *time.Time `json:"end_date,omitempty"`             // 结束日期
+	CategoryID         *uint      `json:"category_id"`                    // 分类ID
+	CategoryIDs        []uint     `json:"category_ids,omitempty"`         // 额外计入的分类
+	ExcludeCategoryIDs []uint     `json:"exclude_category_ids,omitempty"` // 排除的分类
+	AccountIDs         []uint     `json:"account_ids,omitempty"`          // 限定的账户
+	Tags               []string   `json:"tags,omitempty"`                 // 限定的标签
+	Payee              string     `json:"payee,omitempty"`                // 限定的收款方
+	NotifyRate         float64    `json:"notify_rate"`                    // 提醒阈值
+	Description        string     `json:"description"`                    // 备注
+	IsActive           bool       `json:"is_active"`                      // 是否激活
+	RolloverMode       string     `json:"rollover_mode"`                  // 结转方式
+	RolloverCap        *float64   `json:"rollover_cap,omitempty"`         // 结转金额上限
+	IsEnvelope         bool       `json:"is_envelope"`                    // 是否为信封 (零基预算模式)
+	CreatedAt          time.Time  `json:"created_at"`                     // 创建时间
+	UpdatedAt          time.Time  `json:"updated_at"`                     // 更新时间
+	Category           *Category  `json:"category,omitempty"`             // 关联的分类
+}
+
+// BudgetRolloverPeriod 结转明细中的一个历史周期
+type BudgetRolloverPeriod struct {
+	StartDate  time.Time `json:"start_date"`  // 周期开始日期
+	EndDate    time.Time `json:"end_date"`    // 周期结束日期
+	Amount     float64   `json:"amount"`      // 周期预算金额
+	CarriedIn  float64   `json:"carried_in"`  // 从上一周期结转进来的金额
+	Spent      float64   `json:"spent"`       // 周期内支出
+	Balance    float64   `json:"balance"`     // 周期结束时的结余 (负数为超支)
+	CarriedOut float64   `json:"carried_out"` // 结转到下一周期的金额
+	Capped     bool      `json:"capped"`      // 结转金额是否被上限截断
+}
+
+// BudgetRollover 预算结转明细
+type BudgetRollover struct {
+	Mode          string                 `json:"mode"`              // 结转方式
+	Cap           *float64               `json:"cap,omitempty"`     // 结转金额上限
+	CarriedAmount float64                `json:"carried_amount"`    // 结转到当前周期的金额 (正数为结余，负数为超支)
+	Periods       []BudgetRolloverPeriod `json:"periods,omitempty"` // 历史周期的结转过程
+}
+
+// BudgetUpcomingItem 当前周期剩余时间内已知的未来支出
+type BudgetUpcomingItem struct {
+	Source string    `json:"source"` // 来源 (loan...)
+	Name   string    `json:"name"`   // 描述
+	Date   time.Time `json:"date"`   // 预计发生日期
+	Amount float64   `json:"amount"` // 金额
+}
+
+// BudgetForecast 预算周期结束时的支出预测
+type BudgetForecast struct {
+	DailyBurnRate          float64              `json:"daily_burn_rate"`                    // 本周期至今的日均支出
+	UpcomingAmount         float64              `json:"upcoming_amount"`                    // 剩余时间内已知的未来支出合计
+	Upcoming               []BudgetUpcomingItem `json:"upcoming,omitempty"`                 // 已知的未来支出明细
+	ProjectedSpend         float64              `json:"projected_spend"`                    // 预计周期结束时的总支出 = 已支出 + 日均支出×剩余天数 + 已知未来支出
+	ProjectedOverspend     float64              `json:"projected_overspend"`                // 预计超支金额，不超支为0
+	WillOverspend          bool                 `json:"will_overspend"`                     // 预计是否会超支
+	ProjectedOverspendDate *time.Time           `json:"projected_overspend_date,omitempty"` // 预计开始超支的日期
+	SafeDailyAllowance     float64              `json:"safe_daily_allowance"`               // 剩余每天可以花的金额 (已扣除已知未来支出)
+	WillMissTarget         bool                 `json:"will_miss_target,omitempty"`         // 收入目标：预计周期结束时是否达不到目标
+	ProjectedShortfall     float64              `json:"projected_shortfall,omitempty"`      // 收入目标：预计距离目标的差额
+	RequiredDailyIncome    float64              `json:"required_daily_income,omitempty"`    // 收入目标：剩余每天需要的收入
+	ExpectedSpendToDate    float64              `json:"expected_spend_to_date"`             // 按时间均匀花费时至今应花的金额
+	Pacing                 string               `json:"pacing"`                             // 花费节奏：ahead 快于计划，on_track 符合计划，behind 慢于计划
 }
 
 // BudgetProgressResponse 预算进度响应
 type BudgetProgressResponse struct {
-	BudgetResponse          // 嵌入预算基本信息
-	SpentAmount     float64 `json:"spent_amount"`     // 已花费金额
-	RemainingAmount float64 `json:"remaining_amount"` // 剩余金额
-	UsageRate       float64 `json:"usage_rate"`       // 使用率 (0-1.0)
-	IsOverBudget    bool    `json:"is_over_budget"`   // 是否超出预算
-	DaysRemaining   int     `json:"days_remaining"`   // 周期内剩余天数
+	BudgetResponse                  // 嵌入预算基本信息
+	EffectiveAmount float64         `json:"effective_amount"`   // 当前周期实际可用金额 = 预算金额 + 结转金额
+	Rollover        *BudgetRollover `json:"rollover,omitempty"` // 结转明细，不结转的预算为空
+	SpentAmount     float64         `json:"spent_amount"`       // 已花费金额
+	RemainingAmount float64         `json:"remaining_amount"`   // 剩余金额 (相对实际可用金额)
+	UsageRate       float64         `json:"usage_rate"`         // 使用率 (0-1.0，相对实际可用金额)
+	IsOverBudget    bool            `json:"is_over_budget"`     // 是否超出预算，收入目标始终为 false
+	IsTargetReached bool            `json:"is_target_reached"`  // 收入目标是否已达成
+	DaysRemaining   int             `json:"days_remaining"`     // 周期内剩余天数
+	Forecast        *BudgetForecast `json:"forecast,omitempty"` // 周期结束时的支出预测
 	CurrentPeriod   struct {
 		StartDate time.Time `json:"start_date"` // 当前周期开始日期
 		EndDate   time.Time `json:"end_date"`   // 当前周期结束日期
@@ -82,7 +163,89 @@ type BudgetQuery struct {
 	Page       int    `form:"page" json:"page" binding:"required,min=1"`                   // 页码
 	PageSize   int    `form:"page_size" json:"page_size" binding:"required,min=1,max=100"` // 每页大小
 	Type       string `form:"type" json:"type"`                                            // 预算类型
+	Kind       string `form:"kind" json:"kind"`                                            // 预算方向
 	Period     string `form:"period" json:"period"`                                        // 预算周期
 	CategoryID uint   `form:"category_id" json:"category_id"`                              // 分类ID
 	IsActive   *bool  `form:"is_active" json:"is_active"`                                  // 是否激活
+	IsEnvelope *bool  `form:"is_envelope" json:"is_envelope"`                              // 是否为信封
+}
+
+// BudgetHistoryPeriod 预算在一个周期内的执行情况
+type BudgetHistoryPeriod struct {
+	StartDate    time.Time `json:"start_date"`     // 周期开始日期
+	EndDate      time.Time `json:"end_date"`       // 周期结束日期
+	Amount       float64   `json:"amount"`         // 该周期的预算金额 (修改金额前的周期保留原金额)
+	Spent        float64   `json:"spent"`          // 实际支出
+	Variance     float64   `json:"variance"`       // 差额 = 预算金额 - 实际支出，负数表示超支
+	IsOverBudget bool      `json:"is_over_budget"` // 是否超支，收入目标始终为 false
+	IsCurrent    bool      `json:"is_current"`     // 是否为进行中的当前周期
+}
+
+// BudgetHistoryResponse 预算历史执行情况
+type BudgetHistoryResponse struct {
+	BudgetID uint                  `json:"budget_id"` // 预算ID
+	Name     string                `json:"name"`      // 预算名称
+	Type     string                `json:"type"`      // 预算类型
+	Kind     string                `json:"kind"`      // 预算方向
+	Period   string                `json:"period"`    // 预算周期
+	Periods  []BudgetHistoryPeriod `json:"periods"`   // 从开始日期以来的各周期，按时间升序
+}
+
+// BudgetMatrixRequest 预算与实际对比表的查询参数
+type BudgetMatrixRequest struct {
+	Year            int  `form:"year" binding:"omitempty,min=1970,max=9999"` // 年份，默认今年
+	IncludeInactive bool `form:"include_inactive"`                           // 是否包含未激活的预算
+}
+
+// BudgetMatrixCell 对比表中的一个单元格
+type BudgetMatrixCell struct {
+	Month        string  `json:"month"`          // 月份 (YYYY-MM)，合计行为年份
+	Budgeted     float64 `json:"budgeted"`       // 预算金额
+	Actual       float64 `json:"actual"`         // 实际支出
+	Variance     float64 `json:"variance"`       // 差额 = 预算金额 - 实际支出
+	IsOverBudget bool    `json:"is_over_budget"` // 是否超支
+}
+
+// BudgetMatrixRow 对比表中一个预算的各月数据
+type BudgetMatrixRow struct {
+	BudgetID   uint               `json:"budget_id"`   // 预算ID
+	Name       string             `json:"name"`        // 预算名称
+	Type       string             `json:"type"`        // 预算类型
+	Kind       string             `json:"kind"`        // 预算方向
+	Period     string             `json:"period"`      // 预算周期
+	CategoryID *uint              `json:"category_id"` // 分类ID
+	Months     []BudgetMatrixCell `json:"months"`      // 1-12月
+	Total      BudgetMatrixCell   `json:"total"`       // 全年合计
+}
+
+// BudgetMatrixResponse 预算与实际对比表
+type BudgetMatrixResponse struct {
+	Year    int               `json:"year"`    // 年份
+	Budgets []BudgetMatrixRow `json:"budgets"` // 各预算的数据
+}
+
+// BudgetImpact 一次交易写入使预算在当前周期跨过的阈值
+type BudgetImpact struct {
+	BudgetID        uint      `json:"budget_id"`        // 预算ID
+	Name            string    `json:"name"`             // 预算名称
+	Level           string    `json:"level"`            // 跨过的阈值级别 (warning: 达到提醒阈值, exceeded: 超出预算)
+	NotifyRate      float64   `json:"notify_rate"`      // 提醒阈值
+	UsageRate       float64   `json:"usage_rate"`       // 写入后的使用率
+	SpentAmount     float64   `json:"spent_amount"`     // 写入后的已花费金额
+	EffectiveAmount float64   `json:"effective_amount"` // 实际可用金额
+	PeriodStart     time.Time `json:"period_start"`     // 当前周期开始日期
+	PeriodEnd       time.Time `json:"period_end"`       // 当前周期结束日期
+}
+
+// BudgetThresholdEventResponse 预算跨过阈值的记录
+type BudgetThresholdEventResponse struct {
+	ID              uint      `json:"id"`                       // 记录ID
+	BudgetID        uint      `json:"budget_id"`                // 预算ID
+	PeriodStart     time.Time `json:"period_start"`             // 周期开始日期
+	Level           string    `json:"level"`                    // 阈值级别 (warning, exceeded)
+	TransactionID   *uint     `json:"transaction_id,omitempty"` // 使预算跨过阈值的交易ID，由后台扫描发现时为空
+	SpentAmount     float64   `json:"spent_amount"`             // 跨过阈值时的已花费金额
+	EffectiveAmount float64   `json:"effective_amount"`         // 跨过阈值时的实际可用金额
+	UsageRate       float64   `json:"usage_rate"`               // 跨过阈值时的使用率
+	CreatedAt       time.Time `json:"created_at"`               // 记录时间
 }
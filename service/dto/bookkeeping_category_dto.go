@@ -28,6 +28,7 @@ type CategoryResponse struct {
 	ParentID      *uint              `json:"parent_id,omitempty"`
 	Icon          string             `json:"icon,omitempty"`
 	SortOrder     int                `json:"sort_order"`
+	IsArchived    bool               `json:"is_archived"`
 	CreatedAt     string             `json:"created_at"`
 	UpdatedAt     string             `json:"updated_at"`
 	SubCategories []CategoryResponse `json:"sub_categories,omitempty"` // 子分类列表 (递归展示)
@@ -39,3 +40,45 @@ type CategoryListResponse struct {
 	Total int64              `json:"total"`
 	Items []CategoryResponse `json:"items"`
 }
+
+// MergeCategoryRequest 合并分类的请求体，将路径中的源分类合并到目标分类
+type MergeCategoryRequest struct {
+	TargetCategoryID uint `json:"target_category_id" binding:"required"` // 目标分类ID，必须与源分类类型相同
+	DryRun           bool `json:"dry_run,omitempty"`                     // 只返回合并预览，不做修改
+}
+
+// MergeCategoryResponse 合并分类的结果（或预览）
+type MergeCategoryResponse struct {
+	DryRun              bool             `json:"dry_run"`
+	SourceCategoryID    uint             `json:"source_category_id"`
+	SourceCategoryName  string           `json:"source_category_name"`
+	TargetCategoryID    uint             `json:"target_category_id"`
+	TargetCategoryName  string           `json:"target_category_name"`
+	MovedRecords        map[string]int64 `json:"moved_records"`         // 各类关联记录的迁移数量，sub_categories 为移到目标分类下的子分类数
+	MergedSubCategories []string         `json:"merged_sub_categories"` // 与目标分类下同名子分类合并的子分类路径
+}
+
+// MoveCategoryRequest 移动分类的请求体
+type MoveCategoryRequest struct {
+	ParentID  *uint `json:"parent_id"`            // 新的父分类ID，为空或0表示移动为顶级分类
+	SortOrder *int  `json:"sort_order,omitempty"` // 在新位置的排序值，为空时排在同级分类最后
+}
+
+// SortCategoriesRequest 批量调整同级分类顺序的请求体
+type SortCategoriesRequest struct {
+	ParentID    *uint  `json:"parent_id"`                             // 父分类ID，为空或0表示顶级分类
+	CategoryIDs []uint `json:"category_ids" binding:"required,min=1"` // 按新顺序排列的分类ID
+}
+
+// CategoryTreeNode 分类树中的一个节点
+type CategoryTreeNode struct {
+	ID       uint               `json:"id" binding:"required"`
+	Children []CategoryTreeNode `json:"children,omitempty" binding:"omitempty,dive"`
+}
+
+// ReorderCategoryTreeRequest 整体调整分类树结构的请求体，节点在数组中的位置即为排序
+// 未出现在树中的分类保持原有的父分类和排序
+type ReorderCategoryTreeRequest struct {
+	Type  model.CategoryType `json:"type" binding:"required,oneof=income expense"` // 分类类型
+	Nodes []CategoryTreeNode `json:"nodes" binding:"required,min=1,dive"`          // 顶级节点
+}
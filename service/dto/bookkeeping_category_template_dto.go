@@ -0,0 +1,50 @@
+package dto
+
+import "github.com/dotdancer/gogofly/model"
+
+// CategoryTemplateNode 分类模板中的一个节点，节点在数组中的位置即为排序
+type CategoryTemplateNode struct {
+	Name     string                 `json:"name" binding:"required,min=1,max=100"`
+	Type     model.CategoryType     `json:"type,omitempty" binding:"omitempty,oneof=income expense"` // 顶级节点必填，子节点为空时沿用父节点类型
+	Icon     string                 `json:"icon,omitempty" binding:"omitempty,max=100"`
+	Children []CategoryTemplateNode `json:"children,omitempty" binding:"omitempty,dive"`
+}
+
+// SaveCategoryTemplateRequest 创建或更新分类模板的请求体 (管理员)，也是导出分类树的格式
+type SaveCategoryTemplateRequest struct {
+	Code        string                 `json:"code" binding:"required,min=1,max=50"`
+	Locale      string                 `json:"locale" binding:"required,min=2,max=20"`
+	Name        string                 `json:"name" binding:"required,min=1,max=100"`
+	Description string                 `json:"description,omitempty" binding:"omitempty,max=255"`
+	IsActive    *bool                  `json:"is_active,omitempty"` // 为空时新建模板默认启用，更新时保持不变
+	Nodes       []CategoryTemplateNode `json:"nodes" binding:"required,min=1,dive"`
+}
+
+// ListCategoryTemplatesRequest 查询分类模板列表的请求参数
+type ListCategoryTemplatesRequest struct {
+	Locale string `form:"locale"` // 语言，为空时返回所有语言
+}
+
+// CategoryTemplateResponse 分类模板的响应体，列表中不包含 Nodes
+type CategoryTemplateResponse struct {
+	ID          uint                   `json:"id"`
+	Code        string                 `json:"code"`
+	Locale      string                 `json:"locale"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	IsBuiltin   bool                   `json:"is_builtin"`
+	IsActive    bool                   `json:"is_active"`
+	ItemCount   int                    `json:"item_count"`
+	Nodes       []CategoryTemplateNode `json:"nodes,omitempty"`
+	CreatedAt   string                 `json:"created_at"`
+	UpdatedAt   string                 `json:"updated_at"`
+}
+
+// ApplyCategoryTemplateResponse 应用分类模板的结果
+type ApplyCategoryTemplateResponse struct {
+	TemplateID   uint   `json:"template_id"`
+	TemplateName string `json:"template_name"`
+	Created      int    `json:"created"`  // 新建的分类数
+	Existing     int    `json:"existing"` // 已存在同名分类而跳过的数量
+	TooDeep      int    `json:"too_deep"` // 超过最大层级而跳过的数量
+}
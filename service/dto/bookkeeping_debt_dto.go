@@ -0,0 +1,103 @@
+package dto
+
+// CreateCounterpartyRequest 创建联系人的请求体
+type CreateCounterpartyRequest struct {
+	Name   string `json:"name" binding:"required,max=100"`              // 姓名
+	Phone  string `json:"phone,omitempty" binding:"omitempty,max=30"`   // 联系电话
+	Remark string `json:"remark,omitempty" binding:"omitempty,max=255"` // 备注
+}
+
+// UpdateCounterpartyRequest 更新联系人的请求体
+type UpdateCounterpartyRequest struct {
+	Name   *string `json:"name,omitempty" binding:"omitempty,max=100"`   // 姓名
+	Phone  *string `json:"phone,omitempty" binding:"omitempty,max=30"`   // 联系电话
+	Remark *string `json:"remark,omitempty" binding:"omitempty,max=255"` // 备注
+}
+
+// CounterpartyResponse 联系人的响应体
+type CounterpartyResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Phone     string `json:"phone,omitempty"`
+	Remark    string `json:"remark,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CreateDebtRequest 记录借出或借入的请求体
+type CreateDebtRequest struct {
+	CounterpartyID   uint    `json:"counterparty_id,omitempty"`                               // 联系人ID (与counterparty_name二选一)
+	CounterpartyName string  `json:"counterparty_name,omitempty" binding:"omitempty,max=100"` // 联系人姓名，不存在时自动创建
+	Direction        string  `json:"direction" binding:"required,oneof=lend borrow"`          // 方向 (lend: 借出, borrow: 借入)
+	AccountID        uint    `json:"account_id" binding:"required"`                           // 借出或借入资金的账户ID
+	Amount           float64 `json:"amount" binding:"required,gt=0"`                          // 金额
+	DebtDate         string  `json:"debt_date" binding:"required"`                            // 借贷日期 (YYYY-MM-DD)
+	DueDate          string  `json:"due_date,omitempty"`                                      // 约定还款日期 (YYYY-MM-DD)
+	Notes            string  `json:"notes,omitempty" binding:"omitempty,max=255"`             // 备注
+}
+
+// DebtQuery 借贷记录查询条件
+type DebtQuery struct {
+	CounterpartyID uint   `form:"counterparty_id"`                                 // 联系人ID
+	Direction      string `form:"direction" binding:"omitempty,oneof=lend borrow"` // 方向
+	Status         string `form:"status" binding:"omitempty,oneof=open settled"`   // 状态
+}
+
+// CreateDebtRepaymentRequest 记录还款的请求体
+type CreateDebtRepaymentRequest struct {
+	AccountID     uint    `json:"account_id" binding:"required"`               // 收款或还款账户ID
+	Amount        float64 `json:"amount" binding:"required,gt=0"`              // 还款金额，不能超过未还金额
+	RepaymentDate string  `json:"repayment_date" binding:"required"`           // 还款日期 (YYYY-MM-DD)
+	Notes         string  `json:"notes,omitempty" binding:"omitempty,max=255"` // 备注
+}
+
+// DebtRepaymentResponse 还款记录的响应体
+type DebtRepaymentResponse struct {
+	ID            uint    `json:"id"`
+	DebtID        uint    `json:"debt_id"`
+	AccountID     uint    `json:"account_id"`
+	Amount        float64 `json:"amount"`
+	RepaymentDate string  `json:"repayment_date"`
+	TransactionID *uint   `json:"transaction_id,omitempty"`
+	Notes         string  `json:"notes,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// DebtResponse 借贷记录的响应体
+type DebtResponse struct {
+	ID               uint                    `json:"id"`
+	CounterpartyID   uint                    `json:"counterparty_id"`
+	CounterpartyName string                  `json:"counterparty_name"`
+	Direction        string                  `json:"direction"`
+	AccountID        uint                    `json:"account_id"`
+	Amount           float64                 `json:"amount"`
+	RepaidAmount     float64                 `json:"repaid_amount"`
+	Outstanding      float64                 `json:"outstanding"` // 未还金额
+	DebtDate         string                  `json:"debt_date"`
+	DueDate          string                  `json:"due_date,omitempty"`
+	Status           string                  `json:"status"`
+	IsOverdue        bool                    `json:"is_overdue"`             // 是否已逾期
+	DaysOverdue      int                     `json:"days_overdue,omitempty"` // 逾期天数
+	TransactionID    *uint                   `json:"transaction_id,omitempty"`
+	Notes            string                  `json:"notes,omitempty"`
+	CreatedAt        string                  `json:"created_at"`
+	Repayments       []DebtRepaymentResponse `json:"repayments,omitempty"` // 还款记录 (仅详情返回)
+}
+
+// DebtSummaryItem 单个联系人的借贷汇总
+type DebtSummaryItem struct {
+	CounterpartyID   uint    `json:"counterparty_id"`
+	CounterpartyName string  `json:"counterparty_name"`
+	Receivable       float64 `json:"receivable"`    // 对方欠我 (借出未收回)
+	Payable          float64 `json:"payable"`       // 我欠对方 (借入未归还)
+	Net              float64 `json:"net"`           // 净额，正数表示对方欠我
+	OpenCount        int     `json:"open_count"`    // 未结清笔数
+	OverdueCount     int     `json:"overdue_count"` // 逾期笔数
+}
+
+// DebtSummaryResponse 借贷汇总响应
+type DebtSummaryResponse struct {
+	TotalReceivable float64           `json:"total_receivable"` // 应收合计
+	TotalPayable    float64           `json:"total_payable"`    // 应付合计
+	Net             float64           `json:"net"`              // 净额
+	Items           []DebtSummaryItem `json:"items"`
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+)
+
+// CreateEnvelopeRequest 创建信封请求，信封是按自然月分配资金的分类预算
+type CreateEnvelopeRequest struct {
+	Name               string   `json:"name" binding:"required,max=100"`             // 信封名称
+	CategoryID         uint     `json:"category_id" binding:"required"`              // 支出分类ID (含子分类)
+	CategoryIDs        []uint   `json:"category_ids"`                                // 额外计入的分类 (含子分类)
+	ExcludeCategoryIDs []uint   `json:"exclude_category_ids"`                        // 排除的分类 (含子分类)
+	NotifyRate         *float64 `json:"notify_rate" binding:"omitempty,gte=0,lte=1"` // 提醒阈值，默认0.8
+	Description        string   `json:"description" binding:"omitempty,max=255"`     // 备注
+}
+
+// EnvelopeMonthQuery 信封月度概览查询参数
+type EnvelopeMonthQuery struct {
+	Month string `form:"month"` // 月份 (YYYY-MM)，默认本月
+}
+
+// EnvelopeItem 信封在一个月内的资金情况
+type EnvelopeItem struct {
+	BudgetID    uint    `json:"budget_id"`    // 信封 (预算) ID
+	Name        string  `json:"name"`         // 信封名称
+	CategoryID  *uint   `json:"category_id"`  // 分类ID
+	CarriedIn   float64 `json:"carried_in"`   // 从上月结转的余额 (负数为上月超支)
+	Assigned    float64 `json:"assigned"`     // 本月分配的金额
+	Spent       float64 `json:"spent"`        // 本月支出
+	Available   float64 `json:"available"`    // 可用余额 = 结转 + 分配 - 支出，结转到下月
+	IsOverspent bool    `json:"is_overspent"` // 可用余额是否为负
+}
+
+// EnvelopeMonthResponse 信封模式的月度概览
+type EnvelopeMonthResponse struct {
+	Month         string         `json:"month"`           // 月份 (YYYY-MM)
+	ReadyToAssign float64        `json:"ready_to_assign"` // 待分配金额 = 开启时的金额 + 开启以来的收入 - 已分配到各信封的金额
+	Income        float64        `json:"income"`          // 本月收入
+	Assigned      float64        `json:"assigned"`        // 本月分配合计
+	Spent         float64        `json:"spent"`           // 本月信封支出合计
+	Available     float64        `json:"available"`       // 各信封可用余额合计
+	Envelopes     []EnvelopeItem `json:"envelopes"`       // 各信封
+}
+
+// EnvelopeMoveRequest 分配或移动信封资金的请求
+type EnvelopeMoveRequest struct {
+	Month        string  `json:"month"`                            // 月份 (YYYY-MM)，默认本月
+	FromBudgetID *uint   `json:"from_budget_id"`                   // 来源信封ID，为空表示从待分配中分配
+	ToBudgetID   *uint   `json:"to_budget_id"`                     // 去向信封ID，为空表示退回待分配
+	Amount       float64 `json:"amount" binding:"required,gt=0"`   // 金额
+	Note         string  `json:"note" binding:"omitempty,max=255"` // 备注
+}
+
+// EnvelopeMoveQuery 资金移动记录查询参数
+type EnvelopeMoveQuery struct {
+	Month    string `form:"month"`     // 月份 (YYYY-MM)，为空表示全部
+	BudgetID uint   `form:"budget_id"` // 只看与该信封相关的记录
+}
+
+// EnvelopeMoveResponse 资金分配或移动记录
+type EnvelopeMoveResponse struct {
+	ID           uint      `json:"id"`                  // 记录ID
+	Month        string    `json:"month"`               // 月份
+	FromBudgetID *uint     `json:"from_budget_id"`      // 来源信封ID，为空表示待分配
+	FromName     string    `json:"from_name,omitempty"` // 来源信封名称
+	ToBudgetID   *uint     `json:"to_budget_id"`        // 去向信封ID，为空表示待分配
+	ToName       string    `json:"to_name,omitempty"`   // 去向信封名称
+	Amount       float64   `json:"amount"`              // 金额
+	Note         string    `json:"note,omitempty"`      // 备注
+	CreatedAt    time.Time `json:"created_at"`          // 记录时间
+}
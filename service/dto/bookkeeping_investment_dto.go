@@ -0,0 +1,111 @@
+package dto
+
+// CreateSecurityRequest 创建证券的请求体
+type CreateSecurityRequest struct {
+	Symbol string `json:"symbol" binding:"required,max=30"`                    // 证券代码
+	Name   string `json:"name" binding:"required,max=100"`                     // 证券名称
+	Kind   string `json:"kind" binding:"required,oneof=stock fund bond other"` // 品种类型
+	Remark string `json:"remark,omitempty" binding:"omitempty,max=255"`        // 备注
+}
+
+// SecurityResponse 证券的响应体
+type SecurityResponse struct {
+	ID          uint    `json:"id"`
+	Symbol      string  `json:"symbol"`
+	Name        string  `json:"name"`
+	Kind        string  `json:"kind"`
+	Remark      string  `json:"remark,omitempty"`
+	LatestPrice float64 `json:"latest_price"`         // 最新价格
+	PriceDate   string  `json:"price_date,omitempty"` // 最新价格日期
+	CreatedAt   string  `json:"created_at"`
+}
+
+// SecurityPriceItem 单条证券价格
+type SecurityPriceItem struct {
+	SecurityID uint    `json:"security_id,omitempty"`         // 证券ID (与symbol二选一)
+	Symbol     string  `json:"symbol,omitempty"`              // 证券代码 (与security_id二选一)
+	Date       string  `json:"date" binding:"required"`       // 价格日期 (YYYY-MM-DD)
+	Price      float64 `json:"price" binding:"required,gt=0"` // 收盘价/净值
+}
+
+// SavePricesRequest 批量保存证券价格的请求体
+type SavePricesRequest struct {
+	Items []SecurityPriceItem `json:"items" binding:"required,min=1,dive"` // 价格列表，同一证券同一日期的价格会被覆盖
+}
+
+// SavePricesResponse 保存证券价格的结果
+type SavePricesResponse struct {
+	Saved   int      `json:"saved"`            // 成功保存的条数
+	Skipped int      `json:"skipped"`          // 跳过的条数
+	Errors  []string `json:"errors,omitempty"` // 跳过原因
+}
+
+// SecurityPriceResponse 证券价格的响应体
+type SecurityPriceResponse struct {
+	Date   string  `json:"date"`
+	Price  float64 `json:"price"`
+	Source string  `json:"source"`
+}
+
+// CreateInvestmentTradeRequest 记录投资交易的请求体
+type CreateInvestmentTradeRequest struct {
+	SecurityID uint    `json:"security_id" binding:"required"`                        // 证券ID
+	Type       string  `json:"type" binding:"required,oneof=buy sell dividend split"` // 交易类型
+	TradeDate  string  `json:"trade_date" binding:"required"`                         // 交易日期 (YYYY-MM-DD)
+	Quantity   float64 `json:"quantity" binding:"omitempty,gt=0"`                     // 数量 (买入、卖出必填)
+	Price      float64 `json:"price" binding:"omitempty,gt=0"`                        // 成交价 (买入、卖出必填)
+	Fee        float64 `json:"fee" binding:"omitempty,gte=0"`                         // 手续费
+	Amount     float64 `json:"amount" binding:"omitempty,gt=0"`                       // 分红金额 (分红必填)
+	SplitRatio float64 `json:"split_ratio" binding:"omitempty,gt=0"`                  // 拆股比例 (拆股必填，如2表示1拆2)
+	CategoryID uint    `json:"category_id,omitempty"`                                 // 分红收入的分类ID (可选)
+	Notes      string  `json:"notes,omitempty" binding:"omitempty,max=255"`           // 备注
+}
+
+// InvestmentTradeResponse 投资交易的响应体
+type InvestmentTradeResponse struct {
+	ID            uint    `json:"id"`
+	AccountID     uint    `json:"account_id"`
+	SecurityID    uint    `json:"security_id"`
+	Symbol        string  `json:"symbol"`
+	SecurityName  string  `json:"security_name"`
+	Type          string  `json:"type"`
+	TradeDate     string  `json:"trade_date"`
+	Quantity      float64 `json:"quantity"`
+	Price         float64 `json:"price"`
+	Fee           float64 `json:"fee"`
+	Amount        float64 `json:"amount"`
+	SplitRatio    float64 `json:"split_ratio,omitempty"`
+	RealizedGain  float64 `json:"realized_gain"`
+	TransactionID *uint   `json:"transaction_id,omitempty"`
+	Notes         string  `json:"notes,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// HoldingItem 单个证券的持仓估值
+type HoldingItem struct {
+	SecurityID     uint    `json:"security_id"`
+	Symbol         string  `json:"symbol"`
+	Name           string  `json:"name"`
+	Quantity       float64 `json:"quantity"`        // 持仓数量
+	CostBasis      float64 `json:"cost_basis"`      // 持仓成本
+	Price          float64 `json:"price"`           // 估值价格
+	PriceDate      string  `json:"price_date"`      // 估值价格日期
+	MarketValue    float64 `json:"market_value"`    // 市值
+	UnrealizedGain float64 `json:"unrealized_gain"` // 浮动盈亏
+}
+
+// InvestmentValuationResponse 投资账户估值响应
+type InvestmentValuationResponse struct {
+	AccountID      uint          `json:"account_id"`
+	AccountName    string        `json:"account_name"`
+	Date           string        `json:"date"`            // 估值日期
+	CashBalance    float64       `json:"cash_balance"`    // 现金余额
+	MarketValue    float64       `json:"market_value"`    // 持仓市值
+	TotalValue     float64       `json:"total_value"`     // 总资产（现金+市值）
+	CostBasis      float64       `json:"cost_basis"`      // 持仓成本
+	RealizedGain   float64       `json:"realized_gain"`   // 已实现收益
+	UnrealizedGain float64       `json:"unrealized_gain"` // 浮动盈亏
+	Dividends      float64       `json:"dividends"`       // 累计分红
+	XIRR           *float64      `json:"xirr"`            // 资金加权年化收益率 (无法计算时为null)
+	Holdings       []HoldingItem `json:"holdings"`        // 持仓明细
+}
@@ -0,0 +1,40 @@
+package dto
+
+// 账本检查发现的问题类型
+const (
+	LedgerIssueBalanceMismatch      = "balance_mismatch"       // 账户当前余额与交易记录不一致
+	LedgerIssueMissingAccount       = "missing_account"        // 交易关联的账户不存在或已删除
+	LedgerIssueMissingCategory      = "missing_category"       // 交易关联的分类不存在或已删除
+	LedgerIssueCategoryTypeMismatch = "category_type_mismatch" // 交易类型与分类类型不一致
+)
+
+// LedgerCheckRequest 账本检查的请求参数
+type LedgerCheckRequest struct {
+	UserID uint `form:"user_id" json:"user_id"` // 要检查的用户ID，为空时检查所有用户
+}
+
+// LedgerIssue 账本检查发现的单个问题
+type LedgerIssue struct {
+	Type          string `json:"type"`                     // 问题类型
+	UserID        uint   `json:"user_id"`                  // 所属用户ID
+	AccountID     uint   `json:"account_id,omitempty"`     // 相关账户ID
+	TransactionID uint   `json:"transaction_id,omitempty"` // 相关交易ID
+	CategoryID    uint   `json:"category_id,omitempty"`    // 相关分类ID
+	Description   string `json:"description"`              // 问题描述
+	Suggestion    string `json:"suggestion"`               // 建议的修复方式
+	Repairable    bool   `json:"repairable"`               // 是否可以自动修复
+	Repaired      bool   `json:"repaired"`                 // 是否已修复 (仅修复模式)
+	RepairError   string `json:"repair_error,omitempty"`   // 修复失败的原因
+}
+
+// LedgerCheckReport 账本检查报告
+type LedgerCheckReport struct {
+	UserID              uint          `json:"user_id,omitempty"`    // 检查的用户ID，为空表示所有用户
+	Repair              bool          `json:"repair"`               // 是否为修复模式
+	CheckedAccounts     int           `json:"checked_accounts"`     // 检查的账户数
+	CheckedTransactions int           `json:"checked_transactions"` // 检查的交易数
+	IssueCount          int           `json:"issue_count"`          // 发现的问题数
+	RepairedCount       int           `json:"repaired_count"`       // 已修复的问题数
+	Issues              []LedgerIssue `json:"issues"`
+	CheckedAt           string        `json:"checked_at"`
+}
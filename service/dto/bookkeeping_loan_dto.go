@@ -0,0 +1,101 @@
+package dto
+
+// CreateLoanRequest 创建贷款的请求体
+type CreateLoanRequest struct {
+	AccountID          uint    `json:"account_id,omitempty"`                                              // 关联的负债账户ID，为空时自动创建贷款账户
+	Name               string  `json:"name" binding:"required,max=100"`                                   // 贷款名称
+	Principal          float64 `json:"principal" binding:"required,gt=0"`                                 // 贷款本金
+	AnnualRate         float64 `json:"annual_rate" binding:"gte=0,lte=100"`                               // 年利率 (百分比，如4.9表示4.9%)
+	TermMonths         int     `json:"term_months" binding:"required,min=1,max=600"`                      // 贷款期数 (月)
+	Method             string  `json:"method" binding:"required,oneof=equal_installment equal_principal"` // 还款方式
+	StartDate          string  `json:"start_date" binding:"required"`                                     // 放款日期 (YYYY-MM-DD)
+	InterestCategoryID *uint   `json:"interest_category_id,omitempty"`                                    // 利息支出的分类ID，为空时使用"贷款利息"分类
+	Notes              string  `json:"notes,omitempty" binding:"omitempty,max=255"`                       // 备注
+}
+
+// UpdateLoanRequest 更新贷款的请求体，本金、利率等影响还款计划的字段不允许修改
+type UpdateLoanRequest struct {
+	Name               *string `json:"name,omitempty" binding:"omitempty,max=100"`  // 贷款名称
+	InterestCategoryID *uint   `json:"interest_category_id,omitempty"`              // 利息支出的分类ID
+	Notes              *string `json:"notes,omitempty" binding:"omitempty,max=255"` // 备注
+}
+
+// LoanResponse 贷款的响应体
+type LoanResponse struct {
+	ID                 uint              `json:"id"`
+	AccountID          uint              `json:"account_id"`
+	AccountName        string            `json:"account_name"`
+	Name               string            `json:"name"`
+	Principal          float64           `json:"principal"`
+	AnnualRate         float64           `json:"annual_rate"`
+	TermMonths         int               `json:"term_months"`
+	Method             string            `json:"method"`
+	StartDate          string            `json:"start_date"`
+	InterestCategoryID *uint             `json:"interest_category_id,omitempty"`
+	RemainingPrincipal float64           `json:"remaining_principal"`    // 剩余本金
+	PrincipalPaid      float64           `json:"principal_paid"`         // 累计已还本金
+	TotalInterestPaid  float64           `json:"total_interest_paid"`    // 累计已还利息
+	RemainingInterest  float64           `json:"remaining_interest"`     // 按当前计划剩余应还利息
+	PaidPeriods        int               `json:"paid_periods"`           // 已还期数
+	RemainingTerm      int               `json:"remaining_term"`         // 剩余期数
+	Status             string            `json:"status"`                 // 状态 (active, paid_off)
+	NextPayment        *LoanScheduleItem `json:"next_payment,omitempty"` // 下一期还款
+	Notes              string            `json:"notes,omitempty"`
+	CreatedAt          string            `json:"created_at"`
+}
+
+// LoanScheduleItem 还款计划中的一期
+type LoanScheduleItem struct {
+	Period             int     `json:"period"`              // 期数 (提前还款为0)
+	Kind               string  `json:"kind"`                // 类型 (regular, prepayment)
+	DueDate            string  `json:"due_date"`            // 还款日期 (已还为实际还款日期)
+	Payment            float64 `json:"payment"`             // 还款总额
+	Principal          float64 `json:"principal"`           // 本金
+	Interest           float64 `json:"interest"`            // 利息
+	RemainingPrincipal float64 `json:"remaining_principal"` // 本期还款后的剩余本金
+	Paid               bool    `json:"paid"`                // 是否已还
+}
+
+// LoanScheduleResponse 贷款还款计划的响应体，包含已还记录和按当前状态推算的未还计划
+type LoanScheduleResponse struct {
+	LoanID        uint               `json:"loan_id"`
+	Method        string             `json:"method"`
+	TotalPayment  float64            `json:"total_payment"`  // 还款总额 (已还+未还)
+	TotalInterest float64            `json:"total_interest"` // 利息总额 (已还+未还)
+	Items         []LoanScheduleItem `json:"items"`
+}
+
+// LoanRepaymentRequest 按期还款的请求体，本金和利息按还款计划自动拆分
+type LoanRepaymentRequest struct {
+	FromAccountID uint   `json:"from_account_id" binding:"required"`          // 还款账户ID
+	RepaymentDate string `json:"repayment_date" binding:"required"`           // 还款日期 (YYYY-MM-DD)
+	Notes         string `json:"notes,omitempty" binding:"omitempty,max=255"` // 备注
+}
+
+// LoanPrepaymentRequest 提前还款的请求体
+type LoanPrepaymentRequest struct {
+	FromAccountID uint    `json:"from_account_id" binding:"required"`                        // 还款账户ID
+	RepaymentDate string  `json:"repayment_date" binding:"required"`                         // 还款日期 (YYYY-MM-DD)
+	Amount        float64 `json:"amount" binding:"required,gt=0"`                            // 提前还款本金
+	Mode          string  `json:"mode" binding:"required,oneof=shorten_term reduce_payment"` // 重算方式
+	Notes         string  `json:"notes,omitempty" binding:"omitempty,max=255"`               // 备注
+}
+
+// LoanRepaymentResponse 还款记录的响应体
+type LoanRepaymentResponse struct {
+	ID                     uint    `json:"id"`
+	LoanID                 uint    `json:"loan_id"`
+	Kind                   string  `json:"kind"`
+	Period                 int     `json:"period"`
+	RepaymentDate          string  `json:"repayment_date"`
+	Principal              float64 `json:"principal"`
+	Interest               float64 `json:"interest"`
+	Amount                 float64 `json:"amount"`
+	FromAccountID          uint    `json:"from_account_id"`
+	PrepaymentMode         string  `json:"prepayment_mode,omitempty"`
+	PrincipalTransactionID *uint   `json:"principal_transaction_id,omitempty"`
+	InterestTransactionID  *uint   `json:"interest_transaction_id,omitempty"`
+	LoanTransactionID      *uint   `json:"loan_transaction_id,omitempty"`
+	Notes                  string  `json:"notes,omitempty"`
+	CreatedAt              string  `json:"created_at"`
+}
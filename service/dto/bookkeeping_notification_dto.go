@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+)
+
+// NotificationQuery 通知列表查询参数
+type NotificationQuery struct {
+	Page     int    `form:"page" json:"page" binding:"required,min=1"`                   // 页码
+	PageSize int    `form:"page_size" json:"page_size" binding:"required,min=1,max=100"` // 每页大小
+	Event    string `form:"event" json:"event"`                                          // 事件类型
+	IsRead   *bool  `form:"is_read" json:"is_read"`                                      // 是否已读
+}
+
+// NotificationResponse 通知的响应体，也是推送到各渠道的内容
+type NotificationResponse struct {
+	ID        uint       `json:"id"`                // 通知ID
+	Event     string     `json:"event"`             // 事件类型
+	Title     string     `json:"title"`             // 标题
+	Content   string     `json:"content"`           // 内容
+	RefType   string     `json:"ref_type"`          // 关联对象类型
+	RefID     uint       `json:"ref_id"`            // 关联对象ID
+	IsRead    bool       `json:"is_read"`           // 是否已读
+	ReadAt    *time.Time `json:"read_at,omitempty"` // 阅读时间
+	CreatedAt time.Time  `json:"created_at"`        // 通知时间
+}
+
+// NotificationListResponse 通知列表响应
+type NotificationListResponse struct {
+	Total  int64                  `json:"total"`  // 符合条件的总数
+	Unread int64                  `json:"unread"` // 全部未读通知数
+	Items  []NotificationResponse `json:"items"`  // 通知列表
+}
+
+// NotificationPreferenceItem 一类事件的通知偏好
+type NotificationPreferenceItem struct {
+	Event    string   `json:"event" binding:"required,oneof=budget_threshold bill_due large_transaction import_completed"` // 事件类型
+	Enabled  bool     `json:"enabled"`                                                                                     // 是否产生通知
+	Channels []string `json:"channels" binding:"dive,oneof=email webhook push"`                                            // 站内通知之外的推送渠道
+}
+
+// UpdateNotificationPreferencesRequest 更新通知偏好请求，只更新列出的事件
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceItem `json:"preferences" binding:"required,min=1,dive"` // 各事件的通知偏好
+}
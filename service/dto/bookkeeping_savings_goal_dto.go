@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+)
+
+// CreateSavingsGoalRequest 创建储蓄目标请求
+type CreateSavingsGoalRequest struct {
+	Name         string     `json:"name" binding:"required,max=100"`                               // 目标名称
+	TargetAmount float64    `json:"target_amount" binding:"required,gt=0"`                         // 目标金额
+	StartDate    *time.Time `json:"start_date"`                                                    // 开始日期，默认今天
+	TargetDate   *time.Time `json:"target_date"`                                                   // 目标日期，为空表示不限期
+	AccountIDs   []uint     `json:"account_ids" binding:"required,min=1"`                          // 关联的资产账户
+	ProgressMode string     `json:"progress_mode" binding:"omitempty,oneof=balance contributions"` // 进度计算方式，默认 balance
+	Description  string     `json:"description" binding:"omitempty,max=255"`                       // 备注
+	IsActive     *bool      `json:"is_active"`                                                     // 是否激活
+}
+
+// UpdateSavingsGoalRequest 更新储蓄目标请求
+type UpdateSavingsGoalRequest struct {
+	Name            *string    `json:"name" binding:"omitempty,max=100"`                              // 目标名称
+	TargetAmount    *float64   `json:"target_amount" binding:"omitempty,gt=0"`                        // 目标金额
+	StartDate       *time.Time `json:"start_date"`                                                    // 开始日期
+	TargetDate      *time.Time `json:"target_date"`                                                   // 目标日期
+	ClearTargetDate bool       `json:"clear_target_date"`                                             // 为 true 时取消目标日期
+	AccountIDs      *[]uint    `json:"account_ids" binding:"omitempty,min=1"`                         // 关联的资产账户
+	ProgressMode    *string    `json:"progress_mode" binding:"omitempty,oneof=balance contributions"` // 进度计算方式
+	Description     *string    `json:"description" binding:"omitempty,max=255"`                       // 备注
+	IsActive        *bool      `json:"is_active"`                                                     // 是否激活
+}
+
+// SavingsGoalQuery 储蓄目标查询条件
+type SavingsGoalQuery struct {
+	IsActive *bool `form:"is_active"` // 是否激活
+}
+
+// SavingsGoalProgress 储蓄目标的进度
+type SavingsGoalProgress struct {
+	CurrentAmount               float64    `json:"current_amount"`                      // 当前已存金额 (按进度计算方式)
+	RemainingAmount             float64    `json:"remaining_amount"`                    // 距离目标的金额
+	Percentage                  float64    `json:"percentage"`                          // 完成比例 (0-1.0，超额完成时大于1)
+	IsAchieved                  bool       `json:"is_achieved"`                         // 是否已达成
+	NetContributions            float64    `json:"net_contributions"`                   // 开始日期以来关联账户的净存入
+	AverageMonthlyContribution  float64    `json:"average_monthly_contribution"`        // 开始日期以来平均每月净存入
+	DaysRemaining               *int       `json:"days_remaining,omitempty"`            // 距离目标日期的天数，已过期为0
+	RequiredMonthlyContribution float64    `json:"required_monthly_contribution"`       // 按期达成每月需要存入的金额，不限期时为0
+	ProjectedCompletionDate     *time.Time `json:"projected_completion_date,omitempty"` // 按平均存入速度预计达成的日期，无法达成时为空
+	OnTrack                     bool       `json:"on_track"`                            // 是否能按期达成 (已达成，或预计达成日期不晚于目标日期；不限期时能达成即可)
+}
+
+// SavingsGoalResponse 储蓄目标响应
+type SavingsGoalResponse struct {
+	ID           uint                `json:"id"`                    // 目标ID
+	Name         string              `json:"name"`                  // 目标名称
+	TargetAmount float64             `json:"target_amount"`         // 目标金额
+	StartDate    time.Time           `json:"start_date"`            // 开始日期
+	TargetDate   *time.Time          `json:"target_date,omitempty"` // 目标日期
+	AccountIDs   []uint              `json:"account_ids"`           // 关联的账户
+	ProgressMode string              `json:"progress_mode"`         // 进度计算方式
+	Description  string              `json:"description"`           // 备注
+	IsActive     bool                `json:"is_active"`             // 是否激活
+	CreatedAt    time.Time           `json:"created_at"`            // 创建时间
+	UpdatedAt    time.Time           `json:"updated_at"`            // 更新时间
+	Progress     SavingsGoalProgress `json:"progress"`              // 当前进度
+}
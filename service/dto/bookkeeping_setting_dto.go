@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+)
+
+// UpdateUserSettingRequest 更新记账偏好设置请求
+type UpdateUserSettingRequest struct {
+	MonthStartDay          *int     `json:"month_start_day" binding:"omitempty,min=0,max=28"`   // 每月起始日 (1-28)，0 表示按预算开始日期
+	EnvelopeMode           *bool    `json:"envelope_mode"`                                      // 是否开启信封预算 (零基预算) 模式
+	EnvelopeOpeningAmount  *float64 `json:"envelope_opening_amount" binding:"omitempty,gte=0"`  // 开启信封模式时已有的待分配金额
+	NotifyEmail            *string  `json:"notify_email" binding:"omitempty,email,max=100"`     // 接收邮件通知的地址，空字符串表示使用账号邮箱
+	NotifyWebhookURL       *string  `json:"notify_webhook_url" binding:"omitempty,url,max=255"` // 接收Webhook通知的地址，空字符串表示不推送
+	NotifyWebhookSecret    *string  `json:"notify_webhook_secret" binding:"omitempty,max=100"`  // Webhook签名密钥
+	LargeTransactionAmount *float64 `json:"large_transaction_amount" binding:"omitempty,gte=0"` // 大额交易通知的金额，0 表示不通知
+}
+
+// UserSettingResponse 记账偏好设置响应
+type UserSettingResponse struct {
+	MonthStartDay          int        `json:"month_start_day"`               // 每月起始日，0 表示按预算开始日期
+	EnvelopeMode           bool       `json:"envelope_mode"`                 // 是否开启信封预算模式
+	EnvelopeStartDate      *time.Time `json:"envelope_start_date,omitempty"` // 首次开启信封模式的月份，从该月起的收入计入待分配
+	EnvelopeOpeningAmount  float64    `json:"envelope_opening_amount"`       // 开启信封模式时已有的待分配金额
+	NotifyEmail            string     `json:"notify_email"`                  // 接收邮件通知的地址，为空时使用账号邮箱
+	NotifyWebhookURL       string     `json:"notify_webhook_url"`            // 接收Webhook通知的地址
+	HasWebhookSecret       bool       `json:"has_webhook_secret"`            // 是否设置了Webhook签名密钥
+	LargeTransactionAmount float64    `json:"large_transaction_amount"`      // 大额交易通知的金额，0 表示不通知
+}
@@ -0,0 +1,138 @@
+package dto
+
+// CreateSplitGroupRequest 创建AA账单组的请求体
+type CreateSplitGroupRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`                    // 名称
+	Description string   `json:"description,omitempty" binding:"omitempty,max=255"`  // 描述
+	SelfName    string   `json:"self_name,omitempty" binding:"omitempty,max=100"`    // 本人在组内的名称，默认为"我"
+	Members     []string `json:"members,omitempty" binding:"omitempty,dive,max=100"` // 其他成员名称
+}
+
+// UpdateSplitGroupRequest 更新AA账单组的请求体
+type UpdateSplitGroupRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,max=100"`        // 名称
+	Description *string `json:"description,omitempty" binding:"omitempty,max=255"` // 描述
+}
+
+// AddSplitMemberRequest 添加成员的请求体
+type AddSplitMemberRequest struct {
+	Name string `json:"name" binding:"required,max=100"` // 成员名称
+}
+
+// SplitMemberResponse 成员的响应体
+type SplitMemberResponse struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	IsSelf bool   `json:"is_self"`
+}
+
+// SplitGroupResponse AA账单组的响应体
+type SplitGroupResponse struct {
+	ID           uint                  `json:"id"`
+	Name         string                `json:"name"`
+	Description  string                `json:"description,omitempty"`
+	Members      []SplitMemberResponse `json:"members"`
+	TotalExpense float64               `json:"total_expense"` // 共同支出合计
+	SelfBalance  float64               `json:"self_balance"`  // 本人结余，正数表示别人欠我
+	CreatedAt    string                `json:"created_at"`
+}
+
+// SplitShareItem 单个成员的分摊参数
+type SplitShareItem struct {
+	MemberID uint    `json:"member_id" binding:"required"`    // 成员ID
+	Value    float64 `json:"value" binding:"omitempty,gte=0"` // 按份数分摊时为份数，按金额分摊时为金额，平均分摊时忽略
+}
+
+// CreateSplitExpenseRequest 记录共同支出的请求体
+type CreateSplitExpenseRequest struct {
+	Title         string           `json:"title" binding:"required,max=100"`                   // 标题
+	Amount        float64          `json:"amount" binding:"required,gt=0"`                     // 总金额
+	ExpenseDate   string           `json:"expense_date" binding:"required"`                    // 消费日期 (YYYY-MM-DD)
+	PayerMemberID uint             `json:"payer_member_id" binding:"required"`                 // 付款成员ID
+	Method        string           `json:"method" binding:"required,oneof=equal shares exact"` // 分摊方式
+	Shares        []SplitShareItem `json:"shares" binding:"required,min=1,dive"`               // 参与分摊的成员
+	CategoryID    uint             `json:"category_id,omitempty"`                              // 本人份额的支出分类ID，本人承担份额时必填
+	AccountID     uint             `json:"account_id,omitempty"`                               // 本人付款或记账的账户ID，为空时使用默认账户
+	Notes         string           `json:"notes,omitempty" binding:"omitempty,max=255"`        // 备注
+}
+
+// SplitShareResponse 成员份额的响应体
+type SplitShareResponse struct {
+	MemberID   uint    `json:"member_id"`
+	MemberName string  `json:"member_name"`
+	Weight     float64 `json:"weight,omitempty"`
+	Amount     float64 `json:"amount"`
+}
+
+// SplitExpenseResponse 共同支出的响应体
+type SplitExpenseResponse struct {
+	ID                   uint                 `json:"id"`
+	GroupID              uint                 `json:"group_id"`
+	Title                string               `json:"title"`
+	Amount               float64              `json:"amount"`
+	ExpenseDate          string               `json:"expense_date"`
+	PayerMemberID        uint                 `json:"payer_member_id"`
+	PayerName            string               `json:"payer_name"`
+	Method               string               `json:"method"`
+	CategoryID           uint                 `json:"category_id"`
+	AccountID            *uint                `json:"account_id,omitempty"`
+	OwnShare             float64              `json:"own_share"` // 本人承担的金额
+	OwnTransactionID     *uint                `json:"own_transaction_id,omitempty"`
+	AdvanceTransactionID *uint                `json:"advance_transaction_id,omitempty"`
+	OffsetTransactionID  *uint                `json:"offset_transaction_id,omitempty"`
+	Shares               []SplitShareResponse `json:"shares"`
+	Notes                string               `json:"notes,omitempty"`
+	CreatedAt            string               `json:"created_at"`
+}
+
+// CreateSplitSettlementRequest 记录结算的请求体
+type CreateSplitSettlementRequest struct {
+	FromMemberID   uint    `json:"from_member_id" binding:"required"`           // 付款成员ID
+	ToMemberID     uint    `json:"to_member_id" binding:"required"`             // 收款成员ID
+	Amount         float64 `json:"amount" binding:"required,gt=0"`              // 金额
+	SettlementDate string  `json:"settlement_date" binding:"required"`          // 结算日期 (YYYY-MM-DD)
+	AccountID      uint    `json:"account_id,omitempty"`                        // 本人收付款账户ID，涉及本人时必填
+	Notes          string  `json:"notes,omitempty" binding:"omitempty,max=255"` // 备注
+}
+
+// SplitSettlementResponse 结算记录的响应体
+type SplitSettlementResponse struct {
+	ID             uint    `json:"id"`
+	GroupID        uint    `json:"group_id"`
+	FromMemberID   uint    `json:"from_member_id"`
+	FromName       string  `json:"from_name"`
+	ToMemberID     uint    `json:"to_member_id"`
+	ToName         string  `json:"to_name"`
+	Amount         float64 `json:"amount"`
+	SettlementDate string  `json:"settlement_date"`
+	AccountID      *uint   `json:"account_id,omitempty"`
+	TransactionID  *uint   `json:"transaction_id,omitempty"`
+	Notes          string  `json:"notes,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// SplitMemberBalance 成员的收支结余
+type SplitMemberBalance struct {
+	MemberID   uint    `json:"member_id"`
+	MemberName string  `json:"member_name"`
+	IsSelf     bool    `json:"is_self"`
+	Paid       float64 `json:"paid"`    // 垫付合计
+	Share      float64 `json:"share"`   // 应承担合计
+	Balance    float64 `json:"balance"` // 结余 (含已结算)，正数表示别人欠他，负数表示他欠别人
+}
+
+// SplitTransfer 建议的结算转账
+type SplitTransfer struct {
+	FromMemberID uint    `json:"from_member_id"`
+	FromName     string  `json:"from_name"`
+	ToMemberID   uint    `json:"to_member_id"`
+	ToName       string  `json:"to_name"`
+	Amount       float64 `json:"amount"`
+}
+
+// SplitBalanceResponse 账单组结余和最少结算转账
+type SplitBalanceResponse struct {
+	GroupID   uint                 `json:"group_id"`
+	Balances  []SplitMemberBalance `json:"balances"`
+	Transfers []SplitTransfer      `json:"transfers"` // 结清所有欠款所需的最少转账
+}
@@ -12,22 +12,63 @@ type IncomeExpenseSummaryResponse struct {
 	RangeType    string    `json:"range_type"`     // 时间范围类型（day, week, month, year, all, custom）
 }
 
-// CategorySummaryItem 分类汇总项
+// CategorySummaryItem 分类汇总项，按分类树组织，父分类的汇总包含所有子分类
 type CategorySummaryItem struct {
-	CategoryID       uint    `json:"category_id"`        // 分类ID
-	CategoryName     string  `json:"category_name"`      // 分类名称
-	CategoryIcon     string  `json:"category_icon"`      // 分类图标
-	TotalAmount      float64 `json:"total_amount"`       // 总金额
-	TransactionCount int     `json:"transaction_count"`  // 交易笔数
+	CategoryID          uint                   `json:"category_id"`           // 分类ID
+	ParentID            *uint                  `json:"parent_id,omitempty"`   // 父分类ID
+	CategoryName        string                 `json:"category_name"`         // 分类名称
+	CategoryIcon        string                 `json:"category_icon"`         // 分类图标
+	OwnAmount           float64                `json:"own_amount"`            // 直接记在该分类下的金额
+	TotalAmount         float64                `json:"total_amount"`          // 该分类及所有子分类的总金额
+	Share               float64                `json:"share"`                 // 总金额占所有分类合计的比例 (0-1.0)
+	OwnTransactionCount int                    `json:"own_transaction_count"` // 直接记在该分类下的交易笔数
+	TransactionCount    int                    `json:"transaction_count"`     // 该分类及所有子分类的交易笔数
+	Children            []*CategorySummaryItem `json:"children,omitempty"`    // 有交易的子分类，按总金额降序
 }
 
 // AccountSummaryItem 账户汇总项
 type AccountSummaryItem struct {
-	AccountID      uint    `json:"account_id"`       // 账户ID
-	AccountName    string  `json:"account_name"`     // 账户名称
-	AccountType    string  `json:"account_type"`     // 账户类型
-	CurrentBalance float64 `json:"current_balance"`  // 当前余额
-	InitialBalance float64 `json:"initial_balance"`  // 初始余额
+	AccountID           uint    `json:"account_id"`             // 账户ID
+	AccountName         string  `json:"account_name"`           // 账户名称
+	AccountType         string  `json:"account_type"`           // 账户类型
+	CurrentBalance      float64 `json:"current_balance"`        // 当前余额
+	InitialBalance      float64 `json:"initial_balance"`        // 初始余额
+	Nature              string  `json:"nature"`                 // 账户性质 (asset, liability)
+	ExcludeFromNetWorth bool    `json:"exclude_from_net_worth"` // 是否不计入净资产
+}
+
+// NetWorthAccountItem 净资产构成中的单个账户
+type NetWorthAccountItem struct {
+	AccountID   uint    `json:"account_id"`   // 账户ID
+	AccountName string  `json:"account_name"` // 账户名称
+	AccountType string  `json:"account_type"` // 账户类型
+	Nature      string  `json:"nature"`       // 账户性质 (asset, liability)
+	Balance     float64 `json:"balance"`      // 账户余额 (负债账户为负数)
+	MarketValue float64 `json:"market_value"` // 持仓市值 (仅投资账户)
+	Value       float64 `json:"value"`        // 计入资产或负债的金额 (负债为欠款金额，投资账户含持仓市值)
+}
+
+// NetWorthResponse 净资产响应
+type NetWorthResponse struct {
+	TotalAssets      float64                `json:"total_assets"`      // 总资产
+	TotalLiabilities float64                `json:"total_liabilities"` // 总负债
+	NetWorth         float64                `json:"net_worth"`         // 净资产（总资产-总负债）
+	Assets           []*NetWorthAccountItem `json:"assets"`            // 资产账户明细
+	Liabilities      []*NetWorthAccountItem `json:"liabilities"`       // 负债账户明细
+}
+
+// NetWorthMonthlyData 月度净资产数据
+type NetWorthMonthlyData struct {
+	MonthLabel       string  `json:"month_label"`       // 月份标签，格式：YYYY-MM
+	TotalAssets      float64 `json:"total_assets"`      // 月末总资产
+	TotalLiabilities float64 `json:"total_liabilities"` // 月末总负债
+	NetWorth         float64 `json:"net_worth"`         // 月末净资产
+}
+
+// NetWorthTrendResponse 净资产趋势响应
+type NetWorthTrendResponse struct {
+	MonthsCount int                    `json:"months_count"` // 查询的月份数量
+	Data        []*NetWorthMonthlyData `json:"data"`         // 月度数据列表（当月为截至今天的数据）
 }
 
 // MonthlyData 月度数据
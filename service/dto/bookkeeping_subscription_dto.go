@@ -0,0 +1,95 @@
+package dto
+
+// SubscriptionCandidateQuery 检测候选订阅的查询条件
+type SubscriptionCandidateQuery struct {
+	Months int `form:"months" binding:"omitempty,min=3,max=60"` // 分析最近几个月的交易，默认24
+}
+
+// SubscriptionPriceIncrease 订阅涨价信息
+type SubscriptionPriceIncrease struct {
+	PreviousAmount float64 `json:"previous_amount"` // 涨价前的扣费金额
+	CurrentAmount  float64 `json:"current_amount"`  // 当前扣费金额
+	IncreaseAmount float64 `json:"increase_amount"` // 每期上涨金额
+	IncreaseRate   float64 `json:"increase_rate"`   // 上涨比例 (0.2 表示上涨20%)
+	ChangedOn      string  `json:"changed_on"`      // 第一次按新价格扣费的日期
+}
+
+// SubscriptionCandidate 从交易记录中检测出的候选订阅
+type SubscriptionCandidate struct {
+	Payee            string                     `json:"payee"`              // 收款方 (最近一次扣费记录中的写法)，确认或忽略时使用
+	Frequency        string                     `json:"frequency"`          // 扣费频率 (weekly, monthly, quarterly, yearly)
+	IntervalDays     float64                    `json:"interval_days"`      // 平均扣费间隔天数
+	Amount           float64                    `json:"amount"`             // 最近一次扣费金额
+	AverageAmount    float64                    `json:"average_amount"`     // 平均扣费金额
+	MonthlyCost      float64                    `json:"monthly_cost"`       // 按最近一次扣费金额估算的每月费用
+	AnnualCost       float64                    `json:"annual_cost"`        // 按最近一次扣费金额估算的每年费用
+	ChargeCount      int                        `json:"charge_count"`       // 分析范围内的扣费次数
+	FirstChargeDate  string                     `json:"first_charge_date"`  // 分析范围内第一次扣费日期
+	LastChargeDate   string                     `json:"last_charge_date"`   // 最近一次扣费日期
+	NextExpectedDate string                     `json:"next_expected_date"` // 下次预计扣费日期
+	CategoryID       uint                       `json:"category_id"`        // 最近一次扣费的分类
+	CategoryName     string                     `json:"category_name"`
+	AccountID        uint                       `json:"account_id"` // 最近一次扣费的账户
+	AccountName      string                     `json:"account_name"`
+	PriceIncrease    *SubscriptionPriceIncrease `json:"price_increase,omitempty"` // 检测到涨价时返回
+}
+
+// SubscriptionCandidateListResponse 候选订阅列表的响应体
+type SubscriptionCandidateListResponse struct {
+	Months       int                     `json:"months"`        // 分析的月数
+	MonthlyTotal float64                 `json:"monthly_total"` // 全部候选订阅的每月费用合计
+	AnnualTotal  float64                 `json:"annual_total"`  // 全部候选订阅的每年费用合计
+	Items        []SubscriptionCandidate `json:"items"`
+}
+
+// ConfirmSubscriptionRequest 确认候选订阅的请求体
+type ConfirmSubscriptionRequest struct {
+	Payee string `json:"payee" binding:"required,max=100"`            // 候选订阅的收款方
+	Name  string `json:"name,omitempty" binding:"omitempty,max=100"`  // 订阅名称，为空时使用收款方
+	Notes string `json:"notes,omitempty" binding:"omitempty,max=255"` // 备注
+}
+
+// DismissSubscriptionRequest 忽略候选订阅的请求体
+type DismissSubscriptionRequest struct {
+	Payee string `json:"payee" binding:"required,max=100"` // 候选订阅的收款方
+}
+
+// UpdateSubscriptionRequest 更新订阅的请求体
+type UpdateSubscriptionRequest struct {
+	Name  *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Notes *string `json:"notes,omitempty" binding:"omitempty,max=255"`
+}
+
+// SubscriptionQuery 订阅列表查询条件
+type SubscriptionQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=tracked dismissed"` // 状态，默认 tracked
+}
+
+// SubscriptionResponse 已确认或已忽略的订阅的响应体
+// 扣费相关字段从最近24个月的交易记录重新分析，没有扣费记录时为空
+type SubscriptionResponse struct {
+	ID               uint                       `json:"id"`
+	Name             string                     `json:"name"`
+	Payee            string                     `json:"payee"`
+	Status           string                     `json:"status"`
+	Frequency        string                     `json:"frequency"`
+	Amount           float64                    `json:"amount"` // 当前扣费金额 (最近一次扣费，没有扣费记录时为确认时的金额)
+	MonthlyCost      float64                    `json:"monthly_cost"`
+	AnnualCost       float64                    `json:"annual_cost"`
+	CategoryID       uint                       `json:"category_id"`
+	AccountID        uint                       `json:"account_id"`
+	ChargeCount      int                        `json:"charge_count"`
+	LastChargeDate   string                     `json:"last_charge_date,omitempty"`
+	NextExpectedDate string                     `json:"next_expected_date,omitempty"`
+	IsLapsed         bool                       `json:"is_lapsed"` // 超过两个扣费周期没有扣费，可能已取消
+	PriceIncrease    *SubscriptionPriceIncrease `json:"price_increase,omitempty"`
+	Notes            string                     `json:"notes,omitempty"`
+	CreatedAt        string                     `json:"created_at"`
+}
+
+// SubscriptionListResponse 订阅列表的响应体
+type SubscriptionListResponse struct {
+	MonthlyTotal float64                `json:"monthly_total"` // 未失效订阅的每月费用合计
+	AnnualTotal  float64                `json:"annual_total"`  // 未失效订阅的每年费用合计
+	Items        []SubscriptionResponse `json:"items"`
+}
@@ -13,6 +13,7 @@ type CreateTransactionRequest struct {
 	CategoryID      uint                  `json:"category_id" binding:"required"`                        // 分类ID
 	PayeePayer      string                `json:"payee_payer,omitempty" binding:"omitempty,max=100"`     // 收款方/付款方
 	Notes           string                `json:"notes,omitempty" binding:"omitempty,max=255"`           // 备注
+	Tags            []string              `json:"tags,omitempty" binding:"omitempty,dive,max=50"`        // 标签
 }
 
 // UpdateTransactionRequest 更新交易流水的请求体
@@ -24,21 +25,26 @@ type UpdateTransactionRequest struct {
 	CategoryID      *uint                  `json:"category_id,omitempty"`                                            // 分类ID
 	PayeePayer      *string                `json:"payee_payer,omitempty" binding:"omitempty,max=100"`                // 收款方/付款方
 	Notes           *string                `json:"notes,omitempty" binding:"omitempty,max=255"`                      // 备注
+	Tags            *[]string              `json:"tags,omitempty" binding:"omitempty,dive,max=50"`                   // 标签，传空数组表示清除
 }
 
 // TransactionResponse 单个交易流水的响应体
 type TransactionResponse struct {
-	ID              uint                  `json:"id"`
-	AccountID       uint                  `json:"account_id"`
-	Type            model.TransactionType `json:"type"`
-	Amount          float64               `json:"amount"`
-	TransactionDate string                `json:"transaction_date"` // 格式化为 YYYY-MM-DD
-	CategoryID      uint                  `json:"category_id"`
-	PayeePayer      string                `json:"payee_payer,omitempty"`
-	Notes           string                `json:"notes,omitempty"`
-	CreatedAt       string                `json:"created_at"`
-	UpdatedAt       string                `json:"updated_at"`
-	UserID          uint                  `json:"user_id"`
+	ID               uint                  `json:"id"`
+	AccountID        uint                  `json:"account_id"`
+	Type             model.TransactionType `json:"type"`
+	Amount           float64               `json:"amount"`
+	TransactionDate  string                `json:"transaction_date"` // 格式化为 YYYY-MM-DD
+	CategoryID       uint                  `json:"category_id"`
+	PayeePayer       string                `json:"payee_payer,omitempty"`
+	Notes            string                `json:"notes,omitempty"`
+	Tags             []string              `json:"tags,omitempty"`
+	CreatedAt        string                `json:"created_at"`
+	UpdatedAt        string                `json:"updated_at"`
+	UserID           uint                  `json:"user_id"`
+	ExcludeFromStats bool                  `json:"exclude_from_stats"`        // 是否不计入收支统计 (系统生成的资金流水)
+	RunningBalance   *float64              `json:"running_balance,omitempty"` // 该笔交易后的账户余额 (仅按账户筛选时返回)
+	BudgetImpact     []BudgetImpact        `json:"budget_impact,omitempty"`   // 本次写入使预算跨过的阈值 (仅创建和更新时返回)
 
 	// 关联信息
 	Account  AccountResponse  `json:"account,omitempty"`
@@ -51,6 +57,7 @@ type TransactionQuery struct {
 	PageSize   int    `json:"page_size"`
 	AccountID  uint   `json:"account_id,omitempty"`
 	CategoryID uint   `json:"category_id,omitempty"`
+	Tag        string `json:"tag,omitempty"`
 	Type       string `json:"type,omitempty"`
 	StartDate  string `json:"start_date,omitempty"`
 	EndDate    string `json:"end_date,omitempty"`
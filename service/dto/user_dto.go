@@ -5,6 +5,7 @@ type AddUserDto struct {
 	Password string `json:"password" form:"password" binding:"required"`
 	Email    string `json:"email" form:"email" binding:"required"`
 	Phone    string `json:"phone" form:"phone" binding:"required"`
+	Locale   string `json:"locale" form:"locale"` // 语言 (可选)，用于选择默认分类模板的语言版本
 }
 
 type LoginUserDto struct {
@@ -21,7 +21,15 @@ func (u *UserInfoService) AddUser(user *dto.AddUserDto) error {
 		return errors.New("adding user field mapping failed")
 	}
 
-	return global.DB.Model(&model.UserInfo{}).Create(&userInfo).Error
+	if err := global.DB.Model(&model.UserInfo{}).Create(&userInfo).Error; err != nil {
+		return err
+	}
+
+	// 按默认分类模板为新用户创建分类，失败不影响注册，用户之后可以手动应用模板
+	if err := new(BookkeepingCategoryTemplateService).ApplyDefaultTemplate(userInfo.ID, user.Locale); err != nil {
+		global.Logger.Warn("Failed to apply default category template for new user: " + err.Error())
+	}
+	return nil
 }
 
 // GetUserById 获取用户详情